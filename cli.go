@@ -0,0 +1,642 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"mcp-atlassian-server/pkg/utils"
+)
+
+const cliUsage = `Usage:
+  %[1]s                      Serve MCP over stdio (or HTTP/SSE, see MCP_HTTP and MCP_SSE)
+  %[1]s list-tools [--format table|markdown|json]
+  %[1]s call <tool> [--arg name=value]... [--json '{...}'] [--format text|json|markdown|table]
+  %[1]s completion bash|zsh|fish
+`
+
+var (
+	callFormats = []string{"text", "json", "markdown", "table"}
+	listFormats = []string{"table", "markdown", "json"}
+)
+
+// runCLI executes a CLI subcommand against the tools registered on s and
+// returns the process exit code.
+func runCLI(ctx context.Context, s *server.MCPServer, args []string, stdout, stderr io.Writer) int {
+	prog := filepath.Base(os.Args[0])
+	var err error
+	switch args[0] {
+	case "list-tools":
+		err = listToolsCmd(ctx, s, args[1:], stdout, stderr)
+	case "call":
+		err = callCmd(ctx, s, args[1:], stdout, stderr)
+	case "completion":
+		err = completionCmd(ctx, s, prog, args[1:], stdout)
+	case "help", "-h", "--help":
+		fmt.Fprintf(stdout, cliUsage, prog)
+		return 0
+	default:
+		fmt.Fprintf(stderr, "Unknown command %q\n\n"+cliUsage, args[0], prog)
+		return 2
+	}
+	switch {
+	case err == errToolFailed:
+		return 1
+	case err == flag.ErrHelp:
+		return 0
+	case err != nil:
+		fmt.Fprintln(stderr, "Error:", err)
+		return 1
+	}
+	return 0
+}
+
+// errToolFailed signals that a tool returned an error result, which has
+// already been printed.
+var errToolFailed = fmt.Errorf("tool failed")
+
+// cliTools returns the registered tools allowed by ENABLED_TOOLS and
+// DISABLED_TOOLS, sorted by name.
+func cliTools(ctx context.Context, s *server.MCPServer) []server.ServerTool {
+	registered := s.ListTools()
+	var tools []mcp.Tool
+	for _, t := range registered {
+		tools = append(tools, t.Tool)
+	}
+	var out []server.ServerTool
+	for _, t := range toolFilter(ctx, tools) {
+		out = append(out, *registered[t.Name])
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Tool.Name < out[j].Tool.Name })
+	return out
+}
+
+// argList collects repeated --arg flags.
+type argList []string
+
+func (a *argList) String() string     { return strings.Join(*a, ",") }
+func (a *argList) Set(v string) error { *a = append(*a, v); return nil }
+
+// parseInterspersed parses flags that may appear before or after positional arguments.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+func checkFormat(format string, allowed []string) error {
+	for _, f := range allowed {
+		if format == f {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid format %q: must be one of %s", format, strings.Join(allowed, ", "))
+}
+
+func listToolsCmd(ctx context.Context, s *server.MCPServer, args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("list-tools", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	format := fs.String("format", "table", "Output format: "+strings.Join(listFormats, ", "))
+	if _, err := parseInterspersed(fs, args); err != nil {
+		return err
+	}
+	if err := checkFormat(*format, listFormats); err != nil {
+		return err
+	}
+	tools := cliTools(ctx, s)
+	switch *format {
+	case "json":
+		var schemas []mcp.Tool
+		for _, t := range tools {
+			schemas = append(schemas, t.Tool)
+		}
+		return writeJSON(stdout, schemas)
+	case "markdown":
+		for _, t := range tools {
+			fmt.Fprintf(stdout, "## %s\n\n%s\n\n", t.Tool.Name, t.Tool.Description)
+			params := toolParams(t.Tool)
+			if len(params) == 0 {
+				continue
+			}
+			fmt.Fprintln(stdout, "| Argument | Type | Required | Description |")
+			fmt.Fprintln(stdout, "| --- | --- | --- | --- |")
+			for _, p := range params {
+				fmt.Fprintf(stdout, "| `%s` | %s | %s | %s |\n", p.name, p.typ, yesNo(p.required), markdownCell(p.description))
+			}
+			fmt.Fprintln(stdout)
+		}
+	default:
+		w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tDESCRIPTION")
+		for _, t := range tools {
+			desc, _, _ := strings.Cut(t.Tool.Description, "\n")
+			fmt.Fprintf(w, "%s\t%s\n", t.Tool.Name, desc)
+		}
+		return w.Flush()
+	}
+	return nil
+}
+
+type toolParam struct {
+	name        string
+	typ         string
+	description string
+	required    bool
+}
+
+// toolParams returns the input parameters of tool sorted by name.
+func toolParams(tool mcp.Tool) []toolParam {
+	required := map[string]bool{}
+	for _, r := range tool.InputSchema.Required {
+		required[r] = true
+	}
+	var params []toolParam
+	for name, raw := range tool.InputSchema.Properties {
+		p := toolParam{name: name, typ: "string", required: required[name]}
+		if prop, ok := raw.(map[string]any); ok {
+			if typ, ok := prop["type"].(string); ok {
+				p.typ = typ
+			}
+			p.description, _ = prop["description"].(string)
+		}
+		params = append(params, p)
+	}
+	sort.Slice(params, func(i, j int) bool { return params[i].name < params[j].name })
+	return params
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+func callCmd(ctx context.Context, s *server.MCPServer, args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("call", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var kv argList
+	fs.Var(&kv, "arg", "Tool argument as name=value (repeatable)")
+	jsonArgs := fs.String("json", "", "Tool arguments as a JSON object")
+	format := fs.String("format", "text", "Output format: "+strings.Join(callFormats, ", "))
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return fmt.Errorf("call requires exactly one tool name")
+	}
+	if err := checkFormat(*format, callFormats); err != nil {
+		return err
+	}
+
+	var tool *server.ServerTool
+	for _, t := range cliTools(ctx, s) {
+		if t.Tool.Name == positional[0] {
+			tool = &t
+			break
+		}
+	}
+	if tool == nil {
+		return fmt.Errorf("unknown tool %q; run list-tools to see the available tools", positional[0])
+	}
+	arguments, err := buildArguments(tool.Tool, *jsonArgs, kv)
+	if err != nil {
+		return err
+	}
+
+	req := mcp.CallToolRequest{}
+	req.Params.Name = tool.Tool.Name
+	req.Params.Arguments = arguments
+	result, err := tool.Handler(ctx, req)
+	if err != nil {
+		return err
+	}
+	var texts []string
+	for _, content := range result.Content {
+		if tc, ok := content.(mcp.TextContent); ok {
+			texts = append(texts, tc.Text)
+		}
+	}
+	text := strings.Join(texts, "\n")
+	if result.IsError {
+		fmt.Fprintln(stderr, text)
+		return errToolFailed
+	}
+	return renderResult(stdout, text, *format)
+}
+
+// buildArguments merges --json and --arg values, converting --arg values to
+// the types declared in the tool's input schema.
+func buildArguments(tool mcp.Tool, jsonArgs string, kv []string) (map[string]any, error) {
+	arguments := map[string]any{}
+	if jsonArgs != "" {
+		if err := json.Unmarshal([]byte(jsonArgs), &arguments); err != nil {
+			return nil, fmt.Errorf("invalid --json arguments: %w", err)
+		}
+	}
+	types := map[string]string{}
+	for _, p := range toolParams(tool) {
+		types[p.name] = p.typ
+	}
+	for _, pair := range kv {
+		name, raw, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid --arg %q, expected name=value", pair)
+		}
+		typ, known := types[name]
+		if !known {
+			return nil, fmt.Errorf("unknown argument %q for %s", name, tool.Name)
+		}
+		value, err := convertArg(typ, raw)
+		if err != nil {
+			return nil, fmt.Errorf("argument %s: %w", name, err)
+		}
+		arguments[name] = value
+	}
+	for _, r := range tool.InputSchema.Required {
+		if _, ok := arguments[r]; !ok {
+			return nil, fmt.Errorf("missing required argument %q for %s", r, tool.Name)
+		}
+	}
+	return arguments, nil
+}
+
+func convertArg(typ, raw string) (any, error) {
+	switch typ {
+	case "number", "integer":
+		return strconv.ParseFloat(raw, 64)
+	case "boolean":
+		return strconv.ParseBool(raw)
+	case "array":
+		if strings.HasPrefix(strings.TrimSpace(raw), "[") {
+			var v []any
+			err := json.Unmarshal([]byte(raw), &v)
+			return v, err
+		}
+		var v []any
+		for _, item := range utils.SplitAndTrim(raw) {
+			v = append(v, item)
+		}
+		return v, nil
+	case "object":
+		var v map[string]any
+		err := json.Unmarshal([]byte(raw), &v)
+		return v, err
+	}
+	return raw, nil
+}
+
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// renderResult prints a tool's text output in the requested format. Outputs
+// that are not JSON are printed as-is except in json format.
+func renderResult(w io.Writer, text, format string) error {
+	var v any
+	if format == "text" || json.Unmarshal([]byte(text), &v) != nil {
+		if format == "json" {
+			return writeJSON(w, text)
+		}
+		_, err := fmt.Fprintln(w, text)
+		return err
+	}
+	switch format {
+	case "json":
+		return writeJSON(w, v)
+	case "markdown":
+		return renderMarkdown(w, v)
+	}
+	return renderTable(w, v)
+}
+
+// listKeys are the fields holding the items of paginated Atlassian responses.
+var listKeys = []string{"issues", "values", "results", "comments", "worklogs", "transitions"}
+
+// preferredColumns are shown first, in this order, when present.
+var preferredColumns = []string{"key", "id", "name", "title", "summary", "status", "issuetype", "assignee", "displayName"}
+
+// skippedColumns carry links and presentation data that are noise in a table.
+var skippedColumns = map[string]bool{"self": true, "expand": true, "avatarUrls": true, "_links": true, "_expandable": true}
+
+// resultRows extracts the list of objects in v, either v itself or its single list field.
+func resultRows(v any) ([]map[string]any, bool) {
+	switch x := v.(type) {
+	case []any:
+		return objectRows(x)
+	case map[string]any:
+		for _, k := range listKeys {
+			if items, ok := x[k].([]any); ok {
+				return objectRows(items)
+			}
+		}
+		var found []any
+		for _, item := range x {
+			if items, ok := item.([]any); ok {
+				if found != nil {
+					return nil, false
+				}
+				found = items
+			}
+		}
+		if found != nil {
+			return objectRows(found)
+		}
+	}
+	return nil, false
+}
+
+func objectRows(items []any) ([]map[string]any, bool) {
+	rows := make([]map[string]any, 0, len(items))
+	for _, item := range items {
+		m, ok := item.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		rows = append(rows, flattenFields(m))
+	}
+	return rows, true
+}
+
+// flattenFields lifts the entries of a Jira "fields" object to the top level.
+func flattenFields(m map[string]any) map[string]any {
+	fields, ok := m["fields"].(map[string]any)
+	if !ok {
+		return m
+	}
+	out := map[string]any{}
+	for k, v := range m {
+		if k != "fields" {
+			out[k] = v
+		}
+	}
+	for k, v := range fields {
+		if _, exists := out[k]; !exists {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+func columns(rows []map[string]any) []string {
+	seen := map[string]bool{}
+	var rest []string
+	for _, row := range rows {
+		for k, v := range row {
+			if !seen[k] && !skippedColumns[k] && v != nil {
+				seen[k] = true
+				rest = append(rest, k)
+			}
+		}
+	}
+	sort.Strings(rest)
+	var cols []string
+	for _, c := range preferredColumns {
+		if seen[c] {
+			cols = append(cols, c)
+		}
+	}
+	for _, c := range rest {
+		if !contains(preferredColumns, c) {
+			cols = append(cols, c)
+		}
+	}
+	return cols
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// cellValue renders a JSON value for a single table cell, using the display
+// name of Atlassian objects where there is one.
+func cellValue(v any) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return x
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(x)
+	case map[string]any:
+		for _, k := range []string{"displayName", "name", "key", "value", "title", "id"} {
+			if s, ok := x[k].(string); ok && s != "" {
+				return s
+			}
+		}
+	case []any:
+		parts := make([]string, 0, len(x))
+		for _, item := range x {
+			parts = append(parts, cellValue(item))
+		}
+		return strings.Join(parts, ", ")
+	}
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+var whitespaceRe = regexp.MustCompile(`\s+`)
+
+func tableCell(v any) string {
+	s := whitespaceRe.ReplaceAllString(cellValue(v), " ")
+	if len(s) > 60 {
+		s = s[:57] + "..."
+	}
+	return s
+}
+
+func markdownCell(s string) string {
+	s = whitespaceRe.ReplaceAllString(s, " ")
+	return strings.ReplaceAll(s, "|", `\|`)
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		if !skippedColumns[k] {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func renderTable(w io.Writer, v any) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if rows, ok := resultRows(v); ok {
+		cols := columns(rows)
+		fmt.Fprintln(tw, strings.ToUpper(strings.Join(cols, "\t")))
+		for _, row := range rows {
+			cells := make([]string, len(cols))
+			for i, c := range cols {
+				cells[i] = tableCell(row[c])
+			}
+			fmt.Fprintln(tw, strings.Join(cells, "\t"))
+		}
+	} else if m, ok := v.(map[string]any); ok {
+		m = flattenFields(m)
+		fmt.Fprintln(tw, "FIELD\tVALUE")
+		for _, k := range sortedKeys(m) {
+			fmt.Fprintf(tw, "%s\t%s\n", k, tableCell(m[k]))
+		}
+	} else {
+		fmt.Fprintln(tw, cellValue(v))
+	}
+	return tw.Flush()
+}
+
+func renderMarkdown(w io.Writer, v any) error {
+	if rows, ok := resultRows(v); ok {
+		cols := columns(rows)
+		fmt.Fprintf(w, "| %s |\n", strings.Join(cols, " | "))
+		fmt.Fprintf(w, "|%s\n", strings.Repeat(" --- |", len(cols)))
+		for _, row := range rows {
+			cells := make([]string, len(cols))
+			for i, c := range cols {
+				cells[i] = markdownCell(cellValue(row[c]))
+			}
+			fmt.Fprintf(w, "| %s |\n", strings.Join(cells, " | "))
+		}
+		return nil
+	}
+	if m, ok := v.(map[string]any); ok {
+		m = flattenFields(m)
+		fmt.Fprintln(w, "| Field | Value |")
+		fmt.Fprintln(w, "| --- | --- |")
+		for _, k := range sortedKeys(m) {
+			fmt.Fprintf(w, "| %s | %s |\n", k, markdownCell(cellValue(m[k])))
+		}
+		return nil
+	}
+	_, err := fmt.Fprintln(w, cellValue(v))
+	return err
+}
+
+var nonIdentRe = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+func completionCmd(ctx context.Context, s *server.MCPServer, prog string, args []string, w io.Writer) error {
+	if len(args) != 1 {
+		return fmt.Errorf("completion requires a shell: bash, zsh or fish")
+	}
+	tools := cliTools(ctx, s)
+	switch args[0] {
+	case "bash":
+		writeBashCompletion(w, prog, tools)
+	case "zsh":
+		fmt.Fprintln(w, "autoload -U +X bashcompinit && bashcompinit")
+		writeBashCompletion(w, prog, tools)
+	case "fish":
+		writeFishCompletion(w, prog, tools)
+	default:
+		return fmt.Errorf("unsupported shell %q: must be bash, zsh or fish", args[0])
+	}
+	return nil
+}
+
+func toolNames(tools []server.ServerTool) string {
+	names := make([]string, len(tools))
+	for i, t := range tools {
+		names[i] = t.Tool.Name
+	}
+	return strings.Join(names, " ")
+}
+
+func argWords(tool mcp.Tool) string {
+	var words []string
+	for _, p := range toolParams(tool) {
+		words = append(words, p.name+"=")
+	}
+	return strings.Join(words, " ")
+}
+
+func writeBashCompletion(w io.Writer, prog string, tools []server.ServerTool) {
+	fn := "_" + nonIdentRe.ReplaceAllString(prog, "_")
+	fmt.Fprintf(w, `%s() {
+  local cur="${COMP_WORDS[COMP_CWORD]}" prev="${COMP_WORDS[COMP_CWORD-1]}"
+  if [[ $COMP_CWORD -eq 1 ]]; then
+    COMPREPLY=($(compgen -W "list-tools call completion help" -- "$cur"))
+    return
+  fi
+  case "${COMP_WORDS[1]}" in
+  list-tools)
+    if [[ $prev == --format ]]; then
+      COMPREPLY=($(compgen -W "%s" -- "$cur"))
+    else
+      COMPREPLY=($(compgen -W "--format" -- "$cur"))
+    fi
+    ;;
+  completion)
+    COMPREPLY=($(compgen -W "bash zsh fish" -- "$cur"))
+    ;;
+  call)
+    if [[ $COMP_CWORD -eq 2 ]]; then
+      COMPREPLY=($(compgen -W "%s" -- "$cur"))
+      return
+    fi
+    case "$prev" in
+    --format)
+      COMPREPLY=($(compgen -W "%s" -- "$cur"))
+      ;;
+    --arg)
+      case "${COMP_WORDS[2]}" in
+`, fn, strings.Join(listFormats, " "), toolNames(tools), strings.Join(callFormats, " "))
+	for _, t := range tools {
+		fmt.Fprintf(w, "      %s) COMPREPLY=($(compgen -W \"%s\" -- \"$cur\")) ;;\n", t.Tool.Name, argWords(t.Tool))
+	}
+	fmt.Fprintf(w, `      esac
+      compopt -o nospace 2>/dev/null
+      ;;
+    --json) ;;
+    *)
+      COMPREPLY=($(compgen -W "--arg --json --format" -- "$cur"))
+      ;;
+    esac
+    ;;
+  esac
+}
+complete -F %s %s
+`, fn, prog)
+}
+
+func writeFishCompletion(w io.Writer, prog string, tools []server.ServerTool) {
+	fmt.Fprintf(w, "complete -c %s -f\n", prog)
+	fmt.Fprintf(w, "complete -c %s -n __fish_use_subcommand -a 'list-tools call completion help'\n", prog)
+	fmt.Fprintf(w, "complete -c %s -n '__fish_seen_subcommand_from completion' -a 'bash zsh fish'\n", prog)
+	fmt.Fprintf(w, "complete -c %s -n '__fish_seen_subcommand_from list-tools' -l format -xa '%s'\n", prog, strings.Join(listFormats, " "))
+	fmt.Fprintf(w, "complete -c %s -n '__fish_seen_subcommand_from call' -l format -xa '%s'\n", prog, strings.Join(callFormats, " "))
+	fmt.Fprintf(w, "complete -c %s -n '__fish_seen_subcommand_from call' -l json -x\n", prog)
+	fmt.Fprintf(w, "complete -c %s -n '__fish_seen_subcommand_from call; and test (count (commandline -opc)) -eq 2' -a '%s'\n", prog, toolNames(tools))
+	for _, t := range tools {
+		fmt.Fprintf(w, "complete -c %s -n '__fish_seen_subcommand_from %s' -l arg -xa '%s'\n", prog, t.Tool.Name, argWords(t.Tool))
+	}
+}
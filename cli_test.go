@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"mcp-atlassian-server/pkg/fakeatlassian"
+)
+
+func runCLITest(t *testing.T, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := runCLI(context.Background(), newServer(), args, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestCLICall(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		wantCode int
+		stdout   []string
+		stderr   []string
+	}{
+		{
+			name:   "text output",
+			args:   []string{"call", "jira_ping"},
+			stdout: []string{"Jira OK"},
+		},
+		{
+			name:   "arg flags are typed from the schema",
+			args:   []string{"call", "jira_search", "--arg", "jql=project = PROJ", "--arg", "limit=2", "--format", "table"},
+			stdout: []string{"KEY", "SUMMARY", "PROJ-1", "PROJ-2"},
+		},
+		{
+			name:   "json arguments with markdown output",
+			args:   []string{"call", "jira_get_issue", "--json", `{"issue_key":"PROJ-2"}`, "--format", "markdown"},
+			stdout: []string{"| Field | Value |", "| key | PROJ-2 |", "| status | In Progress |"},
+		},
+		{
+			name:     "tool error",
+			args:     []string{"call", "jira_get_issue", "--arg", "issue_key=PROJ-99"},
+			wantCode: 1,
+			stderr:   []string{"HTTP 404"},
+		},
+		{
+			name:     "unknown argument",
+			args:     []string{"call", "jira_get_issue", "--arg", "nope=1"},
+			wantCode: 1,
+			stderr:   []string{`unknown argument "nope" for jira_get_issue`},
+		},
+		{
+			name:     "missing required argument",
+			args:     []string{"call", "jira_get_issue"},
+			wantCode: 1,
+			stderr:   []string{`missing required argument "issue_key"`},
+		},
+		{
+			name:     "unknown tool",
+			args:     []string{"call", "jira_nope"},
+			wantCode: 1,
+			stderr:   []string{`unknown tool "jira_nope"`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeatlassian.New(t)
+			code, stdout, stderr := runCLITest(t, tt.args...)
+			if code != tt.wantCode {
+				t.Fatalf("exit code = %d, want %d\nstdout: %s\nstderr: %s", code, tt.wantCode, stdout, stderr)
+			}
+			for _, want := range tt.stdout {
+				if !strings.Contains(stdout, want) {
+					t.Errorf("stdout does not contain %q:\n%s", want, stdout)
+				}
+			}
+			for _, want := range tt.stderr {
+				if !strings.Contains(stderr, want) {
+					t.Errorf("stderr does not contain %q:\n%s", want, stderr)
+				}
+			}
+		})
+	}
+}
+
+func TestCLIListToolsHonoursFilter(t *testing.T) {
+	t.Setenv("ENABLED_TOOLS", "jira_ping,confluence_ping")
+	code, stdout, _ := runCLITest(t, "list-tools", "--format", "json")
+	if code != 0 {
+		t.Fatalf("exit code = %d", code)
+	}
+	var tools []struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &tools); err != nil {
+		t.Fatal(err)
+	}
+	if len(tools) != 2 || tools[0].Name != "confluence_ping" || tools[1].Name != "jira_ping" {
+		t.Errorf("tools = %+v", tools)
+	}
+}
+
+func TestCLICompletion(t *testing.T) {
+	for _, shell := range []string{"bash", "zsh", "fish"} {
+		code, stdout, stderr := runCLITest(t, "completion", shell)
+		if code != 0 {
+			t.Fatalf("%s: exit code = %d: %s", shell, code, stderr)
+		}
+		if !strings.Contains(stdout, "jira_get_issue") || !strings.Contains(stdout, "issue_key=") {
+			t.Errorf("%s completion lacks tool schemas:\n%s", shell, stdout)
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"slices"
 	"strings"
 
 	log "github.com/sirupsen/logrus"
@@ -13,25 +14,55 @@ import (
 	"github.com/mark3labs/mcp-go/server"
 
 	"mcp-atlassian-server/pkg/clients"
+	"mcp-atlassian-server/pkg/config"
 	"mcp-atlassian-server/pkg/tools/confluence"
 	"mcp-atlassian-server/pkg/tools/jira"
 )
 
-func init() {
-	if os.Getenv("DEBUG") != "" {
-		log.SetLevel(log.DebugLevel)
+func main() {
+	configPath := os.Getenv(config.EnvConfigFile)
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		log.Fatal(err)
 	}
-}
+	config.Set(cfg)
+	applyLogging(cfg.Logging)
 
-const (
-	envEnabledTools  = "ENABLED_TOOLS"
-	envDisabledTools = "DISABLED_TOOLS"
-	envMCPMode       = "MCP_MODE"
-	envMCPHTTP       = "MCP_HTTP"
-	envMCP_SSE       = "MCP_SSE"
-)
+	s := newServer()
+	warnUnknownTools(s, cfg.Tools)
 
-func main() {
+	if len(os.Args) > 1 {
+		os.Exit(runCLI(context.Background(), s, os.Args[1:], os.Stdout, os.Stderr))
+	}
+
+	// Tool filters, limits and logging apply to open sessions after a reload
+	config.Watch(context.Background(), configPath, func(cfg *config.Config) {
+		applyLogging(cfg.Logging)
+		warnUnknownTools(s, cfg.Tools)
+		s.SendNotificationToAllClients(mcp.MethodNotificationToolsListChanged, nil)
+	})
+
+	if cfg.Server.HTTP {
+		svr := server.NewStreamableHTTPServer(s, server.WithHTTPContextFunc(svrCtxFunc))
+		log.Infof("Listening on %s/mcp", cfg.Server.Listen)
+		if err := svr.Start(cfg.Server.Listen); err != nil {
+			log.Fatal(err)
+		}
+	} else if cfg.Server.SSE {
+		svr := server.NewSSEServer(s, server.WithSSEContextFunc(svrCtxFunc))
+		log.Infof("Listening on %s/mcp", cfg.Server.Listen)
+		if err := svr.Start(cfg.Server.Listen); err != nil {
+			log.Fatal(err)
+		}
+	} else {
+		if err := server.ServeStdio(s); err != nil {
+			fmt.Printf("Server error: %v\n", err)
+		}
+	}
+}
+
+// newServer builds the MCP server with the tools selected by server.mode.
+func newServer() *server.MCPServer {
 	hooks := &server.Hooks{}
 	s := server.NewMCPServer(
 		"Atlassian MCP - Provides tools for interacting with Atlassian Jira & Confluence",
@@ -42,9 +73,10 @@ func main() {
 		server.WithRecovery(),
 		server.WithLogging(),
 		server.WithToolFilter(toolFilter),
+		server.WithToolHandlerMiddleware(toolGuard),
 	)
 
-	switch strings.ToUpper(os.Getenv("MCP_MODE")) {
+	switch strings.ToUpper(config.Current().Server.Mode) {
 	case "JIRA":
 		jira.AddTools(s)
 	case "CONFLUENCE":
@@ -53,26 +85,9 @@ func main() {
 		confluence.AddTools(s)
 		jira.AddTools(s)
 	default:
-		log.Fatal("Unknown MCP_MODE value")
-	}
-
-	if os.Getenv("MCP_HTTP") != "" {
-		svr := server.NewStreamableHTTPServer(s, server.WithHTTPContextFunc(svrCtxFunc))
-		log.Info("Listening on :8080/mcp")
-		if err := svr.Start(":8080"); err != nil {
-			log.Fatal(err)
-		}
-	} else if os.Getenv("MCP_SSE") != "" {
-		svr := server.NewSSEServer(s, server.WithSSEContextFunc(svrCtxFunc))
-		log.Info("Listening on :8080/mcp")
-		if err := svr.Start(":8080"); err != nil {
-			log.Fatal(err)
-		}
-	} else {
-		if err := server.ServeStdio(s); err != nil {
-			fmt.Printf("Server error: %v\n", err)
-		}
+		log.Fatal("Unknown server mode")
 	}
+	return s
 }
 
 func svrCtxFunc(ctx context.Context, r *http.Request) context.Context {
@@ -89,36 +104,55 @@ func svrCtxFunc(ctx context.Context, r *http.Request) context.Context {
 
 func toolFilter(ctx context.Context, tools []mcp.Tool) []mcp.Tool {
 	enabledTools := []mcp.Tool{}
-	enabledEnv := os.Getenv(envEnabledTools)
-	disabledEnv := os.Getenv(envDisabledTools)
-
-	if enabledEnv != "" {
-		enabledSet := map[string]struct{}{}
-		for _, name := range strings.Split(enabledEnv, ",") {
-			enabledSet[strings.TrimSpace(name)] = struct{}{}
+	for _, t := range tools {
+		if toolEnabled(t.Name) {
+			enabledTools = append(enabledTools, t)
 		}
-		for _, t := range tools {
-			if _, ok := enabledSet[t.Name]; ok {
-				enabledTools = append(enabledTools, t)
-			}
+	}
+	return enabledTools
+}
+
+// toolEnabled reports whether name passes the configured tool filter. An
+// enabled list takes precedence over a disabled list; with neither, every
+// tool is enabled.
+func toolEnabled(name string) bool {
+	filter := config.Current().Tools
+	if len(filter.Enabled) > 0 {
+		return slices.Contains(filter.Enabled, name)
+	}
+	return !slices.Contains(filter.Disabled, name)
+}
+
+// toolGuard rejects calls to tools hidden by the tool filter, which may have
+// changed since the client listed them.
+func toolGuard(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if !toolEnabled(req.Params.Name) {
+			return mcp.NewToolResultError(fmt.Sprintf("Tool %s is disabled by the server configuration", req.Params.Name)), nil
 		}
-		return enabledTools
-	} else if disabledEnv != "" {
-		disabledTools := strings.Split(disabledEnv, ",")
-		for _, t := range tools {
-			enabled := true
-			for _, disabled := range disabledTools {
-				if t.Name == strings.TrimSpace(disabled) {
-					enabled = false
-					break
-				}
-			}
-			if enabled {
-				enabledTools = append(enabledTools, t)
-			}
+		return next(ctx, req)
+	}
+}
+
+// warnUnknownTools logs tool filter entries that match no registered tool.
+func warnUnknownTools(s *server.MCPServer, filter config.Tools) {
+	registered := s.ListTools()
+	for _, name := range append(append([]string{}, filter.Enabled...), filter.Disabled...) {
+		if _, ok := registered[name]; !ok {
+			log.Warnf("Tool filter entry %q does not match any registered tool", name)
 		}
-		return enabledTools
 	}
-	// If neither is set, return all tools
-	return tools
+}
+
+// applyLogging sets the logrus level and format. The configuration has
+// already been validated.
+func applyLogging(cfg config.Logging) {
+	if level, err := log.ParseLevel(cfg.Level); err == nil {
+		log.SetLevel(level)
+	}
+	if cfg.Format == "json" {
+		log.SetFormatter(&log.JSONFormatter{})
+	} else {
+		log.SetFormatter(&log.TextFormatter{})
+	}
 }
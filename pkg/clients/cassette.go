@@ -0,0 +1,314 @@
+package clients
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+
+	"mcp-atlassian-server/pkg/config"
+)
+
+const (
+	CassetteRecord = "record"
+	CassetteReplay = "replay"
+
+	redacted = "REDACTED"
+)
+
+// sensitiveHeaders are replaced with a placeholder before recording.
+var sensitiveHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+// sensitiveKeys are query parameters and JSON keys whose values are
+// redacted. Keys containing "password" or "secret" are always redacted.
+var sensitiveKeys = map[string]bool{
+	"token": true, "apitoken": true, "api_token": true, "apikey": true, "api_key": true,
+	"access_token": true, "refresh_token": true, "authorization": true,
+}
+
+// Cassette is a set of recorded HTTP interactions.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is a single recorded request and its response.
+type Interaction struct {
+	Request  CassetteRequest  `json:"request"`
+	Response CassetteResponse `json:"response"`
+}
+
+type CassetteRequest struct {
+	Method  string      `json:"method"`
+	URL     string      `json:"url"`
+	Headers http.Header `json:"headers,omitempty"`
+	Body    string      `json:"body,omitempty"`
+}
+
+type CassetteResponse struct {
+	Status  int         `json:"status"`
+	Headers http.Header `json:"headers,omitempty"`
+	Body    string      `json:"body,omitempty"`
+}
+
+// CassetteTransport records traffic to a cassette file, or replays a
+// cassette without touching the network.
+type CassetteTransport struct {
+	rt       http.RoundTripper
+	path     string
+	mode     string
+	mu       sync.Mutex
+	cassette Cassette
+	used     []bool
+}
+
+// NewCassetteTransport wraps rt in record or replay mode. Recording starts a
+// fresh cassette at path; replaying loads it.
+func NewCassetteTransport(rt http.RoundTripper, path, mode string) (*CassetteTransport, error) {
+	t := &CassetteTransport{rt: rt, path: path, mode: strings.ToLower(mode)}
+	switch t.mode {
+	case CassetteRecord:
+		if err := t.save(); err != nil {
+			return nil, err
+		}
+		log.Infof("Recording Atlassian traffic to %s", path)
+	case CassetteReplay:
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading cassette: %w", err)
+		}
+		if err := json.Unmarshal(data, &t.cassette); err != nil {
+			return nil, fmt.Errorf("parsing cassette %s: %w", path, err)
+		}
+		t.used = make([]bool, len(t.cassette.Interactions))
+		log.Infof("Replaying Atlassian traffic from %s (%d interactions)", path, len(t.cassette.Interactions))
+	default:
+		return nil, fmt.Errorf("invalid cassette mode %q: must be %q or %q", mode, CassetteRecord, CassetteReplay)
+	}
+	return t, nil
+}
+
+func (t *CassetteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	secrets := requestSecrets(req)
+	recorded := CassetteRequest{
+		Method:  req.Method,
+		URL:     redactURL(req.URL),
+		Headers: redactHeaders(req.Header),
+		Body:    redactBody(body, secrets),
+	}
+	if t.mode == CassetteReplay {
+		return t.replay(req, recorded)
+	}
+
+	resp, err := t.rt.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.cassette.Interactions = append(t.cassette.Interactions, Interaction{
+		Request: recorded,
+		Response: CassetteResponse{
+			Status:  resp.StatusCode,
+			Headers: redactHeaders(resp.Header),
+			Body:    redactBody(respBody, secrets),
+		},
+	})
+	if err := t.save(); err != nil {
+		log.Errorf("Failed to write cassette: %v", err)
+	}
+	return resp, nil
+}
+
+// replay serves the first unused interaction matching the request's method,
+// path, query and body. Once all matches are used the last one is repeated.
+func (t *CassetteTransport) replay(req *http.Request, recorded CassetteRequest) (*http.Response, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	match := -1
+	for i, in := range t.cassette.Interactions {
+		if !sameRequest(in.Request, recorded) {
+			continue
+		}
+		match = i
+		if !t.used[i] {
+			break
+		}
+	}
+	if match < 0 {
+		return nil, fmt.Errorf("cassette %s has no interaction for %s %s", t.path, recorded.Method, recorded.URL)
+	}
+	t.used[match] = true
+	in := t.cassette.Interactions[match].Response
+	header := in.Headers.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", in.Status, http.StatusText(in.Status)),
+		StatusCode:    in.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(in.Body)),
+		ContentLength: int64(len(in.Body)),
+		Request:       req,
+	}, nil
+}
+
+func (t *CassetteTransport) save() error {
+	data, err := json.MarshalIndent(t.cassette, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(t.path, data, 0o600)
+}
+
+// withCassette wraps rt in a CassetteTransport when cfg names a cassette.
+func withCassette(rt http.RoundTripper, cfg config.Transport) (http.RoundTripper, error) {
+	if cfg.Cassette == "" {
+		return rt, nil
+	}
+	return NewCassetteTransport(rt, cfg.Cassette, cfg.CassetteMode)
+}
+
+// sameRequest compares requests ignoring the host, so a cassette recorded
+// against one instance replays against any base URL with the same path.
+func sameRequest(a, b CassetteRequest) bool {
+	if a.Method != b.Method || a.Body != b.Body {
+		return false
+	}
+	ua, errA := url.Parse(a.URL)
+	ub, errB := url.Parse(b.URL)
+	if errA != nil || errB != nil {
+		return a.URL == b.URL
+	}
+	return ua.Path == ub.Path && ua.Query().Encode() == ub.Query().Encode()
+}
+
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// requestSecrets returns credential values sent with req so they can be
+// scrubbed wherever they are echoed back.
+func requestSecrets(req *http.Request) []string {
+	var secrets []string
+	for _, h := range []string{"Authorization", "Proxy-Authorization"} {
+		v := req.Header.Get(h)
+		if _, cred, ok := strings.Cut(v, " "); ok && cred != "" {
+			secrets = append(secrets, cred)
+		}
+	}
+	cfg := config.Current()
+	for _, token := range []string{cfg.Jira.PersonalToken, cfg.Confluence.PersonalToken} {
+		if token != "" {
+			secrets = append(secrets, token)
+		}
+	}
+	return secrets
+}
+
+func isSensitive(key string) bool {
+	key = strings.ToLower(key)
+	return sensitiveKeys[key] || strings.Contains(key, "password") || strings.Contains(key, "secret")
+}
+
+func redactHeaders(h http.Header) http.Header {
+	if len(h) == 0 {
+		return nil
+	}
+	out := h.Clone()
+	for _, name := range sensitiveHeaders {
+		if out.Get(name) != "" {
+			out.Set(name, redacted)
+		}
+	}
+	return out
+}
+
+func redactURL(u *url.URL) string {
+	c := *u
+	if c.User != nil {
+		c.User = url.User(redacted)
+	}
+	q := c.Query()
+	changed := false
+	for k := range q {
+		if isSensitive(k) {
+			q.Set(k, redacted)
+			changed = true
+		}
+	}
+	if changed {
+		c.RawQuery = q.Encode()
+	}
+	return c.String()
+}
+
+// redactBody masks sensitive JSON values and any known credential values.
+func redactBody(body []byte, secrets []string) string {
+	if len(body) == 0 {
+		return ""
+	}
+	out := string(body)
+	var v any
+	if err := json.Unmarshal(body, &v); err == nil && redactJSON(v) {
+		if data, err := json.Marshal(v); err == nil {
+			out = string(data)
+		}
+	}
+	for _, s := range secrets {
+		out = strings.ReplaceAll(out, s, redacted)
+	}
+	return out
+}
+
+func redactJSON(v any) bool {
+	changed := false
+	switch x := v.(type) {
+	case map[string]any:
+		for k, val := range x {
+			if _, isString := val.(string); isString && isSensitive(k) {
+				x[k] = redacted
+				changed = true
+			} else if redactJSON(val) {
+				changed = true
+			}
+		}
+	case []any:
+		for _, val := range x {
+			if redactJSON(val) {
+				changed = true
+			}
+		}
+	}
+	return changed
+}
@@ -0,0 +1,65 @@
+package clients
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCassetteRecordAndReplay(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "JSESSIONID=abc")
+		w.Write([]byte(`{"key":"PROJ-1","echo":"` + r.Header.Get("Authorization") + `","password":"hunter2"}`))
+	}))
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	rec, err := NewCassetteTransport(http.DefaultTransport, path, CassetteRecord)
+	if err != nil {
+		t.Fatal(err)
+	}
+	get := func(rt http.RoundTripper) (int, string) {
+		t.Helper()
+		req, _ := http.NewRequest("GET", srv.URL+"/rest/api/2/issue/PROJ-1?token=s3cr3t&expand=names", nil)
+		req.Header.Set("Authorization", "Bearer my-pat")
+		resp, err := rt.RoundTrip(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+	if _, body := get(rec); !strings.Contains(body, "Bearer my-pat") {
+		t.Fatalf("recording altered the live response: %s", body)
+	}
+	srv.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"my-pat", "s3cr3t", "hunter2", "JSESSIONID"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("cassette contains %q:\n%s", secret, data)
+		}
+	}
+
+	replay, err := NewCassetteTransport(nil, path, CassetteReplay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	status, body := get(replay)
+	if status != http.StatusOK || !strings.Contains(body, `"key":"PROJ-1"`) {
+		t.Errorf("replay = %d %s", status, body)
+	}
+
+	req, _ := http.NewRequest("GET", srv.URL+"/rest/api/2/issue/PROJ-2", nil)
+	if _, err := replay.RoundTrip(req); err == nil {
+		t.Error("expected an error for an unrecorded request")
+	}
+}
@@ -4,36 +4,53 @@ import (
 	"context"
 	"fmt"
 	"net/http"
-	"os"
+	"net/url"
 	"strings"
 
 	"github.com/ctreminiom/go-atlassian/v2/confluence"
+	log "github.com/sirupsen/logrus"
+
+	"mcp-atlassian-server/pkg/config"
 )
 
 // ConfluenceRoundTripper modifies requests for on-prem compatibility
 type ConfluenceRoundTripper struct {
-	rt http.RoundTripper
+	rt       http.RoundTripper
+	basePath string // Context path of the Confluence instance (e.g. "/confluence"), without trailing slash
 }
 
 func (w *ConfluenceRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
-	req.URL.Path = strings.TrimPrefix(req.URL.Path, "/wiki")
-	// fmt.Printf("Request: %s %s\n", req.Method, req.URL)
+	// Server/DC serves the REST API directly under the context path, not under /wiki
+	if path := strings.TrimPrefix(req.URL.Path, w.basePath); strings.HasPrefix(path, "/wiki/") {
+		req.URL.Path = w.basePath + strings.TrimPrefix(path, "/wiki")
+		req.URL.RawPath = ""
+	}
+	log.Debugf("Confluence request: %s %s", req.Method, req.URL)
 	return w.rt.RoundTrip(req)
 }
 
-// GetConfluenceClient returns a new Confluence client using environment variables.
+// GetConfluenceClient returns a new Confluence client using the current configuration.
 func GetConfluenceClient(ctx context.Context) (*confluence.Client, error) {
-	baseURL := os.Getenv("CONFLUENCE_URL")
-	apiToken := os.Getenv("CONFLUENCE_PERSONAL_TOKEN")
+	cfg := config.Current()
+	baseURL := cfg.Confluence.URL
+	apiToken := cfg.Confluence.PersonalToken
 	if token := ctx.Value(ConfluencePersonalTokenKey); token != nil {
 		apiToken = token.(string)
 	}
 	if baseURL == "" || apiToken == "" {
-		return nil, fmt.Errorf("missing Confluence credentials in environment variables")
+		return nil, fmt.Errorf("missing Confluence credentials: set confluence.url and confluence.personal_token or CONFLUENCE_URL and CONFLUENCE_PERSONAL_TOKEN")
+	}
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CONFLUENCE_URL: %w", err)
+	}
+	rt, err := baseTransport()
+	if err != nil {
+		return nil, err
 	}
 	c := &http.Client{
-		Timeout:   http.DefaultClient.Timeout,
-		Transport: &ConfluenceRoundTripper{http.DefaultTransport},
+		Timeout:   cfg.Limits.RequestTimeout,
+		Transport: &ConfluenceRoundTripper{rt: rt, basePath: strings.TrimSuffix(u.Path, "/")},
 	}
 	api, err := confluence.New(c, baseURL)
 	if err != nil {
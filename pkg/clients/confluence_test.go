@@ -0,0 +1,31 @@
+package clients
+
+import (
+	"context"
+	"testing"
+)
+
+func TestConfluenceClientHonoursContextPath(t *testing.T) {
+	srv, requests := newRecordingServer(t)
+	t.Setenv("CONFLUENCE_URL", srv.URL+"/confluence")
+	t.Setenv("CONFLUENCE_PERSONAL_TOKEN", "env-token")
+
+	client, err := GetConfluenceClient(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := client.Content.Get(context.Background(), "1001", nil, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	got := requests()
+	if len(got) != 1 {
+		t.Fatalf("got %d requests, want 1: %+v", len(got), got)
+	}
+	if want := "/confluence/rest/api/content/1001"; got[0].path != want {
+		t.Errorf("path = %q, want %q", got[0].path, want)
+	}
+	if want := "Bearer env-token"; got[0].auth != want {
+		t.Errorf("auth = %q, want %q", got[0].auth, want)
+	}
+}
@@ -4,68 +4,88 @@ import (
 	"context"
 	"fmt"
 	"net/http"
-	"os"
-	"strings"
+	"net/url"
 
 	"github.com/ctreminiom/go-atlassian/v2/jira/agile"
 	jira "github.com/ctreminiom/go-atlassian/v2/jira/v2"
+	log "github.com/sirupsen/logrus"
+
+	"mcp-atlassian-server/pkg/config"
 )
 
-// JiraRoundTripper modifies requests for on-prem compatibility
+// JiraRoundTripper logs the requests of the Jira clients. User lookups pick
+// the username or accountId parameter themselves, as Server and Cloud differ.
 type JiraRoundTripper struct {
 	rt http.RoundTripper
 }
 
 func (w *JiraRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
-	// Swap accountId query param to username
-	if strings.HasPrefix(req.URL.Path, "/rest/api/2/user") {
-		q := req.URL.Query()
-		if accountId := q.Get("accountId"); accountId != "" {
-			q.Del("accountId")
-			q.Set("username", accountId)
-			req.URL.RawQuery = q.Encode()
-		}
-	}
-	// fmt.Printf("Request: %s %s\n", req.Method, req.URL)
+	log.Debugf("Jira request: %s %s", req.Method, req.URL)
 	return w.rt.RoundTrip(req)
 }
 
-// GetJiraClient returns a new Jira client using environment variables.
-func GetJiraClient(ctx context.Context) (*jira.Client, error) {
-	baseURL := os.Getenv("JIRA_URL")
-	apiToken := os.Getenv("JIRA_PERSONAL_TOKEN")
+// jiraConnection holds what the Jira REST and Agile REST clients share: the
+// instance URL, the token for the current request and the HTTP client.
+type jiraConnection struct {
+	baseURL  string
+	apiToken string
+	http     *http.Client
+}
+
+// newJiraConnection resolves the Jira instance and credentials for ctx and
+// builds an HTTP client routed through JiraRoundTripper and the shared transport.
+func newJiraConnection(ctx context.Context) (*jiraConnection, error) {
+	cfg := config.Current()
+	baseURL := cfg.Jira.URL
+	apiToken := cfg.Jira.PersonalToken
 	if token := ctx.Value(JiraPersonalTokenKey); token != nil {
 		apiToken = token.(string)
 	}
 	if baseURL == "" || apiToken == "" {
-		return nil, fmt.Errorf("missing Jira credentials in environment variables")
+		return nil, fmt.Errorf("missing Jira credentials: set jira.url and jira.personal_token or JIRA_URL and JIRA_PERSONAL_TOKEN")
 	}
-	c := &http.Client{
-		Timeout:   http.DefaultClient.Timeout,
-		Transport: &JiraRoundTripper{http.DefaultTransport},
+	if _, err := url.Parse(baseURL); err != nil {
+		return nil, fmt.Errorf("invalid JIRA_URL: %w", err)
+	}
+	rt, err := baseTransport()
+	if err != nil {
+		return nil, err
+	}
+	return &jiraConnection{
+		baseURL:  baseURL,
+		apiToken: apiToken,
+		http: &http.Client{
+			Timeout:   cfg.Limits.RequestTimeout,
+			Transport: &JiraRoundTripper{rt: rt},
+		},
+	}, nil
+}
+
+// GetJiraClient returns a new Jira client using the current configuration.
+func GetJiraClient(ctx context.Context) (*jira.Client, error) {
+	conn, err := newJiraConnection(ctx)
+	if err != nil {
+		return nil, err
 	}
-	api, err := jira.New(c, baseURL)
+	api, err := jira.New(conn.http, conn.baseURL)
 	if err != nil {
 		return nil, err
 	}
-	api.Auth.SetBearerToken(apiToken)
+	api.Auth.SetBearerToken(conn.apiToken)
 	return api, nil
 }
 
-// GetAgileClient returns a new Jira client using environment variables.
+// GetAgileClient returns a new Jira Agile client using the current configuration.
+// It shares the transport, credentials and instance routing of GetJiraClient.
 func GetAgileClient(ctx context.Context) (*agile.Client, error) {
-	baseURL := os.Getenv("JIRA_URL")
-	apiToken := os.Getenv("JIRA_PERSONAL_TOKEN")
-	if token := ctx.Value(JiraPersonalTokenKey); token != nil {
-		apiToken = token.(string)
-	}
-	if baseURL == "" || apiToken == "" {
-		return nil, fmt.Errorf("missing Jira credentials in environment variables")
+	conn, err := newJiraConnection(ctx)
+	if err != nil {
+		return nil, err
 	}
-	api, err := agile.New(nil, baseURL)
+	api, err := agile.New(conn.http, conn.baseURL)
 	if err != nil {
 		return nil, err
 	}
-	api.Auth.SetBearerToken(apiToken)
+	api.Auth.SetBearerToken(conn.apiToken)
 	return api, nil
 }
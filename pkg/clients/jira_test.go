@@ -0,0 +1,85 @@
+package clients
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+type recordedRequest struct {
+	path  string
+	query string
+	auth  string
+}
+
+func newRecordingServer(t *testing.T) (*httptest.Server, func() []recordedRequest) {
+	t.Helper()
+	var mu sync.Mutex
+	var requests []recordedRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, recordedRequest{r.URL.Path, r.URL.RawQuery, r.Header.Get("Authorization")})
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func() []recordedRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]recordedRequest(nil), requests...)
+	}
+}
+
+func TestJiraAndAgileClientsShareTransport(t *testing.T) {
+	srv, requests := newRecordingServer(t)
+	t.Setenv("JIRA_URL", srv.URL+"/jira")
+	t.Setenv("JIRA_PERSONAL_TOKEN", "env-token")
+	ctx := context.WithValue(context.Background(), JiraPersonalTokenKey, "ctx-token")
+
+	jiraClient, err := GetJiraClient(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	agileClient, err := GetAgileClient(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := jiraClient.HTTP.(*http.Client).Transport.(*JiraRoundTripper); !ok {
+		t.Errorf("jira client does not use JiraRoundTripper")
+	}
+	if _, ok := agileClient.HTTP.(*http.Client).Transport.(*JiraRoundTripper); !ok {
+		t.Errorf("agile client does not use JiraRoundTripper")
+	}
+
+	if _, _, err := jiraClient.User.Get(ctx, "5b10ac8d82e05b22cc7d4ef5", nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := agileClient.Board.Get(ctx, 42); err != nil {
+		t.Fatal(err)
+	}
+
+	got := requests()
+	want := []recordedRequest{
+		{"/jira/rest/api/2/user", "accountId=5b10ac8d82e05b22cc7d4ef5", "Bearer ctx-token"},
+		{"/jira/rest/agile/1.0/board/42", "", "Bearer ctx-token"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d requests, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("request %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestGetAgileClientMissingCredentials(t *testing.T) {
+	t.Setenv("JIRA_URL", "")
+	t.Setenv("JIRA_PERSONAL_TOKEN", "")
+	if _, err := GetAgileClient(context.Background()); err == nil {
+		t.Fatal("expected error for missing credentials")
+	}
+}
@@ -0,0 +1,107 @@
+package clients
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/net/http/httpproxy"
+
+	"mcp-atlassian-server/pkg/config"
+)
+
+// NewTransport builds an HTTP transport honouring the CA bundle, client
+// certificate, proxy and verification settings in cfg.
+func NewTransport(cfg config.Transport) (*http.Transport, error) {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if cfg.CABundle != "" {
+		pem, err := os.ReadFile(cfg.CABundle)
+		if err != nil {
+			return nil, fmt.Errorf("reading CA bundle: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %s", cfg.CABundle)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.ClientCert != "" || cfg.ClientKey != "" {
+		if cfg.ClientCert == "" || cfg.ClientKey == "" {
+			return nil, fmt.Errorf("both a client certificate and key must be set for mutual TLS")
+		}
+		cert, err := tls.LoadX509KeyPair(cfg.ClientCert, cfg.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("loading client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if cfg.InsecureSkipVerify {
+		log.Warn("TLS certificate verification is DISABLED for Atlassian connections; traffic can be intercepted. Do not use this in production.")
+		tlsConfig.InsecureSkipVerify = true
+	}
+	t.TLSClientConfig = tlsConfig
+
+	if cfg.Proxy != "" {
+		if _, err := url.Parse(cfg.Proxy); err != nil {
+			return nil, fmt.Errorf("invalid proxy URL: %w", err)
+		}
+		proxy := (&httpproxy.Config{
+			HTTPProxy:  cfg.Proxy,
+			HTTPSProxy: cfg.Proxy,
+			NoProxy:    cfg.NoProxy,
+		}).ProxyFunc()
+		t.Proxy = func(req *http.Request) (*url.URL, error) {
+			return proxy(req.URL)
+		}
+	} else if cfg.NoProxy != "" {
+		env := httpproxy.FromEnvironment()
+		env.NoProxy = cfg.NoProxy
+		proxy := env.ProxyFunc()
+		t.Proxy = func(req *http.Request) (*url.URL, error) {
+			return proxy(req.URL)
+		}
+	}
+	return t, nil
+}
+
+// transportCache holds the shared transport once it has been built.
+var transportCache struct {
+	sync.Mutex
+	rt http.RoundTripper
+}
+
+// baseTransport returns the shared transport used by every Atlassian client.
+// It is built on first use so connections are pooled across tool calls, and
+// is wrapped in a CassetteTransport when a cassette is configured. A failed
+// build is not kept, so the next call retries with the current settings;
+// once built, the transport lasts until the process restarts.
+func baseTransport() (http.RoundTripper, error) {
+	transportCache.Lock()
+	defer transportCache.Unlock()
+	if transportCache.rt != nil {
+		return transportCache.rt, nil
+	}
+	cfg := config.Current().Transport
+	t, err := NewTransport(cfg)
+	if err != nil {
+		return nil, err
+	}
+	rt, err := withCassette(t, cfg)
+	if err != nil {
+		return nil, err
+	}
+	transportCache.rt = rt
+	return rt, nil
+}
@@ -0,0 +1,29 @@
+package clients
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestBaseTransportRetriesFailedBuild(t *testing.T) {
+	reset := func() {
+		transportCache.Lock()
+		transportCache.rt = nil
+		transportCache.Unlock()
+	}
+	reset()
+	t.Cleanup(reset)
+
+	t.Setenv("ATLASSIAN_CA_BUNDLE", filepath.Join(t.TempDir(), "missing.pem"))
+	if _, err := baseTransport(); err == nil {
+		t.Fatal("expected error for a missing CA bundle")
+	}
+	t.Setenv("ATLASSIAN_CA_BUNDLE", "")
+	rt, err := baseTransport()
+	if err != nil || rt == nil {
+		t.Fatalf("baseTransport after fixing the CA bundle = %v, %v", rt, err)
+	}
+	if again, _ := baseTransport(); again != rt {
+		t.Error("transport was rebuilt instead of shared")
+	}
+}
@@ -0,0 +1,353 @@
+// Package config loads the server configuration from an optional YAML or
+// TOML file, with environment variables taking precedence over the file.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+
+	"mcp-atlassian-server/pkg/utils"
+)
+
+// EnvConfigFile names the environment variable holding the config file path.
+const EnvConfigFile = "MCP_CONFIG"
+
+// Config is the complete server configuration.
+type Config struct {
+	Jira       Instance  `yaml:"jira" toml:"jira"`
+	Confluence Instance  `yaml:"confluence" toml:"confluence"`
+	Transport  Transport `yaml:"transport" toml:"transport"`
+	Server     Server    `yaml:"server" toml:"server"`
+	Tools      Tools     `yaml:"tools" toml:"tools"`
+	Limits     Limits    `yaml:"limits" toml:"limits"`
+	Bulk       Bulk      `yaml:"bulk" toml:"bulk"`
+	Logging    Logging   `yaml:"logging" toml:"logging"`
+}
+
+// Instance is a Jira or Confluence Server/Data Center instance.
+type Instance struct {
+	URL           string `yaml:"url" toml:"url"`                       // Base URL including any context path
+	PersonalToken string `yaml:"personal_token" toml:"personal_token"` // Personal access token, overridable per HTTP request
+}
+
+// Transport describes how connections to Atlassian instances are made.
+type Transport struct {
+	CABundle           string `yaml:"ca_bundle" toml:"ca_bundle"`                       // Path to a PEM bundle appended to the system roots
+	ClientCert         string `yaml:"client_cert" toml:"client_cert"`                   // Path to a PEM client certificate for mutual TLS
+	ClientKey          string `yaml:"client_key" toml:"client_key"`                     // Path to the PEM key for ClientCert
+	Proxy              string `yaml:"proxy" toml:"proxy"`                               // Explicit proxy URL, overrides HTTP(S)_PROXY
+	NoProxy            string `yaml:"no_proxy" toml:"no_proxy"`                         // Comma-separated hosts, domains or CIDRs to reach directly
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify" toml:"insecure_skip_verify"` // Disable TLS certificate verification
+	Cassette           string `yaml:"cassette" toml:"cassette"`                         // Cassette file to record to or replay from
+	CassetteMode       string `yaml:"cassette_mode" toml:"cassette_mode"`               // "record" or "replay"
+}
+
+// Server selects the tool set and the MCP transport.
+type Server struct {
+	Mode   string `yaml:"mode" toml:"mode"`     // "jira", "confluence" or empty for both
+	HTTP   bool   `yaml:"http" toml:"http"`     // Serve streamable HTTP instead of stdio
+	SSE    bool   `yaml:"sse" toml:"sse"`       // Serve SSE instead of stdio
+	Listen string `yaml:"listen" toml:"listen"` // Listen address for HTTP and SSE
+}
+
+// Tools filters the tools offered to clients. Enabled takes precedence over Disabled.
+type Tools struct {
+	Enabled  []string `yaml:"enabled" toml:"enabled"`
+	Disabled []string `yaml:"disabled" toml:"disabled"`
+}
+
+// Limits bound the work a single tool call can cause.
+type Limits struct {
+	MaxResults     int           `yaml:"max_results" toml:"max_results"`         // Upper bound for "limit" arguments
+	MaxFetchAll    int           `yaml:"max_fetch_all" toml:"max_fetch_all"`     // Most items a "fetch_all" call collects
+	RequestTimeout time.Duration `yaml:"request_timeout" toml:"request_timeout"` // Timeout per HTTP request, 0 for none
+}
+
+// Bulk configures jira_bulk_update.
+type Bulk struct {
+	MaxIssues     int    `yaml:"max_issues" toml:"max_issues"`         // Most issues a single bulk operation may select
+	CheckpointDir string `yaml:"checkpoint_dir" toml:"checkpoint_dir"` // Where bulk plans and progress are kept, defaults to the user cache directory
+}
+
+// Logging configures logrus.
+type Logging struct {
+	Level  string `yaml:"level" toml:"level"`   // logrus level name
+	Format string `yaml:"format" toml:"format"` // "text" or "json"
+}
+
+// Default returns the configuration used when nothing is set.
+func Default() *Config {
+	return &Config{
+		Transport: Transport{CassetteMode: "replay"},
+		Server:    Server{Listen: ":8080"},
+		Limits:    Limits{MaxResults: 50, MaxFetchAll: 500},
+		Bulk:      Bulk{MaxIssues: 500},
+		Logging:   Logging{Level: "info", Format: "text"},
+	}
+}
+
+// Load reads the file at path, if any, applies environment overrides and
+// validates the result.
+func Load(path string) (*Config, error) {
+	cfg := Default()
+	if path != "" {
+		if err := cfg.readFile(path); err != nil {
+			return nil, err
+		}
+	}
+	var problems []string
+	cfg.applyEnv(&problems)
+	cfg.validate(&problems)
+	if len(problems) > 0 {
+		return nil, &ValidationError{Source: path, Problems: problems}
+	}
+	return cfg, nil
+}
+
+// ValidationError lists every problem found in a configuration.
+type ValidationError struct {
+	Source   string
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	source := "environment"
+	if e.Source != "" {
+		source = e.Source + " and environment"
+	}
+	return fmt.Sprintf("invalid configuration (%s):\n  - %s", source, strings.Join(e.Problems, "\n  - "))
+}
+
+func (c *Config) readFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading config file: %w", err)
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("parsing %s: %w", path, err)
+		}
+	case ".toml":
+		md, err := toml.Decode(string(data), c)
+		if err != nil {
+			return fmt.Errorf("parsing %s: %w", path, err)
+		}
+		if undecoded := md.Undecoded(); len(undecoded) > 0 {
+			keys := make([]string, len(undecoded))
+			for i, k := range undecoded {
+				keys[i] = k.String()
+			}
+			return fmt.Errorf("parsing %s: unknown keys %s", path, strings.Join(keys, ", "))
+		}
+	default:
+		return fmt.Errorf("unsupported config file %s: use a .yaml, .yml or .toml extension", path)
+	}
+	return nil
+}
+
+// applyEnv overrides file values with any environment variables that are set.
+func (c *Config) applyEnv(problems *[]string) {
+	str := func(key string, dst *string) {
+		if v := os.Getenv(key); v != "" {
+			*dst = v
+		}
+	}
+	str("JIRA_URL", &c.Jira.URL)
+	str("JIRA_PERSONAL_TOKEN", &c.Jira.PersonalToken)
+	str("CONFLUENCE_URL", &c.Confluence.URL)
+	str("CONFLUENCE_PERSONAL_TOKEN", &c.Confluence.PersonalToken)
+
+	str("ATLASSIAN_CA_BUNDLE", &c.Transport.CABundle)
+	str("ATLASSIAN_CLIENT_CERT", &c.Transport.ClientCert)
+	str("ATLASSIAN_CLIENT_KEY", &c.Transport.ClientKey)
+	str("ATLASSIAN_PROXY", &c.Transport.Proxy)
+	str("ATLASSIAN_NO_PROXY", &c.Transport.NoProxy)
+	str("ATLASSIAN_CASSETTE", &c.Transport.Cassette)
+	str("ATLASSIAN_CASSETTE_MODE", &c.Transport.CassetteMode)
+	if v := os.Getenv("ATLASSIAN_INSECURE_SKIP_VERIFY"); v != "" {
+		skip, err := strconv.ParseBool(v)
+		if err != nil {
+			*problems = append(*problems, fmt.Sprintf("ATLASSIAN_INSECURE_SKIP_VERIFY: %q is not a boolean", v))
+		} else {
+			c.Transport.InsecureSkipVerify = skip
+		}
+	}
+
+	str("MCP_MODE", &c.Server.Mode)
+	str("MCP_LISTEN", &c.Server.Listen)
+	if os.Getenv("MCP_HTTP") != "" {
+		c.Server.HTTP = true
+	}
+	if os.Getenv("MCP_SSE") != "" {
+		c.Server.SSE = true
+	}
+
+	// Either variable replaces the whole tool filter from the file.
+	if v := os.Getenv("ENABLED_TOOLS"); v != "" {
+		c.Tools = Tools{Enabled: utils.SplitAndTrim(v)}
+	} else if v := os.Getenv("DISABLED_TOOLS"); v != "" {
+		c.Tools = Tools{Disabled: utils.SplitAndTrim(v)}
+	}
+
+	if v := os.Getenv("MCP_MAX_RESULTS"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			*problems = append(*problems, fmt.Sprintf("MCP_MAX_RESULTS: %q is not an integer", v))
+		} else {
+			c.Limits.MaxResults = n
+		}
+	}
+	if v := os.Getenv("MCP_MAX_FETCH_ALL"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			*problems = append(*problems, fmt.Sprintf("MCP_MAX_FETCH_ALL: %q is not an integer", v))
+		} else {
+			c.Limits.MaxFetchAll = n
+		}
+	}
+	if v := os.Getenv("MCP_REQUEST_TIMEOUT"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			*problems = append(*problems, fmt.Sprintf("MCP_REQUEST_TIMEOUT: %q is not a duration such as 30s", v))
+		} else {
+			c.Limits.RequestTimeout = d
+		}
+	}
+
+	if v := os.Getenv("MCP_BULK_MAX_ISSUES"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			*problems = append(*problems, fmt.Sprintf("MCP_BULK_MAX_ISSUES: %q is not an integer", v))
+		} else {
+			c.Bulk.MaxIssues = n
+		}
+	}
+	str("MCP_BULK_CHECKPOINT_DIR", &c.Bulk.CheckpointDir)
+
+	str("LOG_LEVEL", &c.Logging.Level)
+	str("LOG_FORMAT", &c.Logging.Format)
+	if os.Getenv("DEBUG") != "" {
+		c.Logging.Level = "debug"
+	}
+}
+
+func (c *Config) validate(problems *[]string) {
+	add := func(format string, args ...any) {
+		*problems = append(*problems, fmt.Sprintf(format, args...))
+	}
+
+	c.Server.Mode = strings.ToLower(c.Server.Mode)
+	switch c.Server.Mode {
+	case "", "jira", "confluence":
+	default:
+		add("server.mode: %q must be jira, confluence or empty for both", c.Server.Mode)
+	}
+	if c.Server.HTTP && c.Server.SSE {
+		add("server.http and server.sse cannot both be enabled")
+	}
+	if c.Server.Listen == "" && (c.Server.HTTP || c.Server.SSE) {
+		add("server.listen is required for HTTP and SSE")
+	}
+
+	for _, inst := range []struct{ name, url string }{{"jira", c.Jira.URL}, {"confluence", c.Confluence.URL}} {
+		if inst.url == "" {
+			continue
+		}
+		u, err := url.Parse(inst.url)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			add("%s.url: %q must be an absolute http or https URL", inst.name, inst.url)
+		}
+	}
+
+	t := c.Transport
+	for _, f := range []struct{ name, path string }{{"ca_bundle", t.CABundle}, {"client_cert", t.ClientCert}, {"client_key", t.ClientKey}} {
+		if f.path == "" {
+			continue
+		}
+		if _, err := os.Stat(f.path); err != nil {
+			add("transport.%s: %v", f.name, err)
+		}
+	}
+	if (t.ClientCert == "") != (t.ClientKey == "") {
+		add("transport.client_cert and transport.client_key must be set together")
+	}
+	if t.Proxy != "" {
+		if u, err := url.Parse(t.Proxy); err != nil || u.Host == "" {
+			add("transport.proxy: %q is not a valid proxy URL", t.Proxy)
+		}
+	}
+	c.Transport.CassetteMode = strings.ToLower(t.CassetteMode)
+	if t.Cassette != "" {
+		switch c.Transport.CassetteMode {
+		case "record":
+		case "replay":
+			if _, err := os.Stat(t.Cassette); err != nil {
+				add("transport.cassette: %v", err)
+			}
+		default:
+			add("transport.cassette_mode: %q must be record or replay", t.CassetteMode)
+		}
+	}
+
+	if len(c.Tools.Enabled) > 0 && len(c.Tools.Disabled) > 0 {
+		log.Warn("tools.enabled and tools.disabled are both set; tools.disabled is ignored")
+	}
+
+	if c.Limits.MaxResults < 1 {
+		add("limits.max_results: must be at least 1, got %d", c.Limits.MaxResults)
+	}
+	if c.Limits.MaxFetchAll < 1 {
+		add("limits.max_fetch_all: must be at least 1, got %d", c.Limits.MaxFetchAll)
+	}
+	if c.Limits.RequestTimeout < 0 {
+		add("limits.request_timeout: must not be negative, got %s", c.Limits.RequestTimeout)
+	}
+
+	if c.Bulk.MaxIssues < 1 {
+		add("bulk.max_issues: must be at least 1, got %d", c.Bulk.MaxIssues)
+	}
+
+	if _, err := log.ParseLevel(c.Logging.Level); err != nil {
+		add("logging.level: %q is not a valid level (trace, debug, info, warn, error)", c.Logging.Level)
+	}
+	switch c.Logging.Format {
+	case "text", "json":
+	default:
+		add("logging.format: %q must be text or json", c.Logging.Format)
+	}
+}
+
+var current atomic.Pointer[Config]
+
+// Current returns the active configuration. Until Set is called it is built
+// from the environment on each call.
+func Current() *Config {
+	if c := current.Load(); c != nil {
+		return c
+	}
+	cfg := Default()
+	var problems []string
+	cfg.applyEnv(&problems)
+	return cfg
+}
+
+// Set makes cfg the active configuration.
+func Set(cfg *Config) {
+	current.Store(cfg)
+}
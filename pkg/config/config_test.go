@@ -0,0 +1,178 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadYAMLWithEnvOverrides(t *testing.T) {
+	path := writeFile(t, "config.yaml", `
+jira:
+  url: https://jira.example.com/jira
+  personal_token: file-token
+server:
+  mode: Jira
+tools:
+  disabled: [jira_delete_issue]
+limits:
+  max_results: 20
+  request_timeout: 45s
+`)
+	t.Setenv("JIRA_PERSONAL_TOKEN", "env-token")
+	t.Setenv("ENABLED_TOOLS", "jira_search, jira_get_issue")
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Jira.URL != "https://jira.example.com/jira" || cfg.Jira.PersonalToken != "env-token" {
+		t.Errorf("jira = %+v", cfg.Jira)
+	}
+	if cfg.Server.Mode != "jira" || cfg.Server.Listen != ":8080" {
+		t.Errorf("server = %+v", cfg.Server)
+	}
+	if len(cfg.Tools.Enabled) != 2 || cfg.Tools.Enabled[1] != "jira_get_issue" || len(cfg.Tools.Disabled) != 0 {
+		t.Errorf("tools = %+v", cfg.Tools)
+	}
+	if cfg.Limits.MaxResults != 20 || cfg.Limits.RequestTimeout != 45*time.Second {
+		t.Errorf("limits = %+v", cfg.Limits)
+	}
+}
+
+func TestLoadTOML(t *testing.T) {
+	path := writeFile(t, "config.toml", `
+[confluence]
+url = "https://wiki.example.com"
+
+[logging]
+level = "debug"
+format = "json"
+`)
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Confluence.URL != "https://wiki.example.com" || cfg.Logging.Level != "debug" || cfg.Logging.Format != "json" {
+		t.Errorf("cfg = %+v", cfg)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		env     map[string]string
+		want    []string
+	}{
+		{
+			name:    "unknown yaml key",
+			file:    "config.yaml",
+			content: "jira:\n  uri: https://jira\n",
+			want:    []string{"field uri not found"},
+		},
+		{
+			name:    "unknown toml key",
+			file:    "config.toml",
+			content: "[limits]\nmax_result = 5\n",
+			want:    []string{"unknown keys limits.max_result"},
+		},
+		{
+			name: "unsupported extension",
+			file: "config.json",
+			want: []string{"use a .yaml, .yml or .toml extension"},
+		},
+		{
+			name: "all problems are reported",
+			file: "config.yaml",
+			content: `
+jira: {url: "jira.example.com"}
+server: {mode: bamboo, http: true, sse: true}
+transport: {client_cert: /nonexistent/cert.pem}
+limits: {max_results: 0}
+bulk: {max_issues: 0}
+logging: {level: loud, format: xml}
+`,
+			env: map[string]string{"MCP_REQUEST_TIMEOUT": "soon"},
+			want: []string{
+				`MCP_REQUEST_TIMEOUT: "soon" is not a duration`,
+				`server.mode: "bamboo"`,
+				"server.http and server.sse cannot both be enabled",
+				`jira.url: "jira.example.com" must be an absolute http or https URL`,
+				"transport.client_cert: stat /nonexistent/cert.pem",
+				"transport.client_cert and transport.client_key must be set together",
+				"limits.max_results: must be at least 1",
+				"bulk.max_issues: must be at least 1",
+				`logging.level: "loud"`,
+				`logging.format: "xml"`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			_, err := Load(writeFile(t, tt.file, tt.content))
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error does not contain %q:\n%v", want, err)
+				}
+			}
+		})
+	}
+}
+
+func TestWatchReloadsOnFileChange(t *testing.T) {
+	pollInterval = 10 * time.Millisecond
+	t.Cleanup(func() { pollInterval = 2 * time.Second; current.Store(nil) })
+
+	path := writeFile(t, "config.yaml", "limits: {max_results: 10}\n")
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	Set(cfg)
+
+	reloaded := make(chan *Config, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	Watch(ctx, path, func(cfg *Config) { reloaded <- cfg })
+
+	// An invalid change is ignored
+	later := time.Now().Add(time.Second)
+	os.WriteFile(path, []byte("limits: {max_results: -1}\n"), 0o600)
+	os.Chtimes(path, later, later)
+	time.Sleep(50 * time.Millisecond)
+	if got := Current().Limits.MaxResults; got != 10 {
+		t.Fatalf("max_results = %d after invalid change, want 10", got)
+	}
+
+	later = later.Add(time.Second)
+	os.WriteFile(path, []byte("limits: {max_results: 5}\ntools: {enabled: [jira_search]}\n"), 0o600)
+	os.Chtimes(path, later, later)
+	select {
+	case cfg := <-reloaded:
+		if cfg.Limits.MaxResults != 5 || Current() != cfg {
+			t.Errorf("reloaded config = %+v", cfg.Limits)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("configuration was not reloaded")
+	}
+}
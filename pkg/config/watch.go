@@ -0,0 +1,80 @@
+package config
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"reflect"
+	"syscall"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// pollInterval is how often the config file is checked for changes.
+var pollInterval = 2 * time.Second
+
+// Watch reloads the configuration from path when the process receives SIGHUP
+// or the file changes, until ctx is done. A valid configuration becomes
+// current and is passed to onReload; an invalid one is logged and ignored.
+// Jira and Confluence URLs and tokens are read on every client, so they
+// apply immediately. Server and transport settings are only read when the
+// server starts and the shared transport is built, so changes to them are
+// reported as needing a restart.
+func Watch(ctx context.Context, path string, onReload func(*Config)) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	ticker := time.NewTicker(pollInterval)
+	lastMod := modTime(path)
+
+	go func() {
+		defer signal.Stop(hup)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-hup:
+				log.Info("Received SIGHUP, reloading configuration")
+			case <-ticker.C:
+				if path == "" {
+					continue
+				}
+				mod := modTime(path)
+				if mod.Equal(lastMod) {
+					continue
+				}
+				lastMod = mod
+				log.Infof("%s changed, reloading configuration", path)
+			}
+			reload(path, onReload)
+		}
+	}()
+}
+
+func reload(path string, onReload func(*Config)) {
+	cfg, err := Load(path)
+	if err != nil {
+		log.Errorf("Keeping the previous configuration: %v", err)
+		return
+	}
+	old := Current()
+	if !reflect.DeepEqual(old.Server, cfg.Server) || !reflect.DeepEqual(old.Transport, cfg.Transport) {
+		log.Warn("Server and transport settings changed; they take effect after a restart. Jira and Confluence URLs, tokens and limits apply immediately")
+	}
+	Set(cfg)
+	if onReload != nil {
+		onReload(cfg)
+	}
+}
+
+func modTime(path string) time.Time {
+	if path == "" {
+		return time.Time{}
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
@@ -0,0 +1,77 @@
+package fakeatlassian
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// Attach adds an attachment to an existing issue, as uploading it would.
+func (s *Server) Attach(key, filename string, content []byte) {
+	s.Lock()
+	defer s.Unlock()
+	s.attach(s.Issues[key], filename, content)
+}
+
+// attach stores the content and lists it in the attachment field of the
+// issue. The caller must hold the lock.
+func (s *Server) attach(issue *Issue, filename string, content []byte) map[string]any {
+	id := s.newID()
+	s.Attachments[id] = content
+	meta := map[string]any{
+		"id":       id,
+		"filename": filename,
+		"author":   s.lookupUser(currentUser),
+		"created":  "2024-02-01T10:00:00.000+0000",
+		"size":     len(content),
+		"mimeType": http.DetectContentType(content),
+		"content":  s.JiraURL() + "/secure/attachment/" + id + "/" + url.PathEscape(filename),
+	}
+	list, _ := issue.Fields["attachment"].([]any)
+	issue.Fields["attachment"] = append(list, meta)
+	return meta
+}
+
+// addAttachments accepts multipart uploads in the "file" part, which Jira
+// only allows with the X-Atlassian-Token: no-check header.
+func (s *Server) addAttachments(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("X-Atlassian-Token") != "no-check" {
+		jiraError(w, http.StatusForbidden, "XSRF check failed")
+		return
+	}
+	if err := r.ParseMultipartForm(10 << 20); err != nil {
+		jiraError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.Lock()
+	defer s.Unlock()
+	issue := s.lookupIssue(w, r)
+	if issue == nil {
+		return
+	}
+	out := []map[string]any{}
+	for _, fh := range r.MultipartForm.File["file"] {
+		f, err := fh.Open()
+		if err != nil {
+			jiraError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		content, _ := io.ReadAll(f)
+		f.Close()
+		out = append(out, s.attach(issue, fh.Filename, content))
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// getAttachmentContent serves the content URL listed in attachment metadata.
+func (s *Server) getAttachmentContent(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	content, ok := s.Attachments[r.PathValue("id")]
+	if !ok {
+		jiraError(w, http.StatusNotFound, "Attachment not found")
+		return
+	}
+	w.Header().Set("Content-Type", http.DetectContentType(content))
+	w.Write(content)
+}
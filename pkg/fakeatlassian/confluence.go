@@ -0,0 +1,281 @@
+package fakeatlassian
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+func (s *Server) registerConfluence(mux *http.ServeMux) {
+	api := "/confluence/rest/api"
+	mux.HandleFunc("GET "+api+"/search", s.searchContent)
+	mux.HandleFunc("POST "+api+"/content", s.createContent)
+	mux.HandleFunc("GET "+api+"/content/{id}", s.getContent)
+	mux.HandleFunc("PUT "+api+"/content/{id}", s.updateContent)
+	mux.HandleFunc("DELETE "+api+"/content/{id}", s.deleteContent)
+	mux.HandleFunc("GET "+api+"/content/{id}/child/{type}", s.getChildren)
+	mux.HandleFunc("GET "+api+"/content/{id}/label", s.getLabels)
+	mux.HandleFunc("POST "+api+"/content/{id}/label", s.addLabels)
+}
+
+func confluenceError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]any{"statusCode": status, "message": message})
+}
+
+func (s *Server) pageJSON(p *Page) map[string]any {
+	labels := []map[string]any{}
+	for _, l := range p.Labels {
+		labels = append(labels, map[string]any{"prefix": "global", "name": l})
+	}
+	out := map[string]any{
+		"id":      p.ID,
+		"type":    p.Type,
+		"status":  "current",
+		"title":   p.Title,
+		"space":   map[string]any{"key": p.SpaceKey},
+		"version": map[string]any{"number": p.Version},
+		"body":    map[string]any{"storage": map[string]any{"value": p.Body, "representation": "storage"}},
+		"metadata": map[string]any{
+			"labels": map[string]any{"results": labels, "size": len(labels)},
+		},
+		"_links": map[string]any{"webui": "/pages/viewpage.action?pageId=" + p.ID},
+	}
+	if p.ParentID != "" {
+		out["ancestors"] = []map[string]any{{"id": p.ParentID}}
+	}
+	return out
+}
+
+func (s *Server) sortedPages(match func(*Page) bool) []*Page {
+	var out []*Page
+	for _, p := range s.Pages {
+		if match(p) {
+			out = append(out, p)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+func (s *Server) lookupPage(w http.ResponseWriter, r *http.Request) *Page {
+	if p, ok := s.Pages[r.PathValue("id")]; ok {
+		return p
+	}
+	confluenceError(w, http.StatusNotFound, "No content found with id: "+r.PathValue("id"))
+	return nil
+}
+
+func (s *Server) searchContent(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	cql := r.URL.Query().Get("cql")
+	s.Searches = append(s.Searches, cql)
+	pages := s.sortedPages(func(p *Page) bool { return p.Type == "page" && s.matchCQL(p, cql) })
+	start, limit := queryInt(r, "start", 0), queryInt(r, "limit", 25)
+	results := []map[string]any{}
+	for _, p := range page(pages, start, limit) {
+		results = append(results, map[string]any{"content": s.pageJSON(p), "title": p.Title, "entityType": "content"})
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"results": results, "start": start, "limit": limit, "size": len(results), "totalSize": len(pages), "cqlQuery": cql})
+}
+
+// matchCQL evaluates cql against p. Queries without an operator are
+// treated as a plain text search of the title and body.
+func (s *Server) matchCQL(p *Page, cql string) bool {
+	if !strings.ContainsAny(cql, "=~") {
+		return strings.Contains(strings.ToLower(p.Title+" "+p.Body), strings.ToLower(strings.Trim(cql, `"`)))
+	}
+	return evalQuery(cql, func(field string) ([]string, bool) {
+		switch field {
+		case "id":
+			return []string{p.ID}, true
+		case "type":
+			return []string{p.Type}, true
+		case "title":
+			return []string{p.Title}, true
+		case "space":
+			return []string{p.SpaceKey}, true
+		case "label":
+			return p.Labels, true
+		case "parent", "ancestor":
+			return []string{p.ParentID}, true
+		case "text", "sitesearch":
+			return []string{p.Title + " " + p.Body}, true
+		}
+		return nil, false
+	})
+}
+
+func (s *Server) getContent(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	if p := s.lookupPage(w, r); p != nil {
+		writeJSON(w, http.StatusOK, s.pageJSON(p))
+	}
+}
+
+type contentPayload struct {
+	Type  string `json:"type"`
+	Title string `json:"title"`
+	Space *struct {
+		Key string `json:"key"`
+	} `json:"space"`
+	Container *struct {
+		ID string `json:"id"`
+	} `json:"container"`
+	Ancestors []struct {
+		ID string `json:"id"`
+	} `json:"ancestors"`
+	Version *struct {
+		Number int `json:"number"`
+	} `json:"version"`
+	Body struct {
+		Storage struct {
+			Value          string `json:"value"`
+			Representation string `json:"representation"`
+		} `json:"storage"`
+	} `json:"body"`
+}
+
+func (s *Server) createContent(w http.ResponseWriter, r *http.Request) {
+	var payload contentPayload
+	if err := readJSON(r, &payload); err != nil {
+		confluenceError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.Lock()
+	defer s.Unlock()
+	if payload.Type == "comment" {
+		if payload.Container == nil || s.Pages[payload.Container.ID] == nil {
+			confluenceError(w, http.StatusNotFound, "Container not found")
+			return
+		}
+		parent := s.Pages[payload.Container.ID]
+		parent.Comments = append(parent.Comments, payload.Body.Storage.Value)
+		writeJSON(w, http.StatusOK, map[string]any{"id": s.newID(), "type": "comment", "body": payload.Body})
+		return
+	}
+	if payload.Title == "" || payload.Space == nil {
+		confluenceError(w, http.StatusBadRequest, "Content title and space are required")
+		return
+	}
+	for _, p := range s.Pages {
+		if p.SpaceKey == payload.Space.Key && p.Title == payload.Title {
+			confluenceError(w, http.StatusBadRequest, "A page with this title already exists: A page already exists with the title "+payload.Title+" in this space.")
+			return
+		}
+	}
+	p := &Page{ID: s.newID(), Type: "page", Title: payload.Title, SpaceKey: payload.Space.Key, Body: payload.Body.Storage.Value, Version: 1}
+	if len(payload.Ancestors) > 0 {
+		p.ParentID = payload.Ancestors[len(payload.Ancestors)-1].ID
+	}
+	s.Pages[p.ID] = p
+	writeJSON(w, http.StatusOK, s.pageJSON(p))
+}
+
+func (s *Server) updateContent(w http.ResponseWriter, r *http.Request) {
+	var payload contentPayload
+	if err := readJSON(r, &payload); err != nil {
+		confluenceError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.Lock()
+	defer s.Unlock()
+	p := s.lookupPage(w, r)
+	if p == nil {
+		return
+	}
+	if payload.Version == nil || payload.Version.Number != p.Version+1 {
+		confluenceError(w, http.StatusConflict, "Version must be incremented on update. Current version is: "+strconv.Itoa(p.Version))
+		return
+	}
+	p.Version = payload.Version.Number
+	p.Title = payload.Title
+	p.Body = payload.Body.Storage.Value
+	if len(payload.Ancestors) > 0 {
+		p.ParentID = payload.Ancestors[len(payload.Ancestors)-1].ID
+	}
+	writeJSON(w, http.StatusOK, s.pageJSON(p))
+}
+
+func (s *Server) deleteContent(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	if p := s.lookupPage(w, r); p != nil {
+		delete(s.Pages, p.ID)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (s *Server) getChildren(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	parent := s.lookupPage(w, r)
+	if parent == nil {
+		return
+	}
+	start, limit := queryInt(r, "start", 0), queryInt(r, "limit", 25)
+	results := []map[string]any{}
+	total := 0
+	switch r.PathValue("type") {
+	case "page":
+		children := s.sortedPages(func(p *Page) bool { return p.ParentID == parent.ID })
+		total = len(children)
+		for _, p := range page(children, start, limit) {
+			results = append(results, s.pageJSON(p))
+		}
+	case "comment":
+		total = len(parent.Comments)
+		for i, c := range page(parent.Comments, start, limit) {
+			results = append(results, map[string]any{
+				"id": parent.ID + "-c" + strconv.Itoa(start+i), "type": "comment", "title": "Re: " + parent.Title,
+				"body": map[string]any{"storage": map[string]any{"value": c, "representation": "storage"}},
+			})
+		}
+	}
+	// Like Confluence, children are not counted; a next link says there are more.
+	links := map[string]any{}
+	if start+len(results) < total {
+		links["next"] = fmt.Sprintf("%s?start=%d&limit=%d", r.URL.Path, start+len(results), limit)
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"results": results, "start": start, "limit": limit, "size": len(results), "_links": links})
+}
+
+func (s *Server) getLabels(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	if p := s.lookupPage(w, r); p != nil {
+		writeJSON(w, http.StatusOK, labelsJSON(p))
+	}
+}
+
+func labelsJSON(p *Page) map[string]any {
+	results := []map[string]any{}
+	for _, l := range p.Labels {
+		results = append(results, map[string]any{"prefix": "global", "name": l, "id": l})
+	}
+	return map[string]any{"results": results, "start": 0, "limit": 200, "size": len(results)}
+}
+
+func (s *Server) addLabels(w http.ResponseWriter, r *http.Request) {
+	var payload []struct {
+		Prefix string `json:"prefix"`
+		Name   string `json:"name"`
+	}
+	if err := readJSON(r, &payload); err != nil {
+		confluenceError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.Lock()
+	defer s.Unlock()
+	p := s.lookupPage(w, r)
+	if p == nil {
+		return
+	}
+	for _, l := range payload {
+		p.Labels = append(p.Labels, l.Name)
+	}
+	writeJSON(w, http.StatusOK, labelsJSON(p))
+}
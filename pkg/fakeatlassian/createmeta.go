@@ -0,0 +1,167 @@
+package fakeatlassian
+
+import (
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// createMetaFields describes the create screen of an issue type in a project,
+// keyed by field id as in the createmeta response.
+func (s *Server) createMetaFields(project, issueType map[string]any) map[string]any {
+	system := func(id, name, typ string, required bool, allowed []map[string]any) map[string]any {
+		f := map[string]any{
+			"required":        required,
+			"name":            name,
+			"key":             id,
+			"hasDefaultValue": false,
+			"operations":      []string{"set"},
+			"schema":          map[string]any{"type": typ, "system": id},
+		}
+		if allowed != nil {
+			f["allowedValues"] = allowed
+		}
+		return f
+	}
+	array := func(f map[string]any, items string) map[string]any {
+		f["schema"] = map[string]any{"type": "array", "items": items, "system": f["key"]}
+		f["operations"] = []string{"add", "set", "remove"}
+		return f
+	}
+	fields := map[string]any{
+		"summary":     system("summary", "Summary", "string", true, nil),
+		"project":     system("project", "Project", "project", true, []map[string]any{project}),
+		"issuetype":   system("issuetype", "Issue Type", "issuetype", true, []map[string]any{issueType}),
+		"description": system("description", "Description", "string", false, nil),
+		"assignee":    system("assignee", "Assignee", "user", false, nil),
+		"reporter":    system("reporter", "Reporter", "user", false, nil),
+		"priority":    system("priority", "Priority", "priority", false, s.Priorities),
+		"duedate":     system("duedate", "Due Date", "date", false, nil),
+		"labels":      array(system("labels", "Labels", "array", false, nil), "string"),
+		"components":  array(system("components", "Component/s", "array", false, projectItems(s.Components, project)), "component"),
+		"fixVersions": array(system("fixVersions", "Fix Version/s", "array", false, projectItems(s.Versions, project)), "version"),
+		"versions":    array(system("versions", "Affects Version/s", "array", false, projectItems(s.Versions, project)), "version"),
+	}
+	fields["priority"].(map[string]any)["hasDefaultValue"] = true
+	if issueType["subtask"] == true {
+		fields["parent"] = system("parent", "Parent", "issuelink", true, nil)
+	}
+	for _, f := range s.Fields {
+		if f["custom"] != true {
+			continue
+		}
+		id := f["id"].(string)
+		meta := map[string]any{"required": false, "name": f["name"], "key": id, "hasDefaultValue": false, "operations": []string{"set"}, "schema": f["schema"]}
+		if options, ok := s.Options[id]; ok {
+			meta["allowedValues"] = options
+		}
+		fields[id] = meta
+	}
+	for _, id := range s.Required[issueType["name"].(string)] {
+		if f, ok := fields[id].(map[string]any); ok {
+			f["required"] = true
+		}
+	}
+	return fields
+}
+
+// getCreateMeta serves the legacy createmeta resource, filtered by the
+// projectKeys and issuetypeNames parameters. Fields are only included with
+// expand=projects.issuetypes.fields.
+func (s *Server) getCreateMeta(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	q := r.URL.Query()
+	keys := strings.Split(q.Get("projectKeys"), ",")
+	typeNames := strings.Split(q.Get("issuetypeNames"), ",")
+	expand := strings.Contains(q.Get("expand"), "projects.issuetypes.fields")
+	projects := []map[string]any{}
+	for _, p := range s.Projects {
+		if q.Get("projectKeys") != "" && !slices.Contains(keys, p["key"].(string)) {
+			continue
+		}
+		ref := map[string]any{"id": p["id"], "key": p["key"], "name": p["name"]}
+		types := []map[string]any{}
+		out := maps.Clone(ref)
+		for _, it := range s.IssueTypes {
+			if q.Get("issuetypeNames") != "" && !slices.Contains(typeNames, it["name"].(string)) {
+				continue
+			}
+			t := map[string]any{"id": it["id"], "name": it["name"], "subtask": it["subtask"]}
+			if expand {
+				t["fields"] = s.createMetaFields(ref, it)
+			}
+			types = append(types, t)
+		}
+		out["issuetypes"] = types
+		projects = append(projects, out)
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"projects": projects})
+}
+
+// createMetaRoutes serves the paged createmeta resources ahead of next.
+// ServeMux refuses to register them beside /issue/{key}/worklog/{id}, which
+// matches the same paths without either pattern being more specific.
+func (s *Server) createMetaRoutes(next http.Handler) http.Handler {
+	api := JiraPath + "/rest/api/2"
+	mux := http.NewServeMux()
+	mux.Handle("/", next)
+	mux.HandleFunc("GET "+api+"/issue/createmeta/{project}/issuetypes", s.getCreateMetaIssueTypes)
+	mux.HandleFunc("GET "+api+"/issue/createmeta/{project}/issuetypes/{id}", s.getCreateMetaFields)
+	return mux
+}
+
+// getCreateMetaIssueTypes serves the paged issue types of a project that
+// replaced the legacy createmeta resource in Jira 9.
+func (s *Server) getCreateMetaIssueTypes(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	if s.lookupProject(r.PathValue("project")) == nil {
+		jiraError(w, http.StatusNotFound, "Project with key '"+r.PathValue("project")+"' was not found.")
+		return
+	}
+	types := []map[string]any{}
+	for _, it := range s.IssueTypes {
+		types = append(types, map[string]any{"id": it["id"], "name": it["name"], "subtask": it["subtask"]})
+	}
+	writeCreateMetaPage(w, r, types)
+}
+
+// getCreateMetaFields serves the paged fields of an issue type's create
+// screen, each carrying its id as fieldId.
+func (s *Server) getCreateMetaFields(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	project := s.lookupProject(r.PathValue("project"))
+	if project == nil {
+		jiraError(w, http.StatusNotFound, "Project with key '"+r.PathValue("project")+"' was not found.")
+		return
+	}
+	i := slices.IndexFunc(s.IssueTypes, func(it map[string]any) bool { return it["id"] == r.PathValue("id") })
+	if i < 0 {
+		jiraError(w, http.StatusNotFound, "Issue type with id '"+r.PathValue("id")+"' was not found.")
+		return
+	}
+	ref := map[string]any{"id": project["id"], "key": project["key"], "name": project["name"]}
+	fields := []map[string]any{}
+	for id, f := range s.createMetaFields(ref, s.IssueTypes[i]) {
+		f := maps.Clone(f.(map[string]any))
+		f["fieldId"] = id
+		fields = append(fields, f)
+	}
+	slices.SortFunc(fields, func(a, b map[string]any) int { return strings.Compare(a["fieldId"].(string), b["fieldId"].(string)) })
+	writeCreateMetaPage(w, r, fields)
+}
+
+func writeCreateMetaPage(w http.ResponseWriter, r *http.Request, items []map[string]any) {
+	start, limit := queryInt(r, "startAt", 0), queryInt(r, "maxResults", 50)
+	values := page(items, start, limit)
+	writeJSON(w, http.StatusOK, map[string]any{
+		"startAt":    start,
+		"maxResults": limit,
+		"total":      len(items),
+		"isLast":     start+len(values) >= len(items),
+		"values":     values,
+	})
+}
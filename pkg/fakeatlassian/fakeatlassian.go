@@ -0,0 +1,218 @@
+// Package fakeatlassian provides an in-memory Jira and Confluence server for
+// hermetic tests. It implements the subset of the REST APIs used by the tool
+// handlers, behaving like a Server/Data Center instance: Jira is mounted under
+// the "/jira" context path and Confluence under "/confluence", without the
+// Cloud "/wiki" prefix.
+package fakeatlassian
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const (
+	// JiraPath is the context path of the fake Jira instance.
+	JiraPath = "/jira"
+	// ConfluencePath is the context path of the fake Confluence instance.
+	ConfluencePath = "/confluence"
+	// Token is the personal access token accepted by the fake server.
+	Token = "test-token"
+)
+
+// Request is a request received by the fake server.
+type Request struct {
+	Method string
+	Path   string
+	Query  string
+	Body   string
+}
+
+// Server is an in-memory Jira and Confluence instance. The exported maps hold
+// the server state and may be seeded or inspected by tests; use Lock and
+// Unlock when touching them while requests may be in flight.
+type Server struct {
+	*httptest.Server
+	sync.Mutex
+
+	// Jira
+	Deployment  string                      // "Server" or "Cloud", as reported by /serverInfo
+	Issues      map[string]*Issue           // by issue key
+	Projects    []map[string]any            // summaries as listed by /project
+	Components  []map[string]any            // with a "project" key
+	Versions    []map[string]any            // with a "project" key
+	IssueTypes  []map[string]any            // /rest/api/2/issuetype
+	Priorities  []map[string]any            // allowed values of the priority field
+	Options     map[string][]map[string]any // allowed values of select custom fields, by field id
+	Required    map[string][]string         // extra fields required on create, by issue type name
+	Fields      []map[string]any            // /rest/api/2/field
+	Users       []map[string]any            // known users
+	Transitions map[string][]map[string]any // available transitions by status name
+	Worklogs    map[string][]map[string]any // by issue key
+	Comments    map[string][]map[string]any // by issue key
+	Attachments map[string][]byte           // content by attachment id
+	Watchers    map[string][]string         // usernames by issue key
+	Votes       map[string][]string         // usernames by issue key
+	LinkTypes   []map[string]any
+	Links       map[string]map[string]any   // by link id
+	Filters     []map[string]any            // saved filters, with "owner" and "favourite"
+	Dashboards  []map[string]any            // with "owner" and "isFavourite"
+	Gadgets     map[string][]map[string]any // by dashboard id, with item "properties"
+	Roles       map[string]string           // project role names by id
+	Boards      []map[string]any
+	Sprints     map[int]map[string]any // by sprint id
+	SprintIssue map[int][]string       // issue keys by sprint id
+	Rank        []string               // issue keys in rank order, ahead of unranked issues
+	Searches    []string               // JQL received by the search endpoints
+	AppFields   []string               // JQL fields of apps, searchable but missing from the autocomplete data
+	Missing     []string               // path suffixes answered with 404, as by instances without the resource
+
+	// Confluence
+	Pages map[string]*Page // by page id
+
+	Requests []Request
+	nextID   int
+}
+
+// Issue is a Jira issue held by the fake server.
+type Issue struct {
+	ID     string
+	Key    string
+	Fields map[string]any
+}
+
+// Page is a Confluence page held by the fake server.
+type Page struct {
+	ID       string
+	Type     string
+	Title    string
+	SpaceKey string
+	Body     string
+	Version  int
+	ParentID string
+	Labels   []string
+	Comments []string
+}
+
+// New starts a fake server seeded with default data, points the Jira and
+// Confluence environment variables at it and stops it when the test ends.
+func New(t testing.TB) *Server {
+	t.Helper()
+	s := &Server{nextID: 10000}
+	s.seed()
+	mux := http.NewServeMux()
+	s.registerJira(mux)
+	s.registerConfluence(mux)
+	s.Server = httptest.NewServer(s.middleware(s.createMetaRoutes(mux)))
+	t.Cleanup(s.Close)
+
+	t.Setenv("JIRA_URL", s.JiraURL())
+	t.Setenv("JIRA_PERSONAL_TOKEN", Token)
+	t.Setenv("CONFLUENCE_URL", s.ConfluenceURL())
+	t.Setenv("CONFLUENCE_PERSONAL_TOKEN", Token)
+	return s
+}
+
+// JiraURL returns the base URL of the fake Jira instance.
+func (s *Server) JiraURL() string {
+	return s.URL + JiraPath
+}
+
+// ConfluenceURL returns the base URL of the fake Confluence instance.
+func (s *Server) ConfluenceURL() string {
+	return s.URL + ConfluencePath
+}
+
+// RequestsTo returns the recorded requests whose path ends with suffix.
+func (s *Server) RequestsTo(method, suffix string) []Request {
+	s.Lock()
+	defer s.Unlock()
+	var out []Request
+	for _, r := range s.Requests {
+		if r.Method == method && strings.HasSuffix(r.Path, suffix) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// LastSearch returns the most recent JQL received by the search endpoints.
+func (s *Server) LastSearch() string {
+	s.Lock()
+	defer s.Unlock()
+	if len(s.Searches) == 0 {
+		return ""
+	}
+	return s.Searches[len(s.Searches)-1]
+}
+
+func (s *Server) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		r.Body = io.NopCloser(strings.NewReader(string(body)))
+		s.Lock()
+		s.Requests = append(s.Requests, Request{r.Method, r.URL.Path, r.URL.RawQuery, string(body)})
+		s.Unlock()
+		if r.Header.Get("Authorization") != "Bearer "+Token {
+			writeJSON(w, http.StatusUnauthorized, map[string]any{
+				"errorMessages": []string{"You are not authenticated. Authentication required to perform this operation."},
+			})
+			return
+		}
+		s.Lock()
+		missing := slices.ContainsFunc(s.Missing, func(suffix string) bool { return strings.HasSuffix(r.URL.Path, suffix) })
+		s.Unlock()
+		if missing {
+			http.NotFound(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// newID returns a fresh numeric identifier. The caller must hold the lock.
+func (s *Server) newID() string {
+	s.nextID++
+	return strconv.Itoa(s.nextID)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if v != nil {
+		json.NewEncoder(w).Encode(v)
+	}
+}
+
+func jiraError(w http.ResponseWriter, status int, messages ...string) {
+	writeJSON(w, status, map[string]any{"errorMessages": messages, "errors": map[string]string{}})
+}
+
+func readJSON(r *http.Request, v any) error {
+	return json.NewDecoder(r.Body).Decode(v)
+}
+
+func queryInt(r *http.Request, name string, fallback int) int {
+	if v, err := strconv.Atoi(r.URL.Query().Get(name)); err == nil {
+		return v
+	}
+	return fallback
+}
+
+// page slices items according to start and limit, clamping both.
+func page[T any](items []T, start, limit int) []T {
+	if start > len(items) {
+		start = len(items)
+	}
+	end := len(items)
+	if limit > 0 && start+limit < end {
+		end = start + limit
+	}
+	return items[start:end]
+}
@@ -0,0 +1,337 @@
+package fakeatlassian
+
+import (
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+func (s *Server) lookupFilter(w http.ResponseWriter, r *http.Request) map[string]any {
+	for _, f := range s.Filters {
+		if f["id"] == r.PathValue("id") {
+			return f
+		}
+	}
+	jiraError(w, http.StatusBadRequest, "The selected filter is not available to you, perhaps it has been deleted or had its permissions changed.")
+	return nil
+}
+
+// filterJSON hides the share permissions unless expanded, as Jira does for
+// list resources.
+func filterJSON(f map[string]any, expand bool) map[string]any {
+	out := maps.Clone(f)
+	if !expand {
+		delete(out, "sharePermissions")
+	}
+	return out
+}
+
+func ownedBy(f map[string]any, name string) bool {
+	owner, _ := f["owner"].(map[string]any)
+	return owner["name"] == name
+}
+
+func (s *Server) getMyFilters(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	withFavourites := r.URL.Query().Get("includeFavourites") == "true"
+	expand := strings.Contains(r.URL.Query().Get("expand"), "sharePermissions")
+	out := []map[string]any{}
+	for _, f := range s.Filters {
+		if ownedBy(f, currentUser) || (withFavourites && f["favourite"] == true) {
+			out = append(out, filterJSON(f, expand))
+		}
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *Server) getFavouriteFilters(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	out := []map[string]any{}
+	for _, f := range s.Filters {
+		if f["favourite"] == true {
+			out = append(out, filterJSON(f, true))
+		}
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// searchFilters is only available on Cloud.
+func (s *Server) searchFilters(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	if s.Deployment != "Cloud" {
+		jiraError(w, http.StatusNotFound, "null for uri: "+r.URL.String())
+		return
+	}
+	name := strings.ToLower(r.URL.Query().Get("filterName"))
+	values := []map[string]any{}
+	for _, f := range s.Filters {
+		if strings.Contains(strings.ToLower(f["name"].(string)), name) {
+			values = append(values, filterJSON(f, false))
+		}
+	}
+	startAt, maxResults := queryInt(r, "startAt", 0), queryInt(r, "maxResults", 50)
+	writeJSON(w, http.StatusOK, map[string]any{"startAt": startAt, "maxResults": maxResults, "total": len(values), "isLast": true, "values": page(values, startAt, maxResults)})
+}
+
+func (s *Server) getFilter(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	if f := s.lookupFilter(w, r); f != nil {
+		writeJSON(w, http.StatusOK, filterJSON(f, true))
+	}
+}
+
+// sharePermissions gives each share permission an id and expands its project,
+// role and user, as Jira does when a filter is saved.
+func (s *Server) sharePermissions(perms []any) []any {
+	out := []any{}
+	for _, p := range perms {
+		perm := maps.Clone(p.(map[string]any))
+		perm["id"], _ = strconv.Atoi(s.newID())
+		if ref, ok := perm["project"].(map[string]any); ok {
+			if project := s.lookupProject(ref["id"].(string)); project != nil {
+				perm["project"] = map[string]any{"id": project["id"], "key": project["key"], "name": project["name"]}
+			}
+		}
+		if ref, ok := perm["role"].(map[string]any); ok {
+			id := strconv.Itoa(int(ref["id"].(float64)))
+			perm["role"] = map[string]any{"id": ref["id"], "name": s.Roles[id]}
+		}
+		if ref, ok := perm["user"].(map[string]any); ok {
+			name, _ := ref["name"].(string)
+			if id, _ := ref["accountId"].(string); id != "" {
+				name = id
+			}
+			if u := s.lookupUser(name); u != nil {
+				perm["user"] = u
+			}
+		}
+		out = append(out, perm)
+	}
+	return out
+}
+
+func (s *Server) createFilter(w http.ResponseWriter, r *http.Request) {
+	var payload map[string]any
+	if err := readJSON(r, &payload); err != nil {
+		jiraError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.Lock()
+	defer s.Unlock()
+	name, _ := payload["name"].(string)
+	if name == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"errorMessages": []string{}, "errors": map[string]string{"filterName": "You must specify a name to save this filter as."}})
+		return
+	}
+	for _, f := range s.Filters {
+		if ownedBy(f, currentUser) && strings.EqualFold(f["name"].(string), name) {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"errorMessages": []string{}, "errors": map[string]string{"filterName": "Filter with same name already exists."}})
+			return
+		}
+	}
+	id := s.newID()
+	f := map[string]any{
+		"id":               id,
+		"name":             name,
+		"jql":              payload["jql"],
+		"owner":            s.lookupUser(currentUser),
+		"favourite":        payload["favourite"] == true,
+		"sharePermissions": s.sharePermissions(anySlice(payload["sharePermissions"])),
+		"viewUrl":          s.JiraURL() + "/issues/?filter=" + id,
+		"searchUrl":        s.JiraURL() + "/rest/api/2/search?jql=" + url.QueryEscape(payload["jql"].(string)),
+	}
+	if d, ok := payload["description"]; ok {
+		f["description"] = d
+	}
+	s.Filters = append(s.Filters, f)
+	writeJSON(w, http.StatusOK, filterJSON(f, true))
+}
+
+func (s *Server) updateFilter(w http.ResponseWriter, r *http.Request) {
+	var payload map[string]any
+	if err := readJSON(r, &payload); err != nil {
+		jiraError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.Lock()
+	defer s.Unlock()
+	f := s.lookupFilter(w, r)
+	if f == nil {
+		return
+	}
+	if !ownedBy(f, currentUser) {
+		jiraError(w, http.StatusBadRequest, "You must be the owner of the filter to update it.")
+		return
+	}
+	if name, _ := payload["name"].(string); name == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"errorMessages": []string{}, "errors": map[string]string{"filterName": "You must specify a name to save this filter as."}})
+		return
+	}
+	for _, k := range []string{"name", "jql", "description", "favourite"} {
+		if v, ok := payload[k]; ok {
+			f[k] = v
+		}
+	}
+	if perms, ok := payload["sharePermissions"]; ok {
+		f["sharePermissions"] = s.sharePermissions(anySlice(perms))
+	}
+	writeJSON(w, http.StatusOK, filterJSON(f, true))
+}
+
+func (s *Server) deleteFilterPermission(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	f := s.lookupFilter(w, r)
+	if f == nil {
+		return
+	}
+	if !ownedBy(f, currentUser) {
+		jiraError(w, http.StatusBadRequest, "You must be the owner of the filter to update it.")
+		return
+	}
+	perms := anySlice(f["sharePermissions"])
+	i := slices.IndexFunc(perms, func(p any) bool {
+		return fmt.Sprint(p.(map[string]any)["id"]) == r.PathValue("permission")
+	})
+	if i < 0 {
+		jiraError(w, http.StatusNotFound, "Share permission "+r.PathValue("permission")+" does not exist.")
+		return
+	}
+	f["sharePermissions"] = slices.Delete(slices.Clone(perms), i, i+1)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) unfavouriteFilter(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	f := s.lookupFilter(w, r)
+	if f == nil {
+		return
+	}
+	f["favourite"] = false
+	writeJSON(w, http.StatusOK, filterJSON(f, false))
+}
+
+func anySlice(v any) []any {
+	list, _ := v.([]any)
+	return list
+}
+
+func (s *Server) getDashboards(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	filter := r.URL.Query().Get("filter")
+	list := []map[string]any{}
+	for _, d := range s.Dashboards {
+		if (filter == "my" && !ownedBy(d, currentUser)) || (filter == "favourite" && d["isFavourite"] != true) {
+			continue
+		}
+		list = append(list, d)
+	}
+	startAt, maxResults := queryInt(r, "startAt", 0), queryInt(r, "maxResults", 20)
+	writeJSON(w, http.StatusOK, map[string]any{"startAt": startAt, "maxResults": maxResults, "total": len(list), "dashboards": page(list, startAt, maxResults)})
+}
+
+func (s *Server) lookupDashboard(w http.ResponseWriter, r *http.Request) map[string]any {
+	for _, d := range s.Dashboards {
+		if d["id"] == r.PathValue("id") {
+			return d
+		}
+	}
+	jiraError(w, http.StatusNotFound, "The dashboard with id '"+r.PathValue("id")+"' does not exist.")
+	return nil
+}
+
+func (s *Server) getDashboard(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	if d := s.lookupDashboard(w, r); d != nil {
+		writeJSON(w, http.StatusOK, d)
+	}
+}
+
+// getGadgets lists the gadgets of a dashboard, without their properties.
+// Only Cloud has this resource.
+func (s *Server) getGadgets(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	if s.Deployment != "Cloud" {
+		jiraError(w, http.StatusNotFound, "null for uri: "+r.URL.String())
+		return
+	}
+	if s.lookupDashboard(w, r) == nil {
+		return
+	}
+	gadgets := []map[string]any{}
+	for _, g := range s.Gadgets[r.PathValue("id")] {
+		out := maps.Clone(g)
+		delete(out, "properties")
+		gadgets = append(gadgets, out)
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"gadgets": gadgets})
+}
+
+func (s *Server) lookupGadget(w http.ResponseWriter, r *http.Request) map[string]any {
+	for _, g := range s.Gadgets[r.PathValue("id")] {
+		if strconv.Itoa(g["id"].(int)) == r.PathValue("item") {
+			return g
+		}
+	}
+	jiraError(w, http.StatusNotFound, "The dashboard item with id '"+r.PathValue("item")+"' does not exist.")
+	return nil
+}
+
+func (s *Server) getItemPropertyKeys(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	g := s.lookupGadget(w, r)
+	if g == nil {
+		return
+	}
+	props, _ := g["properties"].(map[string]any)
+	keys := []map[string]any{}
+	for _, k := range slices.Sorted(maps.Keys(props)) {
+		keys = append(keys, map[string]any{"key": k, "self": s.JiraURL() + r.URL.Path + "/" + k})
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"keys": keys})
+}
+
+func (s *Server) getItemProperty(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	g := s.lookupGadget(w, r)
+	if g == nil {
+		return
+	}
+	props, _ := g["properties"].(map[string]any)
+	v, ok := props[r.PathValue("key")]
+	if !ok {
+		jiraError(w, http.StatusNotFound, "The property with key '"+r.PathValue("key")+"' does not exist.")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"key": r.PathValue("key"), "value": v})
+}
+
+// getProjectRoles maps role names to their URLs, as Jira does.
+func (s *Server) getProjectRoles(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	p := s.lookupProject(r.PathValue("key"))
+	if p == nil {
+		jiraError(w, http.StatusNotFound, "No project could be found with key '"+r.PathValue("key")+"'.")
+		return
+	}
+	out := map[string]any{}
+	for id, name := range s.Roles {
+		out[name] = s.JiraURL() + "/rest/api/2/project/" + p["id"].(string) + "/role/" + id
+	}
+	writeJSON(w, http.StatusOK, out)
+}
@@ -0,0 +1,1309 @@
+package fakeatlassian
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"maps"
+	"net/http"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+func (s *Server) registerJira(mux *http.ServeMux) {
+	api := "/jira/rest/api/2"
+	mux.HandleFunc("GET "+api+"/myself", s.getMyself)
+	mux.HandleFunc("GET "+api+"/serverInfo", s.getServerInfo)
+	mux.HandleFunc("GET "+api+"/user", s.getUser)
+	mux.HandleFunc("GET "+api+"/user/search", s.searchUsers)
+	mux.HandleFunc("GET "+api+"/user/assignable/search", s.searchUsers)
+	mux.HandleFunc("GET "+api+"/field", s.getFields)
+	mux.HandleFunc("GET "+api+"/field/search", s.searchFields)
+	mux.HandleFunc("POST "+api+"/search", s.search)
+	mux.HandleFunc("GET "+api+"/jql/autocompletedata", s.getJQLAutocomplete)
+	mux.HandleFunc("GET "+api+"/jql/autocompletedata/suggestions", s.getJQLSuggestions)
+	mux.HandleFunc("POST "+api+"/issue", s.createIssue)
+	mux.HandleFunc("GET "+api+"/issue/createmeta", s.getCreateMeta)
+	mux.HandleFunc("GET "+api+"/issue/{key}", s.getIssue)
+	mux.HandleFunc("PUT "+api+"/issue/{key}", s.updateIssue)
+	mux.HandleFunc("DELETE "+api+"/issue/{key}", s.deleteIssue)
+	mux.HandleFunc("GET "+api+"/issue/{key}/transitions", s.getTransitions)
+	mux.HandleFunc("POST "+api+"/issue/{key}/transitions", s.doTransition)
+	mux.HandleFunc("GET "+api+"/issue/{key}/worklog", s.getWorklogs)
+	mux.HandleFunc("POST "+api+"/issue/{key}/worklog", s.addWorklog)
+	mux.HandleFunc("GET "+api+"/issue/{key}/worklog/{id}", s.getWorklog)
+	mux.HandleFunc("PUT "+api+"/issue/{key}/worklog/{id}", s.updateWorklog)
+	mux.HandleFunc("DELETE "+api+"/issue/{key}/worklog/{id}", s.deleteWorklog)
+	mux.HandleFunc("POST "+api+"/issue/{key}/attachments", s.addAttachments)
+	mux.HandleFunc("GET /jira/secure/attachment/{id}/{filename}", s.getAttachmentContent)
+	mux.HandleFunc("GET "+api+"/issue/{key}/comment", s.getComments)
+	mux.HandleFunc("POST "+api+"/issue/{key}/comment", s.addComment)
+	mux.HandleFunc("GET "+api+"/issue/{key}/watchers", s.getWatchers)
+	mux.HandleFunc("POST "+api+"/issue/{key}/watchers", s.addWatcher)
+	mux.HandleFunc("DELETE "+api+"/issue/{key}/watchers", s.removeWatcher)
+	mux.HandleFunc("GET "+api+"/issue/{key}/votes", s.getVotes)
+	mux.HandleFunc("POST "+api+"/issue/{key}/votes", s.addVote)
+	mux.HandleFunc("DELETE "+api+"/issue/{key}/votes", s.removeVote)
+	mux.HandleFunc("GET "+api+"/issue/{key}/comment/{id}", s.getComment)
+	mux.HandleFunc("PUT "+api+"/issue/{key}/comment/{id}", s.updateComment)
+	mux.HandleFunc("DELETE "+api+"/issue/{key}/comment/{id}", s.deleteComment)
+	mux.HandleFunc("GET "+api+"/project", s.getProjects)
+	mux.HandleFunc("GET "+api+"/project/{key}", s.getProject)
+	mux.HandleFunc("GET "+api+"/project/{key}/components", s.getProjectComponents)
+	mux.HandleFunc("GET "+api+"/project/{key}/versions", s.getProjectVersions)
+	mux.HandleFunc("GET "+api+"/project/{key}/statuses", s.getProjectStatuses)
+	mux.HandleFunc("POST "+api+"/component", s.createComponent)
+	mux.HandleFunc("PUT "+api+"/component/{id}", s.updateComponent)
+	mux.HandleFunc("DELETE "+api+"/component/{id}", s.deleteComponent)
+	mux.HandleFunc("POST "+api+"/version", s.createVersion)
+	mux.HandleFunc("GET "+api+"/version/{id}", s.getVersion)
+	mux.HandleFunc("PUT "+api+"/version/{id}", s.updateVersion)
+	mux.HandleFunc("DELETE "+api+"/version/{id}", s.deleteVersion)
+	mux.HandleFunc("GET "+api+"/issuetype", s.getIssueTypes)
+	mux.HandleFunc("GET /jira/rest/projectconfig/1/workflowscheme/{key}", s.getWorkflowScheme)
+	mux.HandleFunc("GET /jira/rest/projectconfig/1/workflow", s.getProjectWorkflow)
+	mux.HandleFunc("GET "+api+"/workflowscheme/project", s.getWorkflowSchemeAssociations)
+	mux.HandleFunc("GET "+api+"/workflow/search", s.searchWorkflows)
+	mux.HandleFunc("GET "+api+"/status", s.getStatuses)
+	mux.HandleFunc("GET "+api+"/project/{key}/role", s.getProjectRoles)
+	mux.HandleFunc("GET "+api+"/filter/my", s.getMyFilters)
+	mux.HandleFunc("GET "+api+"/filter/favourite", s.getFavouriteFilters)
+	mux.HandleFunc("GET "+api+"/filter/search", s.searchFilters)
+	mux.HandleFunc("GET "+api+"/filter/{id}", s.getFilter)
+	mux.HandleFunc("POST "+api+"/filter", s.createFilter)
+	mux.HandleFunc("PUT "+api+"/filter/{id}", s.updateFilter)
+	mux.HandleFunc("DELETE "+api+"/filter/{id}/permission/{permission}", s.deleteFilterPermission)
+	mux.HandleFunc("DELETE "+api+"/filter/{id}/favourite", s.unfavouriteFilter)
+	mux.HandleFunc("GET "+api+"/dashboard", s.getDashboards)
+	mux.HandleFunc("GET "+api+"/dashboard/{id}", s.getDashboard)
+	mux.HandleFunc("GET "+api+"/dashboard/{id}/gadget", s.getGadgets)
+	mux.HandleFunc("GET "+api+"/dashboard/{id}/items/{item}/properties", s.getItemPropertyKeys)
+	mux.HandleFunc("GET "+api+"/dashboard/{id}/items/{item}/properties/{key}", s.getItemProperty)
+	mux.HandleFunc("GET "+api+"/issueLinkType", s.getLinkTypes)
+	mux.HandleFunc("POST "+api+"/issueLink", s.createLink)
+	mux.HandleFunc("DELETE "+api+"/issueLink/{id}", s.deleteLink)
+
+	agile := "/jira/rest/agile/1.0"
+	mux.HandleFunc("GET "+agile+"/board", s.getBoards)
+	mux.HandleFunc("GET "+agile+"/board/{id}", s.getBoard)
+	mux.HandleFunc("GET "+agile+"/board/{id}/issue", s.getBoardIssues)
+	mux.HandleFunc("GET "+agile+"/board/{id}/backlog", s.getBacklog)
+	mux.HandleFunc("GET "+agile+"/board/{id}/configuration", s.getBoardConfiguration)
+	mux.HandleFunc("GET "+agile+"/board/{id}/sprint", s.getBoardSprints)
+	mux.HandleFunc("POST "+agile+"/sprint", s.createSprint)
+	mux.HandleFunc("GET "+agile+"/sprint/{id}", s.getSprint)
+	mux.HandleFunc("POST "+agile+"/sprint/{id}", s.updateSprint)
+	mux.HandleFunc("PUT "+agile+"/sprint/{id}", s.updateSprint)
+	mux.HandleFunc("GET "+agile+"/sprint/{id}/issue", s.getSprintIssues)
+	mux.HandleFunc("POST "+agile+"/sprint/{id}/issue", s.moveToSprint)
+	mux.HandleFunc("POST "+agile+"/backlog/issue", s.moveToBacklog)
+	mux.HandleFunc("PUT "+agile+"/issue/rank", s.rankIssues)
+	mux.HandleFunc("POST "+agile+"/epic/{key}/issue", s.moveToEpic)
+}
+
+// issueJSON renders an issue restricted to the requested fields.
+func (s *Server) issueJSON(issue *Issue, fields []string, expand []string) map[string]any {
+	out := map[string]any{
+		"id":   issue.ID,
+		"key":  issue.Key,
+		"self": s.JiraURL() + "/rest/api/2/issue/" + issue.ID,
+	}
+	all := len(fields) == 0 || (len(fields) == 1 && (fields[0] == "*all" || fields[0] == "*navigable"))
+	f := map[string]any{}
+	for k, v := range issue.Fields {
+		if all || contains(fields, k) {
+			f[k] = v
+		}
+	}
+	if all || contains(fields, "comment") {
+		comments := s.Comments[issue.Key]
+		f["comment"] = map[string]any{"comments": comments, "total": len(comments), "maxResults": len(comments), "startAt": 0}
+	}
+	out["fields"] = f
+	if contains(expand, "transitions") {
+		out["transitions"] = s.transitionsFor(issue)
+	}
+	return out
+}
+
+func (s *Server) transitionsFor(issue *Issue) []map[string]any {
+	status, _ := issue.Fields["status"].(map[string]any)
+	name, _ := status["name"].(string)
+	return s.Transitions[name]
+}
+
+func (s *Server) lookupIssue(w http.ResponseWriter, r *http.Request) *Issue {
+	key := r.PathValue("key")
+	for _, issue := range s.Issues {
+		if issue.Key == key || issue.ID == key {
+			return issue
+		}
+	}
+	jiraError(w, http.StatusNotFound, "Issue Does Not Exist")
+	return nil
+}
+
+func (s *Server) lookupUser(name string) map[string]any {
+	for _, u := range s.Users {
+		if strings.EqualFold(u["name"].(string), name) || u["key"] == name {
+			return u
+		}
+	}
+	return nil
+}
+
+// lookupUserID finds a user by account ID on Cloud, or by username or key on
+// Server, the way endpoints that take a bare user identifier do.
+func (s *Server) lookupUserID(id string) map[string]any {
+	if s.Deployment != "Cloud" {
+		return s.lookupUser(id)
+	}
+	for _, u := range s.Users {
+		if u["accountId"] == id {
+			return u
+		}
+	}
+	return nil
+}
+
+func (s *Server) getMyself(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	writeJSON(w, http.StatusOK, s.lookupUser(currentUser))
+}
+
+func (s *Server) getServerInfo(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	writeJSON(w, http.StatusOK, map[string]any{"baseUrl": s.JiraURL(), "version": "9.12.0", "deploymentType": s.Deployment})
+}
+
+func (s *Server) getUser(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	q := r.URL.Query()
+	if id := q.Get("accountId"); id != "" {
+		if s.Deployment != "Cloud" {
+			jiraError(w, http.StatusBadRequest, "The accountId query parameter is not supported on Jira Server.")
+			return
+		}
+		for _, u := range s.Users {
+			if u["accountId"] == id {
+				writeJSON(w, http.StatusOK, u)
+				return
+			}
+		}
+		jiraError(w, http.StatusNotFound, "The user with account ID '"+id+"' does not exist")
+		return
+	}
+	name := q.Get("username")
+	if name == "" {
+		name = q.Get("key")
+	}
+	if u := s.lookupUser(name); u != nil {
+		writeJSON(w, http.StatusOK, u)
+		return
+	}
+	jiraError(w, http.StatusNotFound, "The user named '"+name+"' does not exist")
+}
+
+// searchUsers matches the username parameter on Server, or query on Cloud,
+// against usernames, display names and emails. The assignable variant
+// requires a known project and leaves out inactive users.
+func (s *Server) searchUsers(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	q := r.URL.Query()
+	query := q.Get("username")
+	if s.Deployment == "Cloud" {
+		query = q.Get("query")
+	}
+	if query == "" {
+		jiraError(w, http.StatusBadRequest, "The username query parameter was not provided")
+		return
+	}
+	assignable := strings.HasSuffix(r.URL.Path, "/assignable/search")
+	if assignable && !s.projectExists(q.Get("project")) {
+		jiraError(w, http.StatusNotFound, "No project could be found with key '"+q.Get("project")+"'.")
+		return
+	}
+	query = strings.ToLower(query)
+	users := []map[string]any{}
+	for _, u := range s.Users {
+		if assignable && u["active"] != true {
+			continue
+		}
+		for _, v := range names(u, "name", "displayName", "emailAddress") {
+			if strings.Contains(strings.ToLower(v), query) {
+				users = append(users, u)
+				break
+			}
+		}
+	}
+	if n := queryInt(r, "maxResults", 50); len(users) > n {
+		users = users[:n]
+	}
+	writeJSON(w, http.StatusOK, users)
+}
+
+// validateCustomFields rejects unknown custom fields and values that do not
+// match the field schema, the way Jira does.
+func (s *Server) validateCustomFields(fields map[string]any, errs map[string]string) {
+	for id, value := range fields {
+		if !strings.HasPrefix(id, "customfield_") {
+			continue
+		}
+		var schema map[string]any
+		for _, f := range s.Fields {
+			if f["id"] == id {
+				schema, _ = f["schema"].(map[string]any)
+			}
+		}
+		if schema == nil {
+			errs[id] = "Field '" + id + "' cannot be set. It is not on the appropriate screen, or unknown."
+			continue
+		}
+		if value == nil {
+			continue
+		}
+		switch schema["type"] {
+		case "number":
+			if _, ok := value.(float64); !ok {
+				errs[id] = "Operation value must be a number"
+			}
+		case "option":
+			if m, ok := value.(map[string]any); !ok || (m["value"] == nil && m["id"] == nil) {
+				errs[id] = "Could not find valid 'id' or 'value' in the Parent Option object."
+			}
+		case "date":
+			if v, ok := value.(string); !ok || len(v) != len("2006-01-02") {
+				errs[id] = "Error parsing date string: " + fmt.Sprint(value)
+			}
+		}
+	}
+}
+
+func (s *Server) getFields(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	writeJSON(w, http.StatusOK, s.Fields)
+}
+
+func (s *Server) searchFields(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	query := strings.ToLower(r.URL.Query().Get("query"))
+	var matched []map[string]any
+	for _, f := range s.Fields {
+		if query == "" || strings.Contains(strings.ToLower(f["name"].(string)), query) || strings.Contains(strings.ToLower(f["id"].(string)), query) {
+			matched = append(matched, f)
+		}
+	}
+	startAt, maxResults := queryInt(r, "startAt", 0), queryInt(r, "maxResults", 50)
+	writeJSON(w, http.StatusOK, map[string]any{
+		"startAt": startAt, "maxResults": maxResults, "total": len(matched),
+		"isLast": startAt+maxResults >= len(matched), "values": page(matched, startAt, maxResults),
+	})
+}
+
+// sortedIssues returns the issues matching jql ordered by key.
+func (s *Server) sortedIssues(jql string) []*Issue {
+	var out []*Issue
+	for _, issue := range s.Issues {
+		if s.matchJQL(issue, jql) {
+			out = append(out, issue)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return issueNumber(out[i].Key) < issueNumber(out[j].Key) || (issueNumber(out[i].Key) == issueNumber(out[j].Key) && out[i].Key < out[j].Key)
+	})
+	return out
+}
+
+func issueNumber(key string) int {
+	n, _ := strconv.Atoi(key[strings.LastIndex(key, "-")+1:])
+	return n
+}
+
+func (s *Server) searchResult(issues []*Issue, startAt, maxResults int, fields, expand []string) map[string]any {
+	if maxResults <= 0 {
+		maxResults = 50
+	}
+	rendered := []map[string]any{}
+	for _, issue := range page(issues, startAt, maxResults) {
+		rendered = append(rendered, s.issueJSON(issue, fields, expand))
+	}
+	return map[string]any{"startAt": startAt, "maxResults": maxResults, "total": len(issues), "issues": rendered}
+}
+
+func (s *Server) search(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		JQL        string   `json:"jql"`
+		StartAt    int      `json:"startAt"`
+		MaxResults int      `json:"maxResults"`
+		Fields     []string `json:"fields"`
+		Expand     []string `json:"expand"`
+	}
+	if err := readJSON(r, &payload); err != nil {
+		jiraError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.Lock()
+	defer s.Unlock()
+	s.Searches = append(s.Searches, payload.JQL)
+	if strings.Count(payload.JQL, "(") != strings.Count(payload.JQL, ")") {
+		jiraError(w, http.StatusBadRequest, "Error in the JQL Query: Expecting ')' before the end of the query.")
+		return
+	}
+	if field := s.unknownJQLField(payload.JQL); field != "" {
+		jiraError(w, http.StatusBadRequest, "Field '"+field+"' does not exist or you do not have permission to view it.")
+		return
+	}
+	writeJSON(w, http.StatusOK, s.searchResult(s.sortedIssues(payload.JQL), payload.StartAt, payload.MaxResults, payload.Fields, payload.Expand))
+}
+
+func (s *Server) getIssue(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	issue := s.lookupIssue(w, r)
+	if issue == nil {
+		return
+	}
+	q := r.URL.Query()
+	var fields, expand []string
+	if v := q.Get("fields"); v != "" {
+		fields = strings.Split(v, ",")
+	}
+	if v := q.Get("expand"); v != "" {
+		expand = strings.Split(v, ",")
+	}
+	writeJSON(w, http.StatusOK, s.issueJSON(issue, fields, expand))
+}
+
+func (s *Server) createIssue(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		Fields map[string]any `json:"fields"`
+	}
+	if err := readJSON(r, &payload); err != nil {
+		jiraError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.Lock()
+	defer s.Unlock()
+	errs := map[string]string{}
+	if summary, _ := payload.Fields["summary"].(string); summary == "" {
+		errs["summary"] = "You must specify a summary of the issue."
+	}
+	project, _ := payload.Fields["project"].(map[string]any)
+	projectKey, _ := project["key"].(string)
+	if p := s.lookupProject(projectKey); p != nil {
+		projectKey = p["key"].(string)
+		payload.Fields["project"] = map[string]any{"id": p["id"], "key": projectKey, "name": p["name"]}
+	} else {
+		errs["project"] = "valid project is required"
+	}
+	if assignee, ok := payload.Fields["assignee"].(map[string]any); ok {
+		if name, _ := assignee["name"].(string); name != "" && s.lookupUser(name) == nil {
+			errs["assignee"] = "User '" + name + "' does not exist."
+		}
+		if id, _ := assignee["accountId"].(string); id != "" && !slices.ContainsFunc(s.Users, func(u map[string]any) bool { return u["accountId"] == id }) {
+			errs["assignee"] = "Specified user does not exist or you do not have required permissions"
+		}
+	}
+	s.validateCustomFields(payload.Fields, errs)
+	// Like Jira, store the full issue type whether it was given by id or name.
+	issueType, _ := payload.Fields["issuetype"].(map[string]any)
+	for _, it := range s.IssueTypes {
+		if it["id"] == issueType["id"] || it["name"] == issueType["name"] {
+			issueType = maps.Clone(it)
+			payload.Fields["issuetype"] = issueType
+		}
+	}
+	typeName, _ := issueType["name"].(string)
+	if parent, ok := payload.Fields["parent"].(map[string]any); ok {
+		if key, _ := parent["key"].(string); s.Issues[key] == nil {
+			errs["parent"] = "Could not find issue by id or key."
+		}
+	} else if issueType["subtask"] == true {
+		errs["issuetype"] = "Issue type is a sub-task but parent issue key or id not specified."
+	}
+	for _, id := range s.Required[typeName] {
+		if payload.Fields[id] == nil {
+			errs[id] = id + " is required."
+		}
+	}
+	if len(errs) > 0 {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"errorMessages": []string{}, "errors": errs})
+		return
+	}
+	max := 0
+	for _, issue := range s.Issues {
+		if strings.HasPrefix(issue.Key, projectKey+"-") && issueNumber(issue.Key) > max {
+			max = issueNumber(issue.Key)
+		}
+	}
+	issue := &Issue{ID: s.newID(), Key: projectKey + "-" + strconv.Itoa(max+1), Fields: payload.Fields}
+	issue.Fields["status"] = map[string]any{"id": "1", "name": "To Do", "statusCategory": map[string]any{"key": "new"}}
+	if _, ok := issue.Fields["labels"]; !ok {
+		issue.Fields["labels"] = []any{}
+	}
+	s.Issues[issue.Key] = issue
+	writeJSON(w, http.StatusCreated, map[string]any{"id": issue.ID, "key": issue.Key, "self": s.JiraURL() + "/rest/api/2/issue/" + issue.ID})
+}
+
+func (s *Server) updateIssue(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		Fields map[string]any              `json:"fields"`
+		Update map[string][]map[string]any `json:"update"`
+	}
+	if err := readJSON(r, &payload); err != nil {
+		jiraError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.Lock()
+	defer s.Unlock()
+	issue := s.lookupIssue(w, r)
+	if issue == nil {
+		return
+	}
+	errs := map[string]string{}
+	s.validateCustomFields(payload.Fields, errs)
+	if len(errs) > 0 {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"errorMessages": []string{}, "errors": errs})
+		return
+	}
+	for k, v := range payload.Fields {
+		issue.Fields[k] = v
+	}
+	for field, ops := range payload.Update {
+		for _, op := range ops {
+			s.applyUpdateOp(issue, field, op)
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// applyUpdateOp applies one entry of the "update" section of an edit request.
+func (s *Server) applyUpdateOp(issue *Issue, field string, op map[string]any) {
+	if field == "comment" {
+		if add, ok := op["add"].(map[string]any); ok {
+			s.appendComment(issue.Key, add)
+		}
+		return
+	}
+	if field == "worklog" {
+		if add, ok := op["add"].(map[string]any); ok {
+			add["id"] = s.newID()
+			add["issueId"] = issue.ID
+			add["author"] = s.lookupUser(currentUser)
+			s.Worklogs[issue.Key] = append(s.Worklogs[issue.Key], add)
+		}
+		return
+	}
+	list, _ := issue.Fields[field].([]any)
+	for verb, value := range op {
+		switch verb {
+		case "set":
+			issue.Fields[field] = value
+		case "add":
+			list = append(list, value)
+			issue.Fields[field] = list
+		case "remove":
+			var kept []any
+			for _, item := range list {
+				if !sameValue(item, value) {
+					kept = append(kept, item)
+				}
+			}
+			if kept == nil {
+				kept = []any{}
+			}
+			issue.Fields[field] = kept
+		}
+	}
+}
+
+func sameValue(a, b any) bool {
+	am, aok := a.(map[string]any)
+	bm, bok := b.(map[string]any)
+	if aok && bok {
+		// Compare objects on the first identifier both carry, so {"id": ...}
+		// matches a stored value that also has a name.
+		for _, k := range []string{"id", "name", "key", "value"} {
+			if av, ok := am[k]; ok {
+				if bv, ok := bm[k]; ok {
+					return av == bv
+				}
+			}
+		}
+		return false
+	}
+	an, bn := names(a, "name", "id", "key", "value"), names(b, "name", "id", "key", "value")
+	return len(an) > 0 && len(bn) > 0 && an[0] == bn[0]
+}
+
+func (s *Server) deleteIssue(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	issue := s.lookupIssue(w, r)
+	if issue == nil {
+		return
+	}
+	delete(s.Issues, issue.Key)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) getTransitions(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	issue := s.lookupIssue(w, r)
+	if issue == nil {
+		return
+	}
+	// Screen fields are only included when expanded, as in Jira.
+	withFields := contains(strings.Split(r.URL.Query().Get("expand"), ","), "transitions.fields")
+	transitions := []map[string]any{}
+	for _, t := range s.transitionsFor(issue) {
+		out := map[string]any{}
+		for k, v := range t {
+			if k != "fields" || withFields {
+				out[k] = v
+			}
+		}
+		if withFields && out["fields"] == nil {
+			out["fields"] = map[string]any{}
+		}
+		transitions = append(transitions, out)
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"expand": "transitions", "transitions": transitions})
+}
+
+func (s *Server) doTransition(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		Transition struct {
+			ID string `json:"id"`
+		} `json:"transition"`
+		Fields map[string]any              `json:"fields"`
+		Update map[string][]map[string]any `json:"update"`
+	}
+	if err := readJSON(r, &payload); err != nil {
+		jiraError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.Lock()
+	defer s.Unlock()
+	issue := s.lookupIssue(w, r)
+	if issue == nil {
+		return
+	}
+	for _, t := range s.transitionsFor(issue) {
+		if t["id"] == payload.Transition.ID {
+			screen, _ := t["fields"].(map[string]any)
+			errs := map[string]string{}
+			for id, f := range screen {
+				field, _ := f.(map[string]any)
+				if field["required"] == true && payload.Fields[id] == nil {
+					errs[id] = fmt.Sprintf("%v is required.", field["name"])
+				}
+			}
+			if len(errs) > 0 {
+				writeJSON(w, http.StatusBadRequest, map[string]any{"errorMessages": []string{}, "errors": errs})
+				return
+			}
+			for k, v := range payload.Fields {
+				issue.Fields[k] = v
+			}
+			for field, ops := range payload.Update {
+				for _, op := range ops {
+					s.applyUpdateOp(issue, field, op)
+				}
+			}
+			issue.Fields["status"] = t["to"]
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+	jiraError(w, http.StatusBadRequest, "It seems that you have tried to perform a workflow operation ("+payload.Transition.ID+") that is not valid for the current state of this issue ("+issue.Key+").")
+}
+
+func (s *Server) getWorklogs(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	issue := s.lookupIssue(w, r)
+	if issue == nil {
+		return
+	}
+	logs := s.Worklogs[issue.Key]
+	if logs == nil {
+		logs = []map[string]any{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"startAt": 0, "maxResults": len(logs), "total": len(logs), "worklogs": logs})
+}
+
+func (s *Server) addWorklog(w http.ResponseWriter, r *http.Request) {
+	var payload map[string]any
+	if err := readJSON(r, &payload); err != nil {
+		jiraError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.Lock()
+	defer s.Unlock()
+	issue := s.lookupIssue(w, r)
+	if issue == nil {
+		return
+	}
+	if payload["timeSpent"] == nil && payload["timeSpentSeconds"] == nil {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"errorMessages": []string{}, "errors": map[string]string{"timeLogged": "You must indicate the time spent working."}})
+		return
+	}
+	if !s.adjustEstimate(w, r, issue, "reduceBy") {
+		return
+	}
+	payload["id"] = s.newID()
+	payload["issueId"] = issue.ID
+	payload["author"] = s.lookupUser(currentUser)
+	s.Worklogs[issue.Key] = append(s.Worklogs[issue.Key], payload)
+	writeJSON(w, http.StatusCreated, payload)
+}
+
+// adjustEstimate checks the adjustEstimate query of a worklog write and
+// records a new remaining estimate. manual names the parameter that manual
+// adjustment takes, or is empty where Jira does not allow it.
+func (s *Server) adjustEstimate(w http.ResponseWriter, r *http.Request, issue *Issue, manual string) bool {
+	q := r.URL.Query()
+	switch q.Get("adjustEstimate") {
+	case "", "auto", "leave":
+	case "new":
+		if q.Get("newEstimate") == "" {
+			jiraError(w, http.StatusBadRequest, "You must supply a valid new estimate.")
+			return false
+		}
+		tt, _ := issue.Fields["timetracking"].(map[string]any)
+		if tt == nil {
+			tt = map[string]any{}
+			issue.Fields["timetracking"] = tt
+		}
+		tt["remainingEstimate"] = q.Get("newEstimate")
+	case "manual":
+		if manual == "" || q.Get(manual) == "" {
+			jiraError(w, http.StatusBadRequest, "You must supply a valid amount of time to adjust the estimate by.")
+			return false
+		}
+	default:
+		jiraError(w, http.StatusBadRequest, "Invalid value for adjustEstimate: "+q.Get("adjustEstimate"))
+		return false
+	}
+	return true
+}
+
+func (s *Server) lookupWorklog(w http.ResponseWriter, r *http.Request) (*Issue, int) {
+	issue := s.lookupIssue(w, r)
+	if issue == nil {
+		return nil, -1
+	}
+	for i, l := range s.Worklogs[issue.Key] {
+		if l["id"] == r.PathValue("id") {
+			return issue, i
+		}
+	}
+	jiraError(w, http.StatusNotFound, "Cannot find worklog with id: "+r.PathValue("id"))
+	return nil, -1
+}
+
+func (s *Server) getWorklog(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	issue, i := s.lookupWorklog(w, r)
+	if issue == nil {
+		return
+	}
+	writeJSON(w, http.StatusOK, s.Worklogs[issue.Key][i])
+}
+
+func (s *Server) updateWorklog(w http.ResponseWriter, r *http.Request) {
+	var payload map[string]any
+	if err := readJSON(r, &payload); err != nil {
+		jiraError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.Lock()
+	defer s.Unlock()
+	issue, i := s.lookupWorklog(w, r)
+	if issue == nil || !s.adjustEstimate(w, r, issue, "") {
+		return
+	}
+	l := s.Worklogs[issue.Key][i]
+	for _, k := range []string{"timeSpent", "timeSpentSeconds", "started", "comment"} {
+		if v, ok := payload[k]; ok {
+			l[k] = v
+		}
+	}
+	if _, ok := payload["timeSpent"]; ok {
+		delete(l, "timeSpentSeconds")
+	}
+	l["updateAuthor"] = s.lookupUser(currentUser)
+	writeJSON(w, http.StatusOK, l)
+}
+
+func (s *Server) deleteWorklog(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	issue, i := s.lookupWorklog(w, r)
+	if issue == nil || !s.adjustEstimate(w, r, issue, "increaseBy") {
+		return
+	}
+	s.Worklogs[issue.Key] = slices.Delete(s.Worklogs[issue.Key], i, i+1)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) getComments(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	issue := s.lookupIssue(w, r)
+	if issue == nil {
+		return
+	}
+	comments := s.Comments[issue.Key]
+	if r.URL.Query().Get("orderBy") == "-created" {
+		comments = slices.Clone(comments)
+		slices.Reverse(comments)
+	}
+	startAt, maxResults := queryInt(r, "startAt", 0), queryInt(r, "maxResults", 50)
+	out := []map[string]any{}
+	for _, c := range page(comments, startAt, maxResults) {
+		out = append(out, commentJSON(c, r))
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"startAt": startAt, "maxResults": maxResults, "total": len(comments), "comments": out})
+}
+
+// commentJSON adds a rendered body when requested with expand=renderedBody.
+func commentJSON(c map[string]any, r *http.Request) map[string]any {
+	if !contains(strings.Split(r.URL.Query().Get("expand"), ","), "renderedBody") {
+		return c
+	}
+	out := maps.Clone(c)
+	body, _ := c["body"].(string)
+	out["renderedBody"] = "<p>" + wikiBold.ReplaceAllString(html.EscapeString(body), "<b>$1</b>") + "</p>"
+	return out
+}
+
+var wikiBold = regexp.MustCompile(`\*([^*]+)\*`)
+
+func (s *Server) lookupComment(w http.ResponseWriter, r *http.Request) (*Issue, int) {
+	issue := s.lookupIssue(w, r)
+	if issue == nil {
+		return nil, -1
+	}
+	for i, c := range s.Comments[issue.Key] {
+		if c["id"] == r.PathValue("id") {
+			return issue, i
+		}
+	}
+	jiraError(w, http.StatusNotFound, "Can not find a comment for the id: "+r.PathValue("id")+".")
+	return nil, -1
+}
+
+func (s *Server) getComment(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	issue, i := s.lookupComment(w, r)
+	if issue == nil {
+		return
+	}
+	writeJSON(w, http.StatusOK, commentJSON(s.Comments[issue.Key][i], r))
+}
+
+func (s *Server) updateComment(w http.ResponseWriter, r *http.Request) {
+	var payload map[string]any
+	if err := readJSON(r, &payload); err != nil {
+		jiraError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.Lock()
+	defer s.Unlock()
+	issue, i := s.lookupComment(w, r)
+	if issue == nil {
+		return
+	}
+	c := s.Comments[issue.Key][i]
+	if body, ok := payload["body"]; ok {
+		c["body"] = body
+	}
+	// Like Jira, an update without visibility makes the comment public.
+	if v, ok := payload["visibility"]; ok {
+		c["visibility"] = v
+	} else {
+		delete(c, "visibility")
+	}
+	c["updateAuthor"] = s.lookupUser(currentUser)
+	c["updated"] = "2024-02-02T10:00:00.000+0000"
+	writeJSON(w, http.StatusOK, c)
+}
+
+func (s *Server) deleteComment(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	issue, i := s.lookupComment(w, r)
+	if issue == nil {
+		return
+	}
+	s.Comments[issue.Key] = slices.Delete(s.Comments[issue.Key], i, i+1)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) appendComment(key string, payload map[string]any) map[string]any {
+	comment := map[string]any{
+		"id":      s.newID(),
+		"author":  s.lookupUser(currentUser),
+		"body":    payload["body"],
+		"created": "2024-02-01T10:00:00.000+0000",
+		"updated": "2024-02-01T10:00:00.000+0000",
+	}
+	if v, ok := payload["visibility"]; ok {
+		comment["visibility"] = v
+	}
+	s.Comments[key] = append(s.Comments[key], comment)
+	return comment
+}
+
+func (s *Server) addComment(w http.ResponseWriter, r *http.Request) {
+	var payload map[string]any
+	if err := readJSON(r, &payload); err != nil {
+		jiraError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.Lock()
+	defer s.Unlock()
+	issue := s.lookupIssue(w, r)
+	if issue == nil {
+		return
+	}
+	writeJSON(w, http.StatusCreated, s.appendComment(issue.Key, payload))
+}
+
+func (s *Server) getLinkTypes(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	writeJSON(w, http.StatusOK, map[string]any{"issueLinkTypes": s.LinkTypes})
+}
+
+func (s *Server) createLink(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		Type         map[string]any `json:"type"`
+		InwardIssue  map[string]any `json:"inwardIssue"`
+		OutwardIssue map[string]any `json:"outwardIssue"`
+	}
+	if err := readJSON(r, &payload); err != nil {
+		jiraError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.Lock()
+	defer s.Unlock()
+	var linkType map[string]any
+	for _, lt := range s.LinkTypes {
+		if lt["name"] == payload.Type["name"] || lt["id"] == payload.Type["id"] {
+			linkType = lt
+		}
+	}
+	if linkType == nil {
+		jiraError(w, http.StatusNotFound, "No issue link type with name '"+names(payload.Type, "name")[0]+"' found.")
+		return
+	}
+	inward, outward := s.Issues[payload.InwardIssue["key"].(string)], s.Issues[payload.OutwardIssue["key"].(string)]
+	if inward == nil || outward == nil {
+		jiraError(w, http.StatusNotFound, "Issue Does Not Exist")
+		return
+	}
+	id := s.newID()
+	s.Links[id] = map[string]any{"id": id, "type": linkType, "inwardIssue": inward.Key, "outwardIssue": outward.Key}
+	s.linkIssues(id, linkType, inward, outward)
+	w.WriteHeader(http.StatusCreated)
+}
+
+// Link links two existing issues with the named link type, as creating the
+// link through the API would: inward <outward description> outward.
+func (s *Server) Link(typeName, inwardKey, outwardKey string) {
+	s.Lock()
+	defer s.Unlock()
+	for _, lt := range s.LinkTypes {
+		if lt["name"] == typeName {
+			id := s.newID()
+			s.Links[id] = map[string]any{"id": id, "type": lt, "inwardIssue": inwardKey, "outwardIssue": outwardKey}
+			s.linkIssues(id, lt, s.Issues[inwardKey], s.Issues[outwardKey])
+			return
+		}
+	}
+	panic("unknown link type " + typeName)
+}
+
+// linkIssues records the link in the issuelinks field of both issues.
+func (s *Server) linkIssues(id string, linkType map[string]any, inward, outward *Issue) {
+	ref := func(issue *Issue) map[string]any {
+		return map[string]any{"id": issue.ID, "key": issue.Key, "fields": map[string]any{
+			"summary": issue.Fields["summary"], "status": issue.Fields["status"], "issuetype": issue.Fields["issuetype"],
+		}}
+	}
+	inLinks, _ := inward.Fields["issuelinks"].([]any)
+	inward.Fields["issuelinks"] = append(inLinks, map[string]any{"id": id, "type": linkType, "outwardIssue": ref(outward)})
+	outLinks, _ := outward.Fields["issuelinks"].([]any)
+	outward.Fields["issuelinks"] = append(outLinks, map[string]any{"id": id, "type": linkType, "inwardIssue": ref(inward)})
+}
+
+func (s *Server) deleteLink(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	id := r.PathValue("id")
+	if _, ok := s.Links[id]; !ok {
+		jiraError(w, http.StatusNotFound, "No issue link with id '"+id+"' exists.")
+		return
+	}
+	delete(s.Links, id)
+	for _, issue := range s.Issues {
+		links, _ := issue.Fields["issuelinks"].([]any)
+		var kept []any
+		for _, l := range links {
+			if l.(map[string]any)["id"] != id {
+				kept = append(kept, l)
+			}
+		}
+		if links != nil {
+			issue.Fields["issuelinks"] = kept
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) getBoards(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	q := r.URL.Query()
+	var matched []map[string]any
+	for _, b := range s.Boards {
+		if name := q.Get("name"); name != "" && !strings.Contains(strings.ToLower(b["name"].(string)), strings.ToLower(name)) {
+			continue
+		}
+		if typ := q.Get("type"); typ != "" && b["type"] != typ {
+			continue
+		}
+		if project := q.Get("projectKeyOrId"); project != "" && b["location"].(map[string]any)["projectKey"] != project {
+			continue
+		}
+		matched = append(matched, b)
+	}
+	startAt, maxResults := queryInt(r, "startAt", 0), queryInt(r, "maxResults", 50)
+	writeJSON(w, http.StatusOK, map[string]any{"startAt": startAt, "maxResults": maxResults, "total": len(matched), "isLast": true, "values": page(matched, startAt, maxResults)})
+}
+
+func (s *Server) lookupBoard(w http.ResponseWriter, r *http.Request) map[string]any {
+	id, _ := strconv.Atoi(r.PathValue("id"))
+	for _, b := range s.Boards {
+		if b["id"] == id {
+			return b
+		}
+	}
+	jiraError(w, http.StatusNotFound, "Board does not exist or you do not have permission to see it.")
+	return nil
+}
+
+func (s *Server) getBoard(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	if board := s.lookupBoard(w, r); board != nil {
+		writeJSON(w, http.StatusOK, board)
+	}
+}
+
+func (s *Server) getBoardIssues(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	board := s.lookupBoard(w, r)
+	if board == nil {
+		return
+	}
+	q := r.URL.Query()
+	jql := "project = " + board["location"].(map[string]any)["projectKey"].(string)
+	if extra := q.Get("jql"); extra != "" {
+		jql += " AND (" + extra + ")"
+		s.Searches = append(s.Searches, extra)
+	}
+	var fields []string
+	if v := q.Get("fields"); v != "" {
+		fields = strings.Split(v, ",")
+	}
+	writeJSON(w, http.StatusOK, s.searchResult(s.sortedIssues(jql), queryInt(r, "startAt", 0), queryInt(r, "maxResults", 50), fields, nil))
+}
+
+func (s *Server) getBoardSprints(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	board := s.lookupBoard(w, r)
+	if board == nil {
+		return
+	}
+	states := strings.Split(r.URL.Query().Get("state"), ",")
+	var matched []map[string]any
+	for _, id := range s.sprintIDs() {
+		sp := s.Sprints[id]
+		if sp["originBoardId"] != board["id"] {
+			continue
+		}
+		if states[0] != "" && !contains(states, sp["state"].(string)) {
+			continue
+		}
+		matched = append(matched, sp)
+	}
+	startAt, maxResults := queryInt(r, "startAt", 0), queryInt(r, "maxResults", 50)
+	writeJSON(w, http.StatusOK, map[string]any{"startAt": startAt, "maxResults": maxResults, "isLast": true, "values": page(matched, startAt, maxResults)})
+}
+
+func (s *Server) sprintIDs() []int {
+	ids := make([]int, 0, len(s.Sprints))
+	for id := range s.Sprints {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+func (s *Server) createSprint(w http.ResponseWriter, r *http.Request) {
+	var payload map[string]any
+	if err := readJSON(r, &payload); err != nil {
+		jiraError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.Lock()
+	defer s.Unlock()
+	id := 100 + len(s.Sprints)
+	for s.Sprints[id] != nil {
+		id++
+	}
+	payload["id"] = id
+	payload["state"] = "future"
+	if v, ok := payload["originBoardId"].(float64); ok {
+		payload["originBoardId"] = int(v)
+	}
+	s.Sprints[id] = payload
+	writeJSON(w, http.StatusCreated, payload)
+}
+
+func (s *Server) lookupSprint(w http.ResponseWriter, r *http.Request) (int, map[string]any) {
+	id, _ := strconv.Atoi(r.PathValue("id"))
+	if sp, ok := s.Sprints[id]; ok {
+		return id, sp
+	}
+	jiraError(w, http.StatusNotFound, "Sprint does not exist")
+	return id, nil
+}
+
+func (s *Server) getSprint(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	if _, sp := s.lookupSprint(w, r); sp != nil {
+		writeJSON(w, http.StatusOK, sp)
+	}
+}
+
+func (s *Server) updateSprint(w http.ResponseWriter, r *http.Request) {
+	var payload map[string]any
+	if err := readJSON(r, &payload); err != nil {
+		jiraError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.Lock()
+	defer s.Unlock()
+	_, sp := s.lookupSprint(w, r)
+	if sp == nil {
+		return
+	}
+	if msg := s.checkSprintState(sp, payload); msg != "" {
+		jiraError(w, http.StatusBadRequest, msg)
+		return
+	}
+	for k, v := range payload {
+		if k == "state" {
+			v = strings.ToLower(v.(string))
+		}
+		sp[k] = v
+	}
+	writeJSON(w, http.StatusOK, sp)
+}
+
+func (s *Server) getSprintIssues(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	id, sp := s.lookupSprint(w, r)
+	if sp == nil {
+		return
+	}
+	var issues []*Issue
+	for _, key := range s.SprintIssue[id] {
+		if issue, ok := s.Issues[key]; ok {
+			issues = append(issues, issue)
+		}
+	}
+	var fields []string
+	if v := r.URL.Query().Get("fields"); v != "" {
+		fields = strings.Split(v, ",")
+	}
+	writeJSON(w, http.StatusOK, s.searchResult(s.byRank(issues), queryInt(r, "startAt", 0), queryInt(r, "maxResults", 50), fields, nil))
+}
+
+func (s *Server) moveToEpic(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		Issues []string `json:"issues"`
+	}
+	if err := readJSON(r, &payload); err != nil {
+		jiraError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.Lock()
+	defer s.Unlock()
+	// Moving to the epic "none" removes the issues from their epic.
+	var epic *Issue
+	if r.PathValue("key") != "none" {
+		if epic = s.lookupIssue(w, r); epic == nil {
+			return
+		}
+	}
+	for _, key := range payload.Issues {
+		issue, ok := s.Issues[key]
+		if !ok {
+			jiraError(w, http.StatusBadRequest, "Issue "+key+" does not exist")
+			return
+		}
+		if epic == nil {
+			delete(issue.Fields, "customfield_10014")
+		} else {
+			issue.Fields["customfield_10014"] = epic.Key
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func contains(list []string, v string) bool {
+	for _, item := range list {
+		if strings.TrimSpace(item) == v {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) usersJSON(names []string) []map[string]any {
+	out := []map[string]any{}
+	for _, name := range names {
+		if u := s.lookupUser(name); u != nil {
+			out = append(out, u)
+		}
+	}
+	return out
+}
+
+func (s *Server) getWatchers(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	issue := s.lookupIssue(w, r)
+	if issue == nil {
+		return
+	}
+	watchers := s.Watchers[issue.Key]
+	writeJSON(w, http.StatusOK, map[string]any{
+		"isWatching": slices.Contains(watchers, currentUser), "watchCount": len(watchers), "watchers": s.usersJSON(watchers),
+	})
+}
+
+// addWatcher takes a JSON string username, or no body for the current user.
+func (s *Server) addWatcher(w http.ResponseWriter, r *http.Request) {
+	name := currentUser
+	if data, _ := io.ReadAll(r.Body); len(data) > 0 {
+		if err := json.Unmarshal(data, &name); err != nil {
+			jiraError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	s.Lock()
+	defer s.Unlock()
+	issue := s.lookupIssue(w, r)
+	if issue == nil {
+		return
+	}
+	u := s.lookupUserID(name)
+	if u == nil {
+		jiraError(w, http.StatusNotFound, "The user \""+name+"\" does not exist")
+		return
+	}
+	if !slices.Contains(s.Watchers[issue.Key], u["name"].(string)) {
+		s.Watchers[issue.Key] = append(s.Watchers[issue.Key], u["name"].(string))
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) removeWatcher(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	issue := s.lookupIssue(w, r)
+	if issue == nil {
+		return
+	}
+	param := "username"
+	if s.Deployment == "Cloud" {
+		param = "accountId"
+	}
+	id := r.URL.Query().Get(param)
+	if id == "" {
+		jiraError(w, http.StatusBadRequest, param+" is required")
+		return
+	}
+	u := s.lookupUserID(id)
+	if u == nil {
+		jiraError(w, http.StatusNotFound, "The user \""+id+"\" does not exist")
+		return
+	}
+	s.Watchers[issue.Key] = slices.DeleteFunc(s.Watchers[issue.Key], func(n string) bool { return n == u["name"] })
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) getVotes(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	issue := s.lookupIssue(w, r)
+	if issue == nil {
+		return
+	}
+	votes := s.Votes[issue.Key]
+	writeJSON(w, http.StatusOK, map[string]any{
+		"votes": len(votes), "hasVoted": slices.Contains(votes, currentUser), "voters": s.usersJSON(votes),
+	})
+}
+
+func (s *Server) addVote(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	issue := s.lookupIssue(w, r)
+	if issue == nil {
+		return
+	}
+	if names(issue.Fields["resolution"], "name") != nil {
+		jiraError(w, http.StatusNotFound, "You cannot vote for a resolved issue.")
+		return
+	}
+	if !slices.Contains(s.Votes[issue.Key], currentUser) {
+		s.Votes[issue.Key] = append(s.Votes[issue.Key], currentUser)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) removeVote(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	issue := s.lookupIssue(w, r)
+	if issue == nil {
+		return
+	}
+	s.Votes[issue.Key] = slices.DeleteFunc(s.Votes[issue.Key], func(n string) bool { return n == currentUser })
+	w.WriteHeader(http.StatusNoContent)
+}
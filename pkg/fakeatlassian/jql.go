@@ -0,0 +1,429 @@
+package fakeatlassian
+
+import (
+	"maps"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// currentUser is the user name returned for currentUser() in JQL.
+const currentUser = "jdoe"
+
+var (
+	orderByRe = regexp.MustCompile(`(?i)\s+order\s+by\s+.*$`)
+	clauseRe  = regexp.MustCompile(`(?i)^("[^"]+"|'[^']+'|[^\s=!~]+)(\s*(?:!=|=|~)\s*|\s+(?:not\s+in|in|is\s+not|is)\b\s*)(.+)$`)
+)
+
+// matchJQL reports whether issue satisfies jql. It understands AND, OR,
+// parentheses and the =, !=, ~, in, not in, is and is not operators over the
+// fields used by the fixtures. Clauses it cannot evaluate match everything.
+func (s *Server) matchJQL(issue *Issue, jql string) bool {
+	return evalQuery(jql, func(field string) ([]string, bool) {
+		return s.fieldValues(issue, field)
+	})
+}
+
+// evalQuery evaluates a JQL or CQL query, resolving field values with lookup.
+func evalQuery(query string, lookup func(field string) ([]string, bool)) bool {
+	query = strings.TrimSpace(orderByRe.ReplaceAllString(" "+query, ""))
+	if query == "" {
+		return true
+	}
+	return evalExpr(query, lookup)
+}
+
+func evalExpr(expr string, lookup func(string) ([]string, bool)) bool {
+	expr = trimParens(strings.TrimSpace(expr))
+	if parts := splitTopLevel(expr, "OR"); len(parts) > 1 {
+		for _, p := range parts {
+			if evalExpr(p, lookup) {
+				return true
+			}
+		}
+		return false
+	}
+	if parts := splitTopLevel(expr, "AND"); len(parts) > 1 {
+		for _, p := range parts {
+			if !evalExpr(p, lookup) {
+				return false
+			}
+		}
+		return true
+	}
+	m := clauseRe.FindStringSubmatch(expr)
+	if m == nil {
+		return true
+	}
+	actual, known := lookup(strings.ToLower(unquote(m[1])))
+	if !known {
+		return true
+	}
+	return compare(actual, strings.ToLower(strings.Join(strings.Fields(m[2]), " ")), parseValues(m[3]))
+}
+
+// unknownJQLField returns the first field of a query that is neither a
+// system field, a custom field nor one of s.AppFields.
+func (s *Server) unknownJQLField(query string) string {
+	var walk func(expr string) string
+	walk = func(expr string) string {
+		expr = trimParens(strings.TrimSpace(expr))
+		parts := splitTopLevel(expr, "OR")
+		if len(parts) == 1 {
+			parts = splitTopLevel(expr, "AND")
+		}
+		if len(parts) > 1 {
+			for _, p := range parts {
+				if field := walk(p); field != "" {
+					return field
+				}
+			}
+			return ""
+		}
+		m := clauseRe.FindStringSubmatch(expr)
+		if m == nil || s.knownJQLField(unquote(m[1])) {
+			return ""
+		}
+		return unquote(m[1])
+	}
+	return walk(orderByRe.ReplaceAllString(" "+query, ""))
+}
+
+func (s *Server) knownJQLField(name string) bool {
+	for _, c := range jqlClauses {
+		if strings.EqualFold(c.name, name) {
+			return true
+		}
+	}
+	for _, f := range s.Fields {
+		id, _ := f["id"].(string)
+		if strings.EqualFold(f["name"].(string), name) || strings.EqualFold("cf["+strings.TrimPrefix(id, "customfield_")+"]", name) {
+			return true
+		}
+	}
+	return slices.ContainsFunc(s.AppFields, func(f string) bool { return strings.EqualFold(f, name) })
+}
+
+func compare(actual []string, op string, values []string) bool {
+	switch op {
+	case "is":
+		return len(actual) == 0
+	case "is not":
+		return len(actual) != 0
+	case "~":
+		for _, a := range actual {
+			for _, v := range values {
+				if strings.Contains(strings.ToLower(a), strings.ToLower(v)) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	found := false
+	for _, a := range actual {
+		for _, v := range values {
+			if strings.EqualFold(a, v) {
+				found = true
+			}
+		}
+	}
+	if op == "!=" || op == "not in" {
+		return !found
+	}
+	return found
+}
+
+// fieldValues returns the comparable string values of a JQL field on issue.
+func (s *Server) fieldValues(issue *Issue, field string) ([]string, bool) {
+	f := issue.Fields
+	switch field {
+	case "key", "issuekey", "id":
+		return []string{issue.Key, issue.ID}, true
+	case "project":
+		return names(f["project"], "key", "name", "id"), true
+	case "status", "issuetype", "type", "priority", "resolution":
+		if field == "type" {
+			field = "issuetype"
+		}
+		return names(f[field], "name", "id"), true
+	case "statuscategory":
+		if st, ok := f["status"].(map[string]any); ok {
+			return names(st["statusCategory"], "key", "name"), true
+		}
+		return nil, true
+	case "assignee", "reporter":
+		return names(f[field], "name", "key", "accountId"), true
+	case "labels":
+		return names(f["labels"]), true
+	case "fixversion":
+		return names(f["fixVersions"], "name", "id"), true
+	case "component":
+		return names(f["components"], "name", "id"), true
+	case "parent":
+		return names(f["parent"], "key", "id"), true
+	case "epic link", "cf[10014]":
+		return names(f["customfield_10014"]), true
+	case "worklogauthor":
+		var out []string
+		for _, l := range s.Worklogs[issue.Key] {
+			out = append(out, names(l["author"], "name", "key", "accountId")...)
+		}
+		return out, true
+	case "summary", "text":
+		return names(f["summary"]), true
+	case "sprint":
+		var out []string
+		for id, keys := range s.SprintIssue {
+			for _, k := range keys {
+				if k == issue.Key {
+					out = append(out, strconv.Itoa(id))
+					if sp, ok := s.Sprints[id]; ok {
+						out = append(out, sp["name"].(string))
+					}
+				}
+			}
+		}
+		return out, true
+	}
+	return nil, false
+}
+
+// names flattens a field value into strings, reading the given keys of objects.
+func names(v any, keys ...string) []string {
+	switch x := v.(type) {
+	case nil:
+		return nil
+	case string:
+		return []string{x}
+	case float64:
+		return []string{strconv.FormatFloat(x, 'f', -1, 64)}
+	case []any:
+		var out []string
+		for _, item := range x {
+			out = append(out, names(item, keys...)...)
+		}
+		return out
+	case []map[string]any:
+		var out []string
+		for _, item := range x {
+			out = append(out, names(item, keys...)...)
+		}
+		return out
+	case map[string]any:
+		var out []string
+		for _, k := range keys {
+			if s, ok := x[k].(string); ok && s != "" {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+func parseValues(raw string) []string {
+	raw = strings.TrimSpace(raw)
+	if strings.HasPrefix(raw, "(") && strings.HasSuffix(raw, ")") {
+		var out []string
+		for _, part := range splitTopLevel(raw[1:len(raw)-1], ",") {
+			out = append(out, parseValue(part))
+		}
+		return out
+	}
+	return []string{parseValue(raw)}
+}
+
+func parseValue(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if strings.EqualFold(raw, "currentUser()") {
+		return currentUser
+	}
+	return unquote(raw)
+}
+
+func unquote(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return strings.ReplaceAll(s[1:len(s)-1], `\`+string(s[0]), string(s[0]))
+	}
+	return s
+}
+
+// trimParens removes parentheses wrapping the whole expression.
+func trimParens(expr string) string {
+	for strings.HasPrefix(expr, "(") && strings.HasSuffix(expr, ")") {
+		depth := 0
+		wraps := true
+		for i, r := range expr {
+			switch r {
+			case '(':
+				depth++
+			case ')':
+				depth--
+				if depth == 0 && i != len(expr)-1 {
+					wraps = false
+				}
+			}
+		}
+		if !wraps {
+			break
+		}
+		expr = strings.TrimSpace(expr[1 : len(expr)-1])
+	}
+	return expr
+}
+
+// splitTopLevel splits expr on sep outside quotes and parentheses. Keyword
+// separators (AND, OR) match case-insensitively on word boundaries.
+func splitTopLevel(expr, sep string) []string {
+	var parts []string
+	depth, start := 0, 0
+	var quote byte
+	word := sep != ","
+	for i := 0; i < len(expr); i++ {
+		c := expr[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case depth == 0 && !word && c == sep[0]:
+			parts = append(parts, expr[start:i])
+			start = i + 1
+		case depth == 0 && word && i > 0 && expr[i-1] == ' ' && i+len(sep) < len(expr) &&
+			strings.EqualFold(expr[i:i+len(sep)], sep) && expr[i+len(sep)] == ' ':
+			parts = append(parts, expr[start:i])
+			start = i + len(sep)
+			i += len(sep) - 1
+		}
+	}
+	return append(parts, expr[start:])
+}
+
+var (
+	equalityOps   = []string{"=", "!=", "in", "not in", "is", "is not"}
+	historyOps    = []string{"=", "!=", "in", "not in", "is", "is not", "was", "was in", "was not", "was not in", "changed"}
+	comparisonOps = []string{"=", "!=", ">", ">=", "<", "<=", "in", "not in", "is", "is not"}
+	textOps       = []string{"~", "!~", "is", "is not"}
+)
+
+// jqlClauses are the system fields listed by getJQLAutocomplete, with their
+// operators and whether they can be ordered by.
+var jqlClauses = []struct {
+	name      string
+	ops       []string
+	orderable bool
+}{
+	{"project", equalityOps, true}, {"key", comparisonOps, true}, {"issuekey", comparisonOps, true},
+	{"issue", comparisonOps, false}, {"id", comparisonOps, true}, {"parent", equalityOps, false},
+	{"issuetype", equalityOps, true}, {"type", equalityOps, true}, {"status", historyOps, true},
+	{"statusCategory", equalityOps, false}, {"priority", historyOps, true}, {"resolution", historyOps, true},
+	{"assignee", historyOps, true}, {"reporter", historyOps, true}, {"labels", equalityOps, true},
+	{"component", equalityOps, true}, {"fixVersion", historyOps, true}, {"sprint", equalityOps, true},
+	{"summary", textOps, true}, {"description", textOps, true}, {"comment", textOps, false}, {"text", textOps, false},
+	{"created", comparisonOps, true}, {"updated", comparisonOps, true}, {"resolved", comparisonOps, true},
+	{"duedate", comparisonOps, true}, {"due", comparisonOps, true},
+	{"worklogAuthor", equalityOps, false}, {"worklogDate", comparisonOps, false},
+}
+
+var jqlFunctions = []string{
+	"currentUser()", "membersOf()", "now()", "startOfDay()", "endOfDay()", "startOfWeek()", "endOfWeek()",
+	"startOfMonth()", "endOfMonth()", "startOfYear()", "endOfYear()", "openSprints()", "closedSprints()",
+	"futureSprints()", "linkedIssues()", "issueHistory()", "updatedBy()",
+}
+
+// getJQLAutocomplete lists the JQL fields, functions and reserved words.
+// Custom fields are listed by quoted name and by cf[id], as Jira does.
+func (s *Server) getJQLAutocomplete(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	flag := func(b bool) string { return strconv.FormatBool(b) }
+	fields := []map[string]any{}
+	for _, c := range jqlClauses {
+		fields = append(fields, map[string]any{"value": c.name, "displayName": c.name, "orderable": flag(c.orderable), "searchable": "true", "operators": c.ops})
+	}
+	for _, f := range s.Fields {
+		if f["custom"] != true {
+			continue
+		}
+		ops := equalityOps
+		switch f["schema"].(map[string]any)["type"] {
+		case "number", "date":
+			ops = comparisonOps
+		case "string":
+			ops = textOps
+		}
+		cf := "cf[" + strings.TrimPrefix(f["id"].(string), "customfield_") + "]"
+		display := f["name"].(string) + " - " + cf
+		fields = append(fields,
+			map[string]any{"value": `"` + f["name"].(string) + `"`, "displayName": display, "cfid": cf, "orderable": "true", "searchable": "true", "operators": ops},
+			map[string]any{"value": cf, "displayName": display, "cfid": cf, "orderable": "true", "searchable": "true", "operators": ops})
+	}
+	functions := []map[string]any{}
+	for _, fn := range jqlFunctions {
+		functions = append(functions, map[string]any{"value": fn, "displayName": fn})
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"visibleFieldNames":    fields,
+		"visibleFunctionNames": functions,
+		"jqlReservedWords":     []string{"and", "or", "not", "empty", "null", "order", "by", "asc", "desc", "in", "is", "was", "changed", "limit", "select", "from", "where"},
+	})
+}
+
+// getJQLSuggestions suggests values of a field starting with fieldValue,
+// highlighting the match in bold as Jira does.
+func (s *Server) getJQLSuggestions(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	prefix := strings.ToLower(r.URL.Query().Get("fieldValue"))
+	var values, displays []string
+	add := func(value, display string) {
+		if strings.HasPrefix(strings.ToLower(value), prefix) && !slices.Contains(values, value) {
+			values, displays = append(values, value), append(displays, display)
+		}
+	}
+	switch strings.ToLower(r.URL.Query().Get("fieldName")) {
+	case "project":
+		for _, p := range s.Projects {
+			add(p["key"].(string), p["name"].(string)+" ("+p["key"].(string)+")")
+		}
+	case "status":
+		for _, name := range slices.Sorted(maps.Keys(s.Transitions)) {
+			add(name, name)
+		}
+	case "issuetype", "type":
+		for _, it := range s.IssueTypes {
+			add(it["name"].(string), it["name"].(string))
+		}
+	case "assignee", "reporter":
+		for _, u := range s.Users {
+			add(u["name"].(string), u["displayName"].(string)+" - "+u["name"].(string))
+		}
+	case "labels":
+		for _, key := range slices.Sorted(maps.Keys(s.Issues)) {
+			for _, l := range names(s.Issues[key].Fields["labels"]) {
+				add(l, l)
+			}
+		}
+	}
+	results := []map[string]any{}
+	for i, v := range values {
+		d := displays[i]
+		if n := len(prefix); n > 0 && strings.HasPrefix(strings.ToLower(d), prefix) {
+			d = "<b>" + d[:n] + "</b>" + d[n:]
+		}
+		results = append(results, map[string]any{"value": v, "displayName": d})
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"results": results})
+}
@@ -0,0 +1,57 @@
+package fakeatlassian
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// NewMCPClient returns an initialized in-process MCP client for a server
+// carrying the tools added by register.
+func NewMCPClient(t testing.TB, register ...func(*server.MCPServer)) *client.Client {
+	t.Helper()
+	s := server.NewMCPServer("test", "0.0.0", server.WithToolCapabilities(true), server.WithRecovery())
+	for _, r := range register {
+		r(s)
+	}
+	c, err := client.NewInProcessClient(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	ctx := context.Background()
+	if err := c.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	init := mcp.InitializeRequest{}
+	init.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	init.Params.ClientInfo = mcp.Implementation{Name: "fakeatlassian", Version: "0.0.0"}
+	if _, err := c.Initialize(ctx, init); err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+// CallTool invokes a tool and returns its text output and whether the tool
+// reported an error.
+func CallTool(t testing.TB, c *client.Client, name string, args map[string]any) (string, bool) {
+	t.Helper()
+	req := mcp.CallToolRequest{}
+	req.Params.Name = name
+	req.Params.Arguments = args
+	result, err := c.CallTool(context.Background(), req)
+	if err != nil {
+		t.Fatalf("calling %s: %v", name, err)
+	}
+	var text []string
+	for _, content := range result.Content {
+		if tc, ok := content.(mcp.TextContent); ok {
+			text = append(text, tc.Text)
+		}
+	}
+	return strings.Join(text, "\n"), result.IsError
+}
@@ -0,0 +1,387 @@
+package fakeatlassian
+
+import (
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+func (s *Server) lookupProject(key string) map[string]any {
+	for _, p := range s.Projects {
+		if strings.EqualFold(p["key"].(string), key) || p["id"] == key {
+			return p
+		}
+	}
+	return nil
+}
+
+func (s *Server) projectExists(key string) bool {
+	return s.lookupProject(key) != nil
+}
+
+// projectItems returns the components or versions of a project.
+func projectItems(items []map[string]any, project map[string]any) []map[string]any {
+	out := []map[string]any{}
+	for _, item := range items {
+		if item["project"] == project["key"] {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// getProjects lists every project. Like Jira Server, the lead and description
+// are only included when expanded.
+func (s *Server) getProjects(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	expand := strings.Split(r.URL.Query().Get("expand"), ",")
+	out := []map[string]any{}
+	for _, p := range s.Projects {
+		view := maps.Clone(p)
+		for _, k := range []string{"lead", "description"} {
+			if !contains(expand, k) {
+				delete(view, k)
+			}
+		}
+		out = append(out, view)
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *Server) getProject(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	p := s.lookupProject(r.PathValue("key"))
+	if p == nil {
+		jiraError(w, http.StatusNotFound, "No project could be found with key '"+r.PathValue("key")+"'.")
+		return
+	}
+	view := maps.Clone(p)
+	view["components"] = projectItems(s.Components, p)
+	view["versions"] = projectItems(s.Versions, p)
+	view["issueTypes"] = s.IssueTypes
+	base := s.JiraURL() + "/rest/api/2/project/" + p["id"].(string) + "/role/"
+	view["roles"] = map[string]any{"Administrators": base + "10002", "Developers": base + "10001", "Users": base + "10000"}
+	writeJSON(w, http.StatusOK, view)
+}
+
+func (s *Server) getProjectComponents(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	p := s.lookupProject(r.PathValue("key"))
+	if p == nil {
+		jiraError(w, http.StatusNotFound, "No project could be found with key '"+r.PathValue("key")+"'.")
+		return
+	}
+	writeJSON(w, http.StatusOK, projectItems(s.Components, p))
+}
+
+// getProjectStatuses lists the statuses of the workflow of each issue type,
+// which all share the workflow in s.Transitions.
+func (s *Server) getProjectStatuses(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	if s.lookupProject(r.PathValue("key")) == nil {
+		jiraError(w, http.StatusNotFound, "No project could be found with key '"+r.PathValue("key")+"'.")
+		return
+	}
+	statuses := []map[string]any{}
+	seen := map[string]bool{}
+	for _, name := range slices.Sorted(maps.Keys(s.Transitions)) {
+		for _, t := range s.Transitions[name] {
+			to := t["to"].(map[string]any)
+			if !seen[to["name"].(string)] {
+				seen[to["name"].(string)] = true
+				statuses = append(statuses, to)
+			}
+		}
+	}
+	out := []map[string]any{}
+	for _, it := range s.IssueTypes {
+		out = append(out, map[string]any{"id": it["id"], "name": it["name"], "subtask": it["subtask"], "statuses": statuses})
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *Server) getProjectVersions(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	p := s.lookupProject(r.PathValue("key"))
+	if p == nil {
+		jiraError(w, http.StatusNotFound, "No project could be found with key '"+r.PathValue("key")+"'.")
+		return
+	}
+	writeJSON(w, http.StatusOK, projectItems(s.Versions, p))
+}
+
+func (s *Server) getIssueTypes(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	writeJSON(w, http.StatusOK, s.IssueTypes)
+}
+
+// getWorkflowScheme serves the project configuration endpoint Jira Server
+// uses for the workflow scheme of a project.
+func (s *Server) getWorkflowScheme(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	p := s.lookupProject(r.PathValue("key"))
+	if p == nil {
+		jiraError(w, http.StatusNotFound, "No project could be found with key '"+r.PathValue("key")+"'.")
+		return
+	}
+	key := p["key"].(string)
+	writeJSON(w, http.StatusOK, map[string]any{"id": 10100, "name": key + " Workflow Scheme", "shared": false, "mappings": []map[string]any{{"name": key + " Workflow", "default": true}}})
+}
+
+func lookupItem(items []map[string]any, id string) (int, map[string]any) {
+	for i, item := range items {
+		if item["id"] == id {
+			return i, item
+		}
+	}
+	return -1, nil
+}
+
+// numericID is the ID of a project as Jira puts it on components and
+// versions: a number, not a string.
+func numericID(project map[string]any) int {
+	id, _ := strconv.Atoi(project["id"].(string))
+	return id
+}
+
+// nameTaken reports whether another item of the project already uses name.
+func nameTaken(items []map[string]any, project, name, exceptID string) bool {
+	return slices.ContainsFunc(items, func(item map[string]any) bool {
+		return item["project"] == project && item["id"] != exceptID && strings.EqualFold(item["name"].(string), name)
+	})
+}
+
+// replaceOnIssues swaps old for to in the given list field of every issue,
+// or removes it when to is nil.
+func (s *Server) replaceOnIssues(field string, old, to map[string]any) {
+	for _, issue := range s.Issues {
+		list, _ := issue.Fields[field].([]any)
+		var out []any
+		changed := false
+		for _, item := range list {
+			if m, ok := item.(map[string]any); ok && (m["id"] == old["id"] || m["name"] == old["name"]) {
+				changed = true
+				if to != nil {
+					out = append(out, map[string]any{"id": to["id"], "name": to["name"]})
+				}
+				continue
+			}
+			out = append(out, item)
+		}
+		if changed {
+			if out == nil {
+				out = []any{}
+			}
+			issue.Fields[field] = out
+		}
+	}
+}
+
+var assigneeTypes = []string{"PROJECT_DEFAULT", "COMPONENT_LEAD", "PROJECT_LEAD", "UNASSIGNED"}
+
+// applyComponent copies the writable fields of a component payload.
+func (s *Server) applyComponent(c, payload map[string]any) string {
+	for _, k := range []string{"name", "description"} {
+		if v, ok := payload[k]; ok {
+			c[k] = v
+		}
+	}
+	if v, ok := payload["assigneeType"].(string); ok {
+		if !slices.Contains(assigneeTypes, v) {
+			return "Invalid assignee type: " + v
+		}
+		c["assigneeType"] = v
+	}
+	if name, ok := payload["leadUserName"].(string); ok {
+		if name == "" {
+			delete(c, "lead")
+		} else if u := s.lookupUser(name); u != nil {
+			c["lead"] = u
+		} else {
+			return "The user " + name + " does not exist."
+		}
+	}
+	return ""
+}
+
+func (s *Server) createComponent(w http.ResponseWriter, r *http.Request) {
+	var payload map[string]any
+	if err := readJSON(r, &payload); err != nil {
+		jiraError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.Lock()
+	defer s.Unlock()
+	key, _ := payload["project"].(string)
+	p := s.lookupProject(key)
+	if p == nil {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"errorMessages": []string{}, "errors": map[string]string{"project": "The project is not valid."}})
+		return
+	}
+	name, _ := payload["name"].(string)
+	if name == "" || nameTaken(s.Components, p["key"].(string), name, "") {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"errorMessages": []string{}, "errors": map[string]string{"name": "A component with the name " + name + " already exists in this project."}})
+		return
+	}
+	c := map[string]any{"id": s.newID(), "project": p["key"], "projectId": numericID(p), "assigneeType": "PROJECT_DEFAULT"}
+	if msg := s.applyComponent(c, payload); msg != "" {
+		jiraError(w, http.StatusBadRequest, msg)
+		return
+	}
+	s.Components = append(s.Components, c)
+	writeJSON(w, http.StatusCreated, c)
+}
+
+func (s *Server) updateComponent(w http.ResponseWriter, r *http.Request) {
+	var payload map[string]any
+	if err := readJSON(r, &payload); err != nil {
+		jiraError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.Lock()
+	defer s.Unlock()
+	_, c := lookupItem(s.Components, r.PathValue("id"))
+	if c == nil {
+		jiraError(w, http.StatusNotFound, "The component with id "+r.PathValue("id")+" does not exist.")
+		return
+	}
+	if name, ok := payload["name"].(string); ok && nameTaken(s.Components, c["project"].(string), name, c["id"].(string)) {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"errorMessages": []string{}, "errors": map[string]string{"name": "A component with the name " + name + " already exists in this project."}})
+		return
+	}
+	old := maps.Clone(c)
+	if msg := s.applyComponent(c, payload); msg != "" {
+		jiraError(w, http.StatusBadRequest, msg)
+		return
+	}
+	s.replaceOnIssues("components", old, c)
+	writeJSON(w, http.StatusOK, c)
+}
+
+func (s *Server) deleteComponent(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	i, c := lookupItem(s.Components, r.PathValue("id"))
+	if c == nil {
+		jiraError(w, http.StatusNotFound, "The component with id "+r.PathValue("id")+" does not exist.")
+		return
+	}
+	var to map[string]any
+	if id := r.URL.Query().Get("moveIssuesTo"); id != "" {
+		if _, to = lookupItem(s.Components, id); to == nil || id == c["id"] {
+			jiraError(w, http.StatusBadRequest, "The component with id "+id+" does not exist.")
+			return
+		}
+	}
+	s.replaceOnIssues("components", c, to)
+	s.Components = slices.Delete(s.Components, i, i+1)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// applyVersion copies the writable fields of a version payload.
+func applyVersion(v, payload map[string]any) {
+	for _, k := range []string{"name", "description", "startDate", "releaseDate", "released", "archived"} {
+		if value, ok := payload[k]; ok {
+			v[k] = value
+		}
+	}
+}
+
+func (s *Server) createVersion(w http.ResponseWriter, r *http.Request) {
+	var payload map[string]any
+	if err := readJSON(r, &payload); err != nil {
+		jiraError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.Lock()
+	defer s.Unlock()
+	key, _ := payload["project"].(string)
+	if id, ok := payload["projectId"].(float64); ok {
+		key = strconv.Itoa(int(id))
+	}
+	p := s.lookupProject(key)
+	if p == nil {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"errorMessages": []string{}, "errors": map[string]string{"project": "Project must be specified to create a version."}})
+		return
+	}
+	name, _ := payload["name"].(string)
+	if name == "" || nameTaken(s.Versions, p["key"].(string), name, "") {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"errorMessages": []string{}, "errors": map[string]string{"name": "A version with this name already exists in this project."}})
+		return
+	}
+	v := map[string]any{"id": s.newID(), "project": p["key"], "projectId": numericID(p), "released": false, "archived": false}
+	applyVersion(v, payload)
+	s.Versions = append(s.Versions, v)
+	writeJSON(w, http.StatusCreated, v)
+}
+
+func (s *Server) getVersion(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	_, v := lookupItem(s.Versions, r.PathValue("id"))
+	if v == nil {
+		jiraError(w, http.StatusNotFound, "Could not find version for id '"+r.PathValue("id")+"'")
+		return
+	}
+	writeJSON(w, http.StatusOK, v)
+}
+
+func (s *Server) updateVersion(w http.ResponseWriter, r *http.Request) {
+	var payload map[string]any
+	if err := readJSON(r, &payload); err != nil {
+		jiraError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.Lock()
+	defer s.Unlock()
+	_, v := lookupItem(s.Versions, r.PathValue("id"))
+	if v == nil {
+		jiraError(w, http.StatusNotFound, "Could not find version for id '"+r.PathValue("id")+"'")
+		return
+	}
+	if name, ok := payload["name"].(string); ok && nameTaken(s.Versions, v["project"].(string), name, v["id"].(string)) {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"errorMessages": []string{}, "errors": map[string]string{"name": "A version with this name already exists in this project."}})
+		return
+	}
+	old := maps.Clone(v)
+	applyVersion(v, payload)
+	s.replaceOnIssues("fixVersions", old, v)
+	s.replaceOnIssues("versions", old, v)
+	writeJSON(w, http.StatusOK, v)
+}
+
+func (s *Server) deleteVersion(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	i, v := lookupItem(s.Versions, r.PathValue("id"))
+	if v == nil {
+		jiraError(w, http.StatusNotFound, "Could not find version for id '"+r.PathValue("id")+"'")
+		return
+	}
+	targets := map[string]map[string]any{}
+	for field, param := range map[string]string{"fixVersions": "moveFixIssuesTo", "versions": "moveAffectedIssuesTo"} {
+		id := r.URL.Query().Get(param)
+		if id == "" {
+			continue
+		}
+		_, to := lookupItem(s.Versions, id)
+		if to == nil || id == v["id"] {
+			jiraError(w, http.StatusBadRequest, "Could not find version for id '"+id+"'")
+			return
+		}
+		targets[field] = to
+	}
+	s.replaceOnIssues("fixVersions", v, targets["fixVersions"])
+	s.replaceOnIssues("versions", v, targets["versions"])
+	s.Versions = slices.Delete(s.Versions, i, i+1)
+	w.WriteHeader(http.StatusNoContent)
+}
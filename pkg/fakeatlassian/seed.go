@@ -0,0 +1,169 @@
+package fakeatlassian
+
+// seed populates the default fixtures: a PROJ project with an epic, a story
+// and a bug, a small workflow, two users, saved filters and dashboards, one
+// scrum board with an active sprint and a two-page Confluence space.
+func (s *Server) seed() {
+	project := map[string]any{"id": "10000", "key": "PROJ", "name": "Project"}
+	jdoe := map[string]any{"name": "jdoe", "key": "JIRAUSER10000", "displayName": "John Doe", "emailAddress": "john.doe@example.com", "active": true}
+	asmith := map[string]any{"name": "asmith", "key": "JIRAUSER10001", "displayName": "Alice Smith", "emailAddress": "alice.smith@example.com", "active": true}
+	s.Users = []map[string]any{jdoe, asmith}
+	s.Deployment = "Server"
+
+	s.Projects = []map[string]any{
+		{"id": "10000", "key": "PROJ", "name": "Project", "projectTypeKey": "software", "description": "Login and accounts", "lead": jdoe, "projectCategory": map[string]any{"id": "10000", "name": "Engineering"}},
+		{"id": "10010", "key": "SUP", "name": "Support", "projectTypeKey": "service_desk", "description": "Customer requests", "lead": asmith, "projectCategory": map[string]any{"id": "10001", "name": "Operations"}},
+	}
+	s.IssueTypes = []map[string]any{
+		{"id": "10000", "name": "Epic", "subtask": false},
+		{"id": "10001", "name": "Story", "subtask": false},
+		{"id": "10002", "name": "Task", "subtask": false},
+		{"id": "10003", "name": "Sub-task", "subtask": true},
+		{"id": "10004", "name": "Bug", "subtask": false},
+	}
+	s.Priorities = []map[string]any{
+		{"id": "1", "name": "Highest"},
+		{"id": "2", "name": "High"},
+		{"id": "3", "name": "Medium"},
+		{"id": "4", "name": "Low"},
+		{"id": "5", "name": "Lowest"},
+	}
+	s.Options = map[string][]map[string]any{
+		"customfield_10020": {{"id": "10300", "value": "Platform"}, {"id": "10301", "value": "Mobile"}, {"id": "10302", "value": "Payments"}},
+	}
+	s.Required = map[string][]string{}
+	s.Components = []map[string]any{
+		{"id": "10100", "name": "UI", "description": "Web frontend", "project": "PROJ", "projectId": 10000, "assigneeType": "PROJECT_DEFAULT", "lead": asmith},
+		{"id": "10101", "name": "API", "description": "REST backend", "project": "PROJ", "projectId": 10000, "assigneeType": "COMPONENT_LEAD", "lead": jdoe},
+	}
+	s.Versions = []map[string]any{
+		{"id": "10200", "name": "1.0", "project": "PROJ", "projectId": 10000, "released": true, "archived": false, "releaseDate": "2024-01-02"},
+		{"id": "10201", "name": "1.1", "project": "PROJ", "projectId": 10000, "released": false, "archived": false, "startDate": "2024-01-03"},
+	}
+
+	s.Fields = []map[string]any{
+		{"id": "summary", "key": "summary", "name": "Summary", "custom": false, "navigable": true, "searchable": true, "clauseNames": []string{"summary"}, "schema": map[string]any{"type": "string", "system": "summary"}},
+		{"id": "status", "key": "status", "name": "Status", "custom": false, "navigable": true, "searchable": true, "clauseNames": []string{"status"}, "schema": map[string]any{"type": "status", "system": "status"}},
+		{"id": "assignee", "key": "assignee", "name": "Assignee", "custom": false, "navigable": true, "searchable": true, "clauseNames": []string{"assignee"}, "schema": map[string]any{"type": "user", "system": "assignee"}},
+		{"id": "labels", "key": "labels", "name": "Labels", "custom": false, "navigable": true, "searchable": true, "clauseNames": []string{"labels"}, "schema": map[string]any{"type": "array", "items": "string", "system": "labels"}},
+		{"id": "resolution", "key": "resolution", "name": "Resolution", "custom": false, "navigable": true, "searchable": true, "clauseNames": []string{"resolution"}, "schema": map[string]any{"type": "resolution", "system": "resolution"}},
+		{"id": "duedate", "key": "duedate", "name": "Due Date", "custom": false, "navigable": true, "searchable": true, "clauseNames": []string{"duedate", "due"}, "schema": map[string]any{"type": "date", "system": "duedate"}},
+		{"id": "customfield_10014", "key": "customfield_10014", "name": "Epic Link", "custom": true, "navigable": true, "searchable": true, "clauseNames": []string{"cf[10014]", "Epic Link"}, "schema": map[string]any{"type": "any", "custom": "com.pyxis.greenhopper.jira:gh-epic-link", "customId": 10014}},
+		{"id": "customfield_10016", "key": "customfield_10016", "name": "Story Points", "custom": true, "navigable": true, "searchable": true, "clauseNames": []string{"cf[10016]", "Story Points"}, "schema": map[string]any{"type": "number", "custom": "com.atlassian.jira.plugin.system.customfieldtypes:float", "customId": 10016}},
+		{"id": "customfield_10020", "key": "customfield_10020", "name": "Team", "custom": true, "navigable": true, "searchable": true, "clauseNames": []string{"cf[10020]", "Team"}, "schema": map[string]any{"type": "option", "custom": "com.atlassian.jira.plugin.system.customfieldtypes:select", "customId": 10020}},
+		{"id": "customfield_10030", "key": "customfield_10030", "name": "Target Date", "custom": true, "navigable": true, "searchable": true, "clauseNames": []string{"cf[10030]", "Target Date"}, "schema": map[string]any{"type": "date", "custom": "com.atlassian.jira.plugin.system.customfieldtypes:datepicker", "customId": 10030}},
+	}
+
+	s.Issues = map[string]*Issue{
+		"PROJ-1": {ID: "10001", Key: "PROJ-1", Fields: map[string]any{
+			"project":   project,
+			"summary":   "Epic for the login revamp",
+			"issuetype": map[string]any{"id": "10000", "name": "Epic"},
+			"status":    map[string]any{"id": "1", "name": "To Do", "statusCategory": map[string]any{"key": "new"}},
+			"priority":  map[string]any{"id": "3", "name": "Medium"},
+			"labels":    []any{},
+			"assignee":  jdoe,
+			"created":   "2024-01-01T10:00:00.000+0000",
+			"updated":   "2024-01-02T10:00:00.000+0000",
+		}},
+		"PROJ-2": {ID: "10002", Key: "PROJ-2", Fields: map[string]any{
+			"project":           project,
+			"summary":           "Login form rejects valid passwords",
+			"issuetype":         map[string]any{"id": "10004", "name": "Bug"},
+			"status":            map[string]any{"id": "3", "name": "In Progress", "statusCategory": map[string]any{"key": "indeterminate"}},
+			"priority":          map[string]any{"id": "2", "name": "High"},
+			"labels":            []any{"flaky"},
+			"assignee":          asmith,
+			"customfield_10014": "PROJ-1",
+			"created":           "2024-01-03T10:00:00.000+0000",
+			"updated":           "2024-01-04T10:00:00.000+0000",
+		}},
+		"PROJ-3": {ID: "10003", Key: "PROJ-3", Fields: map[string]any{
+			"project":           project,
+			"summary":           "Add remember-me checkbox",
+			"issuetype":         map[string]any{"id": "10001", "name": "Story"},
+			"status":            map[string]any{"id": "1", "name": "To Do", "statusCategory": map[string]any{"key": "new"}},
+			"priority":          map[string]any{"id": "3", "name": "Medium"},
+			"labels":            []any{},
+			"assignee":          nil,
+			"customfield_10014": "PROJ-1",
+			"customfield_10016": 3.0,
+			"created":           "2024-01-05T10:00:00.000+0000",
+			"updated":           "2024-01-06T10:00:00.000+0000",
+		}},
+	}
+
+	todo := map[string]any{"id": "1", "name": "To Do", "statusCategory": map[string]any{"key": "new"}}
+	inProgress := map[string]any{"id": "3", "name": "In Progress", "statusCategory": map[string]any{"key": "indeterminate"}}
+	inReview := map[string]any{"id": "10002", "name": "In Review", "statusCategory": map[string]any{"key": "indeterminate"}}
+	done := map[string]any{"id": "10001", "name": "Done", "statusCategory": map[string]any{"key": "done"}}
+	resolutionField := map[string]any{
+		"required": true, "hasDefaultValue": false, "name": "Resolution", "operations": []string{"set"},
+		"schema":        map[string]any{"type": "resolution", "system": "resolution"},
+		"allowedValues": []map[string]any{{"id": "1", "name": "Fixed"}, {"id": "2", "name": "Won't Do"}},
+	}
+	s.Transitions = map[string][]map[string]any{
+		"To Do":       {{"id": "11", "name": "Start Progress", "to": inProgress}},
+		"In Progress": {{"id": "21", "name": "Resolve", "to": done}, {"id": "31", "name": "Stop Progress", "to": todo}, {"id": "51", "name": "Request Review", "to": inReview}},
+		"In Review":   {{"id": "61", "name": "Approve", "to": done, "fields": map[string]any{"resolution": resolutionField}}, {"id": "71", "name": "Request Changes", "to": inProgress}},
+		"Done":        {{"id": "41", "name": "Reopen", "to": todo}},
+	}
+
+	s.Worklogs = map[string][]map[string]any{
+		"PROJ-2": {{"id": "20001", "issueId": "10002", "author": jdoe, "timeSpent": "1h", "timeSpentSeconds": 3600, "started": "2024-01-04T09:00:00.000+0000", "comment": "Investigation"}},
+	}
+	s.Comments = map[string][]map[string]any{
+		"PROJ-2": {
+			{"id": "30001", "author": jdoe, "body": "First comment", "created": "2024-01-03T11:00:00.000+0000", "updated": "2024-01-03T11:00:00.000+0000"},
+			{"id": "30002", "author": asmith, "body": "Second comment", "created": "2024-01-04T11:00:00.000+0000", "updated": "2024-01-04T11:00:00.000+0000"},
+		},
+	}
+	s.LinkTypes = []map[string]any{
+		{"id": "10000", "name": "Blocks", "inward": "is blocked by", "outward": "blocks"},
+		{"id": "10001", "name": "Relates", "inward": "relates to", "outward": "relates to"},
+		{"id": "10002", "name": "Duplicate", "inward": "is duplicated by", "outward": "duplicates"},
+		{"id": "10003", "name": "Cloners", "inward": "is cloned by", "outward": "clones"},
+	}
+	s.Links = map[string]map[string]any{}
+	s.Attachments = map[string][]byte{}
+	s.Watchers = map[string][]string{"PROJ-2": {"jdoe"}}
+	s.Votes = map[string][]string{"PROJ-2": {"asmith"}}
+
+	s.Roles = map[string]string{"10002": "Administrators", "10100": "Developers"}
+	s.Filters = []map[string]any{
+		{"id": "10100", "name": "Open bugs", "jql": "project = PROJ AND issuetype = Bug AND resolution is EMPTY", "owner": jdoe, "favourite": true,
+			"sharePermissions": []any{map[string]any{"id": 1, "type": "project", "project": map[string]any{"id": "10000", "key": "PROJ", "name": "Project"}}}},
+		{"id": "10101", "name": "Support queue", "jql": "project = SUP ORDER BY created DESC", "owner": asmith, "favourite": true,
+			"sharePermissions": []any{map[string]any{"id": 2, "type": "global"}}},
+		{"id": "10102", "name": "Team backlog", "jql": "project = PROJ AND status = \"To Do\" ORDER BY key ASC", "owner": jdoe, "favourite": false,
+			"sharePermissions": []any{}},
+	}
+	s.Dashboards = []map[string]any{
+		{"id": "10000", "name": "Login team", "owner": jdoe, "isFavourite": true, "view": "/secure/Dashboard.jspa?selectPageId=10000"},
+		{"id": "10001", "name": "Support overview", "owner": asmith, "isFavourite": false, "view": "/secure/Dashboard.jspa?selectPageId=10001"},
+	}
+	s.Gadgets = map[string][]map[string]any{
+		"10000": {
+			{"id": 10001, "moduleKey": "com.atlassian.jira.gadgets:filter-results-gadget", "title": "Open bugs", "position": map[string]any{"row": 0, "column": 0},
+				"properties": map[string]any{"config": map[string]any{"filterId": "filter-10100", "num": "10"}}},
+			{"id": 10002, "moduleKey": "com.atlassian.jira.gadgets:two-dimensional-stats-gadget", "title": "Backlog by assignee", "position": map[string]any{"row": 1, "column": 0},
+				"properties": map[string]any{"config": map[string]any{"statType": "assignees", "filterId": "10102"}}},
+			{"id": 10003, "moduleKey": "com.atlassian.jira.gadgets:text-gadget", "title": "Welcome", "position": map[string]any{"row": 0, "column": 1}},
+		},
+	}
+
+	s.Boards = []map[string]any{
+		{"id": 1, "name": "PROJ board", "type": "scrum", "location": map[string]any{"projectKey": "PROJ"}},
+		{"id": 2, "name": "Support kanban", "type": "kanban", "location": map[string]any{"projectKey": "SUP"}},
+	}
+	s.Sprints = map[int]map[string]any{
+		100: {"id": 100, "name": "Sprint 1", "state": "active", "originBoardId": 1, "goal": "Ship login"},
+		101: {"id": 101, "name": "Sprint 2", "state": "future", "originBoardId": 1},
+	}
+	s.SprintIssue = map[int][]string{100: {"PROJ-2", "PROJ-3"}}
+
+	s.Pages = map[string]*Page{
+		"1001": {ID: "1001", Type: "page", Title: "Team Home", SpaceKey: "DEV", Body: "<h1>Welcome</h1><p>Team <strong>home</strong> page.</p>", Version: 3, Labels: []string{"home"}, Comments: []string{"<p>Nice page</p>"}},
+		"1002": {ID: "1002", Type: "page", Title: "Runbook", SpaceKey: "DEV", Body: "<p>Restart the service.</p>", Version: 1, ParentID: "1001"},
+	}
+}
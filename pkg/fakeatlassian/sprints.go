@@ -0,0 +1,238 @@
+package fakeatlassian
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// maxMoveIssues is how many issues Jira moves or ranks in one request.
+const maxMoveIssues = 50
+
+type movePayload struct {
+	Issues          []string `json:"issues"`
+	RankBeforeIssue string   `json:"rankBeforeIssue"`
+	RankAfterIssue  string   `json:"rankAfterIssue"`
+}
+
+// checkMove returns the error Jira gives for a move or rank request, if any.
+func (s *Server) checkMove(p movePayload) string {
+	if len(p.Issues) == 0 {
+		return "issues: must not be empty"
+	}
+	if len(p.Issues) > maxMoveIssues {
+		return fmt.Sprintf("issues: at most %d issues can be moved at once", maxMoveIssues)
+	}
+	if p.RankBeforeIssue != "" && p.RankAfterIssue != "" {
+		return "Only one of rankBeforeIssue and rankAfterIssue can be set"
+	}
+	if target := p.RankBeforeIssue + p.RankAfterIssue; slices.Contains(p.Issues, target) {
+		return "Cannot rank an issue relative to itself: " + target
+	}
+	for _, key := range append(slices.Clone(p.Issues), p.RankBeforeIssue, p.RankAfterIssue) {
+		if _, ok := s.Issues[key]; key != "" && !ok {
+			return "Issue does not exist or you do not have permission to see it: " + key
+		}
+	}
+	return ""
+}
+
+// byRank orders issues by s.Rank, followed by unranked issues in key order.
+func (s *Server) byRank(issues []*Issue) []*Issue {
+	out := slices.Clone(issues)
+	pos := func(issue *Issue) int {
+		if i := slices.Index(s.Rank, issue.Key); i >= 0 {
+			return i
+		}
+		return len(s.Rank) + issueNumber(issue.Key)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return pos(out[i]) < pos(out[j]) })
+	return out
+}
+
+// rank moves keys before or after another issue, keeping their order.
+func (s *Server) rank(keys []string, before, after string) {
+	if before == "" && after == "" {
+		return
+	}
+	var all []*Issue
+	for _, issue := range s.Issues {
+		all = append(all, issue)
+	}
+	var order []string
+	for _, issue := range s.byRank(all) {
+		if !slices.Contains(keys, issue.Key) {
+			order = append(order, issue.Key)
+		}
+	}
+	i := slices.Index(order, before)
+	if after != "" {
+		i = slices.Index(order, after) + 1
+	}
+	s.Rank = slices.Insert(order, i, keys...)
+}
+
+// inOpenSprint reports whether an issue is in an active or future sprint.
+func (s *Server) inOpenSprint(key string) bool {
+	for id, keys := range s.SprintIssue {
+		if s.Sprints[id]["state"] != "closed" && slices.Contains(keys, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// leaveOpenSprints takes issues out of active and future sprints. Closed
+// sprints keep them, as their history.
+func (s *Server) leaveOpenSprints(keys []string) {
+	for id, members := range s.SprintIssue {
+		if s.Sprints[id]["state"] != "closed" {
+			s.SprintIssue[id] = slices.DeleteFunc(members, func(k string) bool { return slices.Contains(keys, k) })
+		}
+	}
+}
+
+func (s *Server) moveToSprint(w http.ResponseWriter, r *http.Request) {
+	var payload movePayload
+	if err := readJSON(r, &payload); err != nil {
+		jiraError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.Lock()
+	defer s.Unlock()
+	id, sp := s.lookupSprint(w, r)
+	if sp == nil {
+		return
+	}
+	if sp["state"] == "closed" {
+		jiraError(w, http.StatusBadRequest, "Issues cannot be moved to a closed sprint.")
+		return
+	}
+	if msg := s.checkMove(payload); msg != "" {
+		jiraError(w, http.StatusBadRequest, msg)
+		return
+	}
+	s.leaveOpenSprints(payload.Issues)
+	s.SprintIssue[id] = append(s.SprintIssue[id], payload.Issues...)
+	s.rank(payload.Issues, payload.RankBeforeIssue, payload.RankAfterIssue)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) moveToBacklog(w http.ResponseWriter, r *http.Request) {
+	var payload movePayload
+	if err := readJSON(r, &payload); err != nil {
+		jiraError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.Lock()
+	defer s.Unlock()
+	if msg := s.checkMove(payload); msg != "" {
+		jiraError(w, http.StatusBadRequest, msg)
+		return
+	}
+	s.leaveOpenSprints(payload.Issues)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) rankIssues(w http.ResponseWriter, r *http.Request) {
+	var payload movePayload
+	if err := readJSON(r, &payload); err != nil {
+		jiraError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.Lock()
+	defer s.Unlock()
+	if payload.RankBeforeIssue == "" && payload.RankAfterIssue == "" {
+		jiraError(w, http.StatusBadRequest, "One of rankBeforeIssue and rankAfterIssue must be set")
+		return
+	}
+	if msg := s.checkMove(payload); msg != "" {
+		jiraError(w, http.StatusBadRequest, msg)
+		return
+	}
+	s.rank(payload.Issues, payload.RankBeforeIssue, payload.RankAfterIssue)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// getBacklog lists the issues of the board's project that are not in an
+// active or future sprint and not done, in rank order.
+func (s *Server) getBacklog(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	board := s.lookupBoard(w, r)
+	if board == nil {
+		return
+	}
+	q := r.URL.Query()
+	jql := "project = " + board["location"].(map[string]any)["projectKey"].(string)
+	if extra := q.Get("jql"); extra != "" {
+		jql += " AND (" + extra + ")"
+		s.Searches = append(s.Searches, extra)
+	}
+	var issues []*Issue
+	for _, issue := range s.sortedIssues(jql) {
+		status, _ := issue.Fields["status"].(map[string]any)
+		category, _ := status["statusCategory"].(map[string]any)
+		if !s.inOpenSprint(issue.Key) && category["key"] != "done" {
+			issues = append(issues, issue)
+		}
+	}
+	var fields []string
+	if v := q.Get("fields"); v != "" {
+		fields = strings.Split(v, ",")
+	}
+	writeJSON(w, http.StatusOK, s.searchResult(s.byRank(issues), queryInt(r, "startAt", 0), queryInt(r, "maxResults", 50), fields, nil))
+}
+
+// getBoardConfiguration maps the seeded statuses to To Do, In Progress and
+// Done columns.
+func (s *Server) getBoardConfiguration(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	board := s.lookupBoard(w, r)
+	if board == nil {
+		return
+	}
+	column := func(name string, ids ...string) map[string]any {
+		var statuses []map[string]any
+		for _, id := range ids {
+			statuses = append(statuses, map[string]any{"id": id, "self": s.JiraURL() + "/rest/api/2/status/" + id})
+		}
+		return map[string]any{"name": name, "statuses": statuses}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"id": board["id"], "name": board["name"], "type": board["type"],
+		"columnConfig": map[string]any{"columns": []map[string]any{
+			column("To Do", "1"), column("In Progress", "3"), column("Done", "10001"),
+		}},
+	})
+}
+
+// checkSprintState returns the error Jira gives when a sprint update changes
+// its state in a way Jira does not allow, if any.
+func (s *Server) checkSprintState(sp map[string]any, payload map[string]any) string {
+	to, _ := payload["state"].(string)
+	to = strings.ToLower(to)
+	from := sp["state"].(string)
+	switch {
+	case to == "" || to == from:
+		return ""
+	case from == "future" && to == "active":
+		for _, k := range []string{"startDate", "endDate"} {
+			if payload[k] == nil && sp[k] == nil {
+				return "A sprint needs a start and end date to be started."
+			}
+		}
+		for _, other := range s.Sprints {
+			if other["state"] == "active" && other["originBoardId"] == sp["originBoardId"] {
+				return "Another sprint is already active on this board. Complete it before starting a new one."
+			}
+		}
+		return ""
+	case from == "active" && to == "closed":
+		return ""
+	}
+	return fmt.Sprintf("A sprint cannot go from %s to %s.", from, to)
+}
@@ -0,0 +1,104 @@
+package fakeatlassian
+
+import (
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// workflowStatuses returns the statuses of the workflow in s.Transitions by
+// name. A status only known as a source of transitions gets a bare entry.
+func (s *Server) workflowStatuses() map[string]map[string]any {
+	statuses := map[string]map[string]any{}
+	for _, ts := range s.Transitions {
+		for _, t := range ts {
+			to := t["to"].(map[string]any)
+			statuses[to["name"].(string)] = to
+		}
+	}
+	for name := range s.Transitions {
+		if statuses[name] == nil {
+			statuses[name] = map[string]any{"id": name, "name": name, "statusCategory": map[string]any{"key": "indeterminate"}}
+		}
+	}
+	return statuses
+}
+
+// getProjectWorkflow serves the project configuration resource Jira Server
+// uses to show a workflow as text: each status with the transitions out of it.
+func (s *Server) getProjectWorkflow(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	q := r.URL.Query()
+	p := s.lookupProject(q.Get("projectKey"))
+	if p == nil || q.Get("workflowName") != p["key"].(string)+" Workflow" {
+		jiraError(w, http.StatusNotFound, "No workflow could be found with name '"+q.Get("workflowName")+"'.")
+		return
+	}
+	statuses := s.workflowStatuses()
+	sources := []map[string]any{}
+	for _, name := range slices.Sorted(maps.Keys(s.Transitions)) {
+		targets := []map[string]any{}
+		for _, t := range s.Transitions[name] {
+			targets = append(targets, map[string]any{"toStatus": t["to"], "transitionName": t["name"]})
+		}
+		sources = append(sources, map[string]any{"fromStatus": statuses[name], "targets": targets})
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"name": q.Get("workflowName"), "displayName": q.Get("workflowName"), "sources": sources})
+}
+
+// getWorkflowSchemeAssociations serves the Cloud workflow schemes of projects.
+func (s *Server) getWorkflowSchemeAssociations(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	values := []map[string]any{}
+	for _, id := range r.URL.Query()["projectId"] {
+		if p := s.lookupProject(id); p != nil {
+			key := p["key"].(string)
+			values = append(values, map[string]any{
+				"projectIds":     []string{id},
+				"workflowScheme": map[string]any{"id": 10100, "name": key + " Workflow Scheme", "defaultWorkflow": key + " Workflow", "issueTypeMappings": map[string]any{}},
+			})
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"values": values})
+}
+
+// searchWorkflows serves the Cloud workflow search with transitions expanded,
+// which name their statuses by ID.
+func (s *Server) searchWorkflows(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	statuses := s.workflowStatuses()
+	values := []map[string]any{}
+	for _, p := range s.Projects {
+		name := p["key"].(string) + " Workflow"
+		if names := r.URL.Query()["workflowName"]; len(names) > 0 && !slices.Contains(names, name) {
+			continue
+		}
+		transitions := []map[string]any{}
+		for _, from := range slices.Sorted(maps.Keys(s.Transitions)) {
+			for _, t := range s.Transitions[from] {
+				transitions = append(transitions, map[string]any{
+					"id": t["id"], "name": t["name"], "type": "directed",
+					"from": []any{statuses[from]["id"]}, "to": t["to"].(map[string]any)["id"],
+				})
+			}
+		}
+		values = append(values, map[string]any{"id": map[string]any{"name": name}, "transitions": transitions})
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"startAt": 0, "maxResults": 50, "total": len(values), "isLast": true, "values": values})
+}
+
+// getStatuses lists every status of the workflow with its category.
+func (s *Server) getStatuses(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	statuses := s.workflowStatuses()
+	out := []map[string]any{}
+	for _, name := range slices.SortedFunc(maps.Keys(statuses), strings.Compare) {
+		out = append(out, statuses[name])
+	}
+	writeJSON(w, http.StatusOK, out)
+}
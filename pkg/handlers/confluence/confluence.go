@@ -3,14 +3,17 @@ package confluence
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 
 	md "github.com/JohannesKaufmann/html-to-markdown"
+	"github.com/ctreminiom/go-atlassian/v2/confluence"
 	"github.com/ctreminiom/go-atlassian/v2/pkg/infra/models"
 	"github.com/mark3labs/mcp-go/mcp"
 
 	"mcp-atlassian-server/pkg/clients"
+	"mcp-atlassian-server/pkg/handlers"
 )
 
 func PingHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -20,15 +23,8 @@ func PingHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolRes
 	}
 	// Use a simple content search as a health check
 	_, resp, err := client.Search.Content(ctx, "type=page", &models.SearchContentOptions{Limit: 1})
-	if err != nil || resp == nil || resp.StatusCode != 200 {
-		errMsg := "Confluence ping failed"
-		if err != nil {
-			errMsg += ": " + err.Error()
-		}
-		if resp != nil {
-			errMsg += resp.Bytes.String()
-		}
-		return mcp.NewToolResultError(errMsg), nil
+	if err != nil {
+		return handlers.ToolError("Confluence ping failed", resp, err), nil
 	}
 	return mcp.NewToolResultText("Confluence OK"), nil
 }
@@ -39,10 +35,6 @@ func SearchHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolR
 	if err != nil {
 		return mcp.NewToolResultError("Missing required parameter: query"), nil
 	}
-	limit := req.GetInt("limit", 10)
-	if limit < 1 || limit > 50 {
-		limit = 10
-	}
 	spacesFilter := req.GetString("spaces_filter", "")
 
 	client, err := clients.GetConfluenceClient(ctx)
@@ -65,18 +57,33 @@ func SearchHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolR
 		}
 	}
 
-	options := &models.SearchContentOptions{
-		Limit: limit,
-	}
-	_, resp, err := client.Search.Content(ctx, cql, options)
-	if err != nil || resp == nil || resp.StatusCode != 200 {
-		errMsg := "Confluence search failed: " + err.Error()
-		if resp != nil {
-			errMsg += resp.Bytes.String()
+	pager, err := handlers.NewPager(req, 10, "start", "search\n"+cql)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return pagedResult(pager.Collect("results", func(start, limit int) (map[string]any, int, error) {
+		_, resp, err := client.Search.Content(ctx, cql, &models.SearchContentOptions{Start: start, Limit: limit})
+		if err != nil {
+			return nil, 0, errors.New(handlers.ErrorMessage("Confluence search failed", resp, err))
+		}
+		body, err := handlers.DecodePage(resp.Bytes.Bytes())
+		if err != nil {
+			return nil, 0, err
 		}
-		return mcp.NewToolResultError(errMsg), nil
+		return body, handlers.IntField(body, "totalSize", 0), nil
+	})), nil
+}
+
+// pagedResult renders the result of handlers.Pager.Collect.
+func pagedResult(body map[string]any, err error) *mcp.CallToolResult {
+	if err != nil {
+		return mcp.NewToolResultError(err.Error())
 	}
-	return mcp.NewToolResultText(resp.Bytes.String()), nil
+	out, err := json.Marshal(body)
+	if err != nil {
+		return mcp.NewToolResultError("Failed to marshal results: " + err.Error())
+	}
+	return mcp.NewToolResultText(string(out))
 }
 
 // Handler for confluence_get_page
@@ -95,12 +102,8 @@ func GetPageHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallTool
 		// Use correct expands for v2 SDK
 		expands := []string{"body.storage", "version", "metadata.labels"}
 		page, resp, err := client.Content.Get(ctx, pageID, expands, 0)
-		if err != nil || resp == nil || resp.StatusCode != 200 {
-			errMsg := "Failed to retrieve page by ID: " + err.Error()
-			if resp != nil {
-				errMsg += resp.Bytes.String()
-			}
-			return mcp.NewToolResultError(errMsg), nil
+		if err != nil {
+			return handlers.ToolError("Failed to retrieve page by ID", resp, err), nil
 		}
 		if !includeMetadata {
 			if page.Body != nil && page.Body.Storage != nil {
@@ -120,15 +123,11 @@ func GetPageHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallTool
 	} else if title != "" && spaceKey != "" {
 		cql := fmt.Sprintf("title=\"%s\" AND space=\"%s\"", title, spaceKey)
 		results, resp, err := client.Search.Content(ctx, cql, &models.SearchContentOptions{Limit: 1})
-		if err != nil || resp == nil || resp.StatusCode != 200 || len(results.Results) == 0 {
-			errMsg := fmt.Sprintf("Page with title '%s' not found in space '%s'", title, spaceKey)
-			if err != nil {
-				errMsg += ": " + err.Error()
-			}
-			if resp != nil {
-				errMsg += resp.Bytes.String()
-			}
-			return mcp.NewToolResultError(errMsg), nil
+		if err != nil {
+			return handlers.ToolError("Failed to search for page", resp, err), nil
+		}
+		if len(results.Results) == 0 {
+			return mcp.NewToolResultError(fmt.Sprintf("Page with title '%s' not found in space '%s'", title, spaceKey)), nil
 		}
 		jsonBytes, err := json.Marshal(results.Results[0])
 		if err != nil {
@@ -144,8 +143,10 @@ func GetPageHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallTool
 func GetPageChildrenHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	parentID := req.GetString("parent_id", "")
 	expand := req.GetString("expand", "version")
-	limit := req.GetInt("limit", 25)
-	start := req.GetInt("start", 0)
+	pager, err := handlers.NewPager(req, 25, "start", "children\n"+parentID)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 
 	client, err := clients.GetConfluenceClient(ctx)
 	if err != nil {
@@ -159,29 +160,44 @@ func GetPageChildrenHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.
 			expands[i] = strings.TrimSpace(expands[i])
 		}
 	}
-	// Comment out containsBodyStorage usage if present
-	// if includeContent && !containsBodyStorage(expand) {
-	// 	expands = append(expands, "body.storage")
-	// }
-	children, resp, err := client.Content.ChildrenDescendant.ChildrenByType(ctx, parentID, "page", 0, expands, start, limit)
-	if err != nil || resp == nil || resp.StatusCode != 200 {
-		errMsg := "Failed to get child pages: " + err.Error()
-		if resp != nil {
-			errMsg += resp.Bytes.String()
+	// Confluence does not report how many children a page has, so the
+	// total comes from a CQL count when there is more than one page.
+	total := -1
+	countChildren := func() (int, error) {
+		if total < 0 {
+			results, resp, err := client.Search.Content(ctx, fmt.Sprintf("parent = %s AND type = page", parentID), &models.SearchContentOptions{Limit: 1})
+			if err != nil {
+				return 0, errors.New(handlers.ErrorMessage("Failed to count child pages", resp, err))
+			}
+			total = results.TotalSize
+		}
+		return total, nil
+	}
+	body, err := pager.Collect("results", func(start, limit int) (map[string]any, int, error) {
+		children, resp, err := client.Content.ChildrenDescendant.ChildrenByType(ctx, parentID, "page", 0, expands, start, limit)
+		if err != nil {
+			return nil, 0, errors.New(handlers.ErrorMessage("Failed to get child pages", resp, err))
+		}
+		body, err := handlers.DecodePage(resp.Bytes.Bytes())
+		if err != nil {
+			return nil, 0, err
 		}
-		return mcp.NewToolResultError(errMsg), nil
-	}
-	jsonBytes, err := json.Marshal(map[string]any{
-		"parent_id":       parentID,
-		"count":           len(children.Results),
-		"limit_requested": limit,
-		"start_requested": start,
-		"results":         children.Results,
+		if n := handlers.IntField(body, "totalSize", -1); n >= 0 {
+			return body, n, nil
+		}
+		if children.Links == nil || children.Links.Next == "" {
+			return body, start + len(children.Results), nil
+		}
+		n, err := countChildren()
+		return body, n, err
 	})
 	if err != nil {
-		return mcp.NewToolResultError("Failed to marshal children results: " + err.Error()), nil
+		return mcp.NewToolResultError(err.Error()), nil
 	}
-	return mcp.NewToolResultText(string(jsonBytes)), nil
+	results, _ := body["results"].([]any)
+	body["parent_id"] = parentID
+	body["count"] = len(results)
+	return pagedResult(body, nil), nil
 }
 
 // Handler for confluence_get_comments
@@ -193,12 +209,8 @@ func GetCommentsHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.Call
 	}
 	// v2 SDK: expands and pagination
 	_, resp, err := client.Content.Comment.Gets(ctx, pageID, nil, nil, 0, 50)
-	if err != nil || resp == nil || resp.StatusCode != 200 {
-		errMsg := "Failed to get comments: " + err.Error()
-		if resp != nil {
-			errMsg += resp.Bytes.String()
-		}
-		return mcp.NewToolResultError(errMsg), nil
+	if err != nil {
+		return handlers.ToolError("Failed to get comments", resp, err), nil
 	}
 	return mcp.NewToolResultText(resp.Bytes.String()), nil
 }
@@ -211,12 +223,8 @@ func GetLabelsHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallTo
 		return mcp.NewToolResultError("Confluence client error: " + err.Error()), nil
 	}
 	_, resp, err := client.Content.Label.Gets(ctx, pageID, "", 0, 50)
-	if err != nil || resp == nil || resp.StatusCode != 200 {
-		errMsg := "Failed to get labels: " + err.Error()
-		if resp != nil {
-			errMsg += resp.Bytes.String()
-		}
-		return mcp.NewToolResultError(errMsg), nil
+	if err != nil {
+		return handlers.ToolError("Failed to get labels", resp, err), nil
 	}
 	return mcp.NewToolResultText(resp.Bytes.String()), nil
 }
@@ -231,12 +239,8 @@ func AddLabelHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToo
 	}
 	payload := []*models.ContentLabelPayloadScheme{{Prefix: "global", Name: name}}
 	_, resp, err := client.Content.Label.Add(ctx, pageID, payload, false)
-	if err != nil || resp == nil || resp.StatusCode != 200 {
-		errMsg := "Failed to add label: " + err.Error()
-		if resp != nil {
-			errMsg += resp.Bytes.String()
-		}
-		return mcp.NewToolResultError(errMsg), nil
+	if err != nil {
+		return handlers.ToolError("Failed to add label", resp, err), nil
 	}
 	return mcp.NewToolResultText(resp.Bytes.String()), nil
 }
@@ -251,6 +255,16 @@ func CreatePageHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallT
 	if err != nil {
 		return mcp.NewToolResultError("Confluence client error: " + err.Error()), nil
 	}
+	_, resp, err := CreatePage(ctx, client, spaceKey, title, content, parentID)
+	if err != nil {
+		return handlers.ToolError("Failed to create page", resp, err), nil
+	}
+	return mcp.NewToolResultText(resp.Bytes.String()), nil
+}
+
+// CreatePage creates a page from wiki markup content, under parentID when it
+// is set.
+func CreatePage(ctx context.Context, client *confluence.Client, spaceKey, title, content, parentID string) (*models.ContentScheme, *models.ResponseScheme, error) {
 	pagePayload := &models.ContentScheme{
 		Type:  "page",
 		Title: title,
@@ -265,15 +279,7 @@ func CreatePageHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallT
 	if parentID != "" {
 		pagePayload.Ancestors = []*models.ContentScheme{{ID: parentID}}
 	}
-	_, resp, err := client.Content.Create(ctx, pagePayload)
-	if err != nil || resp == nil || (resp.StatusCode != 200 && resp.StatusCode != 201) {
-		errMsg := "Failed to create page: " + err.Error()
-		if resp != nil {
-			errMsg += resp.Bytes.String()
-		}
-		return mcp.NewToolResultError(errMsg), nil
-	}
-	return mcp.NewToolResultText(resp.Bytes.String()), nil
+	return client.Content.Create(ctx, pagePayload)
 }
 
 // Handler for confluence_update_page
@@ -289,12 +295,8 @@ func UpdatePageHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallT
 		return mcp.NewToolResultError("Confluence client error: " + err.Error()), nil
 	}
 	current, resp, err := client.Content.Get(ctx, pageID, []string{"version"}, 0)
-	if err != nil || resp == nil || resp.StatusCode != 200 {
-		errMsg := "Failed to get current page: " + err.Error()
-		if resp != nil {
-			errMsg += resp.Bytes.String()
-		}
-		return mcp.NewToolResultError(errMsg), nil
+	if err != nil {
+		return handlers.ToolError("Failed to get current page", resp, err), nil
 	}
 	newVersion := 1
 	if current.Version != nil && current.Version.Number > 0 {
@@ -320,12 +322,8 @@ func UpdatePageHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallT
 		updatePayload.Ancestors = []*models.ContentScheme{{ID: parentID}}
 	}
 	_, resp, err = client.Content.Update(ctx, pageID, updatePayload)
-	if err != nil || resp == nil || resp.StatusCode != 200 {
-		errMsg := "Failed to update page: " + err.Error()
-		if resp != nil {
-			errMsg += resp.Bytes.String()
-		}
-		return mcp.NewToolResultError(errMsg), nil
+	if err != nil {
+		return handlers.ToolError("Failed to update page", resp, err), nil
 	}
 	return mcp.NewToolResultText(resp.Bytes.String()), nil
 }
@@ -338,12 +336,8 @@ func DeletePageHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallT
 		return mcp.NewToolResultError("Confluence client error: " + err.Error()), nil
 	}
 	resp, err := client.Content.Delete(ctx, pageID, "current")
-	if err != nil || resp == nil || resp.StatusCode != 204 {
-		errMsg := "Failed to delete page: " + err.Error()
-		if resp != nil {
-			errMsg += resp.Bytes.String()
-		}
-		return mcp.NewToolResultError(errMsg), nil
+	if err != nil {
+		return handlers.ToolError("Failed to delete page", resp, err), nil
 	}
 	return mcp.NewToolResultText(fmt.Sprintf("Page %s deleted successfully", pageID)), nil
 }
@@ -372,7 +366,7 @@ func AddCommentHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallT
 	}
 
 	// Build the URL
-	url := fmt.Sprintf("%s://%s/rest/api/content", client.Site.Scheme, client.Site.Host)
+	url := "wiki/rest/api/content"
 	reqHttp, err := client.NewRequest(ctx, "POST", url, "application/json", commentPayload)
 	if err != nil {
 		return mcp.NewToolResultError("Failed to create HTTP request: " + err.Error()), nil
@@ -380,15 +374,7 @@ func AddCommentHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallT
 	var structure any
 	resp, err := client.Call(reqHttp, &structure)
 	if err != nil {
-		errMsg := "Failed to add comment: " + err.Error()
-		if resp != nil {
-			errMsg += resp.Bytes.String()
-		}
-		return mcp.NewToolResultError(errMsg), nil
-	}
-	if resp.StatusCode != 201 && resp.StatusCode != 200 {
-		errMsg := "Failed to add comment: " + string(resp.Bytes.String())
-		return mcp.NewToolResultError(errMsg), nil
+		return handlers.ToolError("Failed to add comment", resp, err), nil
 	}
 	return mcp.NewToolResultText(string(resp.Bytes.String())), nil
 }
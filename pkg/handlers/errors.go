@@ -0,0 +1,93 @@
+// Package handlers provides helpers shared by the Jira and Confluence tool handlers.
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/ctreminiom/go-atlassian/v2/pkg/infra/models"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// maxRawBodyLength caps how much of a non-JSON error body is echoed back.
+const maxRawBodyLength = 500
+
+// statusHints are actionable suggestions appended to errors by HTTP status.
+var statusHints = map[int]string{
+	http.StatusBadRequest:   "The request was rejected by validation. Check the field names and values listed above; jira_search_fields lists valid field IDs.",
+	http.StatusUnauthorized: "Authentication failed. Check that the personal access token (JIRA_PERSONAL_TOKEN or CONFLUENCE_PERSONAL_TOKEN) is valid and has not expired.",
+	http.StatusForbidden:    "The token's user lacks permission for this operation. Ask an administrator for access or use an account with the required project or space permission.",
+	http.StatusNotFound:     "The resource was not found. Check the issue key, project key or page ID, and that the token's user can see it.",
+}
+
+// atlassianError is the union of the Jira and Confluence error payloads.
+type atlassianError struct {
+	// Jira
+	ErrorMessages []string          `json:"errorMessages"`
+	Errors        map[string]string `json:"errors"`
+	// Confluence
+	Message string `json:"message"`
+}
+
+// ToolError translates a failed Atlassian API call into an MCP tool error.
+// The message carries the HTTP status, the error details reported by Jira
+// or Confluence and, for common statuses, a hint on how to fix the call.
+// resp may be nil when the request failed before a response was received.
+func ToolError(action string, resp *models.ResponseScheme, err error) *mcp.CallToolResult {
+	return mcp.NewToolResultError(ErrorMessage(action, resp, err))
+}
+
+// ErrorMessage builds the text used by ToolError.
+func ErrorMessage(action string, resp *models.ResponseScheme, err error) string {
+	var b strings.Builder
+	b.WriteString(action)
+	if resp == nil || resp.Code == 0 {
+		if err != nil {
+			b.WriteString(": " + err.Error())
+		}
+		return b.String()
+	}
+
+	fmt.Fprintf(&b, ": HTTP %d %s", resp.Code, http.StatusText(resp.Code))
+	for _, detail := range errorDetails(resp.Bytes.Bytes()) {
+		b.WriteString("\n- " + detail)
+	}
+	if resp.Code >= 200 && resp.Code < 300 && err != nil {
+		b.WriteString("\n- " + err.Error())
+	}
+	if hint, ok := statusHints[resp.Code]; ok {
+		b.WriteString("\nHint: " + hint)
+	}
+	return b.String()
+}
+
+// errorDetails extracts the human readable messages from an error body.
+func errorDetails(body []byte) []string {
+	raw := strings.TrimSpace(string(body))
+	if raw == "" {
+		return nil
+	}
+	var payload atlassianError
+	if err := json.Unmarshal(body, &payload); err != nil {
+		if len(raw) > maxRawBodyLength {
+			raw = raw[:maxRawBodyLength] + "..."
+		}
+		return []string{raw}
+	}
+	details := append([]string{}, payload.ErrorMessages...)
+	fields := make([]string, 0, len(payload.Errors))
+	for field := range payload.Errors {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		details = append(details, field+": "+payload.Errors[field])
+	}
+	if payload.Message != "" {
+		details = append(details, payload.Message)
+	}
+	return details
+}
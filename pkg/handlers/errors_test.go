@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ctreminiom/go-atlassian/v2/pkg/infra/models"
+)
+
+func response(code int, body string) *models.ResponseScheme {
+	resp := &models.ResponseScheme{Code: code}
+	resp.Bytes.WriteString(body)
+	return resp
+}
+
+func TestErrorMessage(t *testing.T) {
+	tests := []struct {
+		name string
+		resp *models.ResponseScheme
+		err  error
+		want []string
+	}{
+		{
+			name: "transport error",
+			err:  errors.New("dial tcp: connection refused"),
+			want: []string{"Failed to get issue: dial tcp: connection refused"},
+		},
+		{
+			name: "jira field validation",
+			resp: response(400, `{"errorMessages":["Bad request"],"errors":{"summary":"You must specify a summary.","customfield_10014":"Epic does not exist"}}`),
+			err:  models.ErrBadRequest,
+			want: []string{"HTTP 400 Bad Request", "- Bad request", "- customfield_10014: Epic does not exist\n- summary: You must specify a summary.", "Hint: The request was rejected"},
+		},
+		{
+			name: "jira missing issue",
+			resp: response(404, `{"errorMessages":["Issue Does Not Exist"],"errors":{}}`),
+			err:  models.ErrNotFound,
+			want: []string{"HTTP 404 Not Found", "- Issue Does Not Exist", "Hint: The resource was not found"},
+		},
+		{
+			name: "confluence permission",
+			resp: response(403, `{"statusCode":403,"message":"Not permitted to view page"}`),
+			err:  models.ErrInvalidStatusCode,
+			want: []string{"HTTP 403 Forbidden", "- Not permitted to view page", "Hint: The token's user lacks permission"},
+		},
+		{
+			name: "html body",
+			resp: response(401, `<html>Unauthorized</html>`),
+			err:  models.ErrUnauthorized,
+			want: []string{"HTTP 401 Unauthorized", "- <html>Unauthorized</html>", "personal access token"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ErrorMessage("Failed to get issue", tt.resp, tt.err)
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("message %q does not contain %q", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestToolErrorIsError(t *testing.T) {
+	result := ToolError("Failed", response(500, ""), models.ErrInternal)
+	if !result.IsError {
+		t.Fatal("expected IsError to be set")
+	}
+}
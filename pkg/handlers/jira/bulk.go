@@ -0,0 +1,447 @@
+package jira
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	jira "github.com/ctreminiom/go-atlassian/v2/jira/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"mcp-atlassian-server/pkg/clients"
+	"mcp-atlassian-server/pkg/config"
+	"mcp-atlassian-server/pkg/handlers"
+	"mcp-atlassian-server/pkg/utils"
+)
+
+const (
+	bulkMaxConcurrency = 10
+	bulkMaxRate        = 50 // issues started per second
+	bulkSearchPage     = 100
+)
+
+// bulkPlan is the checkpoint of a bulk update: the selected issues, the
+// operations to apply and the outcome per issue so far.
+type bulkPlan struct {
+	ID         string                `json:"id"`
+	JQL        string                `json:"jql"`
+	Created    time.Time             `json:"created"`
+	Operations bulkOperations        `json:"operations"`
+	Keys       []string              `json:"keys"`
+	Results    map[string]bulkResult `json:"results,omitempty"`
+
+	mu sync.Mutex
+}
+
+// bulkOperations are applied to each issue in order: edit, transition, link.
+type bulkOperations struct {
+	Fields       map[string]any `json:"fields,omitempty"` // Keyed by field ID
+	AddLabels    []string       `json:"add_labels,omitempty"`
+	RemoveLabels []string       `json:"remove_labels,omitempty"`
+	Assignee     string         `json:"assignee,omitempty"`     // as given
+	AssigneeRef  map[string]any `json:"assignee_ref,omitempty"` // resolved when the plan was made
+	Comment      string         `json:"comment,omitempty"`
+	Status       string         `json:"status,omitempty"`
+	MaxSteps     int            `json:"max_steps,omitempty"`
+	LinkType     string         `json:"link_type,omitempty"`
+	LinkIssue    string         `json:"link_issue,omitempty"`
+}
+
+type bulkResult struct {
+	Key    string   `json:"key"`
+	Status string   `json:"status"` // "ok" or "failed"
+	Steps  []string `json:"steps,omitempty"`
+	Done   []string `json:"done,omitempty"` // operations applied: "edit", "transition", "link"
+	Error  string   `json:"error,omitempty"`
+}
+
+func (o bulkOperations) empty() bool {
+	return len(o.Fields) == 0 && len(o.AddLabels) == 0 && len(o.RemoveLabels) == 0 && o.AssigneeRef == nil &&
+		o.Comment == "" && o.Status == "" && o.LinkIssue == ""
+}
+
+func (o bulkOperations) edits() bool {
+	return len(o.Fields) > 0 || len(o.AddLabels) > 0 || len(o.RemoveLabels) > 0 || o.AssigneeRef != nil || o.Comment != ""
+}
+
+var checkpointIDPattern = regexp.MustCompile(`^[0-9a-f]{16}$`)
+
+func checkpointDir() (string, error) {
+	if dir := config.Current().Bulk.CheckpointDir; dir != "" {
+		return dir, nil
+	}
+	cache, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("no checkpoint directory: set bulk.checkpoint_dir: %w", err)
+	}
+	return filepath.Join(cache, "mcp-atlassian-server", "bulk"), nil
+}
+
+func newCheckpointID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func loadBulkPlan(id string) (*bulkPlan, error) {
+	if !checkpointIDPattern.MatchString(id) {
+		return nil, fmt.Errorf("invalid checkpoint %q", id)
+	}
+	dir, err := checkpointDir()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filepath.Join(dir, id+".json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("checkpoint %s not found", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	plan := &bulkPlan{}
+	if err := json.Unmarshal(data, plan); err != nil {
+		return nil, fmt.Errorf("reading checkpoint %s: %w", id, err)
+	}
+	return plan, nil
+}
+
+// save writes the plan atomically so an interrupted write cannot lose progress.
+func (p *bulkPlan) save() error {
+	dir, err := checkpointDir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dir, p.ID+".json")
+	if err := os.WriteFile(path+".tmp", data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+func (p *bulkPlan) record(res bulkResult) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.Results == nil {
+		p.Results = map[string]bulkResult{}
+	}
+	p.Results[res.Key] = res
+	return p.save()
+}
+
+// result returns the outcome of an earlier attempt on key, if any.
+func (p *bulkPlan) result(key string) bulkResult {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.Results[key]
+}
+
+// pending returns the keys that have not been applied successfully yet.
+func (p *bulkPlan) pending() []string {
+	var keys []string
+	for _, key := range p.Keys {
+		if p.Results[key].Status != "ok" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func (p *bulkPlan) summary(dryRun bool) map[string]any {
+	out := map[string]any{
+		"checkpoint": p.ID,
+		"jql":        p.JQL,
+		"operations": p.Operations,
+		"total":      len(p.Keys),
+	}
+	pending := p.pending()
+	if dryRun {
+		out["dry_run"] = true
+		out["keys"] = pending
+		out["next"] = fmt.Sprintf("Review the %d issues, then call jira_bulk_update with checkpoint %q and dry_run false to apply", len(pending), p.ID)
+		return out
+	}
+	succeeded, failed := 0, 0
+	results := make([]bulkResult, 0, len(p.Results))
+	for _, key := range p.Keys {
+		res, ok := p.Results[key]
+		if !ok {
+			continue
+		}
+		if res.Status == "ok" {
+			succeeded++
+		} else {
+			failed++
+		}
+		results = append(results, res)
+	}
+	out["succeeded"] = succeeded
+	out["failed"] = failed
+	out["pending"] = len(p.Keys) - succeeded - failed
+	out["complete"] = len(pending) == 0
+	out["results"] = results
+	if len(pending) > 0 {
+		out["next"] = fmt.Sprintf("Call jira_bulk_update with checkpoint %q and dry_run false to retry the %d remaining issues", p.ID, len(pending))
+	}
+	return out
+}
+
+// selectIssues returns the keys matched by jql, failing when there are more
+// than max so a broad query cannot silently touch the whole instance.
+func selectIssues(ctx context.Context, client *jira.Client, jql string, max int) ([]string, error) {
+	var keys []string
+	for startAt := 0; ; {
+		result, resp, err := client.Issue.Search.Post(ctx, jql, []string{"key"}, nil, startAt, bulkSearchPage, "")
+		if err != nil {
+			return nil, errors.New(handlers.ErrorMessage("Failed to search issues", resp, err))
+		}
+		if result.Total > max {
+			return nil, fmt.Errorf("JQL matches %d issues, more than the limit of %d; narrow the query or raise max_issues", result.Total, max)
+		}
+		for _, issue := range result.Issues {
+			keys = append(keys, issue.Key)
+		}
+		startAt += len(result.Issues)
+		if len(result.Issues) == 0 || startAt >= result.Total {
+			return keys, nil
+		}
+	}
+}
+
+// applyBulk runs every operation against one issue, stopping at the first
+// failure. Operations an earlier attempt applied, as recorded in prev, are not
+// repeated, so a resumed run does not comment or link twice.
+func applyBulk(ctx context.Context, client *jira.Client, key string, ops bulkOperations, prev bulkResult) bulkResult {
+	res := bulkResult{Key: key, Status: "ok", Steps: slices.Clone(prev.Steps), Done: slices.Clone(prev.Done)}
+	pending := func(step string) bool { return !slices.Contains(res.Done, step) }
+	done := func(step, desc string) {
+		res.Done = append(res.Done, step)
+		res.Steps = append(res.Steps, desc)
+	}
+	fail := func(err error) bulkResult {
+		res.Status = "failed"
+		res.Error = err.Error()
+		return res
+	}
+	if err := ctx.Err(); err != nil {
+		return fail(err)
+	}
+
+	if ops.edits() && pending("edit") {
+		fields := map[string]any{}
+		for k, v := range ops.Fields {
+			fields[k] = v
+		}
+		if ops.AssigneeRef != nil {
+			fields["assignee"] = ops.AssigneeRef
+		}
+		update := map[string]any{}
+		var labels []map[string]any
+		for _, l := range ops.AddLabels {
+			labels = append(labels, map[string]any{"add": l})
+		}
+		for _, l := range ops.RemoveLabels {
+			labels = append(labels, map[string]any{"remove": l})
+		}
+		if len(labels) > 0 {
+			update["labels"] = labels
+		}
+		if ops.Comment != "" {
+			update["comment"] = []map[string]any{{"add": map[string]any{"body": ops.Comment}}}
+		}
+		payload := map[string]any{}
+		if len(fields) > 0 {
+			payload["fields"] = fields
+		}
+		if len(update) > 0 {
+			payload["update"] = update
+		}
+		req, err := client.NewRequest(ctx, "PUT", fmt.Sprintf("rest/api/2/issue/%s", key), "", payload)
+		if err != nil {
+			return fail(err)
+		}
+		if resp, err := client.Call(req, nil); err != nil {
+			return fail(errors.New(handlers.ErrorMessage("Failed to update issue", resp, err)))
+		}
+		done("edit", "edited")
+	}
+
+	if ops.Status != "" && pending("transition") {
+		path, err := walkTransitions(ctx, client, key, transitionRequest{Status: ops.Status, MaxSteps: ops.MaxSteps})
+		if err != nil {
+			return fail(err)
+		}
+		if len(path) == 1 {
+			done("transition", "already in "+path[0])
+		} else {
+			done("transition", strings.Join(path, " -> "))
+		}
+	}
+
+	if ops.LinkIssue != "" && pending("link") {
+		payload := map[string]any{
+			"type":         map[string]any{"name": ops.LinkType},
+			"inwardIssue":  map[string]any{"key": key},
+			"outwardIssue": map[string]any{"key": ops.LinkIssue},
+		}
+		req, err := client.NewRequest(ctx, "POST", "rest/api/2/issueLink", "", payload)
+		if err != nil {
+			return fail(err)
+		}
+		if resp, err := client.Call(req, nil); err != nil {
+			return fail(errors.New(handlers.ErrorMessage("Failed to create issue link", resp, err)))
+		}
+		done("link", fmt.Sprintf("linked %s %s", ops.LinkType, ops.LinkIssue))
+	}
+	return res
+}
+
+// run applies the plan to its pending issues with at most concurrency
+// issues in flight and no more than rate issues started per second. Each
+// outcome is written to the checkpoint as soon as it is known, so an
+// interrupted run can be resumed.
+func (p *bulkPlan) run(ctx context.Context, client *jira.Client, concurrency int, rate float64) error {
+	jobs := make(chan string)
+	errs := make(chan error, 1)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for key := range jobs {
+				if err := p.record(applyBulk(ctx, client, key, p.Operations, p.result(key))); err != nil {
+					select {
+					case errs <- err:
+					default:
+					}
+				}
+			}
+		}()
+	}
+
+	ticker := time.NewTicker(time.Duration(float64(time.Second) / rate))
+	defer ticker.Stop()
+dispatch:
+	for i, key := range p.pending() {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				break dispatch
+			case <-ticker.C:
+			}
+		}
+		select {
+		case <-ctx.Done():
+			break dispatch
+		case jobs <- key:
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	select {
+	case err := <-errs:
+		return fmt.Errorf("saving checkpoint: %w", err)
+	default:
+		return nil
+	}
+}
+
+func BulkUpdateHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	jql := req.GetString("jql", "")
+	checkpoint := req.GetString("checkpoint", "")
+	dryRun := req.GetBool("dry_run", true)
+	fieldsStr := req.GetString("fields", "")
+	concurrency := min(max(req.GetInt("concurrency", 4), 1), bulkMaxConcurrency)
+	rate := min(max(req.GetFloat("rate", 5), 0.1), bulkMaxRate)
+	maxIssues := min(max(req.GetInt("max_issues", 100), 1), config.Current().Bulk.MaxIssues)
+	if jql == "" && checkpoint == "" {
+		return mcp.NewToolResultError("Missing required parameters: jql or checkpoint is required"), nil
+	}
+	client, err := clients.GetJiraClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
+	}
+
+	var plan *bulkPlan
+	if checkpoint != "" {
+		if plan, err = loadBulkPlan(checkpoint); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	} else {
+		ops := bulkOperations{
+			AddLabels:    utils.SplitAndTrim(req.GetString("add_labels", "")),
+			RemoveLabels: utils.SplitAndTrim(req.GetString("remove_labels", "")),
+			Assignee:     strings.TrimSpace(req.GetString("assignee", "")),
+			Comment:      req.GetString("comment", ""),
+			Status:       strings.TrimSpace(req.GetString("status", "")),
+			MaxSteps:     req.GetInt("max_steps", 1),
+			LinkType:     req.GetString("link_type", ""),
+			LinkIssue:    req.GetString("link_issue", ""),
+		}
+		if (ops.LinkType == "") != (ops.LinkIssue == "") {
+			return mcp.NewToolResultError("link_type and link_issue must be set together"), nil
+		}
+		if fieldsStr != "" {
+			var fields map[string]any
+			if err := json.Unmarshal([]byte(fieldsStr), &fields); err != nil {
+				return mcp.NewToolResultError("Invalid fields JSON: " + err.Error()), nil
+			}
+			catalog, resp, err := getFieldCatalog(ctx, client, false)
+			if err != nil {
+				return handlers.ToolError("Failed to load Jira fields", resp, err), nil
+			}
+			if ops.Fields, err = catalog.resolvePayload(ctx, client, fields); err != nil {
+				return mcp.NewToolResultError("Invalid fields: " + err.Error()), nil
+			}
+		}
+		if ops.Assignee != "" {
+			resolver, err := newUserResolver(ctx, client)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			user, err := resolver.resolve(ctx, ops.Assignee, "")
+			if err != nil {
+				return mcp.NewToolResultError("Invalid assignee: " + err.Error()), nil
+			}
+			ops.AssigneeRef = resolver.ref(user)
+		}
+		if ops.empty() {
+			return mcp.NewToolResultError("No operations given: set fields, add_labels, remove_labels, assignee, comment, status or link_type and link_issue"), nil
+		}
+		keys, err := selectIssues(ctx, client, jql, maxIssues)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		plan = &bulkPlan{ID: newCheckpointID(), JQL: jql, Created: time.Now().UTC(), Operations: ops, Keys: keys}
+		if err := plan.save(); err != nil {
+			return mcp.NewToolResultError("Failed to save checkpoint: " + err.Error()), nil
+		}
+	}
+
+	if !dryRun {
+		if err := plan.run(ctx, client, concurrency, rate); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	}
+	out, err := json.Marshal(plan.summary(dryRun))
+	if err != nil {
+		return mcp.NewToolResultError("Failed to encode result: " + err.Error()), nil
+	}
+	return mcp.NewToolResultText(string(out)), nil
+}
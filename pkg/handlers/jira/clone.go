@@ -0,0 +1,590 @@
+package jira
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	jira "github.com/ctreminiom/go-atlassian/v2/jira/v2"
+	"github.com/ctreminiom/go-atlassian/v2/pkg/infra/models"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"mcp-atlassian-server/pkg/clients"
+	"mcp-atlassian-server/pkg/config"
+	"mcp-atlassian-server/pkg/handlers"
+)
+
+// cloneOptions says where a copy goes and what comes along with it.
+type cloneOptions struct {
+	Project      string
+	IssueType    string            // target type of the top issue; empty keeps the source type
+	TypeMapping  map[string]string // source type name to target type name
+	Overrides    map[string]any    // resolved field values for the top issue
+	Subtasks     bool
+	Links        bool
+	Attachments  bool
+	Labels       bool
+	Components   bool
+	Comments     bool
+	LinkOriginal bool
+}
+
+// cloneResult reports one copied issue and what could not be carried over.
+type cloneResult struct {
+	Source      string         `json:"source"`
+	Key         string         `json:"key"`
+	IssueType   string         `json:"issueType"`
+	Status      string         `json:"status,omitempty"`
+	StatusPath  []string       `json:"statusPath,omitempty"`
+	Links       int            `json:"links,omitempty"`
+	Attachments int            `json:"attachments,omitempty"`
+	Comments    int            `json:"comments,omitempty"`
+	Subtasks    []*cloneResult `json:"subtasks,omitempty"`
+	Skipped     []string       `json:"skipped,omitempty"`
+	source      rawIssue
+	failed      []string // sub-tasks, links, attachments and comments that were not copied
+	screen      *createMetaIssueType
+	from        *createMetaIssueType
+	wantStatus  string
+}
+
+// clonePlan is a source issue with its sub-tasks, each matched to an issue
+// type of the target project before anything is created.
+type clonePlan struct {
+	client *jira.Client
+	opts   cloneOptions
+	target *createMeta
+	metas  map[string]*createMeta // source project createmeta by key
+	top    *cloneResult
+}
+
+// fail reports something of the source that was not copied.
+func (r *cloneResult) fail(msg string) {
+	r.Skipped = append(r.Skipped, msg)
+	r.failed = append(r.failed, msg)
+}
+
+// incomplete lists what was not copied of the issue and its sub-tasks.
+func (r *cloneResult) incomplete() []string {
+	failed := append([]string{}, r.failed...)
+	for _, sub := range r.Subtasks {
+		for _, msg := range sub.failed {
+			failed = append(failed, sub.Source+" "+msg)
+		}
+	}
+	return failed
+}
+
+// cloneSkippedFields are never copied: Jira sets them or they are copied
+// separately.
+var cloneSkippedFields = map[string]bool{
+	"project": true, "issuetype": true, "parent": true, "status": true, "resolution": true,
+	"attachment": true, "issuelinks": true, "subtasks": true, "comment": true, "worklog": true,
+}
+
+// newClonePlan fetches the source issue and, if requested, its sub-tasks, and
+// checks that every one of them has an issue type in the target project.
+func newClonePlan(ctx context.Context, client *jira.Client, issueKey string, opts cloneOptions) (*clonePlan, error) {
+	src, err := fetchIssue(ctx, client, issueKey, []string{"*all"})
+	if err != nil {
+		return nil, err
+	}
+	if opts.Project == "" {
+		opts.Project = src.project()
+	}
+	target, err := getCreateMeta(ctx, client, opts.Project)
+	if err != nil {
+		return nil, err
+	}
+	p := &clonePlan{client: client, opts: opts, target: target, metas: map[string]*createMeta{target.Key: target}}
+	if p.top, err = p.add(ctx, src, opts.IssueType, false); err != nil {
+		return nil, err
+	}
+	if !opts.Subtasks {
+		return p, nil
+	}
+	subs, err := searchRawIssues(ctx, client, fmt.Sprintf("parent = %s ORDER BY key ASC", src.Key), []string{"*all"}, config.Current().Bulk.MaxIssues)
+	if err != nil {
+		return nil, err
+	}
+	for _, sub := range subs {
+		r, err := p.add(ctx, sub, "", true)
+		if err != nil {
+			return nil, fmt.Errorf("sub-task %s: %w", sub.Key, err)
+		}
+		p.top.Subtasks = append(p.top.Subtasks, r)
+	}
+	return p, nil
+}
+
+// add matches an issue to its target issue type and to the create screen it
+// was created through, which decides the fields that are worth copying.
+func (p *clonePlan) add(ctx context.Context, src rawIssue, issueType string, subtask bool) (*cloneResult, error) {
+	node := newHierarchyNode(src)
+	want := issueType
+	if want == "" {
+		want = node.Type
+		for from, to := range p.opts.TypeMapping {
+			if strings.EqualFold(from, node.Type) {
+				want = to
+			}
+		}
+	}
+	screen, err := p.target.issueType(want)
+	if err != nil {
+		return nil, err
+	}
+	if screen.Subtask != subtask {
+		if subtask {
+			return nil, fmt.Errorf("%s is not a sub-task type in %s; map %s to a sub-task type", screen.Name, p.target.Key, node.Type)
+		}
+		return nil, fmt.Errorf("%s is a sub-task type; clone the parent issue instead", screen.Name)
+	}
+	meta, ok := p.metas[src.project()]
+	if !ok {
+		if meta, err = getCreateMeta(ctx, p.client, src.project()); err != nil {
+			return nil, err
+		}
+		p.metas[src.project()] = meta
+	}
+	r := &cloneResult{Source: src.Key, IssueType: screen.Name, source: src, screen: screen}
+	for i, t := range meta.IssueTypes {
+		if t.Name == node.Type {
+			r.from = &meta.IssueTypes[i]
+		}
+	}
+	if r.from == nil {
+		r.from = &createMetaIssueType{Name: node.Type}
+	}
+	return r, nil
+}
+
+// fields builds the create payload of r: the fields set on the source issue
+// that its create screen offers, converted to the target project's values.
+// Fields and values the target does not offer are reported as skipped.
+func (p *clonePlan) fields(r *cloneResult, parentKey string) map[string]any {
+	fields := map[string]any{
+		"project":   map[string]any{"key": p.target.Key},
+		"issuetype": map[string]any{"id": r.screen.ID},
+	}
+	if parentKey != "" {
+		fields["parent"] = map[string]any{"key": parentKey}
+	}
+	for id, value := range r.source.Fields {
+		if cloneSkippedFields[id] || isEmptyValue(value) {
+			continue
+		}
+		if (id == "labels" && !p.opts.Labels) || (id == "components" && !p.opts.Components) {
+			continue
+		}
+		from, onSource := r.from.Fields[id]
+		if !onSource && len(r.from.Fields) > 0 {
+			continue
+		}
+		f, ok := r.screen.Fields[id]
+		if !ok {
+			if onSource {
+				r.Skipped = append(r.Skipped, fmt.Sprintf("%s: not on the %s create screen in %s", from.Name, r.screen.Name, p.target.Key))
+			}
+			continue
+		}
+		converted, dropped := f.carryOver(value)
+		for _, label := range dropped {
+			r.Skipped = append(r.Skipped, fmt.Sprintf("%s: %s is not available in %s", f.Name, label, p.target.Key))
+		}
+		if !isEmptyValue(converted) {
+			fields[id] = converted
+		}
+	}
+	if parentKey == "" {
+		for k, v := range p.opts.Overrides {
+			fields[k] = v
+		}
+	}
+	sort.Strings(r.Skipped)
+	return fields
+}
+
+// carryOver converts a value read from an issue into one f accepts on
+// create. Values without an exact counterpart among the allowed values are
+// dropped and returned by label, rather than guessed.
+func (f createMetaField) carryOver(value any) (any, []string) {
+	convert := func(item any) (any, string) {
+		m, ok := item.(map[string]any)
+		if !ok {
+			return item, ""
+		}
+		// An empty list still restricts the values, e.g. a project without components
+		if f.AllowedValues != nil {
+			label := allowedLabel(m)
+			for _, a := range f.AllowedValues {
+				if strings.EqualFold(allowedLabel(a), label) {
+					matched, _ := f.matchAllowed(allowedLabel(a))
+					return matched, ""
+				}
+			}
+			return nil, label
+		}
+		if f.Schema.Type == "user" || f.Schema.Items == "user" {
+			if id, ok := m["accountId"].(string); ok && id != "" {
+				return map[string]any{"accountId": id}, ""
+			}
+			return map[string]any{"name": m["name"]}, ""
+		}
+		return item, ""
+	}
+	var dropped []string
+	list, ok := value.([]any)
+	if !ok {
+		v, label := convert(value)
+		if label != "" {
+			dropped = append(dropped, label)
+		}
+		return v, dropped
+	}
+	out := make([]any, 0, len(list))
+	for _, item := range list {
+		v, label := convert(item)
+		if label != "" {
+			dropped = append(dropped, label)
+			continue
+		}
+		out = append(out, v)
+	}
+	return out, dropped
+}
+
+// run creates the copies, top issue first, then its sub-tasks under it.
+// Failures after the top issue exists are reported, not returned, so the
+// caller always learns the new key.
+func (p *clonePlan) run(ctx context.Context) error {
+	if err := p.create(ctx, p.top, ""); err != nil {
+		return err
+	}
+	for _, sub := range p.top.Subtasks {
+		if err := p.create(ctx, sub, p.top.Key); err != nil {
+			p.top.fail(fmt.Sprintf("sub-task %s: %v", sub.Source, err))
+		}
+	}
+	return nil
+}
+
+// create copies one issue and whatever goes with it.
+func (p *clonePlan) create(ctx context.Context, r *cloneResult, parentKey string) error {
+	fields, err := r.screen.validate(p.target.Key, p.fields(r, parentKey))
+	if err != nil {
+		return err
+	}
+	// The fields are sent as the create screen takes them, which the SDK's
+	// typed issue payload cannot carry.
+	var created struct {
+		Key string `json:"key"`
+	}
+	if err := callJSON(ctx, p.client, "Failed to create issue", "POST", "rest/api/2/issue", map[string]any{"fields": fields}, &created); err != nil {
+		return err
+	}
+	r.Key = created.Key
+	if p.opts.Links {
+		p.copyLinks(ctx, r)
+	}
+	if p.opts.Attachments {
+		p.copyAttachments(ctx, r)
+	}
+	if p.opts.Comments {
+		p.copyComments(ctx, r)
+	}
+	if p.opts.LinkOriginal {
+		if err := createLink(ctx, p.client, "Cloners", r.Key, r.Source); err != nil {
+			r.Skipped = append(r.Skipped, "link to original: "+err.Error())
+		}
+	}
+	return nil
+}
+
+// createLink links two issues: inward <outward description> outward.
+func createLink(ctx context.Context, client *jira.Client, typeName, inwardKey, outwardKey string) error {
+	payload := &models.LinkPayloadSchemeV2{
+		Type:         &models.LinkTypeScheme{Name: typeName},
+		InwardIssue:  &models.LinkedIssueScheme{Key: inwardKey},
+		OutwardIssue: &models.LinkedIssueScheme{Key: outwardKey},
+	}
+	if resp, err := client.Issue.Link.Create(ctx, payload); err != nil {
+		return errors.New(handlers.ErrorMessage("Failed to link "+inwardKey+" to "+outwardKey, resp, err))
+	}
+	return nil
+}
+
+// copyLinks recreates the links of the source issue on the copy, keeping
+// their direction.
+func (p *clonePlan) copyLinks(ctx context.Context, r *cloneResult) {
+	links, _ := r.source.Fields["issuelinks"].([]any)
+	for _, item := range links {
+		l, _ := item.(map[string]any)
+		t, _ := l["type"].(map[string]any)
+		name, _ := t["name"].(string)
+		inward, outward := r.Key, ""
+		if other, ok := l["outwardIssue"].(map[string]any); ok {
+			outward, _ = other["key"].(string)
+		} else if other, ok := l["inwardIssue"].(map[string]any); ok {
+			inward, _ = other["key"].(string)
+			outward = r.Key
+		}
+		if err := createLink(ctx, p.client, name, inward, outward); err != nil {
+			r.fail(fmt.Sprintf("link %s %s: %v", name, strings.Trim(inward+" "+outward, " "), err))
+			continue
+		}
+		r.Links++
+	}
+}
+
+// copyAttachments downloads each attachment of the source issue and uploads
+// it to the copy.
+func (p *clonePlan) copyAttachments(ctx context.Context, r *cloneResult) {
+	attachments, _ := r.source.Fields["attachment"].([]any)
+	for _, item := range attachments {
+		a, _ := item.(map[string]any)
+		filename, _ := a["filename"].(string)
+		content, _ := a["content"].(string)
+		req, err := p.client.NewRequest(ctx, "GET", content, "", nil)
+		if err != nil {
+			r.fail(fmt.Sprintf("attachment %s: %v", filename, err))
+			continue
+		}
+		resp, err := p.client.Call(req, nil)
+		if err != nil {
+			r.fail(fmt.Sprintf("attachment %s: %s", filename, handlers.ErrorMessage("Failed to download", resp, err)))
+			continue
+		}
+		if _, resp, err := p.client.Issue.Attachment.Add(ctx, r.Key, filename, bytes.NewReader(resp.Bytes.Bytes())); err != nil {
+			r.fail(fmt.Sprintf("attachment %s: %s", filename, handlers.ErrorMessage("Failed to upload", resp, err)))
+			continue
+		}
+		r.Attachments++
+	}
+}
+
+// copyComments adds the comments of the source issue to the copy. Jira sets
+// the author and date of a new comment, so each copy starts with the
+// original ones.
+func (p *clonePlan) copyComments(ctx context.Context, r *cloneResult) {
+	comments, resp, err := getAllComments(ctx, p.client, r.Source, nil)
+	if err != nil {
+		r.fail(handlers.ErrorMessage("comments: Failed to get comments", resp, err))
+		return
+	}
+	for _, c := range comments {
+		author := "unknown"
+		if c.Author != nil {
+			author = c.Author.DisplayName
+		}
+		payload := &models.CommentPayloadSchemeV2{Body: fmt.Sprintf("_Originally posted by %s on %s:_\n\n%s", author, c.Created, c.Body)}
+		if c.Visibility != nil && c.Visibility.Type != "" {
+			payload.Visibility = c.Visibility
+		}
+		if _, resp, err := p.client.Issue.Comment.Add(ctx, r.Key, payload, nil); err != nil {
+			r.fail(fmt.Sprintf("comment %s: %s", c.ID, handlers.ErrorMessage("Failed to add comment", resp, err)))
+			continue
+		}
+		r.Comments++
+	}
+	if len(comments) > 0 {
+		r.Skipped = append(r.Skipped, "comments: authors and dates are quoted in the comment text")
+	}
+}
+
+// statusTargets maps the status of every issue of the plan to one of the
+// target workflow, so a move fails before anything is created rather than
+// leaving copies halfway through a workflow.
+func (p *clonePlan) statusTargets(ctx context.Context, mapping map[string]string) error {
+	types, resp, err := p.client.Project.Statuses(ctx, p.target.Key)
+	if err != nil {
+		return errors.New(handlers.ErrorMessage("Failed to get project statuses", resp, err))
+	}
+	var problems []string
+	for _, r := range append([]*cloneResult{p.top}, p.top.Subtasks...) {
+		want := newHierarchyNode(r.source).Status
+		for from, to := range mapping {
+			if strings.EqualFold(from, want) {
+				want = to
+			}
+		}
+		var valid []string
+		for _, t := range types {
+			if t.Name != r.IssueType {
+				continue
+			}
+			for _, st := range t.Statuses {
+				valid = append(valid, st.Name)
+				if strings.EqualFold(st.Name, want) {
+					r.wantStatus = st.Name
+				}
+			}
+		}
+		if r.wantStatus == "" {
+			problems = append(problems, fmt.Sprintf("%s: status %s does not exist for %s in %s; valid statuses: %s", r.Source, want, r.IssueType, p.target.Key, strings.Join(valid, ", ")))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("set status_mapping for:\n- %s", strings.Join(problems, "\n- "))
+	}
+	return nil
+}
+
+// moveStatus walks a copy to its target status, reporting instead of failing
+// when the workflow has no path there that needs no further input.
+func moveStatus(ctx context.Context, client *jira.Client, r *cloneResult) {
+	tr := transitionRequest{Status: r.wantStatus, MaxSteps: 10}
+	if res, ok := r.source.Fields["resolution"].(map[string]any); ok {
+		tr.Fields = map[string]any{"resolution": map[string]any{"name": res["name"]}}
+	}
+	path, err := walkTransitions(ctx, client, r.Key, tr)
+	if len(path) > 0 {
+		r.Status = path[len(path)-1]
+	}
+	if len(path) > 1 {
+		r.StatusPath = path
+	}
+	if err != nil {
+		r.Skipped = append(r.Skipped, fmt.Sprintf("status %s: %v", r.wantStatus, err))
+	}
+}
+
+// parseMapping reads a JSON object of names to names.
+func parseMapping(name, s string) (map[string]string, error) {
+	if s == "" {
+		return nil, nil
+	}
+	var m map[string]string
+	if err := json.Unmarshal([]byte(s), &m); err != nil {
+		return nil, fmt.Errorf("invalid %s: expected a JSON object of names, e.g. {\"Bug\": \"Defect\"}: %v", name, err)
+	}
+	return m, nil
+}
+
+// cloneRequest reads the parameters shared by jira_clone_issue and
+// jira_move_issue.
+func cloneRequest(ctx context.Context, client *jira.Client, req mcp.CallToolRequest) (cloneOptions, error) {
+	opts := cloneOptions{
+		Project:   req.GetString("project_key", ""),
+		IssueType: req.GetString("issue_type", ""),
+	}
+	var err error
+	if opts.TypeMapping, err = parseMapping("type_mapping", req.GetString("type_mapping", "")); err != nil {
+		return opts, err
+	}
+	if s := req.GetString("fields", ""); s != "" {
+		var fields map[string]any
+		if err := json.Unmarshal([]byte(s), &fields); err != nil {
+			return opts, fmt.Errorf("invalid fields JSON: %v", err)
+		}
+		catalog, resp, err := getFieldCatalog(ctx, client, false)
+		if err != nil {
+			return opts, errors.New(handlers.ErrorMessage("Failed to load Jira fields", resp, err))
+		}
+		if opts.Overrides, err = catalog.resolvePayload(ctx, client, fields); err != nil {
+			return opts, fmt.Errorf("invalid fields: %v", err)
+		}
+	}
+	return opts, nil
+}
+
+// Handler for jira_clone_issue
+func CloneIssueHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	issueKey := req.GetString("issue_key", "")
+	if issueKey == "" {
+		return mcp.NewToolResultError("Missing required parameter: issue_key"), nil
+	}
+	client, err := clients.GetJiraClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
+	}
+	opts, err := cloneRequest(ctx, client, req)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	opts.Subtasks = req.GetBool("include_subtasks", false)
+	opts.Links = req.GetBool("include_links", false)
+	opts.Attachments = req.GetBool("include_attachments", false)
+	opts.Labels = req.GetBool("include_labels", true)
+	opts.Components = req.GetBool("include_components", true)
+	opts.LinkOriginal = req.GetBool("link_to_original", true)
+	plan, err := newClonePlan(ctx, client, issueKey, opts)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if err := plan.run(ctx); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	out, _ := json.Marshal(plan.top)
+	return mcp.NewToolResultText(string(out)), nil
+}
+
+// Handler for jira_move_issue. Jira has no REST resource for moving issues
+// between projects, so the issue is recreated in the target project with its
+// sub-tasks, links, attachments and comments, and walked to the matching
+// status. The original is linked to the copy, and deleted on request once
+// everything was copied.
+func MoveIssueHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	issueKey := req.GetString("issue_key", "")
+	projectKey := req.GetString("project_key", "")
+	if issueKey == "" || projectKey == "" {
+		return mcp.NewToolResultError("Missing required parameters: issue_key and project_key are required"), nil
+	}
+	statusMapping, err := parseMapping("status_mapping", req.GetString("status_mapping", ""))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	client, err := clients.GetJiraClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
+	}
+	opts, err := cloneRequest(ctx, client, req)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	opts.Subtasks, opts.Links, opts.Attachments, opts.Labels, opts.Components, opts.Comments, opts.LinkOriginal = true, true, true, true, true, true, true
+	plan, err := newClonePlan(ctx, client, issueKey, opts)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if plan.top.source.project() == plan.target.Key {
+		return mcp.NewToolResultError(fmt.Sprintf("%s is already in project %s", issueKey, plan.target.Key)), nil
+	}
+	if err := plan.statusTargets(ctx, statusMapping); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if err := plan.run(ctx); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	for _, r := range append([]*cloneResult{plan.top}, plan.top.Subtasks...) {
+		if r.Key != "" {
+			moveStatus(ctx, client, r)
+		}
+	}
+	result := map[string]any{"moved": plan.top, "originalDeleted": false}
+	incomplete := plan.top.incomplete()
+	deleteOriginal := req.GetBool("delete_original", false)
+	if deleteOriginal && len(incomplete) > 0 {
+		deleteOriginal = false
+		result["originalKept"] = fmt.Sprintf("%s was not deleted because not everything was copied:\n- %s", issueKey, strings.Join(incomplete, "\n- "))
+	}
+	if deleteOriginal {
+		if resp, err := client.Issue.Delete(ctx, issueKey, true); err != nil {
+			plan.top.Skipped = append(plan.top.Skipped, handlers.ErrorMessage("Failed to delete original", resp, err))
+		} else {
+			result["originalDeleted"] = true
+		}
+	} else {
+		note := &models.CommentPayloadSchemeV2{Body: fmt.Sprintf("Moved to %s.", plan.top.Key)}
+		if _, resp, err := client.Issue.Comment.Add(ctx, issueKey, note, nil); err != nil {
+			plan.top.Skipped = append(plan.top.Skipped, handlers.ErrorMessage("Failed to comment on original", resp, err))
+		}
+	}
+	plan.top.Skipped = append(plan.top.Skipped, "history, worklogs, watchers and votes stay with "+issueKey)
+	out, _ := json.Marshal(result)
+	return mcp.NewToolResultText(string(out)), nil
+}
@@ -0,0 +1,269 @@
+package jira
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	md "github.com/JohannesKaufmann/html-to-markdown"
+	jira "github.com/ctreminiom/go-atlassian/v2/jira/v2"
+	"github.com/ctreminiom/go-atlassian/v2/pkg/infra/models"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"mcp-atlassian-server/pkg/clients"
+	"mcp-atlassian-server/pkg/handlers"
+)
+
+// jiraTimeLayout is the timestamp format of Jira Server REST responses.
+const jiraTimeLayout = "2006-01-02T15:04:05.000-0700"
+
+// commentPage is the number of comments fetched per request when listing.
+const commentPage = 100
+
+// commentVisibility reads visibility_type and visibility_value. It returns
+// nil when neither is set; "none" as the type makes the comment public.
+func commentVisibility(req mcp.CallToolRequest) (*models.CommentVisibilityScheme, error) {
+	typ := strings.ToLower(strings.TrimSpace(req.GetString("visibility_type", "")))
+	value := strings.TrimSpace(req.GetString("visibility_value", ""))
+	switch typ {
+	case "":
+		if value != "" {
+			return nil, fmt.Errorf("visibility_value requires visibility_type role or group")
+		}
+		return nil, nil
+	case "none":
+		return &models.CommentVisibilityScheme{}, nil
+	case "role", "group":
+		if value == "" {
+			return nil, fmt.Errorf("visibility_value is required for visibility_type %s", typ)
+		}
+		return &models.CommentVisibilityScheme{Type: typ, Value: value}, nil
+	}
+	return nil, fmt.Errorf("visibility_type %q must be role, group or none", typ)
+}
+
+// renderComments replaces each body with Markdown converted from the
+// rendered HTML, or drops the rendered HTML when Markdown is not wanted.
+func renderComments(comments []*models.IssueCommentSchemeV2, markdown bool) error {
+	converter := md.NewConverter("", true, nil)
+	for _, c := range comments {
+		if markdown && c.RenderedBody != "" {
+			body, err := converter.ConvertString(c.RenderedBody)
+			if err != nil {
+				return fmt.Errorf("failed to convert comment %s to Markdown: %w", c.ID, err)
+			}
+			c.Body = body
+		}
+		c.RenderedBody = ""
+	}
+	return nil
+}
+
+// getAllComments fetches every comment on an issue, oldest first.
+func getAllComments(ctx context.Context, client *jira.Client, issueKey string, expand []string) ([]*models.IssueCommentSchemeV2, *models.ResponseScheme, error) {
+	var all []*models.IssueCommentSchemeV2
+	for {
+		page, resp, err := client.Issue.Comment.Gets(ctx, issueKey, "", expand, len(all), commentPage)
+		if err != nil {
+			return nil, resp, err
+		}
+		all = append(all, page.Comments...)
+		if len(page.Comments) == 0 || len(all) >= page.Total {
+			return all, resp, nil
+		}
+	}
+}
+
+// matchesUser reports whether any of a user's identifiers (name, key,
+// display name, email or account ID) equals want, ignoring case.
+func matchesUser(want string, ids ...string) bool {
+	for _, v := range ids {
+		if v != "" && strings.EqualFold(v, want) {
+			return true
+		}
+	}
+	return false
+}
+
+// dateBound parses a since or until argument. A plain date used as an upper
+// bound covers the whole day.
+func dateBound(s string, end bool) (time.Time, error) {
+	t, err := parseDate(s)
+	if err == nil && end && len(strings.TrimSpace(s)) == len("2006-01-02") {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, err
+}
+
+// Handler for jira_get_comments
+func GetCommentsHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	issueKey := req.GetString("issue_key", "")
+	limit := handlers.Limit(req, 10)
+	startAt := max(req.GetInt("start_at", 0), 0)
+	order := strings.ToLower(req.GetString("order", "newest"))
+	author := strings.TrimSpace(req.GetString("author", ""))
+	since := req.GetString("since", "")
+	until := req.GetString("until", "")
+	markdown := req.GetBool("as_markdown", false)
+	if issueKey == "" {
+		return mcp.NewToolResultError("Missing required parameter: issue_key"), nil
+	}
+	if order != "newest" && order != "oldest" {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid order %q: must be newest or oldest", order)), nil
+	}
+	var from, to time.Time
+	var err error
+	if since != "" {
+		if from, err = dateBound(since, false); err != nil {
+			return mcp.NewToolResultError("Invalid since: " + err.Error()), nil
+		}
+	}
+	if until != "" {
+		if to, err = dateBound(until, true); err != nil {
+			return mcp.NewToolResultError("Invalid until: " + err.Error()), nil
+		}
+	}
+	client, err := clients.GetJiraClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
+	}
+	var expand []string
+	if markdown {
+		expand = []string{"renderedBody"}
+	}
+	// Filters cannot be expressed in the comment API, so the whole list is
+	// fetched and paged here.
+	all, resp, err := getAllComments(ctx, client, issueKey, expand)
+	if err != nil {
+		return handlers.ToolError("Failed to get comments", resp, err), nil
+	}
+	var matched []*models.IssueCommentSchemeV2
+	created := map[*models.IssueCommentSchemeV2]time.Time{}
+	for _, c := range all {
+		if author != "" && (c.Author == nil || !matchesUser(author, c.Author.Name, c.Author.Key, c.Author.DisplayName, c.Author.EmailAddress, c.Author.AccountID)) {
+			continue
+		}
+		t, err := time.Parse(jiraTimeLayout, c.Created)
+		if (!from.IsZero() || !to.IsZero()) && (err != nil || (!from.IsZero() && t.Before(from)) || (!to.IsZero() && !t.Before(to))) {
+			continue
+		}
+		created[c] = t
+		matched = append(matched, c)
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		if order == "newest" {
+			return created[matched[i]].After(created[matched[j]])
+		}
+		return created[matched[i]].Before(created[matched[j]])
+	})
+	page := []*models.IssueCommentSchemeV2{}
+	if startAt < len(matched) {
+		page = matched[startAt:min(startAt+limit, len(matched))]
+	}
+	if err := renderComments(page, markdown); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	out, _ := json.Marshal(map[string]any{
+		"startAt":    startAt,
+		"maxResults": limit,
+		"total":      len(matched),
+		"isLast":     startAt+len(page) >= len(matched),
+		"comments":   page,
+	})
+	return mcp.NewToolResultText(string(out)), nil
+}
+
+// Handler for jira_get_comment
+func GetCommentHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	issueKey := req.GetString("issue_key", "")
+	commentID := req.GetString("comment_id", "")
+	markdown := req.GetBool("as_markdown", false)
+	if issueKey == "" || commentID == "" {
+		return mcp.NewToolResultError("Missing required parameters: issue_key and comment_id are required"), nil
+	}
+	client, err := clients.GetJiraClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
+	}
+	endpoint := fmt.Sprintf("rest/api/2/issue/%s/comment/%s", issueKey, url.PathEscape(commentID))
+	if markdown {
+		endpoint += "?expand=renderedBody"
+	}
+	reqHttp, err := client.NewRequest(ctx, "GET", endpoint, "", nil)
+	if err != nil {
+		return mcp.NewToolResultError("Failed to create HTTP request: " + err.Error()), nil
+	}
+	comment := &models.IssueCommentSchemeV2{}
+	resp, err := client.Call(reqHttp, comment)
+	if err != nil {
+		return handlers.ToolError("Failed to get comment", resp, err), nil
+	}
+	if err := renderComments([]*models.IssueCommentSchemeV2{comment}, markdown); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	out, _ := json.Marshal(comment)
+	return mcp.NewToolResultText(string(out)), nil
+}
+
+// Handler for jira_edit_comment
+func EditCommentHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	issueKey := req.GetString("issue_key", "")
+	commentID := req.GetString("comment_id", "")
+	body := req.GetString("comment", "")
+	if issueKey == "" || commentID == "" {
+		return mcp.NewToolResultError("Missing required parameters: issue_key and comment_id are required"), nil
+	}
+	visibility, err := commentVisibility(req)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if body == "" && visibility == nil {
+		return mcp.NewToolResultError("Nothing to change: set comment or visibility_type"), nil
+	}
+	client, err := clients.GetJiraClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
+	}
+	// Jira replaces the body and visibility together, so keep whichever one
+	// the caller did not set.
+	current, resp, err := client.Issue.Comment.Get(ctx, issueKey, commentID)
+	if err != nil {
+		return handlers.ToolError("Failed to get comment", resp, err), nil
+	}
+	payload := &models.CommentPayloadSchemeV2{Body: body, Visibility: visibility}
+	if body == "" {
+		payload.Body = current.Body
+	}
+	if visibility == nil {
+		payload.Visibility = current.Visibility
+	} else if visibility.Type == "" {
+		payload.Visibility = nil
+	}
+	_, resp, err = client.Issue.Comment.Update(ctx, issueKey, commentID, payload, nil)
+	if err != nil {
+		return handlers.ToolError("Failed to update comment", resp, err), nil
+	}
+	return mcp.NewToolResultText(resp.Bytes.String()), nil
+}
+
+// Handler for jira_delete_comment
+func DeleteCommentHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	issueKey := req.GetString("issue_key", "")
+	commentID := req.GetString("comment_id", "")
+	if issueKey == "" || commentID == "" {
+		return mcp.NewToolResultError("Missing required parameters: issue_key and comment_id are required"), nil
+	}
+	client, err := clients.GetJiraClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
+	}
+	resp, err := client.Issue.Comment.Delete(ctx, issueKey, commentID)
+	if err != nil {
+		return handlers.ToolError("Failed to delete comment", resp, err), nil
+	}
+	return mcp.NewToolResultText("Comment deleted successfully"), nil
+}
@@ -0,0 +1,486 @@
+package jira
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	jira "github.com/ctreminiom/go-atlassian/v2/jira/v2"
+	"github.com/ctreminiom/go-atlassian/v2/pkg/infra/models"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"mcp-atlassian-server/pkg/clients"
+	"mcp-atlassian-server/pkg/handlers"
+	"mcp-atlassian-server/pkg/utils"
+)
+
+// createMetaField is one field of a create screen.
+type createMetaField struct {
+	Required        bool             `json:"required"`
+	Name            string           `json:"name"`
+	HasDefaultValue bool             `json:"hasDefaultValue"`
+	AllowedValues   []map[string]any `json:"allowedValues,omitempty"`
+	Schema          struct {
+		Type  string `json:"type"`
+		Items string `json:"items,omitempty"`
+	} `json:"schema"`
+}
+
+// createMetaIssueType is an issue type that can be created in a project,
+// with the fields of its create screen.
+type createMetaIssueType struct {
+	ID      string                     `json:"id"`
+	Name    string                     `json:"name"`
+	Subtask bool                       `json:"subtask"`
+	Fields  map[string]createMetaField `json:"fields"`
+}
+
+// createMeta is the createmeta of a single project.
+type createMeta struct {
+	Key        string                `json:"key"`
+	IssueTypes []createMetaIssueType `json:"issuetypes"`
+}
+
+// createMetaPage is the number of issue types or fields fetched per request
+// from the paged createmeta resources.
+const createMetaPage = 50
+
+// getCreateMeta fetches the create screens of every issue type of a project.
+// Jira 9 and Cloud removed the expanded createmeta resource; a 404 falls back
+// to the paged issue type and field resources that replaced it.
+func getCreateMeta(ctx context.Context, client *jira.Client, projectKey string) (*createMeta, error) {
+	opts := &models.IssueMetadataCreateOptions{ProjectKeys: []string{projectKey}, Expand: "projects.issuetypes.fields"}
+	_, resp, err := client.Issue.Metadata.Create(ctx, opts)
+	if resp != nil && resp.Code == http.StatusNotFound {
+		return getPagedCreateMeta(ctx, client, projectKey)
+	}
+	if err != nil {
+		return nil, errors.New(handlers.ErrorMessage("Failed to get create metadata", resp, err))
+	}
+	var result struct {
+		Projects []createMeta `json:"projects"`
+	}
+	if err := json.Unmarshal(resp.Bytes.Bytes(), &result); err != nil {
+		return nil, err
+	}
+	if len(result.Projects) == 0 {
+		return nil, fmt.Errorf("project %s does not exist or you cannot create issues in it", projectKey)
+	}
+	return &result.Projects[0], nil
+}
+
+// createMetaPageResult is a page of the paged createmeta resources. Data
+// Center lists the items under values, Cloud under issueTypes or fields.
+type createMetaPageResult struct {
+	Total      int               `json:"total"`
+	IsLast     bool              `json:"isLast"`
+	Values     []json.RawMessage `json:"values"`
+	IssueTypes []json.RawMessage `json:"issueTypes"`
+	Fields     []json.RawMessage `json:"fields"`
+}
+
+// fetchCreateMetaPages collects the items of every page that fetch returns
+// and decodes them into out, a pointer to a slice.
+func fetchCreateMetaPages(fetch func(startAt int) (*models.ResponseScheme, error), out any) error {
+	var all []json.RawMessage
+	for {
+		resp, err := fetch(len(all))
+		if err != nil {
+			return err
+		}
+		var page createMetaPageResult
+		if err := json.Unmarshal(resp.Bytes.Bytes(), &page); err != nil {
+			return err
+		}
+		items := slices.Concat(page.Values, page.IssueTypes, page.Fields)
+		all = append(all, items...)
+		if page.IsLast || len(items) == 0 || (page.Total > 0 && len(all) >= page.Total) {
+			break
+		}
+	}
+	data, err := json.Marshal(all)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+// getPagedCreateMeta builds the createmeta of a project from the paged
+// resources, one request for the issue types and one per issue type for its
+// fields.
+func getPagedCreateMeta(ctx context.Context, client *jira.Client, projectKey string) (*createMeta, error) {
+	var types []createMetaIssueType
+	err := fetchCreateMetaPages(func(startAt int) (*models.ResponseScheme, error) {
+		_, resp, err := client.Issue.Metadata.FetchIssueMappings(ctx, projectKey, startAt, createMetaPage)
+		if err != nil {
+			return nil, errors.New(handlers.ErrorMessage("Failed to get create metadata issue types", resp, err))
+		}
+		return resp, nil
+	}, &types)
+	if err != nil {
+		return nil, err
+	}
+	for i := range types {
+		t := &types[i]
+		var fields []struct {
+			createMetaField
+			FieldID string `json:"fieldId"`
+		}
+		err := fetchCreateMetaPages(func(startAt int) (*models.ResponseScheme, error) {
+			_, resp, err := client.Issue.Metadata.FetchFieldMappings(ctx, projectKey, t.ID, startAt, createMetaPage)
+			if err != nil {
+				return nil, errors.New(handlers.ErrorMessage("Failed to get create metadata fields of "+t.Name, resp, err))
+			}
+			return resp, nil
+		}, &fields)
+		if err != nil {
+			return nil, err
+		}
+		t.Fields = make(map[string]createMetaField, len(fields))
+		for _, f := range fields {
+			t.Fields[f.FieldID] = f.createMetaField
+		}
+	}
+	return &createMeta{Key: projectKey, IssueTypes: types}, nil
+}
+
+// issueType finds an issue type by ID, name or unique name prefix.
+func (m *createMeta) issueType(name string) (*createMetaIssueType, error) {
+	names := make([]string, len(m.IssueTypes))
+	for i, t := range m.IssueTypes {
+		if t.ID == name {
+			return &m.IssueTypes[i], nil
+		}
+		names[i] = t.Name
+	}
+	i, err := strictMatch(name, names)
+	if err != nil {
+		return nil, fmt.Errorf("invalid issue type for project %s: %w", m.Key, err)
+	}
+	return &m.IssueTypes[i], nil
+}
+
+// fuzzyMatch returns the index of the candidate that input refers to. An
+// exact match ignoring case wins, then a unique prefix, then a unique
+// substring, then the single closest spelling within a couple of typos.
+func fuzzyMatch(input string, candidates []string) (int, error) {
+	want := strings.ToLower(strings.TrimSpace(input))
+	tiers := []func(c string) bool{
+		func(c string) bool { return c == want },
+		func(c string) bool { return strings.HasPrefix(c, want) },
+		func(c string) bool { return strings.Contains(c, want) },
+	}
+	for _, match := range tiers {
+		var found []int
+		for i, c := range candidates {
+			if match(strings.ToLower(c)) {
+				found = append(found, i)
+			}
+		}
+		if len(found) == 1 {
+			return found[0], nil
+		}
+		if len(found) > 1 {
+			names := make([]string, len(found))
+			for j, i := range found {
+				names[j] = candidates[i]
+			}
+			return -1, fmt.Errorf("%q is ambiguous, it matches %s", input, strings.Join(names, ", "))
+		}
+	}
+	best, bestDist, tie := -1, 2+len(want)/4, false
+	for i, c := range candidates {
+		d := editDistance(want, strings.ToLower(c))
+		if d < bestDist {
+			best, bestDist, tie = i, d, false
+		} else if d == bestDist && best >= 0 {
+			tie = true
+		}
+	}
+	if best >= 0 && !tie {
+		return best, nil
+	}
+	return -1, fmt.Errorf("no value matches %q; valid values: %s", input, strings.Join(candidates, ", "))
+}
+
+// strictMatch returns the index of the candidate that input names exactly,
+// ignoring case, or else of the only candidate it is a prefix of. It is used
+// for values written to Jira, which are not guessed: an input that only
+// fuzzyMatch would accept is refused, naming the candidates it resembles.
+func strictMatch(input string, candidates []string) (int, error) {
+	want := strings.ToLower(strings.TrimSpace(input))
+	var prefixed []string
+	found := -1
+	for i, c := range candidates {
+		lc := strings.ToLower(c)
+		if lc == want {
+			return i, nil
+		}
+		if strings.HasPrefix(lc, want) {
+			prefixed = append(prefixed, c)
+			found = i
+		}
+	}
+	switch {
+	case len(prefixed) == 1:
+		return found, nil
+	case len(prefixed) > 1:
+		return -1, fmt.Errorf("%q is ambiguous, it matches %s", input, strings.Join(prefixed, ", "))
+	}
+	msg := fmt.Sprintf("no value matches %q", input)
+	var similar []string
+	for _, c := range candidates {
+		lc := strings.ToLower(c)
+		if strings.Contains(lc, want) || editDistance(want, lc) < 2+len(want)/4 {
+			similar = append(similar, strconv.Quote(c))
+		}
+	}
+	if len(similar) > 0 {
+		msg += "; did you mean " + strings.Join(similar, " or ")
+	}
+	return -1, fmt.Errorf("%s; valid values: %s", msg, strings.Join(candidates, ", "))
+}
+
+// editDistance is the optimal string alignment distance between a and b:
+// insertions, deletions, substitutions and swaps of adjacent letters.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(ra)][len(rb)]
+}
+
+// allowedLabel is how an allowed value is shown and matched: its name, or
+// its value for select list options.
+func allowedLabel(v map[string]any) string {
+	for _, k := range []string{"name", "value", "key"} {
+		if s, ok := v[k].(string); ok && s != "" {
+			return s
+		}
+	}
+	id, _ := v["id"].(string)
+	return id
+}
+
+// allowedLabels lists the labels of the allowed values of f.
+func (f createMetaField) allowedLabels() []string {
+	labels := make([]string, len(f.AllowedValues))
+	for i, v := range f.AllowedValues {
+		labels[i] = allowedLabel(v)
+	}
+	return labels
+}
+
+// matchAllowed maps one input item to an allowed value of f, returned in the
+// shape Jira expects for the field.
+func (f createMetaField) matchAllowed(item any) (any, error) {
+	var input string
+	switch v := item.(type) {
+	case string:
+		input = v
+	case float64:
+		input = fmt.Sprint(v)
+	case map[string]any:
+		if _, ok := v["child"]; ok {
+			// Cascading selects are passed through as given.
+			return v, nil
+		}
+		if id, ok := v["id"].(string); ok {
+			for _, a := range f.AllowedValues {
+				if a["id"] == id {
+					return v, nil
+				}
+			}
+			return nil, fmt.Errorf("no value has id %q; valid values: %s", id, strings.Join(f.allowedLabels(), ", "))
+		}
+		input = allowedLabel(v)
+	default:
+		return item, nil
+	}
+	i, err := strictMatch(input, f.allowedLabels())
+	if err != nil {
+		return nil, err
+	}
+	a := f.AllowedValues[i]
+	for _, k := range []string{"name", "value", "key"} {
+		if s, ok := a[k].(string); ok && s != "" {
+			return map[string]any{k: s}, nil
+		}
+	}
+	return map[string]any{"id": a["id"]}, nil
+}
+
+// isEmptyValue reports whether a field value would leave the field unset.
+func isEmptyValue(v any) bool {
+	switch x := v.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(x) == ""
+	case []any:
+		return len(x) == 0
+	case []map[string]any:
+		return len(x) == 0
+	}
+	return false
+}
+
+// validate checks fields against the create screen of t. Values of fields
+// with allowed values are matched and replaced by the canonical value; every
+// missing required field and invalid value is reported in a single error.
+func (t *createMetaIssueType) validate(projectKey string, fields map[string]any) (map[string]any, error) {
+	var problems []string
+	out := make(map[string]any, len(fields))
+	for id, value := range fields {
+		f, ok := t.Fields[id]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%s is not on the create screen", id))
+			continue
+		}
+		if len(f.AllowedValues) == 0 || id == "project" || id == "issuetype" || value == nil {
+			out[id] = value
+			continue
+		}
+		if f.Schema.Type != "array" {
+			matched, err := f.matchAllowed(value)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("%s (%s): %v", f.Name, id, err))
+				continue
+			}
+			out[id] = matched
+			continue
+		}
+		var items []any
+		switch v := value.(type) {
+		case string:
+			for _, s := range utils.SplitAndTrim(v) {
+				items = append(items, s)
+			}
+		case []any:
+			items = v
+		case []map[string]any:
+			for _, m := range v {
+				items = append(items, m)
+			}
+		default:
+			items = []any{v}
+		}
+		list := make([]any, 0, len(items))
+		for _, item := range items {
+			matched, err := f.matchAllowed(item)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("%s (%s): %v", f.Name, id, err))
+				continue
+			}
+			list = append(list, matched)
+		}
+		out[id] = list
+	}
+	for id, f := range t.Fields {
+		if !f.Required || f.HasDefaultValue || !isEmptyValue(fields[id]) {
+			continue
+		}
+		msg := fmt.Sprintf("missing required field %s (%s)", f.Name, id)
+		if len(f.AllowedValues) > 0 {
+			msg += "; valid values: " + strings.Join(f.allowedLabels(), ", ")
+		}
+		problems = append(problems, msg)
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return nil, fmt.Errorf("cannot create %s in %s:\n- %s", t.Name, projectKey, strings.Join(problems, "\n- "))
+	}
+	return out, nil
+}
+
+// createMetaFieldInfo is a field as listed by jira_get_create_meta.
+type createMetaFieldInfo struct {
+	ID              string   `json:"id"`
+	Name            string   `json:"name"`
+	Type            string   `json:"type"`
+	Required        bool     `json:"required"`
+	HasDefaultValue bool     `json:"hasDefaultValue,omitempty"`
+	AllowedValues   []string `json:"allowedValues,omitempty"`
+}
+
+// fieldInfo lists the fields of t, required fields first, then by name.
+func (t *createMetaIssueType) fieldInfo(requiredOnly bool) []createMetaFieldInfo {
+	infos := []createMetaFieldInfo{}
+	for id, f := range t.Fields {
+		if requiredOnly && !f.Required {
+			continue
+		}
+		typ := f.Schema.Type
+		if typ == "array" && f.Schema.Items != "" {
+			typ = "array of " + f.Schema.Items
+		}
+		info := createMetaFieldInfo{ID: id, Name: f.Name, Type: typ, Required: f.Required, HasDefaultValue: f.HasDefaultValue}
+		if id != "project" && id != "issuetype" {
+			info.AllowedValues = f.allowedLabels()
+		}
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		if infos[i].Required != infos[j].Required {
+			return infos[i].Required
+		}
+		return infos[i].Name < infos[j].Name
+	})
+	return infos
+}
+
+// Handler for jira_get_create_meta
+func GetCreateMetaHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	projectKey := req.GetString("project_key", "")
+	issueType := req.GetString("issue_type", "")
+	if projectKey == "" {
+		return mcp.NewToolResultError("Missing required parameter: project_key"), nil
+	}
+	client, err := clients.GetJiraClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
+	}
+	meta, err := getCreateMeta(ctx, client, projectKey)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if issueType != "" {
+		t, err := meta.issueType(issueType)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		out, _ := json.Marshal(map[string]any{"project": meta.Key, "id": t.ID, "name": t.Name, "subtask": t.Subtask, "fields": t.fieldInfo(false)})
+		return mcp.NewToolResultText(string(out)), nil
+	}
+	types := make([]map[string]any, 0, len(meta.IssueTypes))
+	for i := range meta.IssueTypes {
+		t := &meta.IssueTypes[i]
+		types = append(types, map[string]any{"id": t.ID, "name": t.Name, "subtask": t.Subtask, "requiredFields": t.fieldInfo(true)})
+	}
+	out, _ := json.Marshal(map[string]any{"project": meta.Key, "issueTypes": types})
+	return mcp.NewToolResultText(string(out)), nil
+}
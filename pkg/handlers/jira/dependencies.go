@@ -0,0 +1,495 @@
+package jira
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	jira "github.com/ctreminiom/go-atlassian/v2/jira/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"mcp-atlassian-server/pkg/clients"
+	"mcp-atlassian-server/pkg/config"
+	"mcp-atlassian-server/pkg/handlers"
+	"mcp-atlassian-server/pkg/utils"
+)
+
+// maxDependencyDepth bounds how far jira_get_dependency_graph follows links.
+const maxDependencyDepth = 10
+
+// linkType is an issue link type, or one of the pseudo types "epic" and
+// "parent" for hierarchy edges.
+type linkType struct {
+	Name    string `json:"name"`
+	Inward  string `json:"inward"`
+	Outward string `json:"outward"`
+}
+
+// directed reports whether the link reads differently from each end, which
+// rules out symmetric types such as "relates to".
+func (t linkType) directed() bool {
+	return !strings.EqualFold(t.Inward, t.Outward)
+}
+
+// blocking reports whether the link means the source must be done first.
+func (t linkType) blocking() bool {
+	return strings.Contains(strings.ToLower(t.Name+" "+t.Outward), "block")
+}
+
+// hierarchyLinkTypes are the pseudo link types for parent and epic edges,
+// which point from the parent to the child.
+var hierarchyLinkTypes = []linkType{
+	{Name: "epic", Inward: "in epic", Outward: "epic of"},
+	{Name: "parent", Inward: "child of", Outward: "parent of"},
+}
+
+// selectLinkTypes maps the link_types argument to link types. Each name may
+// be a type name, its inward or outward description, or a verb such as
+// "blocks" or "duplicated"; "all" selects every type.
+func selectLinkTypes(ctx context.Context, client *jira.Client, names []string) ([]linkType, error) {
+	types, resp, err := client.Issue.Link.Type.Gets(ctx)
+	if err != nil {
+		return nil, errors.New(handlers.ErrorMessage("Failed to get link types", resp, err))
+	}
+	var known []linkType
+	for _, t := range types.IssueLinkTypes {
+		known = append(known, linkType{Name: t.Name, Inward: t.Inward, Outward: t.Outward})
+	}
+	known = append(known, hierarchyLinkTypes...)
+	if slices.Contains(names, "all") {
+		return known, nil
+	}
+	var selected []linkType
+	for _, name := range names {
+		want := strings.ToLower(name)
+		found := false
+		for _, t := range known {
+			if want == strings.ToLower(t.Name) || want == strings.ToLower(t.Inward) || want == strings.ToLower(t.Outward) || want == linkVerb(t.Inward) || want == linkVerb(t.Outward) {
+				if !slices.Contains(selected, t) {
+					selected = append(selected, t)
+				}
+				found = true
+			}
+		}
+		if !found {
+			valid := make([]string, len(known))
+			for i, t := range known {
+				valid[i] = t.Name
+			}
+			return nil, fmt.Errorf("unknown link type %q; valid types: %s, all", name, strings.Join(valid, ", "))
+		}
+	}
+	return selected, nil
+}
+
+// linkVerb is the verb of a link description: "blocked" for "is blocked by",
+// "relates" for "relates to".
+func linkVerb(description string) string {
+	words := strings.Fields(strings.TrimPrefix(strings.ToLower(description), "is "))
+	if len(words) == 0 {
+		return ""
+	}
+	return words[0]
+}
+
+// depNode is an issue in a dependency graph.
+type depNode struct {
+	Key      string `json:"key"`
+	Summary  string `json:"summary"`
+	Type     string `json:"type"`
+	Status   string `json:"status"`
+	Resolved bool   `json:"resolved"`
+	Depth    int    `json:"depth"`
+}
+
+// depEdge is a link between two issues, read from From to To: "From blocks To".
+type depEdge struct {
+	From  string   `json:"from"`
+	To    string   `json:"to"`
+	Type  string   `json:"type"`
+	Label string   `json:"label"`
+	link  linkType `json:"-"`
+}
+
+// depGraph is the result of jira_get_dependency_graph.
+type depGraph struct {
+	Roots        []string   `json:"roots"`
+	Nodes        []*depNode `json:"nodes"`
+	Edges        []depEdge  `json:"edges"`
+	Cycles       [][]string `json:"cycles"`
+	CriticalPath []string   `json:"criticalPath"`
+	Truncated    bool       `json:"truncated,omitempty"`
+	nodes        map[string]*depNode
+}
+
+func newDepNode(issue rawIssue, depth int) *depNode {
+	h := newHierarchyNode(issue)
+	n := &depNode{Key: issue.Key, Summary: h.Summary, Type: h.Type, Status: h.Status, Depth: depth}
+	n.Resolved = h.category == "done" || issue.Fields["resolution"] != nil
+	return n
+}
+
+// issueEdges lists the edges of the selected types that touch an issue.
+func issueEdges(issue rawIssue, h hierarchyFields, types []linkType) []depEdge {
+	var edges []depEdge
+	find := func(name string) (linkType, bool) {
+		for _, t := range types {
+			if strings.EqualFold(t.Name, name) {
+				return t, true
+			}
+		}
+		return linkType{}, false
+	}
+	links, _ := issue.Fields["issuelinks"].([]any)
+	for _, item := range links {
+		l, _ := item.(map[string]any)
+		raw, _ := json.Marshal(l["type"])
+		var lt linkType
+		if json.Unmarshal(raw, &lt) != nil {
+			continue
+		}
+		t, ok := find(lt.Name)
+		if !ok {
+			continue
+		}
+		// An outward link reads "issue <outward> other"; an inward one
+		// "other <outward> issue".
+		if other, ok := l["outwardIssue"].(map[string]any); ok {
+			edges = append(edges, depEdge{From: issue.Key, To: fmt.Sprint(other["key"]), Type: t.Name, Label: t.Outward, link: t})
+		}
+		if other, ok := l["inwardIssue"].(map[string]any); ok {
+			edges = append(edges, depEdge{From: fmt.Sprint(other["key"]), To: issue.Key, Type: t.Name, Label: t.Outward, link: t})
+		}
+	}
+	for _, p := range h.parents(issue) {
+		name := "parent"
+		if p.Relation == "epic" {
+			name = "epic"
+		}
+		if t, ok := find(name); ok {
+			edges = append(edges, depEdge{From: p.Key, To: issue.Key, Type: t.Name, Label: t.Outward, link: t})
+		}
+	}
+	return edges
+}
+
+// buildDepGraph follows the links of the roots breadth first. Issues found
+// at the last level are included with their edges to issues already in the
+// graph, but their other links are not followed.
+func buildDepGraph(ctx context.Context, client *jira.Client, roots []string, types []linkType, direction string, depth, maxIssues int) (*depGraph, error) {
+	h, err := getHierarchyFields(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	followHierarchy := slices.ContainsFunc(types, func(t linkType) bool { return slices.Contains(hierarchyLinkTypes, t) })
+	fields := append(h.searchFields(), "issuelinks", "resolution")
+	g := &depGraph{Roots: roots, Edges: []depEdge{}, Cycles: [][]string{}, CriticalPath: []string{}, nodes: map[string]*depNode{}}
+	seen := map[[3]string]bool{}
+	frontier := roots
+	for level := 0; len(frontier) > 0; level++ {
+		if room := maxIssues - len(g.nodes); len(frontier) > room {
+			frontier = frontier[:room]
+			g.Truncated = true
+		}
+		if len(frontier) == 0 {
+			break
+		}
+		issues, err := searchRawIssues(ctx, client, fmt.Sprintf("key in (%s)", strings.Join(frontier, ", ")), fields, len(frontier))
+		if err != nil {
+			return nil, err
+		}
+		var edges []depEdge
+		for _, issue := range issues {
+			g.nodes[issue.Key] = newDepNode(issue, level)
+			g.Nodes = append(g.Nodes, g.nodes[issue.Key])
+			edges = append(edges, issueEdges(issue, h, types)...)
+		}
+		if followHierarchy && level < depth {
+			// Children are not listed on their parent, so search for them.
+			children, err := searchRawIssues(ctx, client, h.childJQL(frontier), fields, maxIssues)
+			if err != nil {
+				return nil, err
+			}
+			for _, c := range children {
+				for _, e := range issueEdges(c, h, types) {
+					if e.To == c.Key && slices.Contains(frontier, e.From) {
+						edges = append(edges, e)
+					}
+				}
+			}
+		}
+		var next []string
+		for _, e := range edges {
+			other, outward := e.To, slices.Contains(frontier, e.From)
+			if !outward {
+				other = e.From
+			}
+			if e.link.directed() && (direction == "outward" && !outward || direction == "inward" && outward) {
+				continue
+			}
+			if g.nodes[other] == nil {
+				if level >= depth {
+					continue
+				}
+				if !slices.Contains(next, other) {
+					next = append(next, other)
+				}
+			}
+			if id := [3]string{e.From, e.To, e.Type}; !seen[id] {
+				seen[id] = true
+				g.Edges = append(g.Edges, e)
+			}
+		}
+		frontier = next
+	}
+	// Drop edges to issues left out by max_issues.
+	kept := []depEdge{}
+	for _, e := range g.Edges {
+		if g.nodes[e.From] != nil && g.nodes[e.To] != nil {
+			kept = append(kept, e)
+		}
+	}
+	g.Edges = kept
+	sort.Slice(g.Edges, func(i, j int) bool {
+		a, b := g.Edges[i], g.Edges[j]
+		return a.From+" "+a.To+" "+a.Type < b.From+" "+b.To+" "+b.Type
+	})
+	g.findCycles()
+	g.findCriticalPath()
+	return g, nil
+}
+
+// findCycles records the cycles formed by directed edges, each listed once
+// starting from its smallest key.
+func (g *depGraph) findCycles() {
+	out := map[string][]string{}
+	for _, e := range g.Edges {
+		if e.link.directed() {
+			out[e.From] = append(out[e.From], e.To)
+		}
+	}
+	seen := map[string]bool{}
+	state := map[string]int{} // 1 on the stack, 2 done
+	var stack []string
+	var visit func(key string)
+	visit = func(key string) {
+		state[key] = 1
+		stack = append(stack, key)
+		for _, next := range out[key] {
+			switch state[next] {
+			case 0:
+				visit(next)
+			case 1:
+				cycle := slices.Clone(stack[slices.Index(stack, next):])
+				i := slices.Index(cycle, slices.Min(cycle))
+				cycle = append(cycle[i:], cycle[:i]...)
+				if id := strings.Join(cycle, " "); !seen[id] {
+					seen[id] = true
+					g.Cycles = append(g.Cycles, append(cycle, cycle[0]))
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[key] = 2
+	}
+	for _, n := range g.Nodes {
+		if state[n.Key] == 0 {
+			visit(n.Key)
+		}
+	}
+}
+
+// findCriticalPath records the longest chain of unresolved issues blocking
+// one another. With a single root, the chain must end at that root.
+func (g *depGraph) findCriticalPath() {
+	blockers := map[string][]string{}
+	for _, e := range g.Edges {
+		if e.link.blocking() && e.link.directed() && !g.nodes[e.From].Resolved && !g.nodes[e.To].Resolved {
+			blockers[e.To] = append(blockers[e.To], e.From)
+		}
+	}
+	memo := map[string][]string{}
+	onStack := map[string]bool{}
+	var chain func(key string) []string
+	chain = func(key string) []string {
+		if c, ok := memo[key]; ok {
+			return c
+		}
+		onStack[key] = true
+		var best []string
+		for _, b := range blockers[key] {
+			if onStack[b] {
+				continue // part of a cycle
+			}
+			if c := chain(b); len(c) > len(best) {
+				best = c
+			}
+		}
+		onStack[key] = false
+		c := append(slices.Clone(best), key)
+		memo[key] = c
+		return c
+	}
+	var best []string
+	if len(g.Roots) == 1 {
+		best = chain(g.Roots[0])
+	} else {
+		for _, n := range g.Nodes {
+			if c := chain(n.Key); len(c) > len(best) {
+				best = c
+			}
+		}
+	}
+	if len(best) > 1 {
+		g.CriticalPath = best
+	}
+}
+
+// mermaidID turns an issue key into a Mermaid node id.
+func mermaidID(key string) string {
+	return strings.NewReplacer("-", "_", " ", "_").Replace(key)
+}
+
+// onCriticalPath reports whether the edge is a step of the critical path.
+func (g *depGraph) onCriticalPath(e depEdge) bool {
+	for i := 0; i+1 < len(g.CriticalPath); i++ {
+		if g.CriticalPath[i] == e.From && g.CriticalPath[i+1] == e.To && e.link.blocking() {
+			return true
+		}
+	}
+	return false
+}
+
+// mermaid renders the graph as a Mermaid flowchart. Resolved issues are
+// greyed out and the critical path is drawn in red.
+func (g *depGraph) mermaid() string {
+	var b strings.Builder
+	b.WriteString("graph LR\n")
+	for _, n := range g.Nodes {
+		label := strings.ReplaceAll(fmt.Sprintf("%s: %s (%s)", n.Key, n.Summary, n.Status), `"`, "#quot;")
+		fmt.Fprintf(&b, "  %s[\"%s\"]\n", mermaidID(n.Key), label)
+	}
+	var critical []string
+	for i, e := range g.Edges {
+		arrow := "-->"
+		if !e.link.directed() {
+			arrow = "---"
+		}
+		fmt.Fprintf(&b, "  %s %s|%s| %s\n", mermaidID(e.From), arrow, e.Label, mermaidID(e.To))
+		if g.onCriticalPath(e) {
+			critical = append(critical, fmt.Sprint(i))
+		}
+	}
+	var resolved []string
+	for _, n := range g.Nodes {
+		if n.Resolved {
+			resolved = append(resolved, mermaidID(n.Key))
+		}
+	}
+	if len(resolved) > 0 {
+		b.WriteString("  classDef resolved fill:#eee,color:#888\n")
+		fmt.Fprintf(&b, "  class %s resolved\n", strings.Join(resolved, ","))
+	}
+	if len(critical) > 0 {
+		fmt.Fprintf(&b, "  linkStyle %s stroke:#d00,stroke-width:3px\n", strings.Join(critical, ","))
+	}
+	for _, c := range g.Cycles {
+		fmt.Fprintf(&b, "  %%%% cycle: %s\n", strings.Join(c, " -> "))
+	}
+	return b.String()
+}
+
+// dotQuote quotes s as a Graphviz string.
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// dot renders the graph in Graphviz DOT, with the same styling as mermaid.
+func (g *depGraph) dot() string {
+	var b strings.Builder
+	b.WriteString("digraph dependencies {\n  rankdir=LR;\n  node [shape=box];\n")
+	for _, n := range g.Nodes {
+		attrs := "label=" + dotQuote(fmt.Sprintf("%s\n%s\n(%s)", n.Key, n.Summary, n.Status))
+		if n.Resolved {
+			attrs += ", style=filled, fillcolor=\"#eeeeee\", fontcolor=\"#888888\""
+		}
+		fmt.Fprintf(&b, "  %s [%s];\n", dotQuote(n.Key), attrs)
+	}
+	for _, e := range g.Edges {
+		attrs := "label=" + dotQuote(e.Label)
+		if !e.link.directed() {
+			attrs += ", dir=none"
+		}
+		if g.onCriticalPath(e) {
+			attrs += ", color=red, penwidth=2"
+		}
+		fmt.Fprintf(&b, "  %s -> %s [%s];\n", dotQuote(e.From), dotQuote(e.To), attrs)
+	}
+	for _, c := range g.Cycles {
+		fmt.Fprintf(&b, "  // cycle: %s\n", strings.Join(c, " -> "))
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// Handler for jira_get_dependency_graph
+func GetDependencyGraphHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	issueKey := req.GetString("issue_key", "")
+	jql := req.GetString("jql", "")
+	direction := strings.ToLower(req.GetString("direction", "both"))
+	format := strings.ToLower(req.GetString("format", "json"))
+	depth := min(max(req.GetInt("depth", 3), 0), maxDependencyDepth)
+	maxIssues := min(max(req.GetInt("max_issues", 200), 1), config.Current().Bulk.MaxIssues)
+	names := utils.SplitAndTrim(strings.ToLower(req.GetString("link_types", "blocks")))
+	if (issueKey == "") == (jql == "") {
+		return mcp.NewToolResultError("Set exactly one of issue_key and jql"), nil
+	}
+	if direction != "both" && direction != "inward" && direction != "outward" {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid direction %q: must be both, inward or outward", direction)), nil
+	}
+	if format != "json" && format != "mermaid" && format != "dot" {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid format %q: must be json, mermaid or dot", format)), nil
+	}
+	if len(names) == 0 {
+		return mcp.NewToolResultError("link_types must name at least one link type"), nil
+	}
+	client, err := clients.GetJiraClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
+	}
+	types, err := selectLinkTypes(ctx, client, names)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	roots := []string{issueKey}
+	if jql != "" {
+		if roots, err = selectIssues(ctx, client, jql, maxIssues); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		if len(roots) == 0 {
+			return mcp.NewToolResultError("JQL matched no issues"), nil
+		}
+	}
+	g, err := buildDepGraph(ctx, client, roots, types, direction, depth, maxIssues)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if len(g.Nodes) == 0 && jql != "" {
+		return mcp.NewToolResultError(fmt.Sprintf("None of the %d issues matched by the JQL could be read", len(roots))), nil
+	}
+	if len(g.Nodes) == 0 {
+		return mcp.NewToolResultError(fmt.Sprintf("Issue %s does not exist", issueKey)), nil
+	}
+	switch format {
+	case "mermaid":
+		return mcp.NewToolResultText(g.mermaid()), nil
+	case "dot":
+		return mcp.NewToolResultText(g.dot()), nil
+	}
+	out, _ := json.Marshal(g)
+	return mcp.NewToolResultText(string(out)), nil
+}
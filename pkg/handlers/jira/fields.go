@@ -0,0 +1,288 @@
+package jira
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	jira "github.com/ctreminiom/go-atlassian/v2/jira/v2"
+	"github.com/ctreminiom/go-atlassian/v2/pkg/infra/models"
+
+	"mcp-atlassian-server/pkg/utils"
+)
+
+// fieldCacheTTL bounds how long a field catalog is reused before it is fetched again.
+const fieldCacheTTL = time.Hour
+
+// fieldCatalog indexes the fields of a Jira instance by ID and display name.
+type fieldCatalog struct {
+	fetched time.Time
+	fields  []*models.IssueFieldScheme
+	byID    map[string]*models.IssueFieldScheme
+	byName  map[string][]*models.IssueFieldScheme // Keyed by lower-case name
+}
+
+// fieldCache holds one catalog per Jira instance, keyed by base URL.
+var fieldCache = struct {
+	sync.Mutex
+	catalogs map[string]*fieldCatalog
+}{catalogs: map[string]*fieldCatalog{}}
+
+// getFieldCatalog returns the field catalog of the client's instance, fetching
+// it from /rest/api/2/field when it is missing, expired or refresh is set.
+func getFieldCatalog(ctx context.Context, client *jira.Client, refresh bool) (*fieldCatalog, *models.ResponseScheme, error) {
+	key := client.Site.String()
+	fieldCache.Lock()
+	cached := fieldCache.catalogs[key]
+	fieldCache.Unlock()
+	if cached != nil && !refresh && time.Since(cached.fetched) < fieldCacheTTL {
+		return cached, nil, nil
+	}
+
+	fields, resp, err := client.Issue.Field.Gets(ctx)
+	if err != nil {
+		return nil, resp, err
+	}
+	catalog := newFieldCatalog(fields)
+	fieldCache.Lock()
+	fieldCache.catalogs[key] = catalog
+	fieldCache.Unlock()
+	return catalog, resp, nil
+}
+
+func newFieldCatalog(fields []*models.IssueFieldScheme) *fieldCatalog {
+	c := &fieldCatalog{
+		fetched: time.Now(),
+		fields:  fields,
+		byID:    map[string]*models.IssueFieldScheme{},
+		byName:  map[string][]*models.IssueFieldScheme{},
+	}
+	for _, f := range fields {
+		c.byID[f.ID] = f
+		name := strings.ToLower(f.Name)
+		c.byName[name] = append(c.byName[name], f)
+	}
+	return c
+}
+
+// lookup finds a field by ID or case-insensitive display name. It returns
+// nil for unknown names so they can be passed through to Jira unchanged.
+func (c *fieldCatalog) lookup(name string) (*models.IssueFieldScheme, error) {
+	if f, ok := c.byID[name]; ok {
+		return f, nil
+	}
+	matches := c.byName[strings.ToLower(strings.TrimSpace(name))]
+	switch len(matches) {
+	case 0:
+		return nil, nil
+	case 1:
+		return matches[0], nil
+	}
+	ids := make([]string, len(matches))
+	for i, f := range matches {
+		ids[i] = f.ID
+	}
+	return nil, fmt.Errorf("field name %q is ambiguous, it matches %s; use the field ID instead", name, strings.Join(ids, ", "))
+}
+
+// resolveFieldList maps display names in a fields list to field IDs.
+func (c *fieldCatalog) resolveFieldList(names []string) ([]string, error) {
+	out := make([]string, 0, len(names))
+	for _, name := range names {
+		f, err := c.lookup(name)
+		if err != nil {
+			return nil, err
+		}
+		if f == nil {
+			out = append(out, name)
+		} else {
+			out = append(out, f.ID)
+		}
+	}
+	return out, nil
+}
+
+// resolvePayload maps the keys of a write payload to field IDs and coerces
+// each value to the shape its field schema expects. Users given by name,
+// email or account ID are looked up the way the assignee of a new issue is.
+func (c *fieldCatalog) resolvePayload(ctx context.Context, client *jira.Client, fields map[string]any) (map[string]any, error) {
+	var resolver *userResolver
+	userRef := func(ident string) (any, error) {
+		if resolver == nil {
+			r, err := newUserResolver(ctx, client)
+			if err != nil {
+				return nil, err
+			}
+			resolver = r
+		}
+		user, err := resolver.resolve(ctx, ident, "")
+		if err != nil {
+			return nil, err
+		}
+		return resolver.ref(user), nil
+	}
+	out := make(map[string]any, len(fields))
+	for name, value := range fields {
+		f, err := c.lookup(name)
+		if err != nil {
+			return nil, err
+		}
+		if f == nil {
+			out[name] = value
+			continue
+		}
+		coerced, err := coerceFieldValue(f, value, userRef)
+		if err != nil {
+			return nil, fmt.Errorf("field %q (%s): %w", f.Name, f.ID, err)
+		}
+		out[f.ID] = coerced
+	}
+	return out, nil
+}
+
+// displayNames renames custom field IDs in an issue's fields to their
+// display names, keeping the ID where the name is ambiguous or taken.
+func (c *fieldCatalog) displayNames(fields map[string]any) map[string]any {
+	out := make(map[string]any, len(fields))
+	for id, value := range fields {
+		f := c.byID[id]
+		if f == nil || !f.Custom || len(c.byName[strings.ToLower(f.Name)]) > 1 {
+			out[id] = value
+			continue
+		}
+		if _, taken := fields[f.Name]; taken {
+			out[id] = value
+			continue
+		}
+		out[f.Name] = value
+	}
+	return out
+}
+
+// search returns the fields whose name or ID contains keyword, ranking exact
+// and prefix name matches first.
+func (c *fieldCatalog) search(keyword string) []*models.IssueFieldScheme {
+	keyword = strings.ToLower(strings.TrimSpace(keyword))
+	rank := func(f *models.IssueFieldScheme) int {
+		name := strings.ToLower(f.Name)
+		switch {
+		case keyword == "":
+			return 0
+		case name == keyword || strings.ToLower(f.ID) == keyword:
+			return 1
+		case strings.HasPrefix(name, keyword):
+			return 2
+		case strings.Contains(name, keyword) || strings.Contains(strings.ToLower(f.ID), keyword):
+			return 3
+		}
+		return -1
+	}
+	var matched []*models.IssueFieldScheme
+	for _, f := range c.fields {
+		if rank(f) >= 0 {
+			matched = append(matched, f)
+		}
+	}
+	sort.SliceStable(matched, func(i, j int) bool { return rank(matched[i]) < rank(matched[j]) })
+	return matched
+}
+
+// coerceFieldValue converts convenient inputs, such as a plain string for a
+// select list or a number as text, into the JSON shape Jira expects for f.
+// userRef turns a user given as text into a reference.
+func coerceFieldValue(f *models.IssueFieldScheme, value any, userRef func(string) (any, error)) (any, error) {
+	if f.Schema == nil || value == nil {
+		return value, nil
+	}
+	if f.Schema.Type != "array" {
+		return coerceScalar(f.Schema.Type, value, userRef)
+	}
+	var items []any
+	switch v := value.(type) {
+	case string:
+		for _, s := range utils.SplitAndTrim(v) {
+			items = append(items, s)
+		}
+	case []any:
+		items = v
+	default:
+		items = []any{v}
+	}
+	out := make([]any, 0, len(items))
+	for _, item := range items {
+		coerced, err := coerceScalar(f.Schema.Items, item, userRef)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, coerced)
+	}
+	return out, nil
+}
+
+func coerceScalar(typ string, value any, userRef func(string) (any, error)) (any, error) {
+	s, isString := value.(string)
+	if !isString {
+		return value, nil
+	}
+	switch typ {
+	case "number":
+		n, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a number", s)
+		}
+		return n, nil
+	case "option":
+		return map[string]any{"value": s}, nil
+	case "user":
+		return userRef(s)
+	case "priority", "issuetype", "version", "component", "resolution", "securitylevel", "group":
+		return map[string]any{"name": s}, nil
+	case "project":
+		return map[string]any{"key": s}, nil
+	case "date":
+		t, err := parseDate(s)
+		if err != nil {
+			return nil, err
+		}
+		return t.Format("2006-01-02"), nil
+	case "datetime":
+		return utils.ParseJiraTime(s)
+	}
+	return value, nil
+}
+
+func parseDate(s string) (time.Time, error) {
+	for _, layout := range []string{"2006-01-02", time.RFC3339, "2006-01-02T15:04:05", "2006/01/02", "02 Jan 2006", "Jan 2, 2006"} {
+		if t, err := time.Parse(layout, strings.TrimSpace(s)); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%q is not a date, use YYYY-MM-DD", s)
+}
+
+// searchWithDisplayNames renames custom fields in each issue of a search
+// response. The body is returned unchanged if it cannot be parsed.
+func searchWithDisplayNames(catalog *fieldCatalog, body []byte) string {
+	var result map[string]any
+	if err := json.Unmarshal(body, &result); err != nil {
+		return string(body)
+	}
+	issues, _ := result["issues"].([]any)
+	for _, item := range issues {
+		if issue, ok := item.(map[string]any); ok {
+			if fields, ok := issue["fields"].(map[string]any); ok {
+				issue["fields"] = catalog.displayNames(fields)
+			}
+		}
+	}
+	out, err := json.Marshal(result)
+	if err != nil {
+		return string(body)
+	}
+	return string(out)
+}
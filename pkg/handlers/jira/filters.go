@@ -0,0 +1,536 @@
+package jira
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	jira "github.com/ctreminiom/go-atlassian/v2/jira/v2"
+	"github.com/ctreminiom/go-atlassian/v2/pkg/infra/models"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"mcp-atlassian-server/pkg/clients"
+	"mcp-atlassian-server/pkg/handlers"
+	"mcp-atlassian-server/pkg/utils"
+)
+
+// filterSummary is a saved filter as listed by the filter tools. SharedWith
+// uses the syntax of the share_permissions parameter.
+type filterSummary struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	JQL        string   `json:"jql,omitempty"`
+	Owner      string   `json:"owner,omitempty"`
+	Favourite  bool     `json:"favourite"`
+	SharedWith []string `json:"sharedWith,omitempty"`
+	ViewURL    string   `json:"viewUrl,omitempty"`
+}
+
+func newFilterSummary(f *models.FilterScheme) filterSummary {
+	s := filterSummary{ID: f.ID, Name: f.Name, JQL: f.JQL, Favourite: f.Favourite, ViewURL: f.ViewURL}
+	if f.Owner != nil {
+		s.Owner = f.Owner.DisplayName
+	}
+	for _, p := range f.SharePermissions {
+		s.SharedWith = append(s.SharedWith, describeShare(p))
+	}
+	return s
+}
+
+// describeShare writes a share permission the way parseShares reads it.
+func describeShare(p *models.SharePermissionScheme) string {
+	switch {
+	case p.Type == "group" && p.Group != nil:
+		return "group:" + p.Group.Name
+	case p.Type == "projectRole" && p.Project != nil && p.Role != nil:
+		return "project:" + p.Project.Key + ":" + p.Role.Name
+	case p.Type == "project" && p.Project != nil:
+		return "project:" + p.Project.Key
+	case p.Type == "user" && p.User != nil:
+		return "user:" + p.User.DisplayName
+	}
+	return p.Type
+}
+
+// parseShares reads share permissions such as "group:jira-users,
+// project:PROJ, project:PROJ:Developers, user:jdoe, authenticated, global".
+// "private" shares with nobody.
+func parseShares(ctx context.Context, client *jira.Client, spec string) ([]*models.SharePermissionScheme, error) {
+	perms := []*models.SharePermissionScheme{}
+	if strings.EqualFold(strings.TrimSpace(spec), "private") {
+		return perms, nil
+	}
+	for _, item := range utils.SplitAndTrim(spec) {
+		kind, arg, _ := strings.Cut(item, ":")
+		switch strings.ToLower(kind) {
+		case "global", "authenticated":
+			perms = append(perms, &models.SharePermissionScheme{Type: strings.ToLower(kind)})
+		case "group":
+			perms = append(perms, &models.SharePermissionScheme{Type: "group", Group: &models.GroupScheme{Name: arg}})
+		case "project":
+			key, role, hasRole := strings.Cut(arg, ":")
+			project, resp, err := client.Project.Get(ctx, key, nil)
+			if err != nil {
+				return nil, errors.New(handlers.ErrorMessage("Failed to get project "+key, resp, err))
+			}
+			p := &models.SharePermissionScheme{Type: "project", Project: &models.ProjectScheme{ID: project.ID}}
+			if hasRole {
+				id, err := projectRoleID(ctx, client, key, role)
+				if err != nil {
+					return nil, err
+				}
+				p.Type, p.Role = "projectRole", &models.ProjectRoleScheme{ID: id}
+			}
+			perms = append(perms, p)
+		case "user":
+			resolver, err := newUserResolver(ctx, client)
+			if err != nil {
+				return nil, err
+			}
+			u, err := resolver.resolve(ctx, arg, "")
+			if err != nil {
+				return nil, err
+			}
+			perms = append(perms, &models.SharePermissionScheme{Type: "user", User: &models.UserDetailScheme{AccountID: u.AccountID, Name: u.Name}})
+		default:
+			return nil, fmt.Errorf("invalid share %q: use group:NAME, project:KEY, project:KEY:ROLE, user:NAME, authenticated, global or private", item)
+		}
+	}
+	return perms, nil
+}
+
+// projectRoleID finds a role of a project by name.
+func projectRoleID(ctx context.Context, client *jira.Client, projectKey, name string) (int, error) {
+	roles, resp, err := client.Project.Role.Gets(ctx, projectKey)
+	if err != nil {
+		return 0, errors.New(handlers.ErrorMessage("Failed to get project roles", resp, err))
+	}
+	names := make([]string, 0, len(*roles))
+	for n := range *roles {
+		names = append(names, n)
+	}
+	i, err := fuzzyMatch(name, names)
+	if err != nil {
+		return 0, fmt.Errorf("invalid role for project %s: %w", projectKey, err)
+	}
+	return (*roles)[names[i]], nil
+}
+
+// findFilter gets a filter by ID, or by name among the user's own and
+// favourite filters and, on Cloud, every filter visible to them. Names are
+// matched loosely unless exact is set, when only a case-insensitive match of
+// the whole name will do; tools that change a filter use it so a typo cannot
+// pick another one.
+func findFilter(ctx context.Context, client *jira.Client, ref string, exact bool) (*models.FilterScheme, error) {
+	ref = strings.TrimSpace(ref)
+	if id, err := strconv.Atoi(ref); err == nil {
+		f, resp, err := client.Filter.Get(ctx, id, []string{"sharePermissions"})
+		if err != nil {
+			return nil, errors.New(handlers.ErrorMessage("Failed to get filter "+ref, resp, err))
+		}
+		return f, nil
+	}
+	candidates, resp, err := client.Filter.My(ctx, true, nil)
+	if err != nil {
+		return nil, errors.New(handlers.ErrorMessage("Failed to get filters", resp, err))
+	}
+	cloud, err := isCloud(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	if cloud {
+		page, resp, err := client.Filter.Search(ctx, &models.FilterSearchOptionScheme{Name: ref}, 0, 50)
+		if err != nil {
+			return nil, errors.New(handlers.ErrorMessage("Failed to search filters", resp, err))
+		}
+		for _, f := range page.Values {
+			if !containsFilter(candidates, f.ID) {
+				candidates = append(candidates, &models.FilterScheme{ID: f.ID, Name: f.Name})
+			}
+		}
+	}
+	names := make([]string, len(candidates))
+	for i, f := range candidates {
+		names[i] = f.Name
+	}
+	if exact {
+		var found []*models.FilterScheme
+		for _, f := range candidates {
+			if strings.EqualFold(f.Name, ref) {
+				found = append(found, f)
+			}
+		}
+		switch len(found) {
+		case 0:
+			return nil, fmt.Errorf("no filter is named %q; give the filter ID or its exact name. Filters: %s", ref, strings.Join(names, ", "))
+		case 1:
+			return findFilter(ctx, client, found[0].ID, exact)
+		}
+		ids := make([]string, len(found))
+		for i, f := range found {
+			ids[i] = fmt.Sprintf("%s (%s)", f.Name, f.ID)
+		}
+		return nil, fmt.Errorf("%q names several filters, give the filter ID: %s", ref, strings.Join(ids, ", "))
+	}
+	i, err := fuzzyMatch(ref, names)
+	if err != nil {
+		return nil, fmt.Errorf("filter %s", strings.TrimPrefix(err.Error(), "no value matches "))
+	}
+	return findFilter(ctx, client, candidates[i].ID, exact)
+}
+
+func containsFilter(filters []*models.FilterScheme, id string) bool {
+	for _, f := range filters {
+		if f.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Handler for jira_get_filters
+func GetFiltersHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	which := strings.ToLower(req.GetString("which", "all"))
+	client, err := clients.GetJiraClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
+	}
+	var filters []*models.FilterScheme
+	var resp *models.ResponseScheme
+	switch which {
+	case "all", "my":
+		filters, resp, err = client.Filter.My(ctx, which == "all", []string{"sharePermissions"})
+	case "favourite", "favorite":
+		filters, resp, err = client.Filter.Favorite(ctx)
+	default:
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid which %q: must be all, my or favourite", which)), nil
+	}
+	if err != nil {
+		return handlers.ToolError("Failed to get filters", resp, err), nil
+	}
+	summaries := make([]filterSummary, len(filters))
+	for i, f := range filters {
+		summaries[i] = newFilterSummary(f)
+	}
+	out, _ := json.Marshal(map[string]any{"total": len(summaries), "filters": summaries})
+	return mcp.NewToolResultText(string(out)), nil
+}
+
+// Handler for jira_get_filter
+func GetFilterHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ref := req.GetString("filter", "")
+	if ref == "" {
+		return mcp.NewToolResultError("Missing required parameter: filter"), nil
+	}
+	client, err := clients.GetJiraClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
+	}
+	f, err := findFilter(ctx, client, ref, false)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	out, _ := json.Marshal(newFilterSummary(f))
+	return mcp.NewToolResultText(string(out)), nil
+}
+
+// Handler for jira_run_filter
+func RunFilterHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ref := req.GetString("filter", "")
+	if ref == "" {
+		return mcp.NewToolResultError("Missing required parameter: filter"), nil
+	}
+	client, err := clients.GetJiraClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
+	}
+	f, err := findFilter(ctx, client, ref, false)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return searchJQL(ctx, client, req, f.JQL), nil
+}
+
+// Handler for jira_create_filter
+func CreateFilterHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	name := req.GetString("name", "")
+	jql := req.GetString("jql", "")
+	if name == "" || jql == "" {
+		return mcp.NewToolResultError("Missing required parameters: name and jql are required"), nil
+	}
+	client, err := clients.GetJiraClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
+	}
+	payload := &models.FilterPayloadScheme{
+		Name:        name,
+		JQL:         jql,
+		Description: req.GetString("description", ""),
+		Favorite:    req.GetBool("favourite", false),
+	}
+	if shares := req.GetString("share_permissions", ""); shares != "" {
+		if payload.SharePermissions, err = parseShares(ctx, client, shares); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	}
+	f, resp, err := client.Filter.Create(ctx, payload)
+	if err != nil {
+		return handlers.ToolError("Failed to create filter", resp, err), nil
+	}
+	out, _ := json.Marshal(newFilterSummary(f))
+	return mcp.NewToolResultText(string(out)), nil
+}
+
+// Handler for jira_update_filter. Jira replaces the whole filter, so name and
+// JQL default to their current values; share permissions are only replaced
+// when given. The SDK leaves out empty and false values, so making a filter
+// private removes its shares one by one and unfavouriting has its own call.
+func UpdateFilterHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ref := req.GetString("filter", "")
+	if ref == "" {
+		return mcp.NewToolResultError("Missing required parameter: filter"), nil
+	}
+	client, err := clients.GetJiraClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
+	}
+	f, err := findFilter(ctx, client, ref, true)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	id, err := strconv.Atoi(f.ID)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid filter ID %q", f.ID)), nil
+	}
+	payload := &models.FilterPayloadScheme{
+		Name:        f.Name,
+		JQL:         f.JQL,
+		Description: req.GetString("description", ""),
+		Favorite:    req.GetBool("favourite", false),
+	}
+	if name := req.GetString("name", ""); name != "" {
+		payload.Name = name
+	}
+	if jql := req.GetString("jql", ""); jql != "" {
+		payload.JQL = jql
+	}
+	private := false
+	if shares := req.GetString("share_permissions", ""); shares != "" {
+		if payload.SharePermissions, err = parseShares(ctx, client, shares); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		private = len(payload.SharePermissions) == 0
+	}
+	if _, resp, err := client.Filter.Update(ctx, id, payload); err != nil {
+		return handlers.ToolError("Failed to update filter", resp, err), nil
+	}
+	if private {
+		for _, p := range f.SharePermissions {
+			if resp, err := client.Filter.Share.Delete(ctx, id, p.ID); err != nil {
+				return handlers.ToolError("Failed to remove a share of the filter", resp, err), nil
+			}
+		}
+	}
+	if _, ok := req.GetArguments()["favourite"]; ok && !payload.Favorite && f.Favourite {
+		// The SDK has no call for this.
+		if err := callJSON(ctx, client, "Failed to remove the filter from favourites", "DELETE", "rest/api/2/filter/"+f.ID+"/favourite", nil, nil); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	}
+	updated, resp, err := client.Filter.Get(ctx, id, []string{"sharePermissions"})
+	if err != nil {
+		return handlers.ToolError("Failed to get filter "+f.ID, resp, err), nil
+	}
+	out, _ := json.Marshal(newFilterSummary(updated))
+	return mcp.NewToolResultText(string(out)), nil
+}
+
+// dashboardGadget is a gadget with the filter it reports on, if any.
+type dashboardGadget struct {
+	ID        int    `json:"id"`
+	Title     string `json:"title"`
+	ModuleKey string `json:"moduleKey,omitempty"`
+	URI       string `json:"uri,omitempty"`
+	Position  struct {
+		Row    int `json:"row"`
+		Column int `json:"column"`
+	} `json:"position"`
+	Filter      *filterSummary `json:"filter,omitempty"`
+	JQL         string         `json:"jql,omitempty"`
+	FilterError string         `json:"filterError,omitempty"`
+}
+
+// dashboardSummary is a dashboard as listed by the dashboard tools.
+type dashboardSummary struct {
+	ID        string            `json:"id"`
+	Name      string            `json:"name"`
+	Owner     string            `json:"owner,omitempty"`
+	Favourite bool              `json:"favourite"`
+	View      string            `json:"view,omitempty"`
+	Gadgets   []dashboardGadget `json:"gadgets,omitempty"`
+	Note      string            `json:"note,omitempty"`
+}
+
+func newDashboardSummary(d *models.DashboardScheme) *dashboardSummary {
+	s := &dashboardSummary{ID: d.ID, Name: d.Name, Favourite: d.IsFavourite, View: d.View}
+	if d.Owner != nil {
+		s.Owner = d.Owner.DisplayName
+	}
+	return s
+}
+
+// gadgetFilterRefs collects the filter IDs and JQL found in the configuration
+// of a gadget. Gadgets store them under keys such as filterId, with values
+// like "filter-10100" or "10100".
+func gadgetFilterRefs(v any, ids, jqls *[]string) {
+	switch x := v.(type) {
+	case map[string]any:
+		for k, item := range x {
+			s, isString := item.(string)
+			switch {
+			case strings.EqualFold(k, "filterId") && isString:
+				*ids = append(*ids, strings.TrimPrefix(s, "filter-"))
+			case strings.EqualFold(k, "filterId"):
+				if n, ok := item.(float64); ok {
+					*ids = append(*ids, strconv.Itoa(int(n)))
+				}
+			case strings.EqualFold(k, "jql") && isString && s != "":
+				*jqls = append(*jqls, s)
+			default:
+				gadgetFilterRefs(item, ids, jqls)
+			}
+		}
+	case []any:
+		for _, item := range x {
+			gadgetFilterRefs(item, ids, jqls)
+		}
+	}
+}
+
+// dashboardGadgets lists the gadgets of a dashboard with their filters. Only
+// Cloud lists gadgets over REST; elsewhere it returns a note instead.
+func dashboardGadgets(ctx context.Context, client *jira.Client, d *dashboardSummary, filters map[string]*models.FilterScheme) error {
+	base := "rest/api/2/dashboard/" + url.PathEscape(d.ID)
+	req, err := client.NewRequest(ctx, "GET", base+"/gadget", "", nil)
+	if err != nil {
+		return err
+	}
+	var result struct {
+		Gadgets []dashboardGadget `json:"gadgets"`
+	}
+	resp, err := client.Call(req, &result)
+	if resp != nil && resp.Code == http.StatusNotFound {
+		d.Note = "this Jira does not list dashboard gadgets over REST; only Jira Cloud does"
+		return nil
+	}
+	if err != nil {
+		return errors.New(handlers.ErrorMessage("Failed to get gadgets", resp, err))
+	}
+	for i := range result.Gadgets {
+		g := &result.Gadgets[i]
+		// The SDK does not read dashboard item properties.
+		items := fmt.Sprintf("%s/items/%d/properties", base, g.ID)
+		var keys struct {
+			Keys []struct {
+				Key string `json:"key"`
+			} `json:"keys"`
+		}
+		if err := callJSON(ctx, client, "Failed to get gadget properties", "GET", items, nil, &keys); err != nil {
+			g.FilterError = err.Error()
+			continue
+		}
+		var ids, jqls []string
+		for _, k := range keys.Keys {
+			var prop struct {
+				Value any `json:"value"`
+			}
+			if err := callJSON(ctx, client, "Failed to get gadget property", "GET", items+"/"+url.PathEscape(k.Key), nil, &prop); err != nil {
+				g.FilterError = err.Error()
+				continue
+			}
+			gadgetFilterRefs(prop.Value, &ids, &jqls)
+		}
+		if len(jqls) > 0 {
+			g.JQL = jqls[0]
+		}
+		if len(ids) == 0 {
+			continue
+		}
+		f, ok := filters[ids[0]]
+		if !ok {
+			if f, err = findFilter(ctx, client, ids[0], false); err != nil {
+				g.FilterError = err.Error()
+				continue
+			}
+			filters[ids[0]] = f
+		}
+		summary := newFilterSummary(f)
+		g.Filter = &summary
+	}
+	d.Gadgets = result.Gadgets
+	return nil
+}
+
+// Handler for jira_get_dashboards
+func GetDashboardsHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	which := strings.ToLower(req.GetString("which", "all"))
+	limit := handlers.Limit(req, 20)
+	startAt := req.GetInt("start_at", 0)
+	filter := ""
+	switch which {
+	case "all":
+	case "my":
+		filter = "my"
+	case "favourite", "favorite":
+		filter = "favourite"
+	default:
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid which %q: must be all, my or favourite", which)), nil
+	}
+	client, err := clients.GetJiraClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
+	}
+	page, resp, err := client.Dashboard.Gets(ctx, startAt, limit, filter)
+	if err != nil {
+		return handlers.ToolError("Failed to get dashboards", resp, err), nil
+	}
+	filters := map[string]*models.FilterScheme{}
+	dashboards := make([]*dashboardSummary, len(page.Dashboards))
+	for i, d := range page.Dashboards {
+		dashboards[i] = newDashboardSummary(d)
+		if req.GetBool("include_gadgets", false) {
+			if err := dashboardGadgets(ctx, client, dashboards[i], filters); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+		}
+	}
+	out, _ := json.Marshal(map[string]any{"startAt": page.StartAt, "total": page.Total, "dashboards": dashboards})
+	return mcp.NewToolResultText(string(out)), nil
+}
+
+// Handler for jira_get_dashboard
+func GetDashboardHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	id := req.GetString("dashboard_id", "")
+	if id == "" {
+		return mcp.NewToolResultError("Missing required parameter: dashboard_id"), nil
+	}
+	client, err := clients.GetJiraClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
+	}
+	d, resp, err := client.Dashboard.Get(ctx, id)
+	if err != nil {
+		return handlers.ToolError("Failed to get dashboard", resp, err), nil
+	}
+	summary := newDashboardSummary(d)
+	if err := dashboardGadgets(ctx, client, summary, map[string]*models.FilterScheme{}); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	out, _ := json.Marshal(summary)
+	return mcp.NewToolResultText(string(out)), nil
+}
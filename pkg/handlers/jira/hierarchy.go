@@ -0,0 +1,425 @@
+package jira
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	jira "github.com/ctreminiom/go-atlassian/v2/jira/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"mcp-atlassian-server/pkg/clients"
+	"mcp-atlassian-server/pkg/config"
+	"mcp-atlassian-server/pkg/handlers"
+	"mcp-atlassian-server/pkg/utils"
+)
+
+// maxHierarchyDepth bounds the walk up to the top of a hierarchy.
+const maxHierarchyDepth = 10
+
+// hierarchyFields are the custom fields that link an issue to its parent
+// besides the system parent field: Epic Link on Server and Data Center, and
+// the Advanced Roadmaps Parent Link above epics. Either may be empty.
+type hierarchyFields struct {
+	EpicLink   string
+	ParentLink string
+}
+
+func getHierarchyFields(ctx context.Context, client *jira.Client) (hierarchyFields, error) {
+	catalog, resp, err := getFieldCatalog(ctx, client, false)
+	if err != nil {
+		return hierarchyFields{}, errors.New(handlers.ErrorMessage("Failed to load Jira fields", resp, err))
+	}
+	var h hierarchyFields
+	if f, err := catalog.lookup("Epic Link"); err == nil && f != nil && f.Custom {
+		h.EpicLink = f.ID
+	}
+	if f, err := catalog.lookup("Parent Link"); err == nil && f != nil && f.Custom {
+		h.ParentLink = f.ID
+	}
+	return h, nil
+}
+
+// searchFields are the fields needed to place an issue in a hierarchy.
+func (h hierarchyFields) searchFields() []string {
+	fields := []string{"summary", "issuetype", "status", "assignee", "parent"}
+	for _, id := range []string{h.EpicLink, h.ParentLink} {
+		if id != "" {
+			fields = append(fields, id)
+		}
+	}
+	return fields
+}
+
+// childJQL finds the issues whose parent, Epic Link or Parent Link is one of keys.
+func (h hierarchyFields) childJQL(keys []string) string {
+	list := strings.Join(keys, ", ")
+	clauses := []string{fmt.Sprintf("parent in (%s)", list)}
+	for _, id := range []string{h.EpicLink, h.ParentLink} {
+		if id != "" {
+			clauses = append(clauses, fmt.Sprintf("cf[%s] in (%s)", strings.TrimPrefix(id, "customfield_"), list))
+		}
+	}
+	return strings.Join(clauses, " OR ") + " ORDER BY key ASC"
+}
+
+// parentLink is a link from an issue to its parent.
+type parentLink struct {
+	Key      string
+	Relation string // "subtask", "parent", "epic" or "parent link"
+}
+
+// parents lists the links from an issue to its parents, most specific first.
+// A sub-task of a story in an epic links to the story, not to the epic.
+func (h hierarchyFields) parents(issue rawIssue) []parentLink {
+	var links []parentLink
+	if p, ok := issue.Fields["parent"].(map[string]any); ok {
+		if key, _ := p["key"].(string); key != "" {
+			relation := "parent"
+			if t, ok := issue.Fields["issuetype"].(map[string]any); ok && t["subtask"] == true {
+				relation = "subtask"
+			}
+			links = append(links, parentLink{key, relation})
+		}
+	}
+	if h.EpicLink != "" {
+		if key, _ := issue.Fields[h.EpicLink].(string); key != "" {
+			links = append(links, parentLink{key, "epic"})
+		}
+	}
+	if h.ParentLink != "" {
+		// Parent Link is a key on Cloud and an object wrapping it on Server.
+		var key string
+		switch v := issue.Fields[h.ParentLink].(type) {
+		case string:
+			key = v
+		case map[string]any:
+			if data, ok := v["data"].(map[string]any); ok {
+				v = data
+			}
+			key, _ = v["key"].(string)
+		}
+		if key != "" {
+			links = append(links, parentLink{key, "parent link"})
+		}
+	}
+	return links
+}
+
+// statusRollup counts the descendants of an issue by status category.
+type statusRollup struct {
+	Total       int `json:"total"`
+	ToDo        int `json:"toDo"`
+	InProgress  int `json:"inProgress"`
+	Done        int `json:"done"`
+	PercentDone int `json:"percentDone"`
+}
+
+// hierarchyNode is an issue in a hierarchy tree.
+type hierarchyNode struct {
+	Key      string           `json:"key"`
+	Summary  string           `json:"summary"`
+	Type     string           `json:"type"`
+	Status   string           `json:"status"`
+	Assignee string           `json:"assignee,omitempty"`
+	Relation string           `json:"relation,omitempty"`
+	Rollup   *statusRollup    `json:"rollup,omitempty"`
+	Children []*hierarchyNode `json:"children,omitempty"`
+	category string
+}
+
+func newHierarchyNode(issue rawIssue) *hierarchyNode {
+	n := &hierarchyNode{Key: issue.Key}
+	n.Summary, _ = issue.Fields["summary"].(string)
+	if t, ok := issue.Fields["issuetype"].(map[string]any); ok {
+		n.Type, _ = t["name"].(string)
+	}
+	if st, ok := issue.Fields["status"].(map[string]any); ok {
+		n.Status, _ = st["name"].(string)
+		if cat, ok := st["statusCategory"].(map[string]any); ok {
+			n.category, _ = cat["key"].(string)
+		}
+	}
+	if a, ok := issue.Fields["assignee"].(map[string]any); ok {
+		n.Assignee, _ = a["displayName"].(string)
+	}
+	return n
+}
+
+// rollup fills in the status rollup of n and its descendants and returns the
+// counts for n's subtree, n included.
+func (n *hierarchyNode) rollup() statusRollup {
+	var r statusRollup
+	for _, c := range n.Children {
+		sub := c.rollup()
+		r.Total += sub.Total
+		r.ToDo += sub.ToDo
+		r.InProgress += sub.InProgress
+		r.Done += sub.Done
+	}
+	if len(n.Children) > 0 {
+		below := r
+		below.PercentDone = below.Done * 100 / below.Total
+		n.Rollup = &below
+	}
+	r.Total++
+	switch n.category {
+	case "done":
+		r.Done++
+	case "indeterminate":
+		r.InProgress++
+	default:
+		r.ToDo++
+	}
+	return r
+}
+
+// fetchIssue gets one issue with the fields needed to place it in a hierarchy.
+func fetchIssue(ctx context.Context, client *jira.Client, key string, fields []string) (rawIssue, error) {
+	var issue rawIssue
+	_, resp, err := client.Issue.Get(ctx, key, fields, nil)
+	if err != nil {
+		return issue, errors.New(handlers.ErrorMessage("Failed to get issue "+key, resp, err))
+	}
+	err = json.Unmarshal(resp.Bytes.Bytes(), &issue)
+	return issue, err
+}
+
+// childIssues lists the direct children of an issue, returning them with
+// the relation to their parent.
+func childIssues(ctx context.Context, client *jira.Client, h hierarchyFields, key string, max int) ([]*hierarchyNode, error) {
+	issues, err := searchRawIssues(ctx, client, h.childJQL([]string{key}), h.searchFields(), max)
+	if err != nil {
+		return nil, err
+	}
+	nodes := make([]*hierarchyNode, 0, len(issues))
+	for _, issue := range issues {
+		n := newHierarchyNode(issue)
+		for _, p := range h.parents(issue) {
+			if p.Key == key {
+				n.Relation = p.Relation
+				break
+			}
+		}
+		nodes = append(nodes, n)
+	}
+	return nodes, nil
+}
+
+// childList renders child issues with local paging.
+func childList(parent string, nodes []*hierarchyNode, startAt, limit int) *mcp.CallToolResult {
+	total := len(nodes)
+	page := nodes[min(startAt, total):min(startAt+limit, total)]
+	out, _ := json.Marshal(map[string]any{"parent": parent, "total": total, "startAt": startAt, "issues": page})
+	return mcp.NewToolResultText(string(out))
+}
+
+// Handler for jira_get_epic_issues
+func GetEpicIssuesHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	epicKey := req.GetString("epic_key", "")
+	limit := handlers.Limit(req, 50)
+	startAt := max(req.GetInt("start_at", 0), 0)
+	if epicKey == "" {
+		return mcp.NewToolResultError("Missing required parameter: epic_key"), nil
+	}
+	client, err := clients.GetJiraClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
+	}
+	h, err := getHierarchyFields(ctx, client)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	nodes, err := childIssues(ctx, client, h, epicKey, config.Current().Bulk.MaxIssues)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return childList(epicKey, nodes, startAt, limit), nil
+}
+
+// Handler for jira_get_subtasks
+func GetSubtasksHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	issueKey := req.GetString("issue_key", "")
+	if issueKey == "" {
+		return mcp.NewToolResultError("Missing required parameter: issue_key"), nil
+	}
+	client, err := clients.GetJiraClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
+	}
+	// Only the parent field links sub-tasks, so the custom links are not needed.
+	nodes, err := childIssues(ctx, client, hierarchyFields{}, issueKey, config.Current().Bulk.MaxIssues)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	subtasks := []*hierarchyNode{}
+	for _, n := range nodes {
+		if n.Relation == "subtask" {
+			subtasks = append(subtasks, n)
+		}
+	}
+	return childList(issueKey, subtasks, 0, len(subtasks)), nil
+}
+
+// Handler for jira_create_subtask
+func CreateSubtaskHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	parentKey := req.GetString("parent_key", "")
+	issueType := req.GetString("issue_type", "")
+	if parentKey == "" || req.GetString("summary", "") == "" {
+		return mcp.NewToolResultError("Missing required parameters: parent_key and summary are required"), nil
+	}
+	client, err := clients.GetJiraClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
+	}
+	parent, err := fetchIssue(ctx, client, parentKey, []string{"project", "issuetype"})
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if t, ok := parent.Fields["issuetype"].(map[string]any); ok && t["subtask"] == true {
+		return mcp.NewToolResultError(fmt.Sprintf("%s is a sub-task; sub-tasks cannot have sub-tasks", parentKey)), nil
+	}
+	project, _ := parent.Fields["project"].(map[string]any)
+	projectKey, _ := project["key"].(string)
+	meta, err := getCreateMeta(ctx, client, projectKey)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	var names []string
+	for _, t := range meta.IssueTypes {
+		if t.Subtask {
+			names = append(names, t.Name)
+		}
+	}
+	if len(names) == 0 {
+		return mcp.NewToolResultError(fmt.Sprintf("project %s has no sub-task issue type", projectKey)), nil
+	}
+	subtaskType := names[0]
+	if issueType != "" {
+		i, err := strictMatch(issueType, names)
+		if err != nil {
+			return mcp.NewToolResultError("invalid sub-task type: " + err.Error()), nil
+		}
+		subtaskType = names[i]
+	}
+	// The rest is an ordinary create with the parent's project and a sub-task type.
+	args := maps.Clone(req.GetArguments())
+	args["project_key"] = projectKey
+	args["issue_type"] = subtaskType
+	req.Params.Arguments = args
+	return CreateIssueHandler(ctx, req)
+}
+
+// moveToEpic puts issues in an epic, or takes them out of their epic when
+// epicKey is "none".
+func moveToEpic(ctx context.Context, req mcp.CallToolRequest, epicKey string) (*mcp.CallToolResult, error) {
+	keys := utils.SplitAndTrim(req.GetString("issue_keys", ""))
+	if len(keys) == 0 {
+		return mcp.NewToolResultError("Missing required parameter: issue_keys"), nil
+	}
+	client, err := clients.GetAgileClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
+	}
+	resp, err := client.Epic.Move(ctx, epicKey, keys)
+	if epicKey == "none" {
+		if err != nil {
+			return handlers.ToolError("Failed to remove issues from their epic", resp, err), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Removed %s from their epic", strings.Join(keys, ", "))), nil
+	}
+	if err != nil {
+		return handlers.ToolError("Failed to move issues to epic", resp, err), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Moved %s to epic %s", strings.Join(keys, ", "), epicKey)), nil
+}
+
+// Handler for jira_move_to_epic
+func MoveToEpicHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	epicKey := req.GetString("epic_key", "")
+	if epicKey == "" {
+		return mcp.NewToolResultError("Missing required parameter: epic_key"), nil
+	}
+	return moveToEpic(ctx, req, epicKey)
+}
+
+// Handler for jira_remove_from_epic
+func RemoveFromEpicHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return moveToEpic(ctx, req, "none")
+}
+
+// Handler for jira_get_hierarchy
+func GetHierarchyHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	issueKey := req.GetString("issue_key", "")
+	depth := min(max(req.GetInt("max_depth", 3), 1), maxHierarchyDepth)
+	maxIssues := min(max(req.GetInt("max_issues", 200), 1), config.Current().Bulk.MaxIssues)
+	if issueKey == "" {
+		return mcp.NewToolResultError("Missing required parameter: issue_key"), nil
+	}
+	client, err := clients.GetJiraClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
+	}
+	h, err := getHierarchyFields(ctx, client)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	fields := h.searchFields()
+
+	// Climb to the top, through sub-task parents, epics and Parent Links.
+	root, err := fetchIssue(ctx, client, issueKey, fields)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	path := []string{root.Key}
+	if req.GetBool("include_ancestors", true) {
+		for range maxHierarchyDepth {
+			links := h.parents(root)
+			if len(links) == 0 || slices.Contains(path, links[0].Key) {
+				break
+			}
+			parent, err := fetchIssue(ctx, client, links[0].Key, fields)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			root = parent
+			path = append([]string{root.Key}, path...)
+		}
+	}
+
+	// Walk down one level at a time. The path to the issue does not count
+	// towards max_depth, so the issue always gets its own children.
+	top := newHierarchyNode(root)
+	nodes := map[string]*hierarchyNode{root.Key: top}
+	level := []string{root.Key}
+	for d := 0; d < depth+len(path)-1 && len(level) > 0; d++ {
+		issues, err := searchRawIssues(ctx, client, h.childJQL(level), fields, maxIssues-len(nodes))
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("%v; lower max_depth or raise max_issues", err)), nil
+		}
+		var next []string
+		for _, issue := range issues {
+			if nodes[issue.Key] != nil {
+				continue
+			}
+			for _, p := range h.parents(issue) {
+				if parent := nodes[p.Key]; parent != nil && slices.Contains(level, p.Key) {
+					n := newHierarchyNode(issue)
+					n.Relation = p.Relation
+					parent.Children = append(parent.Children, n)
+					nodes[n.Key] = n
+					next = append(next, n.Key)
+					break
+				}
+			}
+		}
+		level = next
+	}
+	top.rollup()
+	out, _ := json.Marshal(map[string]any{"issue": issueKey, "path": path, "total": len(nodes), "root": top})
+	return mcp.NewToolResultText(string(out)), nil
+}
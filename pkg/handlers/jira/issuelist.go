@@ -0,0 +1,223 @@
+package jira
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"mcp-atlassian-server/pkg/clients"
+	"mcp-atlassian-server/pkg/handlers"
+	"mcp-atlassian-server/pkg/utils"
+)
+
+// issueListFormats are the values of the "format" argument of issue lists.
+var issueListFormats = []string{"json", "compact", "markdown_table", "csv"}
+
+// defaultColumns are shown when no "columns" are given.
+var defaultColumns = []string{"key", "type", "status", "priority", "assignee", "summary", "updated"}
+
+// columnAliases maps short column names to field IDs.
+var columnAliases = map[string]string{
+	"type":         "issuetype",
+	"issue_type":   "issuetype",
+	"fix_versions": "fixVersions",
+	"fix_version":  "fixVersions",
+	"due":          "duedate",
+	"component":    "components",
+}
+
+type issueColumn struct {
+	name  string // as given, used as the header
+	field string // field ID, empty for the issue key
+}
+
+// issueList renders the issues of a search in the format chosen with the
+// "format" and "columns" arguments: the raw JSON, or one row per issue with
+// the values of the columns as text.
+type issueList struct {
+	format  string
+	columns []issueColumn
+}
+
+// newIssueList reads the "format" and "columns" arguments of req. Column names
+// may be field IDs, field names or the aliases in columnAliases.
+func newIssueList(ctx context.Context, req mcp.CallToolRequest) (*issueList, error) {
+	format := strings.ToLower(req.GetString("format", "json"))
+	if !slices.Contains(issueListFormats, format) {
+		return nil, fmt.Errorf("Invalid format %q: must be %s", format, strings.Join(issueListFormats, ", "))
+	}
+	list := &issueList{format: format}
+	if format == "json" {
+		return list, nil
+	}
+	names := utils.SplitAndTrim(req.GetString("columns", ""))
+	if len(names) == 0 {
+		names = defaultColumns
+	}
+	client, err := clients.GetJiraClient(ctx)
+	if err != nil {
+		return nil, errors.New("Jira client error: " + err.Error())
+	}
+	catalog, resp, err := getFieldCatalog(ctx, client, false)
+	if err != nil {
+		return nil, errors.New(handlers.ErrorMessage("Failed to load Jira fields", resp, err))
+	}
+	for _, name := range names {
+		col := issueColumn{name: name, field: name}
+		if id, ok := columnAliases[strings.ToLower(name)]; ok {
+			col.field = id
+		} else if strings.EqualFold(name, "key") {
+			col.field = ""
+		} else if f, err := catalog.lookup(name); err != nil {
+			return nil, err
+		} else if f != nil {
+			col.field = f.ID
+		}
+		list.columns = append(list.columns, col)
+	}
+	return list, nil
+}
+
+// fields are the field IDs to request from Jira, or nil for json output.
+func (l *issueList) fields() []string {
+	var ids []string
+	for _, c := range l.columns {
+		if c.field != "" && !slices.Contains(ids, c.field) {
+			ids = append(ids, c.field)
+		}
+	}
+	return ids
+}
+
+// rows returns the column values of each issue in body.
+func (l *issueList) rows(body map[string]any) [][]string {
+	issues, _ := body["issues"].([]any)
+	rows := make([][]string, 0, len(issues))
+	for _, item := range issues {
+		issue, _ := item.(map[string]any)
+		fields, _ := issue["fields"].(map[string]any)
+		row := make([]string, len(l.columns))
+		for i, c := range l.columns {
+			if c.field == "" {
+				row[i] = cellText(issue["key"])
+			} else {
+				row[i] = cellText(fields[c.field])
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// result renders the result of handlers.Pager.Collect.
+func (l *issueList) result(body map[string]any, err error) *mcp.CallToolResult {
+	if err != nil {
+		return mcp.NewToolResultError(err.Error())
+	}
+	if l.format != "json" {
+		return l.render(body)
+	}
+	out, err := json.Marshal(body)
+	if err != nil {
+		return mcp.NewToolResultError("Failed to marshal results: " + err.Error())
+	}
+	return mcp.NewToolResultText(string(out))
+}
+
+// render formats body, a page of issues from handlers.Pager.Collect.
+func (l *issueList) render(body map[string]any) *mcp.CallToolResult {
+	header := make([]string, len(l.columns))
+	for i, c := range l.columns {
+		header[i] = c.name
+	}
+	rows := l.rows(body)
+	switch l.format {
+	case "compact":
+		issues := make([]map[string]string, len(rows))
+		for i, row := range rows {
+			issues[i] = map[string]string{}
+			for j, name := range header {
+				if row[j] != "" {
+					issues[i][name] = row[j]
+				}
+			}
+		}
+		out := map[string]any{"issues": issues}
+		for _, k := range []string{"startAt", "total", "returned", "isLast", "nextCursor", "note"} {
+			if v, ok := body[k]; ok {
+				out[k] = v
+			}
+		}
+		return mcp.NewToolResultText(jsonText(out))
+	case "csv":
+		var b strings.Builder
+		w := csv.NewWriter(&b)
+		_ = w.Write(header)
+		_ = w.WriteAll(rows)
+		return mcp.NewToolResultText(b.String() + "\n" + pageSummary(body))
+	}
+	var b strings.Builder
+	b.WriteString("| " + strings.Join(header, " | ") + " |\n")
+	b.WriteString("|" + strings.Repeat(" --- |", len(header)) + "\n")
+	for _, row := range rows {
+		for i := range row {
+			row[i] = strings.ReplaceAll(strings.Join(strings.Fields(row[i]), " "), "|", `\|`)
+		}
+		b.WriteString("| " + strings.Join(row, " | ") + " |\n")
+	}
+	return mcp.NewToolResultText(b.String() + "\n" + pageSummary(body))
+}
+
+// pageSummary tells which issues of the total a page holds and how to get
+// the next one.
+func pageSummary(body map[string]any) string {
+	start, returned, total := handlers.IntField(body, "startAt", 0), handlers.IntField(body, "returned", 0), handlers.IntField(body, "total", 0)
+	s := fmt.Sprintf("Issues %d-%d of %d.", start+1, start+returned, total)
+	if returned == 0 {
+		s = fmt.Sprintf("No issues from %d of %d.", start, total)
+	}
+	if next, _ := body["nextCursor"].(string); next != "" {
+		s += fmt.Sprintf(" Pass cursor %q for the next page.", next)
+	}
+	if note, _ := body["note"].(string); note != "" {
+		s += " " + note + "."
+	}
+	return s
+}
+
+// cellText renders a field value as text: users and options by name, lists
+// joined with commas.
+func cellText(v any) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return x
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(x)
+	case []any:
+		parts := make([]string, 0, len(x))
+		for _, item := range x {
+			if s := cellText(item); s != "" {
+				parts = append(parts, s)
+			}
+		}
+		return strings.Join(parts, ", ")
+	case map[string]any:
+		for _, k := range []string{"displayName", "name", "value", "key"} {
+			if s, ok := x[k].(string); ok && s != "" {
+				return s
+			}
+		}
+	}
+	return jsonText(v)
+}
@@ -3,15 +3,19 @@ package jira
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 
 	"strings"
 
+	jira "github.com/ctreminiom/go-atlassian/v2/jira/v2"
 	"github.com/ctreminiom/go-atlassian/v2/pkg/infra/models"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/sirupsen/logrus"
 
 	"mcp-atlassian-server/pkg/clients"
+	"mcp-atlassian-server/pkg/handlers"
+	"mcp-atlassian-server/pkg/jql"
 	"mcp-atlassian-server/pkg/utils"
 )
 
@@ -21,35 +25,13 @@ func PingHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolRes
 		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
 	}
 	_, resp, err := client.MySelf.Details(ctx, []string{})
-	if err != nil || resp == nil || resp.StatusCode != 200 {
-		errMsg := "Jira ping failed:" + err.Error()
-		if resp != nil {
-			errMsg += resp.Bytes.String()
-		}
+	if err != nil {
 		logrus.Error(err)
-		return mcp.NewToolResultError(errMsg), nil
+		return handlers.ToolError("Jira ping failed", resp, err), nil
 	}
 	return mcp.NewToolResultText("Jira OK"), nil
 }
 
-// Handler for jira_get_user_profile
-func GetUserProfileHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	userIdentifier := req.GetString("user_identifier", "")
-	client, err := clients.GetJiraClient(ctx)
-	if err != nil {
-		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
-	}
-	_, resp, err := client.User.Get(ctx, userIdentifier, nil)
-	if err != nil || resp == nil || resp.StatusCode != 200 {
-		errMsg := "Failed to get user profile: " + err.Error()
-		if resp != nil {
-			errMsg += resp.Bytes.String()
-		}
-		return mcp.NewToolResultError(errMsg), nil
-	}
-	return mcp.NewToolResultText(resp.Bytes.String()), nil
-}
-
 // Handler for jira_get_issue
 func GetIssueHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	issueKey := req.GetString("issue_key", "")
@@ -60,24 +42,35 @@ func GetIssueHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToo
 	if err != nil {
 		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
 	}
+	catalog, resp, err := getFieldCatalog(ctx, client, false)
+	if err != nil {
+		return handlers.ToolError("Failed to load Jira fields", resp, err), nil
+	}
 	var fieldSlice, expandSlice []string
 	if fields != "" {
-		fieldSlice = utils.SplitAndTrim(fields)
+		if fieldSlice, err = catalog.resolveFieldList(utils.SplitAndTrim(fields)); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
 	}
 	if expand != "" {
 		expandSlice = utils.SplitAndTrim(expand)
 	}
-	issue, resp, err := client.Issue.Get(ctx, issueKey, fieldSlice, expandSlice)
-	if err != nil || resp == nil || resp.StatusCode != 200 {
-		errMsg := "Failed to get issue: " + err.Error()
-		if resp != nil {
-			errMsg += resp.Bytes.String()
-		}
-		return mcp.NewToolResultError(errMsg), nil
+	_, resp, err = client.Issue.Get(ctx, issueKey, fieldSlice, expandSlice)
+	if err != nil {
+		return handlers.ToolError("Failed to get issue", resp, err), nil
 	}
-	// If comments are present and commentLimit is set, trim the comments array
-	if issue.Fields != nil && issue.Fields.Comment != nil && len(issue.Fields.Comment.Comments) > commentLimit && commentLimit > 0 {
-		issue.Fields.Comment.Comments = issue.Fields.Comment.Comments[:commentLimit]
+	var issue map[string]any
+	if err := json.Unmarshal(resp.Bytes.Bytes(), &issue); err != nil {
+		return mcp.NewToolResultError("Failed to parse issue: " + err.Error()), nil
+	}
+	if issueFields, ok := issue["fields"].(map[string]any); ok {
+		// Keep only the newest commentLimit comments; Jira lists them oldest first
+		if comment, ok := issueFields["comment"].(map[string]any); ok {
+			if comments, ok := comment["comments"].([]any); ok && len(comments) > commentLimit && commentLimit >= 0 {
+				comment["comments"] = comments[len(comments)-commentLimit:]
+			}
+		}
+		issue["fields"] = catalog.displayNames(issueFields)
 	}
 	jsonBytes, _ := json.Marshal(issue)
 	return mcp.NewToolResultText(string(jsonBytes)), nil
@@ -85,94 +78,132 @@ func GetIssueHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToo
 
 // Handler for jira_search
 func SearchHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	jql := req.GetString("jql", "")
+	client, err := clients.GetJiraClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
+	}
+	return searchJQL(ctx, client, req, req.GetString("jql", "")), nil
+}
+
+// searchJQL runs query with the paging, fields, expand and projects_filter
+// options of jira_search. Syntax errors found by checkJQL stop it before it
+// is sent; other findings are added to the error if Jira rejects it.
+func searchJQL(ctx context.Context, client *jira.Client, req mcp.CallToolRequest, query string) *mcp.CallToolResult {
 	fields := req.GetString("fields", "")
-	limit := req.GetInt("limit", 10)
-	startAt := req.GetInt("start_at", 0)
 	projectsFilter := req.GetString("projects_filter", "")
 	expand := req.GetString("expand", "")
 	properties := req.GetString("properties", "")
-	client, err := clients.GetJiraClient(ctx)
+	catalog, resp, err := getFieldCatalog(ctx, client, false)
 	if err != nil {
-		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
+		return handlers.ToolError("Failed to load Jira fields", resp, err)
 	}
 	var fieldSlice, expandSlice []string
 	if fields != "" {
-		fieldSlice = utils.SplitAndTrim(fields)
+		if fieldSlice, err = catalog.resolveFieldList(utils.SplitAndTrim(fields)); err != nil {
+			return mcp.NewToolResultError(err.Error())
+		}
 	}
 	if expand != "" {
 		expandSlice = utils.SplitAndTrim(expand)
 	}
-	// If projectsFilter is set, prepend a project filter to the JQL
-	if projectsFilter != "" {
-		projects := utils.SplitAndTrim(projectsFilter)
-		if len(projects) > 0 {
-			quoted := make([]string, len(projects))
-			for i, p := range projects {
-				quoted[i] = "'" + p + "'"
-			}
-			projectJQL := "project in (" + strings.Join(quoted, ",") + ")"
-			if jql != "" {
-				jql = projectJQL + " AND (" + jql + ")"
-			} else {
-				jql = projectJQL
-			}
-		}
+	list, err := newIssueList(ctx, req)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error())
 	}
-	_, resp, err := client.Issue.Search.Post(ctx, jql, fieldSlice, expandSlice, startAt, limit, properties)
-	if err != nil || resp == nil || resp.StatusCode != 200 {
-		errMsg := "Failed to search issues: " + err.Error()
-		if resp != nil {
-			errMsg += resp.Bytes.String()
-		}
-		return mcp.NewToolResultError(errMsg), nil
+	if list.format != "json" {
+		fieldSlice = list.fields()
 	}
-	return mcp.NewToolResultText(resp.Bytes.String()), nil
+	findings, err := checkJQL(ctx, client, query)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error())
+	}
+	if projects := utils.SplitAndTrim(projectsFilter); len(projects) > 0 {
+		query = jql.And(query, jql.In("project", projects...))
+	}
+	pager, err := handlers.NewPager(req, 10, "start_at", "search\n"+query)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error())
+	}
+	body, err := pager.Collect("issues", func(start, limit int) (map[string]any, int, error) {
+		_, resp, err := client.Issue.Search.Post(ctx, query, fieldSlice, expandSlice, start, limit, properties)
+		body, total, err := issuePage("Failed to search issues", resp, err)
+		return body, total, withJQLFindings(err, resp, findings)
+	})
+	if err != nil {
+		return mcp.NewToolResultError(err.Error())
+	}
+	if list.format != "json" {
+		return list.render(body)
+	}
+	out, err := json.Marshal(body)
+	if err != nil {
+		return mcp.NewToolResultError("Failed to marshal search results: " + err.Error())
+	}
+	return mcp.NewToolResultText(searchWithDisplayNames(catalog, out))
+}
+
+// issuePage decodes a page of a Jira issue search for handlers.Pager.
+func issuePage(action string, resp *models.ResponseScheme, err error) (map[string]any, int, error) {
+	if err != nil {
+		return nil, 0, errors.New(handlers.ErrorMessage(action, resp, err))
+	}
+	body, err := handlers.DecodePage(resp.Bytes.Bytes())
+	if err != nil {
+		return nil, 0, err
+	}
+	return body, handlers.IntField(body, "total", 0), nil
 }
 
 // Handler for jira_search_fields
 func SearchFieldsHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	keyword := req.GetString("keyword", "")
-	limit := req.GetInt("limit", 10)
+	limit := handlers.Limit(req, 10)
 	startAt := req.GetInt("start_at", 0)
 	refresh := req.GetBool("refresh", false)
-	_ = refresh // Not used in this handler
 	client, err := clients.GetJiraClient(ctx)
 	if err != nil {
 		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
 	}
-	options := &models.FieldSearchOptionsScheme{Query: keyword}
-	_, resp, err := client.Issue.Field.Search(ctx, options, startAt, limit)
-	if err != nil || resp == nil || resp.StatusCode != 200 {
-		errMsg := "Failed to search fields: " + err.Error()
-		if resp != nil {
-			errMsg += resp.Bytes.String()
-		}
-		return mcp.NewToolResultError(errMsg), nil
+	catalog, resp, err := getFieldCatalog(ctx, client, refresh)
+	if err != nil {
+		return handlers.ToolError("Failed to search fields", resp, err), nil
 	}
-	return mcp.NewToolResultText(resp.Bytes.String()), nil
+	matched := catalog.search(keyword)
+	startAt = max(startAt, 0)
+	values := matched[min(startAt, len(matched)):min(startAt+limit, len(matched))]
+	jsonBytes, err := json.Marshal(map[string]any{
+		"startAt":    startAt,
+		"maxResults": limit,
+		"total":      len(matched),
+		"isLast":     startAt+limit >= len(matched),
+		"values":     values,
+	})
+	if err != nil {
+		return mcp.NewToolResultError("Failed to marshal fields: " + err.Error()), nil
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
 }
 
 // Handler for jira_get_project_issues
 func GetProjectIssuesHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	projectKey := req.GetString("project_key", "")
-	limit := req.GetInt("limit", 10)
-	startAt := req.GetInt("start_at", 0)
+	query := jql.In("project", projectKey)
+	pager, err := handlers.NewPager(req, 10, "start_at", "search\n"+query)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	list, err := newIssueList(ctx, req)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 	client, err := clients.GetJiraClient(ctx)
 	if err != nil {
 		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
 	}
-
-	jql := "project = '" + projectKey + "'"
-	_, resp, err := client.Issue.Search.Post(ctx, jql, nil, nil, startAt, limit, "")
-	if err != nil || resp == nil || resp.StatusCode != 200 {
-		errMsg := "Failed to get project issues: " + err.Error()
-		if resp != nil {
-			errMsg += resp.Bytes.String()
-		}
-		return mcp.NewToolResultError(errMsg), nil
-	}
-	return mcp.NewToolResultText(resp.Bytes.String()), nil
+	return list.result(pager.Collect("issues", func(start, limit int) (map[string]any, int, error) {
+		_, resp, err := client.Issue.Search.Post(ctx, query, list.fields(), nil, start, limit, "")
+		return issuePage("Failed to get project issues", resp, err)
+	})), nil
 }
 
 // --- Jira Tool Handler Implementations ---
@@ -187,36 +218,8 @@ func GetTransitionsHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.C
 		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
 	}
 	_, resp, err := client.Issue.Transitions(ctx, issueKey)
-	if err != nil || resp == nil || resp.StatusCode != 200 {
-		errMsg := "Failed to get transitions: " + err.Error()
-		if resp != nil {
-			errMsg += resp.Bytes.String()
-		}
-		return mcp.NewToolResultError(errMsg), nil
-	}
-	return mcp.NewToolResultText(resp.Bytes.String()), nil
-}
-
-func GetWorklogHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	issueKey := req.GetString("issue_key", "")
-	if issueKey == "" {
-		return mcp.NewToolResultError("Missing required parameter: issue_key"), nil
-	}
-	client, err := clients.GetJiraClient(ctx)
 	if err != nil {
-		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
-	}
-	startAt := 0
-	maxResults := 100
-	after := 0
-	var expand []string
-	_, resp, err := client.Issue.Worklog.Issue(ctx, issueKey, startAt, maxResults, after, expand)
-	if err != nil || resp == nil || resp.StatusCode != 200 {
-		errMsg := "Failed to get worklogs: " + err.Error()
-		if resp != nil {
-			errMsg += resp.Bytes.String()
-		}
-		return mcp.NewToolResultError(errMsg), nil
+		return handlers.ToolError("Failed to get transitions", resp, err), nil
 	}
 	return mcp.NewToolResultText(resp.Bytes.String()), nil
 }
@@ -226,7 +229,7 @@ func GetAgileBoardsHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.C
 	projectKey := req.GetString("project_key", "")
 	boardType := req.GetString("board_type", "")
 	startAt := req.GetInt("start_at", 0)
-	limit := req.GetInt("limit", 10)
+	limit := handlers.Limit(req, 10)
 	agileClient, err := clients.GetAgileClient(ctx)
 	if err != nil {
 		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
@@ -237,12 +240,8 @@ func GetAgileBoardsHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.C
 		ProjectKeyOrID: projectKey,
 	}
 	_, resp, err := agileClient.Board.Gets(ctx, opts, startAt, limit)
-	if err != nil || resp == nil || resp.StatusCode != 200 {
-		errMsg := "Failed to get agile boards: " + err.Error()
-		if resp != nil {
-			errMsg += resp.Bytes.String()
-		}
-		return mcp.NewToolResultError(errMsg), nil
+	if err != nil {
+		return handlers.ToolError("Failed to get agile boards", resp, err), nil
 	}
 	return mcp.NewToolResultText(resp.Bytes.String()), nil
 }
@@ -254,9 +253,15 @@ func GetBoardIssuesHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.C
 	}
 	jql := req.GetString("jql", "")
 	fields := req.GetString("fields", "")
-	startAt := req.GetInt("start_at", 0)
-	limit := req.GetInt("limit", 10)
 	expand := req.GetString("expand", "version")
+	pager, err := handlers.NewPager(req, 10, "start_at", fmt.Sprintf("board %d\n%s", boardID, jql))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	list, err := newIssueList(ctx, req)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 	agileClient, err := clients.GetAgileClient(ctx)
 	if err != nil {
 		return mcp.NewToolResultError("Failed to create agile client: " + err.Error()), nil
@@ -268,19 +273,18 @@ func GetBoardIssuesHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.C
 	if expand != "" {
 		expandSlice = utils.SplitAndTrim(expand)
 	}
-	_, resp, err := agileClient.Board.Issues(ctx, boardID, &models.IssueOptionScheme{
+	if list.format != "json" {
+		fieldSlice = list.fields()
+	}
+	opts := &models.IssueOptionScheme{
 		JQL:    jql,
 		Fields: fieldSlice,
 		Expand: expandSlice,
-	}, startAt, limit)
-	if err != nil || resp == nil || resp.StatusCode != 200 {
-		errMsg := "Failed to get board issues: " + err.Error()
-		if resp != nil {
-			errMsg += resp.Bytes.String()
-		}
-		return mcp.NewToolResultError(errMsg), nil
 	}
-	return mcp.NewToolResultText(resp.Bytes.String()), nil
+	return list.result(pager.Collect("issues", func(start, limit int) (map[string]any, int, error) {
+		_, resp, err := agileClient.Board.Issues(ctx, boardID, opts, start, limit)
+		return issuePage("Failed to get board issues", resp, err)
+	})), nil
 }
 
 func GetSprintsFromBoardHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -290,18 +294,14 @@ func GetSprintsFromBoardHandler(ctx context.Context, req mcp.CallToolRequest) (*
 	}
 	state := req.GetString("state", "")
 	startAt := req.GetInt("start_at", 0)
-	limit := req.GetInt("limit", 10)
+	limit := handlers.Limit(req, 10)
 	agileClient, err := clients.GetAgileClient(ctx)
 	if err != nil {
 		return mcp.NewToolResultError("Failed to create agile client: " + err.Error()), nil
 	}
 	_, resp, err := agileClient.Board.Sprints(ctx, boardID, startAt, limit, []string{state})
-	if err != nil || resp == nil || resp.StatusCode != 200 {
-		errMsg := "Failed to get sprints from board: " + err.Error()
-		if resp != nil {
-			errMsg += resp.Bytes.String()
-		}
-		return mcp.NewToolResultError(errMsg), nil
+	if err != nil {
+		return handlers.ToolError("Failed to get sprints from board", resp, err), nil
 	}
 	return mcp.NewToolResultText(resp.Bytes.String()), nil
 }
@@ -312,8 +312,14 @@ func GetSprintIssuesHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 	fields := req.GetString("fields", "")
-	startAt := req.GetInt("start_at", 0)
-	limit := req.GetInt("limit", 10)
+	pager, err := handlers.NewPager(req, 10, "start_at", fmt.Sprintf("sprint %d", sprintID))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	list, err := newIssueList(ctx, req)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 	agileClient, err := clients.GetAgileClient(ctx)
 	if err != nil {
 		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
@@ -322,18 +328,14 @@ func GetSprintIssuesHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.
 	if fields != "" {
 		fieldSlice = utils.SplitAndTrim(fields)
 	}
-	_, resp, err := agileClient.Sprint.Issues(ctx, sprintID, &models.IssueOptionScheme{
-		Fields: fieldSlice,
-	}, startAt, limit)
-	if err != nil || resp == nil || resp.StatusCode != 200 {
-		errMsg := "Failed to get sprint issues: " + err.Error()
-		if resp != nil {
-			errMsg += resp.Bytes.String()
-		}
-		return mcp.NewToolResultError(errMsg), nil
+	if list.format != "json" {
+		fieldSlice = list.fields()
 	}
-
-	return mcp.NewToolResultText(resp.Bytes.String()), nil
+	opts := &models.IssueOptionScheme{Fields: fieldSlice}
+	return list.result(pager.Collect("issues", func(start, limit int) (map[string]any, int, error) {
+		_, resp, err := agileClient.Sprint.Issues(ctx, sprintID, opts, start, limit)
+		return issuePage("Failed to get sprint issues", resp, err)
+	})), nil
 }
 
 func DownloadAttachmentsHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -347,12 +349,8 @@ func GetLinkTypesHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.Cal
 		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
 	}
 	_, resp, err := client.Issue.Link.Type.Gets(ctx)
-	if err != nil || resp == nil || resp.StatusCode != 200 {
-		errMsg := "Failed to get link types: " + err.Error()
-		if resp != nil {
-			errMsg += resp.Bytes.String()
-		}
-		return mcp.NewToolResultError(errMsg), nil
+	if err != nil {
+		return handlers.ToolError("Failed to get link types", resp, err), nil
 	}
 	return mcp.NewToolResultText(resp.Bytes.String()), nil
 }
@@ -365,49 +363,99 @@ func CreateIssueHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.Call
 	description := req.GetString("description", "")
 	components := req.GetString("components", "")
 	additionalFields := req.GetString("additional_fields", "")
+	parentKey := req.GetString("parent_key", "")
+	epicKey := req.GetString("epic_key", "")
 	if projectKey == "" || summary == "" || issueType == "" {
 		return mcp.NewToolResultError("Missing required parameters: project_key, summary, issue_type are required"), nil
 	}
+	var add map[string]any
+	if additionalFields != "" {
+		if err := json.Unmarshal([]byte(additionalFields), &add); err != nil {
+			return mcp.NewToolResultError("Failed to parse additional_fields: " + err.Error()), nil
+		}
+	}
 	client, err := clients.GetJiraClient(ctx)
 	if err != nil {
 		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
 	}
-	payload := &models.IssueSchemeV2{
-		Fields: &models.IssueFieldsSchemeV2{},
+	catalog, resp, err := getFieldCatalog(ctx, client, false)
+	if err != nil {
+		return handlers.ToolError("Failed to load Jira fields", resp, err), nil
 	}
-	payload.Fields.Project = &models.ProjectScheme{Key: projectKey}
-	payload.Fields.Summary = summary
-	payload.Fields.IssueType = &models.IssueTypeScheme{Name: issueType}
-	payload.Fields.Description = description
-	payload.Fields.Assignee = &models.UserScheme{Name: assignee}
-	if components != "" {
-		comps := utils.SplitAndTrim(components)
-		var compObjs []*models.ComponentScheme
-		for _, c := range comps {
-			compObjs = append(compObjs, &models.ComponentScheme{Name: c})
+	fields := map[string]any{
+		"project":   map[string]any{"key": projectKey},
+		"summary":   summary,
+		"issuetype": map[string]any{"name": issueType},
+	}
+	// Without create metadata the fields cannot be checked up front; Jira
+	// still validates them on create.
+	var screen *createMetaIssueType
+	if meta, err := getCreateMeta(ctx, client, projectKey); err != nil {
+		logrus.Warnf("Creating issue in %s without validating fields: %v", projectKey, err)
+	} else {
+		if screen, err = meta.issueType(issueType); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
 		}
-		payload.Fields.Components = compObjs
+		fields["issuetype"] = map[string]any{"id": screen.ID}
 	}
-	var cfields *models.CustomFields
-	if additionalFields != "" {
-		var add map[string]any
-		if err := json.Unmarshal([]byte(additionalFields), &add); err != nil {
-			return mcp.NewToolResultError("Failed to parse additional_fields: " + err.Error()), nil
-
+	if description != "" {
+		fields["description"] = description
+	}
+	if assignee != "" {
+		resolver, err := newUserResolver(ctx, client)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
 		}
-		if err := json.Unmarshal([]byte(additionalFields), &payload.Fields); err != nil {
-			return mcp.NewToolResultError("Failed to parse additional_fields into issue fields: " + err.Error()), nil
+		user, err := resolver.resolve(ctx, assignee, projectKey)
+		if err != nil {
+			return mcp.NewToolResultError("Invalid assignee: " + err.Error()), nil
 		}
-		cfields = &models.CustomFields{Fields: []map[string]any{add}}
+		fields["assignee"] = resolver.ref(user)
 	}
-
-	_, resp, err := client.Issue.Create(ctx, payload, cfields)
-	if err != nil || resp == nil || (resp.StatusCode != 201 && resp.StatusCode != 200) {
-		errMsg := "Failed to create issue: " + err.Error()
-		if resp != nil {
-			errMsg += resp.Bytes.String()
+	if parentKey != "" {
+		fields["parent"] = map[string]any{"key": parentKey}
+	}
+	if epicKey != "" {
+		h, err := getHierarchyFields(ctx, client)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		// Without an Epic Link field, as on Cloud, epics are plain parents
+		if h.EpicLink != "" {
+			fields[h.EpicLink] = epicKey
+		} else {
+			fields["parent"] = map[string]any{"key": epicKey}
+		}
+	}
+	if components != "" {
+		var compObjs []map[string]any
+		for _, c := range utils.SplitAndTrim(components) {
+			compObjs = append(compObjs, map[string]any{"name": c})
+		}
+		fields["components"] = compObjs
+	}
+	// additional_fields may use display names and override the fields above
+	resolved, err := catalog.resolvePayload(ctx, client, add)
+	if err != nil {
+		return mcp.NewToolResultError("Invalid additional_fields: " + err.Error()), nil
+	}
+	for k, v := range resolved {
+		fields[k] = v
+	}
+	// Report every missing or invalid field at once rather than one 400 at a time
+	if screen != nil {
+		if fields, err = screen.validate(projectKey, fields); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
 		}
-		return mcp.NewToolResultError(errMsg), nil
+	}
+
+	reqHttp, err := client.NewRequest(ctx, "POST", "rest/api/2/issue", "", map[string]any{"fields": fields})
+	if err != nil {
+		return mcp.NewToolResultError("Failed to create HTTP request: " + err.Error()), nil
+	}
+	resp, err = client.Call(reqHttp, nil)
+	if err != nil {
+		return handlers.ToolError("Failed to create issue", resp, err), nil
 	}
 	return mcp.NewToolResultText(resp.Bytes.String()), nil
 }
@@ -433,12 +481,8 @@ func LinkToEpicHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallT
 		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
 	}
 	resp, err := client.Epic.Move(ctx, epicKey, []string{issueKey})
-	if err != nil || resp == nil || resp.StatusCode != 204 {
-		errMsg := "Failed to link to epic: " + err.Error()
-		if resp != nil {
-			errMsg += resp.Bytes.String()
-		}
-		return mcp.NewToolResultError(errMsg), nil
+	if err != nil {
+		return handlers.ToolError("Failed to link to epic", resp, err), nil
 	}
 	return mcp.NewToolResultText("Issue linked to epic successfully"), nil
 }
@@ -468,12 +512,8 @@ func CreateIssueLinkHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.
 		}
 	}
 	resp, err := client.Issue.Link.Create(ctx, payload)
-	if err != nil || resp == nil || (resp.StatusCode != 201 && resp.StatusCode != 200) {
-		errMsg := "Failed to create issue link: " + err.Error()
-		if resp != nil {
-			errMsg += resp.Bytes.String()
-		}
-		return mcp.NewToolResultError(errMsg), nil
+	if err != nil {
+		return handlers.ToolError("Failed to create issue link", resp, err), nil
 	}
 	return mcp.NewToolResultText("Issue link created successfully"), nil
 }
@@ -488,12 +528,8 @@ func RemoveIssueLinkHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.
 		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
 	}
 	resp, err := client.Issue.Link.Delete(ctx, linkID)
-	if err != nil || resp == nil || resp.StatusCode != 204 {
-		errMsg := "Failed to remove issue link: " + err.Error()
-		if resp != nil {
-			errMsg += resp.Bytes.String()
-		}
-		return mcp.NewToolResultError(errMsg), nil
+	if err != nil {
+		return handlers.ToolError("Failed to remove issue link", resp, err), nil
 	}
 	return mcp.NewToolResultText("Issue link removed successfully"), nil
 }
@@ -501,10 +537,14 @@ func RemoveIssueLinkHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.
 func TransitionIssueHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	issueKey := req.GetString("issue_key", "")
 	transitionID := req.GetString("transition_id", "")
+	status := strings.TrimSpace(req.GetString("status", ""))
 	fieldsStr := req.GetString("fields", "")
 	comment := req.GetString("comment", "")
-	if issueKey == "" || transitionID == "" {
-		return mcp.NewToolResultError("Missing required parameters: issue_key and transition_id are required"), nil
+	resolution := req.GetString("resolution", "")
+	worklog := req.GetString("worklog", "")
+	maxSteps := req.GetInt("max_steps", 1)
+	if issueKey == "" || (transitionID == "" && status == "") {
+		return mcp.NewToolResultError("Missing required parameters: issue_key and either transition_id or status are required"), nil
 	}
 	var fields map[string]any
 	if fieldsStr != "" {
@@ -516,37 +556,39 @@ func TransitionIssueHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.
 	if err != nil {
 		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
 	}
-	payload := &models.IssueSchemeV2{
-		Transitions: []*models.IssueTransitionScheme{
-			{
-				ID: transitionID,
-			},
-		},
-		Fields: &models.IssueFieldsSchemeV2{},
-	}
-
 	if len(fields) > 0 {
-		json.Unmarshal([]byte(fieldsStr), &payload.Fields)
-	}
-	cfields := &models.CustomFields{Fields: []map[string]any{fields}}
-	if comment != "" {
-		payload.Fields.Comment = &models.IssueCommentPageSchemeV2{
-			Comments: []*models.IssueCommentSchemeV2{
-				{
-					Body: comment,
-				},
-			},
+		catalog, resp, err := getFieldCatalog(ctx, client, false)
+		if err != nil {
+			return handlers.ToolError("Failed to load Jira fields", resp, err), nil
+		}
+		if fields, err = catalog.resolvePayload(ctx, client, fields); err != nil {
+			return mcp.NewToolResultError("Invalid fields: " + err.Error()), nil
 		}
 	}
-	resp, err := client.Issue.Update(ctx, issueKey, false, payload, cfields, nil)
-	if err != nil || resp == nil || resp.StatusCode != 204 {
-		errMsg := "Failed to transition issue: " + err.Error()
-		if resp != nil {
-			errMsg += resp.Bytes.String()
+	if resolution != "" {
+		if fields == nil {
+			fields = map[string]any{}
 		}
-		return mcp.NewToolResultError(errMsg), nil
+		fields["resolution"] = map[string]any{"name": resolution}
 	}
-	return mcp.NewToolResultText("Issue transitioned successfully"), nil
+	update := map[string]any{}
+	if comment != "" {
+		update["comment"] = []map[string]any{{"add": map[string]any{"body": comment}}}
+	}
+	if worklog != "" {
+		update["worklog"] = []map[string]any{{"add": map[string]any{"timeSpent": worklog}}}
+	}
+
+	path, err := walkTransitions(ctx, client, issueKey, transitionRequest{
+		ID: transitionID, Status: status, Fields: fields, Update: update, MaxSteps: maxSteps,
+	})
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if len(path) == 1 {
+		return mcp.NewToolResultText(fmt.Sprintf("Issue %s is already in status %s", issueKey, path[0])), nil
+	}
+	return mcp.NewToolResultText("Issue transitioned successfully: " + strings.Join(path, " -> ")), nil
 }
 
 func CreateSprintHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -576,12 +618,8 @@ func CreateSprintHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.Cal
 		payload.Goal = goal
 	}
 	_, resp, err := agileClient.Sprint.Create(ctx, payload)
-	if err != nil || resp == nil || (resp.StatusCode != 201 && resp.StatusCode != 200) {
-		errMsg := "Failed to create sprint: " + err.Error()
-		if resp != nil {
-			errMsg += resp.Bytes.String()
-		}
-		return mcp.NewToolResultError(errMsg), nil
+	if err != nil {
+		return handlers.ToolError("Failed to create sprint", resp, err), nil
 	}
 	return mcp.NewToolResultText(resp.Bytes.String()), nil
 }
@@ -617,12 +655,8 @@ func UpdateSprintHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.Cal
 		payload.Goal = goal
 	}
 	_, resp, err := agileClient.Sprint.Update(ctx, sprintID, payload)
-	if err != nil || resp == nil || resp.StatusCode != 200 {
-		errMsg := "Failed to update sprint: " + err.Error()
-		if resp != nil {
-			errMsg += resp.Bytes.String()
-		}
-		return mcp.NewToolResultError(errMsg), nil
+	if err != nil {
+		return handlers.ToolError("Failed to update sprint", resp, err), nil
 	}
 	return mcp.NewToolResultText(resp.Bytes.String()), nil
 }
@@ -638,87 +672,19 @@ func AddCommentHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallT
 	if err != nil {
 		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
 	}
-	payload := &models.CommentPayloadSchemeV2{
-		Body: comment,
-	}
-	_, resp, err := client.Issue.Comment.Add(ctx, issueKey, payload, nil)
-	if err != nil || resp == nil || (resp.StatusCode != 201 && resp.StatusCode != 200) {
-		errMsg := "Failed to add comment: " + err.Error()
-		if resp != nil {
-			errMsg += resp.Bytes.String()
-		}
-		return mcp.NewToolResultError(errMsg), nil
-	}
-	return mcp.NewToolResultText(resp.Bytes.String()), nil
-}
-
-// Handler for jira_add_worklog
-func AddWorklogHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	issueKey, err := req.RequireString("issue_key")
+	visibility, err := commentVisibility(req)
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
-	timeSpent, err := req.RequireString("time_spent")
-	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
-	}
-	comment := req.GetString("comment", "")
-	started := req.GetString("started", "")
-	if started != "" {
-		started, err = utils.ParseJiraTime(started)
-		if err != nil {
-			return mcp.NewToolResultErrorFromErr("error parsing started:", err), nil
-		}
-	}
-	originalEstimate := req.GetString("original_estimate", "")
-	remainingEstimate := req.GetString("remaining_estimate", "")
-
-	client, err := clients.GetJiraClient(ctx)
-	if err != nil {
-		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
-	}
-
-	payload := map[string]any{
-		"timeSpent": timeSpent,
-	}
-	if comment != "" {
-		payload["comment"] = comment
+	payload := &models.CommentPayloadSchemeV2{
+		Body: comment,
 	}
-	if started != "" {
-		payload["started"] = started
+	if visibility != nil && visibility.Type != "" {
+		payload.Visibility = visibility
 	}
-
-	// Build the URL
-	url := fmt.Sprintf("%s://%s/rest/api/2/issue/%s/worklog", client.Site.Scheme, client.Site.Host, issueKey)
-
-	// Add estimate adjustment if needed
-	params := ""
-	if originalEstimate != "" {
-		params = fmt.Sprintf("?adjustEstimate=new&newEstimate=%s", originalEstimate)
-	} else if remainingEstimate != "" {
-		params = fmt.Sprintf("?adjustEstimate=manual&reduceBy=%s", remainingEstimate)
-	}
-	url += params
-
-	reqHttp, err := client.NewRequest(ctx, "POST", url, "application/json", payload)
-	if err != nil {
-		return mcp.NewToolResultError("Failed to create HTTP request: " + err.Error()), nil
-	}
-	var structure any
-	resp, err := client.Call(reqHttp, &structure)
+	_, resp, err := client.Issue.Comment.Add(ctx, issueKey, payload, nil)
 	if err != nil {
-		errMsg := "Failed to add worklog: " + err.Error()
-		if resp != nil {
-			errMsg += resp.Bytes.String()
-		}
-		return mcp.NewToolResultError(errMsg), nil
-	}
-	if resp.StatusCode != 201 && resp.StatusCode != 200 {
-		errMsg := "Failed to add worklog: "
-		if resp != nil {
-			errMsg += resp.Bytes.String()
-		}
-		return mcp.NewToolResultError(errMsg), nil
+		return handlers.ToolError("Failed to add comment", resp, err), nil
 	}
 	return mcp.NewToolResultText(resp.Bytes.String()), nil
 }
@@ -730,10 +696,6 @@ func UpdateIssueHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.Call
 	if issueKey == "" || fieldsStr == "" {
 		return mcp.NewToolResultError("Missing required parameters: issue_key and fields are required"), nil
 	}
-	client, err := clients.GetJiraClient(ctx)
-	if err != nil {
-		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
-	}
 	var fields map[string]any
 	if err := json.Unmarshal([]byte(fieldsStr), &fields); err != nil {
 		return mcp.NewToolResultError("Invalid fields JSON: " + err.Error()), nil
@@ -741,25 +703,33 @@ func UpdateIssueHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.Call
 	// Merge additional_fields if provided
 	if additionalFields != "" {
 		var add map[string]any
-		if err := json.Unmarshal([]byte(additionalFields), &add); err == nil {
-			for k, v := range add {
-				fields[k] = v
-			}
+		if err := json.Unmarshal([]byte(additionalFields), &add); err != nil {
+			return mcp.NewToolResultError("Invalid additional_fields JSON: " + err.Error()), nil
 		}
-	}
-	payload := &models.IssueSchemeV2{
-		Fields: &models.IssueFieldsSchemeV2{},
-	}
-	fieldsBytes, _ := json.Marshal(fields)
-	json.Unmarshal(fieldsBytes, &payload.Fields)
-	cfields := &models.CustomFields{Fields: []map[string]any{fields}}
-	resp, err := client.Issue.Update(ctx, issueKey, false, payload, cfields, nil)
-	if err != nil || resp == nil || resp.StatusCode != 204 {
-		errMsg := "Failed to update issue: " + err.Error()
-		if resp != nil {
-			errMsg += resp.Bytes.String()
+		for k, v := range add {
+			fields[k] = v
 		}
-		return mcp.NewToolResultError(errMsg), nil
+	}
+	client, err := clients.GetJiraClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
+	}
+	catalog, resp, err := getFieldCatalog(ctx, client, false)
+	if err != nil {
+		return handlers.ToolError("Failed to load Jira fields", resp, err), nil
+	}
+	resolved, err := catalog.resolvePayload(ctx, client, fields)
+	if err != nil {
+		return mcp.NewToolResultError("Invalid fields: " + err.Error()), nil
+	}
+	url := fmt.Sprintf("rest/api/2/issue/%s", issueKey)
+	reqHttp, err := client.NewRequest(ctx, "PUT", url, "", map[string]any{"fields": resolved})
+	if err != nil {
+		return mcp.NewToolResultError("Failed to create HTTP request: " + err.Error()), nil
+	}
+	resp, err = client.Call(reqHttp, nil)
+	if err != nil {
+		return handlers.ToolError("Failed to update issue", resp, err), nil
 	}
 	return mcp.NewToolResultText("Issue updated successfully"), nil
 }
@@ -774,12 +744,8 @@ func DeleteIssueHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.Call
 		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
 	}
 	resp, err := client.Issue.Delete(ctx, issueKey, false)
-	if err != nil || resp == nil || resp.StatusCode != 204 {
-		errMsg := "Failed to delete issue: " + err.Error()
-		if resp != nil {
-			errMsg += resp.Bytes.String()
-		}
-		return mcp.NewToolResultError(errMsg), nil
+	if err != nil {
+		return handlers.ToolError("Failed to delete issue", resp, err), nil
 	}
 	return mcp.NewToolResultText("Issue deleted successfully"), nil
 }
@@ -0,0 +1,312 @@
+package jira
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	jira "github.com/ctreminiom/go-atlassian/v2/jira/v2"
+	"github.com/ctreminiom/go-atlassian/v2/pkg/infra/models"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"mcp-atlassian-server/pkg/clients"
+	"mcp-atlassian-server/pkg/handlers"
+	"mcp-atlassian-server/pkg/jql"
+	"mcp-atlassian-server/pkg/utils"
+)
+
+// autocompleteCache holds the JQL reference data of each Jira instance, keyed
+// by base URL, for fieldCacheTTL.
+var autocompleteCache = struct {
+	sync.Mutex
+	data    map[string]*jql.Autocomplete
+	fetched map[string]time.Time
+}{data: map[string]*jql.Autocomplete{}, fetched: map[string]time.Time{}}
+
+// getJQLAutocomplete returns the fields, functions and reserved words JQL
+// accepts on the client's instance.
+func getJQLAutocomplete(ctx context.Context, client *jira.Client) (*jql.Autocomplete, error) {
+	key := client.Site.String()
+	autocompleteCache.Lock()
+	cached, fetched := autocompleteCache.data[key], autocompleteCache.fetched[key]
+	autocompleteCache.Unlock()
+	if cached != nil && time.Since(fetched) < fieldCacheTTL {
+		return cached, nil
+	}
+	var data jql.Autocomplete
+	if err := callJSON(ctx, client, "Failed to get JQL reference data", "GET", "rest/api/2/jql/autocompletedata", nil, &data); err != nil {
+		return nil, err
+	}
+	autocompleteCache.Lock()
+	autocompleteCache.data[key], autocompleteCache.fetched[key] = &data, time.Now()
+	autocompleteCache.Unlock()
+	return &data, nil
+}
+
+// jsonText marshals v without escaping <, > and &, which JQL uses.
+func jsonText(v any) string {
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(v)
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// invalidJQL lists the problems of a query, one per line.
+func invalidJQL(errs jql.Errors) error {
+	return errors.New("Invalid JQL:\n" + jqlProblems(errs))
+}
+
+func jqlProblems(errs jql.Errors) string {
+	lines := make([]string, len(errs))
+	for i, e := range errs {
+		lines[i] = "- " + e.Error()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// validateJQL parses a query and checks it against the instance. The
+// instance checks are skipped when Jira does not serve its reference data,
+// in which case note says so; Jira still validates the query when it runs.
+func validateJQL(ctx context.Context, client *jira.Client, query string) (q *jql.Query, note string, errs jql.Errors) {
+	q, err := jql.Parse(query)
+	var syntax *jql.Error
+	if errors.As(err, &syntax) {
+		return nil, "", jql.Errors{syntax}
+	}
+	data, err := getJQLAutocomplete(ctx, client)
+	if err != nil {
+		return q, "field names, operators and functions were not checked: " + err.Error(), nil
+	}
+	return q, "", jql.Validate(q, data)
+}
+
+// checkJQL returns the syntax errors of a query as an error, and the fields,
+// operators and functions missing from the instance's reference data as
+// findings. Apps add fields and functions the reference data may leave out,
+// so findings only explain a query Jira rejects.
+func checkJQL(ctx context.Context, client *jira.Client, query string) (jql.Errors, error) {
+	q, err := jql.Parse(query)
+	var syntax *jql.Error
+	if errors.As(err, &syntax) {
+		return nil, invalidJQL(jql.Errors{syntax})
+	}
+	data, err := getJQLAutocomplete(ctx, client)
+	if err != nil {
+		return nil, nil
+	}
+	return jql.Validate(q, data), nil
+}
+
+// withJQLFindings adds the findings of checkJQL to the error of a query Jira
+// rejected as invalid.
+func withJQLFindings(err error, resp *models.ResponseScheme, findings jql.Errors) error {
+	if len(findings) == 0 || resp == nil || resp.Code != http.StatusBadRequest {
+		return err
+	}
+	return fmt.Errorf("%w\nPossible causes:\n%s", err, jqlProblems(findings))
+}
+
+// Handler for jira_validate_jql
+func ValidateJQLHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	query := req.GetString("jql", "")
+	client, err := clients.GetJiraClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
+	}
+	q, note, errs := validateJQL(ctx, client, query)
+	result := map[string]any{"valid": len(errs) == 0}
+	if len(errs) > 0 {
+		result["errors"] = errs
+	} else {
+		result["jql"] = q.String()
+	}
+	if note != "" {
+		result["note"] = note
+	}
+	return mcp.NewToolResultText(jsonText(result)), nil
+}
+
+var htmlTagRe = regexp.MustCompile(`<[^>]+>`)
+
+// Handler for jira_jql_autocomplete
+func JQLAutocompleteHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	fieldArg := strings.TrimSpace(req.GetString("field", ""))
+	value := req.GetString("value", "")
+	limit := handlers.Limit(req, 20)
+	client, err := clients.GetJiraClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
+	}
+	data, err := getJQLAutocomplete(ctx, client)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if fieldArg == "" {
+		prefix := strings.ToLower(value)
+		fields := []map[string]any{}
+		for _, f := range data.Fields {
+			if strings.Contains(strings.ToLower(f.Name()), prefix) || strings.Contains(strings.ToLower(f.DisplayName), prefix) {
+				fields = append(fields, map[string]any{"name": f.Name(), "displayName": f.DisplayName, "operators": f.Operators, "orderable": f.Orderable == "true"})
+			}
+		}
+		functions := []string{}
+		for _, f := range data.Functions {
+			if strings.HasPrefix(strings.ToLower(f.Value), prefix) {
+				functions = append(functions, f.Value)
+			}
+		}
+		return mcp.NewToolResultText(jsonText(map[string]any{"fields": fields[:min(limit, len(fields))], "functions": functions[:min(limit, len(functions))]})), nil
+	}
+
+	field := data.Field(fieldArg)
+	if field == nil {
+		names := make([]string, len(data.Fields))
+		for i := range data.Fields {
+			names[i] = data.Fields[i].Name()
+		}
+		i, err := fuzzyMatch(fieldArg, names)
+		if err != nil {
+			return mcp.NewToolResultError("Unknown JQL field: " + err.Error()), nil
+		}
+		field = &data.Fields[i]
+	}
+	var result struct {
+		Results []struct {
+			Value       string `json:"value"`
+			DisplayName string `json:"displayName"`
+		} `json:"results"`
+	}
+	endpoint := "rest/api/2/jql/autocompletedata/suggestions?" + url.Values{"fieldName": {field.Name()}, "fieldValue": {value}}.Encode()
+	if err := callJSON(ctx, client, "Failed to get JQL suggestions", "GET", endpoint, nil, &result); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	values := []map[string]string{}
+	for _, r := range result.Results {
+		if len(values) == limit {
+			break
+		}
+		values = append(values, map[string]string{"value": r.Value, "displayName": htmlTagRe.ReplaceAllString(r.DisplayName, ""), "clause": jql.In(fieldRef(field), r.Value)})
+	}
+	return mcp.NewToolResultText(jsonText(map[string]any{"field": field.Name(), "operators": field.Operators, "values": values})), nil
+}
+
+// fieldRef is how a field is written in a query: quoted if its name has
+// spaces, as custom fields such as "Story Points" do.
+func fieldRef(f *jql.AutocompleteField) string {
+	if strings.ContainsAny(f.Name(), " \"'") {
+		return jql.Quote(f.Name())
+	}
+	return f.Name()
+}
+
+// userClause matches a user field against names, where "me" is the current
+// user and "unassigned" no user.
+func userClause(ctx context.Context, client *jira.Client, field, names string) (string, error) {
+	var resolver *userResolver
+	var values []string
+	empty := false
+	for _, name := range utils.SplitAndTrim(names) {
+		switch strings.ToLower(name) {
+		case "me", "currentuser", "currentuser()":
+			values = append(values, "currentUser()")
+			continue
+		case "unassigned", "none", "empty":
+			empty = true
+			continue
+		}
+		if resolver == nil {
+			var err error
+			if resolver, err = newUserResolver(ctx, client); err != nil {
+				return "", err
+			}
+		}
+		u, err := resolver.resolve(ctx, name, "")
+		if err != nil {
+			return "", err
+		}
+		values = append(values, jql.Quote(resolver.id(u)))
+	}
+	var clauses []string
+	if empty {
+		clauses = append(clauses, field+" is EMPTY")
+	}
+	switch len(values) {
+	case 0:
+	case 1:
+		clauses = append(clauses, field+" = "+values[0])
+	default:
+		clauses = append(clauses, field+" in ("+strings.Join(values, ", ")+")")
+	}
+	if len(clauses) > 1 {
+		return "(" + strings.Join(clauses, " OR ") + ")", nil
+	}
+	return strings.Join(clauses, ""), nil
+}
+
+// Handler for jira_build_jql
+func BuildJQLHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	client, err := clients.GetJiraClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
+	}
+	var clauses []string
+	for _, p := range []struct{ arg, field string }{
+		{"project", "project"}, {"issue_type", "issuetype"}, {"status", "status"},
+		{"priority", "priority"}, {"labels", "labels"}, {"components", "component"}, {"fix_version", "fixVersion"},
+	} {
+		if values := utils.SplitAndTrim(req.GetString(p.arg, "")); len(values) > 0 {
+			clauses = append(clauses, jql.In(p.field, values...))
+		}
+	}
+	for _, p := range []string{"assignee", "reporter"} {
+		if names := req.GetString(p, ""); names != "" {
+			clause, err := userClause(ctx, client, p, names)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			clauses = append(clauses, clause)
+		}
+	}
+	if text := req.GetString("text", ""); text != "" {
+		clauses = append(clauses, "text ~ "+jql.Quote(text))
+	}
+	for _, p := range []struct{ arg, field string }{
+		{"created", "created"}, {"updated", "updated"}, {"resolved", "resolved"}, {"due", "duedate"},
+	} {
+		if period := req.GetString(p.arg, ""); period != "" {
+			clause, err := jql.DateClause(p.field, period)
+			if err != nil {
+				return mcp.NewToolResultError(p.arg + ": " + err.Error()), nil
+			}
+			clauses = append(clauses, clause)
+		}
+	}
+	if req.GetBool("unresolved", false) {
+		clauses = append(clauses, "resolution is EMPTY")
+	}
+	query := jql.And(req.GetString("jql", ""), clauses...)
+	if orderBy := req.GetString("order_by", ""); orderBy != "" {
+		where, existing := jql.SplitOrderBy(query)
+		if existing != "" {
+			return mcp.NewToolResultError("order_by cannot be combined with a jql that has its own ORDER BY: " + existing), nil
+		}
+		query = strings.TrimSpace(where + " ORDER BY " + orderBy)
+	}
+	q, note, errs := validateJQL(ctx, client, query)
+	if len(errs) > 0 {
+		return mcp.NewToolResultError(invalidJQL(errs).Error() + "\nJQL: " + query), nil
+	}
+	result := map[string]any{"jql": q.String()}
+	if note != "" {
+		result["note"] = note
+	}
+	return mcp.NewToolResultText(jsonText(result)), nil
+}
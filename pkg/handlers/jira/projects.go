@@ -0,0 +1,440 @@
+package jira
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	jira "github.com/ctreminiom/go-atlassian/v2/jira/v2"
+	"github.com/ctreminiom/go-atlassian/v2/pkg/infra/models"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"mcp-atlassian-server/pkg/clients"
+	"mcp-atlassian-server/pkg/handlers"
+)
+
+// projectSummary is a project as listed by jira_list_projects.
+type projectSummary struct {
+	ID             string    `json:"id"`
+	Key            string    `json:"key"`
+	Name           string    `json:"name"`
+	ProjectTypeKey string    `json:"projectTypeKey,omitempty"`
+	Description    string    `json:"description,omitempty"`
+	Category       string    `json:"category,omitempty"`
+	Lead           *jiraUser `json:"lead,omitempty"`
+}
+
+// isoDate normalises an optional date argument to YYYY-MM-DD.
+func isoDate(name, s string) (string, error) {
+	if s == "" {
+		return "", nil
+	}
+	t, err := parseDate(s)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s: %w", name, err)
+	}
+	return t.Format("2006-01-02"), nil
+}
+
+// callJSON sends a request and decodes the response into out, which may be
+// nil. Failures are described as action followed by the Jira error. It is
+// for endpoints, parameters and payloads the SDK services do not cover.
+func callJSON(ctx context.Context, client *jira.Client, action, method, endpoint string, payload, out any) error {
+	req, err := client.NewRequest(ctx, method, endpoint, "", payload)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Call(req, out)
+	if err != nil {
+		return errors.New(handlers.ErrorMessage(action, resp, err))
+	}
+	return nil
+}
+
+// workflowScheme returns the workflow scheme of a project. Server and Data
+// Center only expose it through the project configuration resource.
+func workflowScheme(ctx context.Context, client *jira.Client, key, id string) (any, error) {
+	cloud, err := isCloud(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	if !cloud {
+		var scheme map[string]any
+		err := callJSON(ctx, client, "Failed to get workflow scheme", "GET", "rest/projectconfig/1/workflowscheme/"+url.PathEscape(key), nil, &scheme)
+		return scheme, err
+	}
+	projectID, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid project ID %q", id)
+	}
+	_, resp, err := client.Workflow.Scheme.Associations(ctx, []int{projectID})
+	if err != nil {
+		return nil, errors.New(handlers.ErrorMessage("Failed to get workflow scheme", resp, err))
+	}
+	var result struct {
+		Values []struct {
+			WorkflowScheme map[string]any `json:"workflowScheme"`
+		} `json:"values"`
+	}
+	if err := json.Unmarshal(resp.Bytes.Bytes(), &result); err != nil {
+		return nil, err
+	}
+	if len(result.Values) == 0 {
+		return nil, nil
+	}
+	return result.Values[0].WorkflowScheme, nil
+}
+
+// Handler for jira_list_projects
+func ListProjectsHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	query := strings.ToLower(strings.TrimSpace(req.GetString("query", "")))
+	category := strings.TrimSpace(req.GetString("category", ""))
+	limit := handlers.Limit(req, 50)
+	startAt := max(req.GetInt("start_at", 0), 0)
+	client, err := clients.GetJiraClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
+	}
+	// The SDK only offers the paginated project search, which Server and
+	// Data Center lack.
+	var projects []struct {
+		projectSummary
+		ProjectCategory *struct {
+			Name string `json:"name"`
+		} `json:"projectCategory"`
+	}
+	if err := callJSON(ctx, client, "Failed to list projects", "GET", "rest/api/2/project?expand=description,lead", nil, &projects); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	matched := []projectSummary{}
+	for _, p := range projects {
+		if p.ProjectCategory != nil {
+			p.Category = p.ProjectCategory.Name
+		}
+		if category != "" && !strings.EqualFold(p.Category, category) {
+			continue
+		}
+		if query != "" && !strings.Contains(strings.ToLower(p.Key), query) && !strings.Contains(strings.ToLower(p.Name), query) {
+			continue
+		}
+		matched = append(matched, p.projectSummary)
+	}
+	sort.Slice(matched, func(i, j int) bool { return matched[i].Key < matched[j].Key })
+	page := []projectSummary{}
+	if startAt < len(matched) {
+		page = matched[startAt:min(startAt+limit, len(matched))]
+	}
+	out, _ := json.Marshal(map[string]any{
+		"startAt":    startAt,
+		"maxResults": limit,
+		"total":      len(matched),
+		"isLast":     startAt+len(page) >= len(matched),
+		"projects":   page,
+	})
+	return mcp.NewToolResultText(string(out)), nil
+}
+
+// Handler for jira_get_project
+func GetProjectHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	projectKey := req.GetString("project_key", "")
+	if projectKey == "" {
+		return mcp.NewToolResultError("Missing required parameter: project_key"), nil
+	}
+	client, err := clients.GetJiraClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
+	}
+	_, resp, err := client.Project.Get(ctx, projectKey, []string{"description", "lead"})
+	if err != nil {
+		return handlers.ToolError("Failed to get project", resp, err), nil
+	}
+	var project map[string]any
+	if err := json.Unmarshal(resp.Bytes.Bytes(), &project); err != nil {
+		return mcp.NewToolResultError("Failed to parse project: " + err.Error()), nil
+	}
+	// Roles come as links; their names are what is useful.
+	if roles, ok := project["roles"].(map[string]any); ok {
+		names := make([]string, 0, len(roles))
+		for name := range roles {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		project["roles"] = names
+	}
+	// Reading the workflow scheme needs administrator rights, so a failure
+	// is reported alongside the project instead of failing the call.
+	key, _ := project["key"].(string)
+	id, _ := project["id"].(string)
+	if scheme, err := workflowScheme(ctx, client, key, id); err != nil {
+		project["workflowSchemeError"] = err.Error()
+	} else {
+		project["workflowScheme"] = scheme
+	}
+	out, _ := json.Marshal(project)
+	return mcp.NewToolResultText(string(out)), nil
+}
+
+// Handler for jira_get_issue_types
+func GetIssueTypesHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	projectKey := req.GetString("project_key", "")
+	client, err := clients.GetJiraClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
+	}
+	if projectKey == "" {
+		_, resp, err := client.Issue.Type.Gets(ctx)
+		if err != nil {
+			return handlers.ToolError("Failed to get issue types", resp, err), nil
+		}
+		return mcp.NewToolResultText(resp.Bytes.String()), nil
+	}
+	_, resp, err := client.Project.Get(ctx, projectKey, nil)
+	if err != nil {
+		return handlers.ToolError("Failed to get project", resp, err), nil
+	}
+	var project struct {
+		IssueTypes json.RawMessage `json:"issueTypes"`
+	}
+	if err := json.Unmarshal(resp.Bytes.Bytes(), &project); err != nil {
+		return mcp.NewToolResultError("Failed to parse project: " + err.Error()), nil
+	}
+	return mcp.NewToolResultText(string(project.IssueTypes)), nil
+}
+
+// componentPayload builds a component write from the tool arguments,
+// resolving the lead to a username or account ID. The SDK's component
+// payload has no leadUserName, which Server and Data Center need, so
+// components are written with callJSON.
+func componentPayload(ctx context.Context, client *jira.Client, req mcp.CallToolRequest) (map[string]any, error) {
+	payload := map[string]any{}
+	for _, key := range []string{"name", "description"} {
+		if v := req.GetString(key, ""); v != "" {
+			payload[key] = v
+		}
+	}
+	if v := req.GetString("assignee_type", ""); v != "" {
+		payload["assigneeType"] = strings.ToUpper(v)
+	}
+	if lead := req.GetString("lead", ""); lead != "" {
+		resolver, err := newUserResolver(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+		user, err := resolver.resolve(ctx, lead, "")
+		if err != nil {
+			return nil, fmt.Errorf("invalid lead: %w", err)
+		}
+		if resolver.cloud {
+			payload["leadAccountId"] = user.AccountID
+		} else {
+			payload["leadUserName"] = user.Name
+		}
+	}
+	return payload, nil
+}
+
+// Handler for jira_get_components
+func GetComponentsHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	projectKey := req.GetString("project_key", "")
+	if projectKey == "" {
+		return mcp.NewToolResultError("Missing required parameter: project_key"), nil
+	}
+	client, err := clients.GetJiraClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
+	}
+	_, resp, err := client.Project.Component.Gets(ctx, projectKey)
+	if err != nil {
+		return handlers.ToolError("Failed to get components", resp, err), nil
+	}
+	return mcp.NewToolResultText(resp.Bytes.String()), nil
+}
+
+// Handler for jira_create_component
+func CreateComponentHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	projectKey := req.GetString("project_key", "")
+	if projectKey == "" || req.GetString("name", "") == "" {
+		return mcp.NewToolResultError("Missing required parameters: project_key and name are required"), nil
+	}
+	client, err := clients.GetJiraClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
+	}
+	payload, err := componentPayload(ctx, client, req)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	payload["project"] = projectKey
+	var component map[string]any
+	if err := callJSON(ctx, client, "Failed to create component", "POST", "rest/api/2/component", payload, &component); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	out, _ := json.Marshal(component)
+	return mcp.NewToolResultText(string(out)), nil
+}
+
+// Handler for jira_update_component
+func UpdateComponentHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	componentID := req.GetString("component_id", "")
+	if componentID == "" {
+		return mcp.NewToolResultError("Missing required parameter: component_id"), nil
+	}
+	client, err := clients.GetJiraClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
+	}
+	payload, err := componentPayload(ctx, client, req)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if len(payload) == 0 {
+		return mcp.NewToolResultError("Nothing to change: set name, description, lead or assignee_type"), nil
+	}
+	var component map[string]any
+	if err := callJSON(ctx, client, "Failed to update component", "PUT", "rest/api/2/component/"+url.PathEscape(componentID), payload, &component); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	out, _ := json.Marshal(component)
+	return mcp.NewToolResultText(string(out)), nil
+}
+
+// Handler for jira_delete_component
+func DeleteComponentHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	componentID := req.GetString("component_id", "")
+	if componentID == "" {
+		return mcp.NewToolResultError("Missing required parameter: component_id"), nil
+	}
+	client, err := clients.GetJiraClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
+	}
+	// The SDK cannot pass moveIssuesTo.
+	endpoint := "rest/api/2/component/" + url.PathEscape(componentID)
+	if moveTo := req.GetString("move_issues_to", ""); moveTo != "" {
+		endpoint += "?" + url.Values{"moveIssuesTo": {moveTo}}.Encode()
+	}
+	if err := callJSON(ctx, client, "Failed to delete component", "DELETE", endpoint, nil, nil); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return mcp.NewToolResultText("Component deleted successfully"), nil
+}
+
+// versionPayload builds a version write from the tool arguments.
+func versionPayload(req mcp.CallToolRequest) (*models.VersionPayloadScheme, error) {
+	startDate, err := isoDate("start_date", req.GetString("start_date", ""))
+	if err != nil {
+		return nil, err
+	}
+	releaseDate, err := isoDate("release_date", req.GetString("release_date", ""))
+	if err != nil {
+		return nil, err
+	}
+	return &models.VersionPayloadScheme{
+		Name:        req.GetString("name", ""),
+		Description: req.GetString("description", ""),
+		StartDate:   startDate,
+		ReleaseDate: releaseDate,
+	}, nil
+}
+
+// Handler for jira_get_versions
+func GetVersionsHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	projectKey := req.GetString("project_key", "")
+	if projectKey == "" {
+		return mcp.NewToolResultError("Missing required parameter: project_key"), nil
+	}
+	client, err := clients.GetJiraClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
+	}
+	_, resp, err := client.Project.Version.Gets(ctx, projectKey)
+	if err != nil {
+		return handlers.ToolError("Failed to get versions", resp, err), nil
+	}
+	return mcp.NewToolResultText(resp.Bytes.String()), nil
+}
+
+// Handler for jira_create_version
+func CreateVersionHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	projectKey := req.GetString("project_key", "")
+	if projectKey == "" || req.GetString("name", "") == "" {
+		return mcp.NewToolResultError("Missing required parameters: project_key and name are required"), nil
+	}
+	payload, err := versionPayload(req)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	client, err := clients.GetJiraClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
+	}
+	project, resp, err := client.Project.Get(ctx, projectKey, nil)
+	if err != nil {
+		return handlers.ToolError("Failed to get project", resp, err), nil
+	}
+	if payload.ProjectID, err = strconv.Atoi(project.ID); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid project ID %q", project.ID)), nil
+	}
+	_, resp, err = client.Project.Version.Create(ctx, payload)
+	if err != nil {
+		return handlers.ToolError("Failed to create version", resp, err), nil
+	}
+	return mcp.NewToolResultText(resp.Bytes.String()), nil
+}
+
+// Handler for jira_update_version
+func UpdateVersionHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	versionID := req.GetString("version_id", "")
+	if versionID == "" {
+		return mcp.NewToolResultError("Missing required parameter: version_id"), nil
+	}
+	payload, err := versionPayload(req)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if *payload == (models.VersionPayloadScheme{}) {
+		return mcp.NewToolResultError("Nothing to change: set name, description, start_date or release_date"), nil
+	}
+	client, err := clients.GetJiraClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
+	}
+	_, resp, err := client.Project.Version.Update(ctx, versionID, payload)
+	if err != nil {
+		return handlers.ToolError("Failed to update version", resp, err), nil
+	}
+	return mcp.NewToolResultText(resp.Bytes.String()), nil
+}
+
+// Handler for jira_delete_version
+func DeleteVersionHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	versionID := req.GetString("version_id", "")
+	if versionID == "" {
+		return mcp.NewToolResultError("Missing required parameter: version_id"), nil
+	}
+	client, err := clients.GetJiraClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
+	}
+	// The SDK has no way to delete a version.
+	q := url.Values{}
+	if v := req.GetString("move_fix_issues_to", ""); v != "" {
+		q.Set("moveFixIssuesTo", v)
+	}
+	if v := req.GetString("move_affected_issues_to", ""); v != "" {
+		q.Set("moveAffectedIssuesTo", v)
+	}
+	endpoint := "rest/api/2/version/" + url.PathEscape(versionID)
+	if len(q) > 0 {
+		endpoint += "?" + q.Encode()
+	}
+	if err := callJSON(ctx, client, "Failed to delete version", "DELETE", endpoint, nil, nil); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return mcp.NewToolResultText("Version deleted successfully"), nil
+}
@@ -0,0 +1,347 @@
+package jira
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	jira "github.com/ctreminiom/go-atlassian/v2/jira/v2"
+	"github.com/ctreminiom/go-atlassian/v2/pkg/infra/models"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"mcp-atlassian-server/pkg/clients"
+	"mcp-atlassian-server/pkg/config"
+	"mcp-atlassian-server/pkg/handlers"
+	"mcp-atlassian-server/pkg/handlers/confluence"
+	"mcp-atlassian-server/pkg/jql"
+)
+
+// jiraVersion is a project version as returned by the version resources.
+type jiraVersion struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Project     string `json:"project,omitempty"`
+	Released    bool   `json:"released"`
+	Archived    bool   `json:"archived"`
+	ReleaseDate string `json:"releaseDate,omitempty"`
+}
+
+// getVersion fetches a version by ID.
+func getVersion(ctx context.Context, client *jira.Client, id string) (*jiraVersion, error) {
+	_, resp, err := client.Project.Version.Get(ctx, id, nil)
+	if err != nil {
+		return nil, errors.New(handlers.ErrorMessage("Failed to get version "+id, resp, err))
+	}
+	var v jiraVersion
+	if err := json.Unmarshal(resp.Bytes.Bytes(), &v); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// findVersion looks up a version of a project by name or ID.
+func findVersion(ctx context.Context, client *jira.Client, projectKey, version string) (*jiraVersion, error) {
+	_, resp, err := client.Project.Version.Gets(ctx, projectKey)
+	if err != nil {
+		return nil, errors.New(handlers.ErrorMessage("Failed to get versions", resp, err))
+	}
+	var versions []jiraVersion
+	if err := json.Unmarshal(resp.Bytes.Bytes(), &versions); err != nil {
+		return nil, err
+	}
+	for _, v := range versions {
+		if v.ID == version || strings.EqualFold(v.Name, version) {
+			return &v, nil
+		}
+	}
+	return nil, fmt.Errorf("project %s has no version %q", projectKey, version)
+}
+
+// moveUnresolved moves the unresolved issues fixed in from to the fixVersion to.
+func moveUnresolved(ctx context.Context, client *jira.Client, from, to *jiraVersion, maxIssues int) ([]bulkResult, error) {
+	keys, err := selectIssues(ctx, client, fmt.Sprintf("fixVersion = %s AND resolution is EMPTY", from.ID), maxIssues)
+	if err != nil {
+		return nil, err
+	}
+	ops := &models.UpdateOperations{}
+	if err := ops.AddMultiRawOperation("fixVersions", []map[string]any{
+		{"remove": map[string]any{"id": from.ID}},
+		{"add": map[string]any{"id": to.ID}},
+	}); err != nil {
+		return nil, err
+	}
+	results := make([]bulkResult, 0, len(keys))
+	for _, key := range keys {
+		res := bulkResult{Key: key, Status: "ok"}
+		if resp, err := client.Issue.Update(ctx, key, true, &models.IssueSchemeV2{}, nil, ops); err != nil {
+			res.Status, res.Error = "failed", handlers.ErrorMessage("Failed to update issue", resp, err)
+		}
+		results = append(results, res)
+	}
+	return results, nil
+}
+
+// moveSummary describes the outcome of moveUnresolved and counts the failures.
+func moveSummary(from, to *jiraVersion, results []bulkResult) (map[string]any, int) {
+	failed := 0
+	for _, res := range results {
+		if res.Status != "ok" {
+			failed++
+		}
+	}
+	return map[string]any{
+		"from":      from.Name,
+		"to":        to.Name,
+		"total":     len(results),
+		"succeeded": len(results) - failed,
+		"failed":    failed,
+		"results":   results,
+	}, failed
+}
+
+// Handler for jira_release_version
+func ReleaseVersionHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	versionID := req.GetString("version_id", "")
+	moveTo := req.GetString("move_unresolved_to", "")
+	maxIssues := min(max(req.GetInt("max_issues", 100), 1), config.Current().Bulk.MaxIssues)
+	if versionID == "" {
+		return mcp.NewToolResultError("Missing required parameter: version_id"), nil
+	}
+	releaseDate, err := isoDate("release_date", req.GetString("release_date", ""))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if releaseDate == "" {
+		releaseDate = time.Now().Format("2006-01-02")
+	}
+	client, err := clients.GetJiraClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
+	}
+	out := map[string]any{}
+	if moveTo != "" {
+		from, err := getVersion(ctx, client, versionID)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		to, err := getVersion(ctx, client, moveTo)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		results, err := moveUnresolved(ctx, client, from, to, maxIssues)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		moved, failed := moveSummary(from, to, results)
+		out["moved"] = moved
+		if failed > 0 {
+			// Leave the version open so the remaining issues can be moved.
+			out["released"] = false
+			b, _ := json.Marshal(out)
+			return mcp.NewToolResultError(fmt.Sprintf("Not releasing: %d unresolved issues could not be moved\n%s", failed, b)), nil
+		}
+	}
+	_, resp, err := client.Project.Version.Update(ctx, versionID, &models.VersionPayloadScheme{Released: true, ReleaseDate: releaseDate})
+	if err != nil {
+		return handlers.ToolError("Failed to release version", resp, err), nil
+	}
+	out["version"] = json.RawMessage(resp.Bytes.Bytes())
+	b, _ := json.Marshal(out)
+	return mcp.NewToolResultText(string(b)), nil
+}
+
+// Handler for jira_archive_version
+func ArchiveVersionHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	versionID := req.GetString("version_id", "")
+	archived := req.GetBool("archived", true)
+	if versionID == "" {
+		return mcp.NewToolResultError("Missing required parameter: version_id"), nil
+	}
+	client, err := clients.GetJiraClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
+	}
+	action := "Failed to archive version"
+	if !archived {
+		action = "Failed to unarchive version"
+	}
+	// The SDK's version payload drops archived when it is false, so it
+	// cannot unarchive.
+	var version map[string]any
+	if err := callJSON(ctx, client, action, "PUT", "rest/api/2/version/"+url.PathEscape(versionID), map[string]any{"archived": archived}, &version); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	out, _ := json.Marshal(version)
+	return mcp.NewToolResultText(string(out)), nil
+}
+
+// Handler for jira_move_unresolved_issues
+func MoveUnresolvedIssuesHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	fromID := req.GetString("from_version_id", "")
+	toID := req.GetString("to_version_id", "")
+	maxIssues := min(max(req.GetInt("max_issues", 100), 1), config.Current().Bulk.MaxIssues)
+	if fromID == "" || toID == "" {
+		return mcp.NewToolResultError("Missing required parameters: from_version_id and to_version_id are required"), nil
+	}
+	if fromID == toID {
+		return mcp.NewToolResultError("from_version_id and to_version_id must differ"), nil
+	}
+	client, err := clients.GetJiraClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
+	}
+	from, err := getVersion(ctx, client, fromID)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	to, err := getVersion(ctx, client, toID)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	results, err := moveUnresolved(ctx, client, from, to, maxIssues)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	summary, _ := moveSummary(from, to, results)
+	out, _ := json.Marshal(summary)
+	return mcp.NewToolResultText(string(out)), nil
+}
+
+// releaseNoteGroup is the issues of one issue type or component.
+type releaseNoteGroup struct {
+	Name   string
+	Issues []*models.IssueSchemeV2
+}
+
+// groupReleaseNotes groups issues by issue type or component, sorted by name.
+// Issues without a component are listed last, under "Other".
+func groupReleaseNotes(issues []*models.IssueSchemeV2, groupBy string) []releaseNoteGroup {
+	byName := map[string][]*models.IssueSchemeV2{}
+	for _, issue := range issues {
+		var names []string
+		if groupBy == "component" {
+			for _, c := range issue.Fields.Components {
+				names = append(names, c.Name)
+			}
+		} else if issue.Fields.IssueType != nil {
+			names = append(names, issue.Fields.IssueType.Name)
+		}
+		if len(names) == 0 {
+			names = []string{""}
+		}
+		for _, name := range names {
+			byName[name] = append(byName[name], issue)
+		}
+	}
+	groups := make([]releaseNoteGroup, 0, len(byName))
+	for name, list := range byName {
+		groups = append(groups, releaseNoteGroup{Name: name, Issues: list})
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if (groups[i].Name == "") != (groups[j].Name == "") {
+			return groups[j].Name == ""
+		}
+		return groups[i].Name < groups[j].Name
+	})
+	for i := range groups {
+		if groups[i].Name == "" {
+			groups[i].Name = "Other"
+		}
+	}
+	return groups
+}
+
+// renderReleaseNotes renders the groups as Markdown, or as Confluence wiki
+// markup when wiki is set.
+func renderReleaseNotes(title, browseURL string, groups []releaseNoteGroup, wiki bool) string {
+	var b strings.Builder
+	if wiki {
+		fmt.Fprintf(&b, "h1. %s\n", title)
+	} else {
+		fmt.Fprintf(&b, "# %s\n", title)
+	}
+	if len(groups) == 0 {
+		b.WriteString("\nNo issues in this version.\n")
+	}
+	for _, g := range groups {
+		if wiki {
+			fmt.Fprintf(&b, "\nh2. %s\n\n", g.Name)
+		} else {
+			fmt.Fprintf(&b, "\n## %s\n\n", g.Name)
+		}
+		for _, issue := range g.Issues {
+			link := browseURL + issue.Key
+			if wiki {
+				fmt.Fprintf(&b, "* [%s|%s] %s\n", issue.Key, link, issue.Fields.Summary)
+			} else {
+				fmt.Fprintf(&b, "- [%s](%s) %s\n", issue.Key, link, issue.Fields.Summary)
+			}
+		}
+	}
+	return b.String()
+}
+
+// Handler for jira_release_notes
+func ReleaseNotesHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	projectKey := req.GetString("project_key", "")
+	versionArg := req.GetString("version", "")
+	groupBy := strings.ToLower(req.GetString("group_by", "type"))
+	maxIssues := min(max(req.GetInt("max_issues", 200), 1), config.Current().Bulk.MaxIssues)
+	spaceKey := req.GetString("space_key", "")
+	if projectKey == "" || versionArg == "" {
+		return mcp.NewToolResultError("Missing required parameters: project_key and version are required"), nil
+	}
+	if groupBy != "type" && groupBy != "component" {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid group_by %q: must be type or component", groupBy)), nil
+	}
+	client, err := clients.GetJiraClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
+	}
+	version, err := findVersion(ctx, client, projectKey, versionArg)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	query := fmt.Sprintf(`project = %s AND fixVersion = %s ORDER BY key ASC`, jql.Quote(projectKey), version.ID)
+	var issues []*models.IssueSchemeV2
+	for startAt := 0; ; {
+		result, resp, err := client.Issue.Search.Post(ctx, query, []string{"summary", "issuetype", "components"}, nil, startAt, bulkSearchPage, "")
+		if err != nil {
+			return handlers.ToolError("Failed to search issues", resp, err), nil
+		}
+		if result.Total > maxIssues {
+			return mcp.NewToolResultError(fmt.Sprintf("Version %s has %d issues, more than the limit of %d; raise max_issues", version.Name, result.Total, maxIssues)), nil
+		}
+		issues = append(issues, result.Issues...)
+		startAt += len(result.Issues)
+		if len(result.Issues) == 0 || startAt >= result.Total {
+			break
+		}
+	}
+	title := req.GetString("title", "")
+	if title == "" {
+		title = fmt.Sprintf("%s %s release notes", projectKey, version.Name)
+	}
+	browseURL := client.Site.JoinPath("browse").String() + "/"
+	groups := groupReleaseNotes(issues, groupBy)
+	notes := renderReleaseNotes(title, browseURL, groups, false)
+	if spaceKey == "" {
+		return mcp.NewToolResultText(notes), nil
+	}
+	confluenceClient, err := clients.GetConfluenceClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError("Confluence client error: " + err.Error()), nil
+	}
+	wiki := renderReleaseNotes(title, browseURL, groups, true)
+	page, resp, err := confluence.CreatePage(ctx, confluenceClient, spaceKey, title, wiki, req.GetString("parent_id", ""))
+	if err != nil {
+		return handlers.ToolError("Failed to publish release notes", resp, err), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("%s\nPublished to Confluence page %s (%s) in space %s\n", notes, page.ID, page.Title, spaceKey)), nil
+}
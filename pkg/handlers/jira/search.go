@@ -0,0 +1,103 @@
+package jira
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	jira "github.com/ctreminiom/go-atlassian/v2/jira/v2"
+
+	"mcp-atlassian-server/pkg/handlers"
+)
+
+// rawIssue is a search result with its fields left as decoded JSON.
+type rawIssue struct {
+	Key    string
+	Fields map[string]any
+}
+
+// isEpic reports whether the issue is an epic.
+func (i rawIssue) isEpic() bool {
+	t, ok := i.Fields["issuetype"].(map[string]any)
+	return ok && strings.EqualFold(fmt.Sprint(t["name"]), "Epic")
+}
+
+// project returns the key of the project the issue is in.
+func (i rawIssue) project() string {
+	p, _ := i.Fields["project"].(map[string]any)
+	key, _ := p["key"].(string)
+	return key
+}
+
+// searchRawIssues returns the issues matching jql with the given fields,
+// failing when there are more than max.
+func searchRawIssues(ctx context.Context, client *jira.Client, jql string, fields []string, max int) ([]rawIssue, error) {
+	var issues []rawIssue
+	for {
+		payload := map[string]any{"jql": jql, "fields": fields, "startAt": len(issues), "maxResults": bulkSearchPage}
+		reqHttp, err := client.NewRequest(ctx, "POST", "rest/api/2/search", "", payload)
+		if err != nil {
+			return nil, err
+		}
+		var result struct {
+			Total  int        `json:"total"`
+			Issues []rawIssue `json:"issues"`
+		}
+		resp, err := client.Call(reqHttp, &result)
+		if err != nil {
+			return nil, errors.New(handlers.ErrorMessage("Failed to search issues", resp, err))
+		}
+		if result.Total > max {
+			return nil, fmt.Errorf("JQL matches %d issues, more than the limit of %d; narrow the query or raise max_issues", result.Total, max)
+		}
+		issues = append(issues, result.Issues...)
+		if len(result.Issues) == 0 || len(issues) >= result.Total {
+			return issues, nil
+		}
+	}
+}
+
+// epicFinder finds the epic of an issue: itself for an epic, the Epic Link,
+// or the epic of its parent. Sub-tasks have no Epic Link, and in parent-based
+// hierarchies stories reach their epic through parent, so parents are
+// fetched and their epics cached.
+type epicFinder struct {
+	client *jira.Client
+	h      hierarchyFields
+	epics  map[string]string // by issue key, "" for none
+}
+
+func (f *epicFinder) epic(ctx context.Context, issue rawIssue) (string, error) {
+	return f.find(ctx, issue, 0)
+}
+
+func (f *epicFinder) find(ctx context.Context, issue rawIssue, depth int) (string, error) {
+	if epic, ok := f.epics[issue.Key]; ok {
+		return epic, nil
+	}
+	epic := ""
+	if issue.isEpic() {
+		epic = issue.Key
+	}
+	links := f.h.parents(issue)
+	for _, p := range links {
+		if epic == "" && p.Relation == "epic" {
+			epic = p.Key
+		}
+	}
+	for _, p := range links {
+		if epic != "" || depth >= maxHierarchyDepth || (p.Relation != "subtask" && p.Relation != "parent") {
+			continue
+		}
+		parent, err := fetchIssue(ctx, f.client, p.Key, f.h.searchFields())
+		if err != nil {
+			return "", err
+		}
+		if epic, err = f.find(ctx, parent, depth+1); err != nil {
+			return "", err
+		}
+	}
+	f.epics[issue.Key] = epic
+	return epic, nil
+}
@@ -0,0 +1,564 @@
+package jira
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ctreminiom/go-atlassian/v2/jira/agile"
+	jira "github.com/ctreminiom/go-atlassian/v2/jira/v2"
+	"github.com/ctreminiom/go-atlassian/v2/pkg/infra/models"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"mcp-atlassian-server/pkg/clients"
+	"mcp-atlassian-server/pkg/config"
+	"mcp-atlassian-server/pkg/handlers"
+	"mcp-atlassian-server/pkg/utils"
+)
+
+// maxMoveIssues is how many issues Jira moves or ranks in one request.
+const maxMoveIssues = 50
+
+// defaultSprintLength is used when a started sprint has no end date.
+const defaultSprintLength = 14 * 24 * time.Hour
+
+var sprintLengthRe = regexp.MustCompile(`^(\d+)\s*(d|w|days?|weeks?)$`)
+
+type sprintSummary struct {
+	ID        int    `json:"id"`
+	Name      string `json:"name"`
+	State     string `json:"state"`
+	StartDate string `json:"startDate,omitempty"`
+	EndDate   string `json:"endDate,omitempty"`
+	Goal      string `json:"goal,omitempty"`
+}
+
+func newSprintSummary(s *models.SprintScheme) *sprintSummary {
+	out := &sprintSummary{ID: s.ID, Name: s.Name, State: s.State, Goal: s.Goal}
+	if !s.StartDate.IsZero() {
+		out.StartDate = jiraTime(s.StartDate)
+	}
+	if !s.EndDate.IsZero() {
+		out.EndDate = jiraTime(s.EndDate)
+	}
+	return out
+}
+
+// jiraTime formats t as the Agile API writes dates.
+func jiraTime(t time.Time) string {
+	return t.Format("2006-01-02T15:04:05.000Z07:00")
+}
+
+// parseSprintLength reads a sprint length such as "2w" or "10d".
+func parseSprintLength(s string) (time.Duration, error) {
+	m := sprintLengthRe.FindStringSubmatch(strings.ToLower(strings.TrimSpace(s)))
+	if m == nil {
+		return 0, fmt.Errorf("invalid duration %q: use days or weeks such as 10d or 2w", s)
+	}
+	n, _ := strconv.Atoi(m[1])
+	days := n
+	if strings.HasPrefix(m[2], "w") {
+		days *= 7
+	}
+	return time.Duration(days) * 24 * time.Hour, nil
+}
+
+// issueKeysArg reads the issue_keys argument, bounded by bulk.max_issues.
+func issueKeysArg(req mcp.CallToolRequest) ([]string, error) {
+	keys := utils.SplitAndTrim(req.GetString("issue_keys", ""))
+	if len(keys) == 0 {
+		return nil, errors.New("Missing required parameter: issue_keys")
+	}
+	if max := config.Current().Bulk.MaxIssues; len(keys) > max {
+		return nil, fmt.Errorf("%d issues given; at most %d can be moved at once (bulk.max_issues)", len(keys), max)
+	}
+	return keys, nil
+}
+
+// rankArgs reads the rank_before and rank_after arguments, of which at most
+// one may be set.
+func rankArgs(req mcp.CallToolRequest) (before, after string, err error) {
+	before, after = req.GetString("rank_before", ""), req.GetString("rank_after", "")
+	if before != "" && after != "" {
+		return "", "", errors.New("Set only one of rank_before and rank_after")
+	}
+	return before, after, nil
+}
+
+// moveInChunks passes keys to move maxMoveIssues at a time and returns the
+// keys that were moved. Each chunk ranked after an issue is ranked after the
+// last of the previous chunk, so the keys keep their order.
+func moveInChunks(keys []string, before, after string, move func(chunk []string, before, after string) error) ([]string, error) {
+	var moved []string
+	for chunk := range slices.Chunk(keys, maxMoveIssues) {
+		if err := move(chunk, before, after); err != nil {
+			if len(moved) > 0 {
+				err = fmt.Errorf("%w\nAlready moved: %s", err, strings.Join(moved, ", "))
+			}
+			return moved, err
+		}
+		moved = append(moved, chunk...)
+		if after != "" {
+			after = chunk[len(chunk)-1]
+		}
+	}
+	return moved, nil
+}
+
+// rankIssues ranks keys before or after another issue. Jira answers 207 with
+// the issues it could not rank when only some of them fail.
+func rankIssues(ctx context.Context, client *jira.Client, keys []string, before, after string) error {
+	_, err := moveInChunks(keys, before, after, func(chunk []string, before, after string) error {
+		payload := &models.BoardMovementPayloadScheme{Issues: chunk, RankBeforeIssue: before, RankAfterIssue: after}
+		req, err := client.NewRequest(ctx, http.MethodPut, "rest/agile/1.0/issue/rank", "", payload)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Call(req, nil)
+		if err != nil {
+			return errors.New(handlers.ErrorMessage("Failed to rank issues", resp, err))
+		}
+		if resp.Code != http.StatusMultiStatus {
+			return nil
+		}
+		var result struct {
+			Entries []struct {
+				IssueKey string   `json:"issueKey"`
+				Status   int      `json:"status"`
+				Errors   []string `json:"errors"`
+			} `json:"entries"`
+		}
+		_ = json.Unmarshal(resp.Bytes.Bytes(), &result)
+		var failed []string
+		for _, e := range result.Entries {
+			if e.Status >= 300 {
+				failed = append(failed, e.IssueKey+": "+strings.Join(e.Errors, "; "))
+			}
+		}
+		if len(failed) > 0 {
+			return errors.New("Failed to rank issues:\n- " + strings.Join(failed, "\n- "))
+		}
+		return nil
+	})
+	return err
+}
+
+// moveToSprint moves keys into a sprint, ranking them if before or after is
+// set. Sprint.Move of the SDK drops the context path of the site from the URL,
+// so the request is made here.
+func moveToSprint(ctx context.Context, client *jira.Client, sprintID int, keys []string, before, after string) ([]string, error) {
+	endpoint := fmt.Sprintf("rest/agile/1.0/sprint/%d/issue", sprintID)
+	return moveInChunks(keys, before, after, func(chunk []string, before, after string) error {
+		payload := &models.SprintMovePayloadScheme{Issues: chunk, RankBeforeIssue: before, RankAfterIssue: after}
+		return callJSON(ctx, client, "Failed to move issues to the sprint", http.MethodPost, endpoint, payload, nil)
+	})
+}
+
+// moveToBacklog takes keys out of their active or future sprints.
+func moveToBacklog(ctx context.Context, agileClient *agile.Client, keys []string) ([]string, error) {
+	return moveInChunks(keys, "", "", func(chunk []string, _, _ string) error {
+		resp, err := agileClient.Backlog.Move(ctx, chunk)
+		if err != nil {
+			return errors.New(handlers.ErrorMessage("Failed to move issues to the backlog", resp, err))
+		}
+		return nil
+	})
+}
+
+// getSprint fetches a sprint, failing unless it is in one of states.
+func getSprint(ctx context.Context, agileClient *agile.Client, sprintID int, states ...string) (*models.SprintScheme, error) {
+	sprint, resp, err := agileClient.Sprint.Get(ctx, sprintID)
+	if err != nil {
+		return nil, errors.New(handlers.ErrorMessage(fmt.Sprintf("Failed to get sprint %d", sprintID), resp, err))
+	}
+	if len(states) > 0 && !slices.Contains(states, strings.ToLower(sprint.State)) {
+		return nil, fmt.Errorf("Sprint %d (%s) is %s; it must be %s", sprint.ID, sprint.Name, strings.ToLower(sprint.State), strings.Join(states, " or "))
+	}
+	return sprint, nil
+}
+
+// startSprint makes a future sprint active from start until end.
+func startSprint(ctx context.Context, agileClient *agile.Client, sprint *models.SprintScheme, start, end time.Time, goal string) (*models.SprintScheme, error) {
+	if !end.After(start) {
+		return nil, fmt.Errorf("The sprint would end (%s) before it starts (%s)", jiraTime(end), jiraTime(start))
+	}
+	payload := &models.SprintPayloadScheme{State: "active", StartDate: jiraTime(start), EndDate: jiraTime(end), Goal: goal}
+	started, resp, err := agileClient.Sprint.Path(ctx, sprint.ID, payload)
+	if err != nil {
+		return nil, errors.New(handlers.ErrorMessage(fmt.Sprintf("Failed to start sprint %d", sprint.ID), resp, err))
+	}
+	return started, nil
+}
+
+// sprintEnd is when a sprint started at start ends: after length when given,
+// at its planned end date when that is later than start, or after the length
+// of previous, or of defaultSprintLength.
+func sprintEnd(sprint *models.SprintScheme, start time.Time, length time.Duration, previous *models.SprintScheme) time.Time {
+	switch {
+	case length > 0:
+		return start.Add(length)
+	case sprint.EndDate.After(start):
+		return sprint.EndDate
+	case previous != nil && previous.EndDate.After(previous.StartDate) && !previous.StartDate.IsZero():
+		return start.Add(previous.EndDate.Sub(previous.StartDate))
+	}
+	return start.Add(defaultSprintLength)
+}
+
+// Handler for jira_get_board_backlog
+func GetBoardBacklogHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	boardID, err := req.RequireInt("board_id")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	jql := req.GetString("jql", "")
+	fields := req.GetString("fields", "")
+	pager, err := handlers.NewPager(req, 10, "start_at", fmt.Sprintf("backlog %d\n%s", boardID, jql))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	list, err := newIssueList(ctx, req)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	agileClient, err := clients.GetAgileClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
+	}
+	opts := &models.IssueOptionScheme{JQL: jql, Fields: utils.SplitAndTrim(fields)}
+	if list.format != "json" {
+		opts.Fields = list.fields()
+	}
+	return list.result(pager.Collect("issues", func(start, limit int) (map[string]any, int, error) {
+		_, resp, err := agileClient.Board.Backlog(ctx, boardID, opts, start, limit)
+		return issuePage("Failed to get the board backlog", resp, err)
+	})), nil
+}
+
+// Handler for jira_move_issues_to_sprint
+func MoveIssuesToSprintHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	sprintID, err := req.RequireInt("sprint_id")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	keys, err := issueKeysArg(req)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	before, after, err := rankArgs(req)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	agileClient, err := clients.GetAgileClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
+	}
+	client, err := clients.GetJiraClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
+	}
+	sprint, err := getSprint(ctx, agileClient, sprintID, "active", "future")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	moved, err := moveToSprint(ctx, client, sprintID, keys, before, after)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	out, _ := json.Marshal(map[string]any{"sprint": newSprintSummary(sprint), "moved": moved})
+	return mcp.NewToolResultText(string(out)), nil
+}
+
+// Handler for jira_move_issues_to_backlog
+func MoveIssuesToBacklogHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	keys, err := issueKeysArg(req)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	before, after, err := rankArgs(req)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	agileClient, err := clients.GetAgileClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
+	}
+	moved, err := moveToBacklog(ctx, agileClient, keys)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if before != "" || after != "" {
+		client, err := clients.GetJiraClient(ctx)
+		if err != nil {
+			return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
+		}
+		if err := rankIssues(ctx, client, keys, before, after); err != nil {
+			return mcp.NewToolResultError("Moved to the backlog, but not ranked: " + err.Error()), nil
+		}
+	}
+	out, _ := json.Marshal(map[string]any{"moved": moved, "to": "backlog"})
+	return mcp.NewToolResultText(string(out)), nil
+}
+
+// Handler for jira_rank_issues
+func RankIssuesHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	keys, err := issueKeysArg(req)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	before, after, err := rankArgs(req)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if before == "" && after == "" {
+		return mcp.NewToolResultError("Set one of rank_before and rank_after"), nil
+	}
+	client, err := clients.GetJiraClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
+	}
+	if err := rankIssues(ctx, client, keys, before, after); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	result := map[string]any{"ranked": keys}
+	if before != "" {
+		result["before"] = before
+	} else {
+		result["after"] = after
+	}
+	out, _ := json.Marshal(result)
+	return mcp.NewToolResultText(string(out)), nil
+}
+
+// Handler for jira_start_sprint
+func StartSprintHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	sprintID, err := req.RequireInt("sprint_id")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	start := time.Now().UTC().Truncate(time.Minute)
+	if v := req.GetString("start_date", ""); v != "" {
+		if start, err = parseDate(v); err != nil {
+			return mcp.NewToolResultError("start_date: " + err.Error()), nil
+		}
+	}
+	var end time.Time
+	if v := req.GetString("end_date", ""); v != "" {
+		if end, err = parseDate(v); err != nil {
+			return mcp.NewToolResultError("end_date: " + err.Error()), nil
+		}
+	}
+	var length time.Duration
+	if v := req.GetString("duration", ""); v != "" {
+		if !end.IsZero() {
+			return mcp.NewToolResultError("Set only one of end_date and duration"), nil
+		}
+		if length, err = parseSprintLength(v); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	}
+	agileClient, err := clients.GetAgileClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
+	}
+	sprint, err := getSprint(ctx, agileClient, sprintID, "future")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if end.IsZero() {
+		end = sprintEnd(sprint, start, length, nil)
+	}
+	started, err := startSprint(ctx, agileClient, sprint, start, end, req.GetString("goal", ""))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	out, _ := json.Marshal(newSprintSummary(started))
+	return mcp.NewToolResultText(string(out)), nil
+}
+
+type sprintIssue struct {
+	Key     string `json:"key"`
+	Summary string `json:"summary"`
+	Status  string `json:"status"`
+}
+
+// sprintIssues returns the issues of a sprint, split into those in the last
+// column of its board, which Jira counts as completed, and the rest. Without
+// the board configuration, issues in the Done status category are completed.
+func sprintIssues(ctx context.Context, agileClient *agile.Client, sprint *models.SprintScheme) (done, open []sprintIssue, err error) {
+	var doneStatuses []string
+	if board, _, err := agileClient.Board.Configuration(ctx, sprint.OriginBoardID); err == nil && board.ColumnConfig != nil && len(board.ColumnConfig.Columns) > 0 {
+		for _, s := range board.ColumnConfig.Columns[len(board.ColumnConfig.Columns)-1].Statuses {
+			doneStatuses = append(doneStatuses, s.ID)
+		}
+	}
+	opts := &models.IssueOptionScheme{Fields: []string{"summary", "status"}}
+	for start := 0; ; {
+		_, resp, err := agileClient.Sprint.Issues(ctx, sprint.ID, opts, start, maxMoveIssues)
+		if err != nil {
+			return nil, nil, errors.New(handlers.ErrorMessage("Failed to get sprint issues", resp, err))
+		}
+		var page struct {
+			Total  int `json:"total"`
+			Issues []struct {
+				Key    string `json:"key"`
+				Fields struct {
+					Summary string `json:"summary"`
+					Status  struct {
+						ID             string `json:"id"`
+						Name           string `json:"name"`
+						StatusCategory struct {
+							Key string `json:"key"`
+						} `json:"statusCategory"`
+					} `json:"status"`
+				} `json:"fields"`
+			} `json:"issues"`
+		}
+		if err := json.Unmarshal(resp.Bytes.Bytes(), &page); err != nil {
+			return nil, nil, fmt.Errorf("failed to decode sprint issues: %w", err)
+		}
+		for _, issue := range page.Issues {
+			status := issue.Fields.Status
+			item := sprintIssue{Key: issue.Key, Summary: issue.Fields.Summary, Status: status.Name}
+			completed := status.StatusCategory.Key == "done"
+			if doneStatuses != nil {
+				completed = slices.Contains(doneStatuses, status.ID)
+			}
+			if completed {
+				done = append(done, item)
+			} else {
+				open = append(open, item)
+			}
+		}
+		start += len(page.Issues)
+		if len(page.Issues) == 0 || start >= page.Total {
+			return done, open, nil
+		}
+	}
+}
+
+// completionTarget finds where the incomplete issues of a completed sprint go: a
+// sprint ID, "backlog", or "next" for the first future sprint of the board,
+// which is nil with a note when the board has none.
+func completionTarget(ctx context.Context, agileClient *agile.Client, sprint *models.SprintScheme, moveTo string) (target *models.SprintScheme, note string, err error) {
+	switch strings.ToLower(moveTo) {
+	case "backlog":
+		return nil, "", nil
+	case "", "next":
+		page, resp, err := agileClient.Board.Sprints(ctx, sprint.OriginBoardID, 0, maxMoveIssues, []string{"future"})
+		if err != nil {
+			return nil, "", errors.New(handlers.ErrorMessage("Failed to get the future sprints of the board", resp, err))
+		}
+		for _, s := range page.Values {
+			if s.ID != sprint.ID {
+				return &models.SprintScheme{ID: s.ID, Name: s.Name, State: s.State, StartDate: s.StartDate, EndDate: s.EndDate, OriginBoardID: s.OriginBoardID, Goal: s.Goal}, "", nil
+			}
+		}
+		return nil, "the board has no future sprint, so incomplete issues go to the backlog", nil
+	}
+	id, err := strconv.Atoi(moveTo)
+	if err != nil {
+		return nil, "", fmt.Errorf("Invalid move_incomplete_to %q: use next, backlog or a sprint ID", moveTo)
+	}
+	if id == sprint.ID {
+		return nil, "", errors.New("move_incomplete_to must be another sprint than the one being completed")
+	}
+	target, err = getSprint(ctx, agileClient, id, "active", "future")
+	return target, "", err
+}
+
+// Handler for jira_complete_sprint
+func CompleteSprintHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	sprintID, err := req.RequireInt("sprint_id")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	startNext := req.GetBool("start_next", false)
+	dryRun := req.GetBool("dry_run", false)
+	agileClient, err := clients.GetAgileClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
+	}
+	client, err := clients.GetJiraClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
+	}
+	sprint, err := getSprint(ctx, agileClient, sprintID, "active")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	target, note, err := completionTarget(ctx, agileClient, sprint, req.GetString("move_incomplete_to", "next"))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	done, open, err := sprintIssues(ctx, agileClient, sprint)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	var notes []string
+	if note != "" && len(open) > 0 {
+		notes = append(notes, note)
+	}
+	if startNext && (target == nil || strings.ToLower(target.State) != "future") {
+		startNext = false
+		notes = append(notes, "start_next applies only when incomplete issues move to a future sprint, so no sprint is started")
+	}
+	result := map[string]any{
+		"sprint":      newSprintSummary(sprint),
+		"completed":   append([]sprintIssue{}, done...),
+		"carriedOver": append([]sprintIssue{}, open...),
+		"movedTo":     "backlog",
+	}
+	if target != nil {
+		result["movedTo"] = newSprintSummary(target)
+	}
+	if dryRun {
+		result["dryRun"] = true
+		if startNext {
+			notes = append(notes, fmt.Sprintf("sprint %d (%s) would be started", target.ID, target.Name))
+		}
+	} else {
+		// Closing first keeps the incomplete issues in the sprint report as
+		// not completed; moving them out of an active sprint would record
+		// them as removed from it.
+		closed, resp, err := agileClient.Sprint.Path(ctx, sprint.ID, &models.SprintPayloadScheme{State: "closed"})
+		if err != nil {
+			return handlers.ToolError(fmt.Sprintf("Failed to complete sprint %d; no issues were moved", sprint.ID), resp, err), nil
+		}
+		keys := make([]string, len(open))
+		for i, issue := range open {
+			keys[i] = issue.Key
+		}
+		if len(keys) > 0 {
+			if target != nil {
+				_, err = moveToSprint(ctx, client, target.ID, keys, "", "")
+			} else {
+				_, err = moveToBacklog(ctx, agileClient, keys)
+			}
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Sprint %d was completed, but its incomplete issues were not all moved: %s", sprint.ID, err)), nil
+			}
+		}
+		result["sprint"] = newSprintSummary(closed)
+		if startNext {
+			start := time.Now().UTC().Truncate(time.Minute)
+			started, err := startSprint(ctx, agileClient, target, start, sprintEnd(target, start, 0, sprint), "")
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Sprint %d was completed, but the next sprint was not started: %s", sprint.ID, err)), nil
+			}
+			result["started"] = newSprintSummary(started)
+		}
+	}
+	if len(notes) > 0 {
+		result["note"] = strings.Join(notes, "; ")
+	}
+	out, _ := json.Marshal(result)
+	return mcp.NewToolResultText(string(out)), nil
+}
@@ -0,0 +1,461 @@
+package jira
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+
+	jira "github.com/ctreminiom/go-atlassian/v2/jira/v2"
+	"github.com/ctreminiom/go-atlassian/v2/pkg/infra/models"
+
+	"mcp-atlassian-server/pkg/handlers"
+)
+
+// transition is a workflow transition as returned with expand=transitions.fields.
+type transition struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	To   struct {
+		ID             string `json:"id"`
+		Name           string `json:"name"`
+		StatusCategory struct {
+			Key string `json:"key"`
+		} `json:"statusCategory"`
+	} `json:"to"`
+	Fields map[string]transitionField `json:"fields"`
+}
+
+// transitionField is a field on a transition screen.
+type transitionField struct {
+	Name            string           `json:"name"`
+	Required        bool             `json:"required"`
+	HasDefaultValue bool             `json:"hasDefaultValue"`
+	AllowedValues   []map[string]any `json:"allowedValues"`
+}
+
+func (t transition) String() string {
+	return fmt.Sprintf("%q (id %s) to %s", t.Name, t.ID, t.To.Name)
+}
+
+// missingFields lists the required screen fields of t that are neither in
+// fields nor in update and have no default value.
+func (t transition) missingFields(fields, update map[string]any) []string {
+	var missing []string
+	for id, f := range t.Fields {
+		if !f.Required || f.HasDefaultValue || fields[id] != nil || update[id] != nil {
+			continue
+		}
+		desc := fmt.Sprintf("%s (%s)", f.Name, id)
+		var allowed []string
+		for _, v := range f.AllowedValues {
+			for _, key := range []string{"name", "value", "key"} {
+				if s, ok := v[key].(string); ok {
+					allowed = append(allowed, s)
+					break
+				}
+			}
+		}
+		if len(allowed) > 0 {
+			desc += ", one of: " + strings.Join(allowed, ", ")
+		}
+		missing = append(missing, desc)
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// getTransitions returns the transitions available for an issue, including
+// their screen fields.
+func getTransitions(ctx context.Context, client *jira.Client, issueKey string) ([]transition, *models.ResponseScheme, error) {
+	url := fmt.Sprintf("rest/api/2/issue/%s/transitions?expand=transitions.fields", issueKey)
+	req, err := client.NewRequest(ctx, "GET", url, "", nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	var result struct {
+		Transitions []transition `json:"transitions"`
+	}
+	resp, err := client.Call(req, &result)
+	if err != nil {
+		return nil, resp, err
+	}
+	return result.Transitions, resp, nil
+}
+
+// findTransition picks the transition with the given ID or, failing that, the
+// one leading to the named status. A transition name is accepted as well.
+func findTransition(transitions []transition, id, status string) *transition {
+	for i, t := range transitions {
+		if id != "" && t.ID == id {
+			return &transitions[i]
+		}
+	}
+	if status == "" {
+		return nil
+	}
+	for i, t := range transitions {
+		if strings.EqualFold(t.To.Name, status) {
+			return &transitions[i]
+		}
+	}
+	for i, t := range transitions {
+		if strings.EqualFold(t.Name, status) {
+			return &transitions[i]
+		}
+	}
+	return nil
+}
+
+// statusCategoryOrder ranks intermediate steps: work in progress first, then
+// back to the start.
+var statusCategoryOrder = map[string]int{"indeterminate": 0, "new": 1}
+
+func categoryRank(category string) int {
+	if rank, ok := statusCategoryOrder[category]; ok {
+		return rank
+	}
+	return len(statusCategoryOrder)
+}
+
+// workflowEdge is a transition of a workflow. From is empty for global
+// transitions, which are available from every status.
+type workflowEdge struct {
+	Name       string
+	From       string
+	To         string
+	ToCategory string
+}
+
+func (e workflowEdge) String() string {
+	return fmt.Sprintf("%s (%s -> %s)", e.Name, e.From, e.To)
+}
+
+// workflowName picks the workflow an issue type uses from a project's
+// workflow scheme, as returned by workflowScheme: issueTypeMappings and
+// defaultWorkflow on Cloud, a list of mappings on Server and Data Center.
+func workflowName(scheme any, issueTypeID string) string {
+	m, _ := scheme.(map[string]any)
+	if byType, ok := m["issueTypeMappings"].(map[string]any); ok {
+		if name, ok := byType[issueTypeID].(string); ok {
+			return name
+		}
+	}
+	if name, ok := m["defaultWorkflow"].(string); ok {
+		return name
+	}
+	fallback := ""
+	mappings, _ := m["mappings"].([]any)
+	for _, raw := range mappings {
+		mapping, _ := raw.(map[string]any)
+		name, _ := mapping["name"].(string)
+		types, _ := mapping["issueTypes"].([]any)
+		for _, t := range types {
+			if fmt.Sprint(t) == issueTypeID {
+				return name
+			}
+		}
+		if mapping["default"] == true {
+			fallback = name
+		}
+	}
+	return fallback
+}
+
+// getWorkflowEdges lists the transitions of the workflow an issue type uses
+// in a project. The public REST API of Server and Data Center does not
+// describe workflow transitions, so it is read from the project
+// configuration resource there.
+func getWorkflowEdges(ctx context.Context, client *jira.Client, projectKey, projectID, issueTypeID string) ([]workflowEdge, error) {
+	scheme, err := workflowScheme(ctx, client, projectKey, projectID)
+	if err != nil {
+		return nil, err
+	}
+	name := workflowName(scheme, issueTypeID)
+	if name == "" {
+		return nil, fmt.Errorf("cannot tell which workflow project %s uses for issue type %s", projectKey, issueTypeID)
+	}
+	cloud, err := isCloud(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	if !cloud {
+		type status struct {
+			Name           string `json:"name"`
+			StatusCategory struct {
+				Key string `json:"key"`
+			} `json:"statusCategory"`
+		}
+		var workflow struct {
+			Sources []struct {
+				FromStatus status `json:"fromStatus"`
+				Targets    []struct {
+					ToStatus       status `json:"toStatus"`
+					TransitionName string `json:"transitionName"`
+				} `json:"targets"`
+			} `json:"sources"`
+		}
+		q := url.Values{"workflowName": {name}, "projectKey": {projectKey}}
+		if err := callJSON(ctx, client, "Failed to get workflow "+name, "GET", "rest/projectconfig/1/workflow?"+q.Encode(), nil, &workflow); err != nil {
+			return nil, err
+		}
+		var edges []workflowEdge
+		for _, src := range workflow.Sources {
+			for _, t := range src.Targets {
+				edges = append(edges, workflowEdge{Name: t.TransitionName, From: src.FromStatus.Name, To: t.ToStatus.Name, ToCategory: t.ToStatus.StatusCategory.Key})
+			}
+		}
+		return edges, nil
+	}
+
+	// The SDK decodes workflow transitions in the shape of the newer
+	// workflows API and fails on the status IDs this resource returns.
+	var page struct {
+		Values []struct {
+			Transitions []struct {
+				Name string   `json:"name"`
+				From []string `json:"from"`
+				To   string   `json:"to"`
+				Type string   `json:"type"`
+			} `json:"transitions"`
+		} `json:"values"`
+	}
+	q := url.Values{"workflowName": {name}, "expand": {"transitions"}}
+	if err := callJSON(ctx, client, "Failed to get workflow "+name, "GET", "rest/api/2/workflow/search?"+q.Encode(), nil, &page); err != nil {
+		return nil, err
+	}
+	if len(page.Values) == 0 {
+		return nil, fmt.Errorf("workflow %s was not found", name)
+	}
+	// The SDK requests the status list by absolute path, dropping any
+	// context path of the instance URL.
+	var statuses []*models.StatusDetailScheme
+	if err := callJSON(ctx, client, "Failed to get statuses", "GET", "rest/api/2/status", nil, &statuses); err != nil {
+		return nil, err
+	}
+	byID := map[string]*models.StatusDetailScheme{}
+	for _, st := range statuses {
+		byID[st.ID] = st
+	}
+	statusName := func(id string) (string, string) {
+		st, ok := byID[id]
+		if !ok {
+			return id, ""
+		}
+		if st.StatusCategory == nil {
+			return st.Name, ""
+		}
+		return st.Name, st.StatusCategory.Key
+	}
+	var edges []workflowEdge
+	for _, t := range page.Values[0].Transitions {
+		if t.Type == "initial" {
+			continue
+		}
+		to, category := statusName(t.To)
+		if len(t.From) == 0 {
+			edges = append(edges, workflowEdge{Name: t.Name, To: to, ToCategory: category})
+		}
+		for _, from := range t.From {
+			fromName, _ := statusName(from)
+			edges = append(edges, workflowEdge{Name: t.Name, From: fromName, To: to, ToCategory: category})
+		}
+	}
+	return edges, nil
+}
+
+// planTransitions finds the shortest chain of at most maxSteps transitions
+// from one status to another, or nil when there is none. Done statuses are
+// never passed through: they usually set a resolution and may trigger
+// notifications or automation the caller did not ask for. Among equally
+// short chains, steps into work in progress are preferred.
+func planTransitions(edges []workflowEdge, from, to string, maxSteps int) []workflowEdge {
+	edges = slices.Clone(edges)
+	sort.SliceStable(edges, func(i, j int) bool {
+		return categoryRank(edges[i].ToCategory) < categoryRank(edges[j].ToCategory)
+	})
+	start, goal := strings.ToLower(from), strings.ToLower(to)
+	via := map[string]workflowEdge{}
+	seen := map[string]bool{start: true}
+	level := []string{start}
+	for step := 1; step <= maxSteps && len(level) > 0; step++ {
+		var next []string
+		for _, status := range level {
+			for _, e := range edges {
+				target := strings.ToLower(e.To)
+				if (e.From != "" && strings.ToLower(e.From) != status) || seen[target] {
+					continue
+				}
+				if target != goal && e.ToCategory == "done" {
+					continue
+				}
+				e.From = via[status].To
+				if status == start {
+					e.From = from
+				}
+				seen[target] = true
+				via[target] = e
+				if target == goal {
+					var plan []workflowEdge
+					for s := goal; s != start; s = strings.ToLower(via[s].From) {
+						plan = append([]workflowEdge{via[s]}, plan...)
+					}
+					return plan
+				}
+				next = append(next, target)
+			}
+		}
+		level = next
+	}
+	return nil
+}
+
+// liveTransition finds the available transition that carries out a planned
+// step: the one with the step's name into its status, or else any into it.
+func liveTransition(transitions []transition, e workflowEdge) *transition {
+	var found *transition
+	for i, t := range transitions {
+		if !strings.EqualFold(t.To.Name, e.To) {
+			continue
+		}
+		if strings.EqualFold(t.Name, e.Name) {
+			return &transitions[i]
+		}
+		if found == nil {
+			found = &transitions[i]
+		}
+	}
+	return found
+}
+
+func describeTransitions(transitions []transition) string {
+	if len(transitions) == 0 {
+		return "none"
+	}
+	names := make([]string, len(transitions))
+	for i, t := range transitions {
+		names[i] = t.String()
+	}
+	return strings.Join(names, "; ")
+}
+
+func doTransition(ctx context.Context, client *jira.Client, issueKey string, payload map[string]any) (*models.ResponseScheme, error) {
+	url := fmt.Sprintf("rest/api/2/issue/%s/transitions", issueKey)
+	req, err := client.NewRequest(ctx, "POST", url, "", payload)
+	if err != nil {
+		return nil, err
+	}
+	return client.Call(req, nil)
+}
+
+// transitionRequest selects a transition by ID, or the target status to walk
+// to in at most MaxSteps transitions. Fields and Update go with the last one.
+type transitionRequest struct {
+	ID       string
+	Status   string
+	Fields   map[string]any
+	Update   map[string]any
+	MaxSteps int
+}
+
+// walkTransitions moves an issue to the requested status and returns the
+// statuses it passed through, starting with the original one. A single entry
+// means the issue was already in the target status. Jira only lists the
+// transitions out of the current status, so a target further away is planned
+// over the issue's workflow before anything is applied, and refused when the
+// workflow cannot be read or has no path to it.
+func walkTransitions(ctx context.Context, client *jira.Client, issueKey string, tr transitionRequest) ([]string, error) {
+	issue, resp, err := client.Issue.Get(ctx, issueKey, []string{"status", "project", "issuetype"}, nil)
+	if err != nil {
+		return nil, errors.New(handlers.ErrorMessage("Failed to get issue", resp, err))
+	}
+	current := ""
+	if issue.Fields != nil && issue.Fields.Status != nil {
+		current = issue.Fields.Status.Name
+	}
+	path := []string{current}
+	if tr.ID == "" && strings.EqualFold(current, tr.Status) {
+		return path, nil
+	}
+
+	var applied []string
+	stopped := func(msg string) error {
+		msg = strings.TrimSuffix(msg, ".")
+		if len(applied) == 0 {
+			return fmt.Errorf("%s. Nothing was applied; %s is still in %s", msg, issueKey, current)
+		}
+		return fmt.Errorf("%s. Applied %s; %s was left in %s", msg, strings.Join(applied, ", "), issueKey, current)
+	}
+	apply := func(t *transition, fields, update map[string]any) error {
+		if missing := t.missingFields(fields, update); len(missing) > 0 {
+			return stopped(fmt.Sprintf("Transition %s requires fields that were not provided: %s", t, strings.Join(missing, "; ")))
+		}
+		payload := map[string]any{"transition": map[string]any{"id": t.ID}}
+		if len(fields) > 0 {
+			payload["fields"] = fields
+		}
+		if len(update) > 0 {
+			payload["update"] = update
+		}
+		if resp, err := doTransition(ctx, client, issueKey, payload); err != nil {
+			return stopped(handlers.ErrorMessage(fmt.Sprintf("Failed to apply transition %s", t), resp, err))
+		}
+		applied = append(applied, workflowEdge{Name: t.Name, From: current, To: t.To.Name}.String())
+		current = t.To.Name
+		path = append(path, current)
+		return nil
+	}
+
+	transitions, resp, err := getTransitions(ctx, client, issueKey)
+	if err != nil {
+		return path, stopped(handlers.ErrorMessage("Failed to get transitions", resp, err))
+	}
+	t := findTransition(transitions, tr.ID, tr.Status)
+	if t == nil && tr.ID != "" {
+		// Leave unlisted IDs for Jira to accept or reject.
+		t = &transition{ID: tr.ID, Name: tr.ID}
+		t.To.Name = "transition " + tr.ID
+	}
+	if t != nil {
+		return path, apply(t, tr.Fields, tr.Update)
+	}
+	if tr.MaxSteps <= 1 {
+		return path, stopped(fmt.Sprintf("Cannot reach status %s from %s. Available transitions: %s. Set max_steps to walk through intermediate statuses", tr.Status, current, describeTransitions(transitions)))
+	}
+
+	projectKey, projectID, issueTypeID := "", "", ""
+	if f := issue.Fields; f != nil && f.Project != nil && f.IssueType != nil {
+		projectKey, projectID, issueTypeID = f.Project.Key, f.Project.ID, f.IssueType.ID
+	}
+	edges, err := getWorkflowEdges(ctx, client, projectKey, projectID, issueTypeID)
+	if err != nil {
+		return path, stopped(fmt.Sprintf("No known workflow path to status %s from %s. Available transitions: %s. The workflow could not be read: %v", tr.Status, current, describeTransitions(transitions), err))
+	}
+	plan := planTransitions(edges, current, tr.Status, tr.MaxSteps)
+	if plan == nil {
+		return path, stopped(fmt.Sprintf("No workflow path to status %s from %s without passing through a done status in at most %d transitions. Available transitions: %s", tr.Status, current, tr.MaxSteps, describeTransitions(transitions)))
+	}
+	for i, step := range plan {
+		if i > 0 {
+			if transitions, resp, err = getTransitions(ctx, client, issueKey); err != nil {
+				return path, stopped(handlers.ErrorMessage("Failed to get transitions", resp, err))
+			}
+		}
+		t := liveTransition(transitions, step)
+		if t == nil {
+			return path, stopped(fmt.Sprintf("Planned transition %s is not available. Available transitions: %s", step, describeTransitions(transitions)))
+		}
+		// Only the last step takes the caller's fields.
+		var fields, update map[string]any
+		if i == len(plan)-1 {
+			fields, update = tr.Fields, tr.Update
+		}
+		if err := apply(t, fields, update); err != nil {
+			return path, err
+		}
+	}
+	return path, nil
+}
@@ -0,0 +1,259 @@
+package jira
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	jira "github.com/ctreminiom/go-atlassian/v2/jira/v2"
+	"github.com/ctreminiom/go-atlassian/v2/pkg/infra/models"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"mcp-atlassian-server/pkg/clients"
+	"mcp-atlassian-server/pkg/handlers"
+)
+
+// maxUserCandidates bounds the users fetched when resolving an identifier.
+const maxUserCandidates = 20
+
+// noUserError is returned by resolve when no user matches the identifier.
+type noUserError string
+
+func (e noUserError) Error() string { return string(e) }
+
+// jiraUser is a user as returned by the user search endpoints.
+type jiraUser struct {
+	Name         string `json:"name,omitempty"`
+	Key          string `json:"key,omitempty"`
+	AccountID    string `json:"accountId,omitempty"`
+	DisplayName  string `json:"displayName,omitempty"`
+	EmailAddress string `json:"emailAddress,omitempty"`
+	Active       bool   `json:"active"`
+}
+
+func (u jiraUser) String() string {
+	ids := []string{}
+	for _, id := range []string{u.Name, u.AccountID, u.EmailAddress} {
+		if id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return fmt.Sprintf("%s (%s)", u.DisplayName, strings.Join(ids, ", "))
+}
+
+// deploymentCache remembers which Jira instances are Cloud, keyed by base URL.
+var deploymentCache = struct {
+	sync.Mutex
+	cloud map[string]bool
+}{cloud: map[string]bool{}}
+
+// userResolver searches users and maps them to the identifier the instance
+// expects: the username on Server and Data Center, the account ID on Cloud.
+type userResolver struct {
+	client *jira.Client
+	cloud  bool
+}
+
+// isCloud asks the instance for its deployment type once and caches it.
+func isCloud(ctx context.Context, client *jira.Client) (bool, error) {
+	key := client.Site.String()
+	deploymentCache.Lock()
+	cloud, known := deploymentCache.cloud[key]
+	deploymentCache.Unlock()
+	if known {
+		return cloud, nil
+	}
+	req, err := client.NewRequest(ctx, "GET", "rest/api/2/serverInfo", "", nil)
+	if err != nil {
+		return false, err
+	}
+	var info struct {
+		DeploymentType string `json:"deploymentType"`
+	}
+	resp, err := client.Call(req, &info)
+	if err != nil {
+		return false, errors.New(handlers.ErrorMessage("Failed to get server info", resp, err))
+	}
+	cloud = strings.EqualFold(info.DeploymentType, "Cloud")
+	deploymentCache.Lock()
+	deploymentCache.cloud[key] = cloud
+	deploymentCache.Unlock()
+	return cloud, nil
+}
+
+func newUserResolver(ctx context.Context, client *jira.Client) (*userResolver, error) {
+	cloud, err := isCloud(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	return &userResolver{client: client, cloud: cloud}, nil
+}
+
+// id returns the identifier of u that the instance expects.
+func (r *userResolver) id(u jiraUser) string {
+	if r.cloud {
+		return u.AccountID
+	}
+	return u.Name
+}
+
+// ref returns the value of a user field, such as assignee, for u.
+func (r *userResolver) ref(u jiraUser) map[string]any {
+	if r.cloud {
+		return map[string]any{"accountId": u.AccountID}
+	}
+	return map[string]any{"name": u.Name}
+}
+
+// idName is the kind of identifier ref uses, for messages.
+func (r *userResolver) idName() string {
+	if r.cloud {
+		return "account ID"
+	}
+	return "username"
+}
+
+// search finds users whose name, display name or email matches query. With
+// a project, only users who can be assigned issues in it are returned.
+func (r *userResolver) search(ctx context.Context, query, project string, max int) ([]jiraUser, error) {
+	q := url.Values{"maxResults": {fmt.Sprint(max)}}
+	if r.cloud {
+		q.Set("query", query)
+	} else {
+		q.Set("username", query)
+	}
+	endpoint := "rest/api/2/user/search"
+	if project != "" {
+		endpoint = "rest/api/2/user/assignable/search"
+		q.Set("project", project)
+	}
+	req, err := r.client.NewRequest(ctx, "GET", endpoint+"?"+q.Encode(), "", nil)
+	if err != nil {
+		return nil, err
+	}
+	var users []jiraUser
+	resp, err := r.client.Call(req, &users)
+	if err != nil {
+		return nil, errors.New(handlers.ErrorMessage("Failed to search users", resp, err))
+	}
+	return users, nil
+}
+
+// resolve finds the single user an identifier refers to. An exact match on
+// username, key, account ID, email or display name wins; otherwise the search
+// must return exactly one user.
+func (r *userResolver) resolve(ctx context.Context, ident, project string) (jiraUser, error) {
+	ident = strings.TrimSpace(ident)
+	candidates, err := r.search(ctx, ident, project, maxUserCandidates)
+	if err != nil {
+		return jiraUser{}, err
+	}
+	var exact []jiraUser
+	for _, u := range candidates {
+		if matchesUser(ident, u.Name, u.Key, u.AccountID, u.EmailAddress, u.DisplayName) {
+			exact = append(exact, u)
+		}
+	}
+	if len(exact) == 1 {
+		return exact[0], nil
+	}
+	if len(exact) == 0 && len(candidates) == 1 {
+		return candidates[0], nil
+	}
+	if len(candidates) == 0 {
+		if project != "" {
+			return jiraUser{}, noUserError(fmt.Sprintf("no user assignable in project %s matches %q", project, ident))
+		}
+		return jiraUser{}, noUserError(fmt.Sprintf("no user matches %q", ident))
+	}
+	if len(exact) > 1 {
+		candidates = exact
+	}
+	names := make([]string, len(candidates))
+	for i, u := range candidates {
+		names[i] = u.String()
+	}
+	return jiraUser{}, fmt.Errorf("user %q is ambiguous, it matches %s; use the %s instead", ident, strings.Join(names, "; "), r.idName())
+}
+
+// profile fetches the full profile of the user with the given username, or
+// account ID on Cloud.
+func (r *userResolver) profile(ctx context.Context, id string) (*models.ResponseScheme, error) {
+	param := "username"
+	if r.cloud {
+		param = "accountId"
+	}
+	req, err := r.client.NewRequest(ctx, "GET", "rest/api/2/user?"+url.Values{param: {id}}.Encode(), "", nil)
+	if err != nil {
+		return nil, err
+	}
+	return r.client.Call(req, nil)
+}
+
+// Handler for jira_get_user_profile
+func GetUserProfileHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	userIdentifier := strings.TrimSpace(req.GetString("user_identifier", ""))
+	if userIdentifier == "" {
+		return mcp.NewToolResultError("Missing required parameter: user_identifier"), nil
+	}
+	client, err := clients.GetJiraClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
+	}
+	resolver, err := newUserResolver(ctx, client)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	resp, err := resolver.profile(ctx, userIdentifier)
+	if err == nil {
+		return mcp.NewToolResultText(resp.Bytes.String()), nil
+	}
+	if resp == nil || resp.Code != http.StatusNotFound {
+		return handlers.ToolError("Failed to get user profile", resp, err), nil
+	}
+	// Not a username or account ID; look it up by name or email.
+	user, rerr := resolver.resolve(ctx, userIdentifier, "")
+	if _, ok := rerr.(noUserError); ok {
+		return handlers.ToolError("Failed to get user profile", resp, err), nil
+	}
+	if rerr != nil {
+		return mcp.NewToolResultError(rerr.Error()), nil
+	}
+	resp, err = resolver.profile(ctx, resolver.id(user))
+	if err != nil {
+		return handlers.ToolError("Failed to get user profile", resp, err), nil
+	}
+	return mcp.NewToolResultText(resp.Bytes.String()), nil
+}
+
+// Handler for jira_search_users
+func SearchUsersHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	query := strings.TrimSpace(req.GetString("query", ""))
+	project := req.GetString("project_key", "")
+	limit := handlers.Limit(req, 10)
+	if query == "" {
+		return mcp.NewToolResultError("Missing required parameter: query"), nil
+	}
+	client, err := clients.GetJiraClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
+	}
+	resolver, err := newUserResolver(ctx, client)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	users, err := resolver.search(ctx, query, project, limit)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if users == nil {
+		users = []jiraUser{}
+	}
+	out, _ := json.Marshal(map[string]any{"total": len(users), "users": users})
+	return mcp.NewToolResultText(string(out)), nil
+}
@@ -0,0 +1,219 @@
+package jira
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	jira "github.com/ctreminiom/go-atlassian/v2/jira/v2"
+	"github.com/ctreminiom/go-atlassian/v2/pkg/infra/models"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"mcp-atlassian-server/pkg/clients"
+	"mcp-atlassian-server/pkg/config"
+	"mcp-atlassian-server/pkg/handlers"
+	"mcp-atlassian-server/pkg/utils"
+)
+
+// watcherIDs resolves the usernames argument, or the current user when it is
+// empty, to the usernames or account IDs the instance identifies users by.
+func watcherIDs(ctx context.Context, client *jira.Client, usernames string) (*userResolver, []string, error) {
+	resolver, err := newUserResolver(ctx, client)
+	if err != nil {
+		return nil, nil, err
+	}
+	idents := utils.SplitAndTrim(usernames)
+	if len(idents) == 0 {
+		me, resp, err := client.MySelf.Details(ctx, nil)
+		if err != nil {
+			return nil, nil, errors.New(handlers.ErrorMessage("Failed to get current user", resp, err))
+		}
+		return resolver, []string{resolver.id(jiraUser{Name: me.Name, AccountID: me.AccountID})}, nil
+	}
+	ids := make([]string, len(idents))
+	for i, ident := range idents {
+		u, err := resolver.resolve(ctx, ident, "")
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid watcher: %w", err)
+		}
+		ids[i] = resolver.id(u)
+	}
+	return resolver, ids, nil
+}
+
+// addWatchers adds each user as a watcher of the issue, stopping at the first failure.
+func addWatchers(ctx context.Context, client *jira.Client, issueKey string, ids []string) error {
+	for _, id := range ids {
+		if resp, err := client.Issue.Watcher.Add(ctx, issueKey, id); err != nil {
+			return errors.New(handlers.ErrorMessage(fmt.Sprintf("Failed to add watcher %s", id), resp, err))
+		}
+	}
+	return nil
+}
+
+// removeWatcher stops a user watching the issue. The SDK identifies the
+// watcher by account ID, which only Cloud accepts; Server and Data Center
+// take the username.
+func removeWatcher(ctx context.Context, r *userResolver, issueKey, id string) (*models.ResponseScheme, error) {
+	if r.cloud {
+		return r.client.Issue.Watcher.Delete(ctx, issueKey, id)
+	}
+	endpoint := fmt.Sprintf("rest/api/2/issue/%s/watchers?username=%s", issueKey, url.QueryEscape(id))
+	req, err := r.client.NewRequest(ctx, "DELETE", endpoint, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	return r.client.Call(req, nil)
+}
+
+// Handler for jira_get_watchers
+func GetWatchersHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	issueKey := req.GetString("issue_key", "")
+	if issueKey == "" {
+		return mcp.NewToolResultError("Missing required parameter: issue_key"), nil
+	}
+	client, err := clients.GetJiraClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
+	}
+	_, resp, err := client.Issue.Watcher.Gets(ctx, issueKey)
+	if err != nil {
+		return handlers.ToolError("Failed to get watchers", resp, err), nil
+	}
+	return mcp.NewToolResultText(resp.Bytes.String()), nil
+}
+
+// Handler for jira_add_watchers
+func AddWatchersHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	issueKey := req.GetString("issue_key", "")
+	if issueKey == "" {
+		return mcp.NewToolResultError("Missing required parameter: issue_key"), nil
+	}
+	client, err := clients.GetJiraClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
+	}
+	_, names, err := watcherIDs(ctx, client, req.GetString("usernames", ""))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if err := addWatchers(ctx, client, issueKey, names); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Added %s as watchers of %s", strings.Join(names, ", "), issueKey)), nil
+}
+
+// Handler for jira_remove_watchers
+func RemoveWatchersHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	issueKey := req.GetString("issue_key", "")
+	if issueKey == "" {
+		return mcp.NewToolResultError("Missing required parameter: issue_key"), nil
+	}
+	client, err := clients.GetJiraClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
+	}
+	resolver, names, err := watcherIDs(ctx, client, req.GetString("usernames", ""))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	for _, name := range names {
+		if resp, err := removeWatcher(ctx, resolver, issueKey, name); err != nil {
+			return handlers.ToolError(fmt.Sprintf("Failed to remove watcher %s", name), resp, err), nil
+		}
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Removed %s as watchers of %s", strings.Join(names, ", "), issueKey)), nil
+}
+
+// Handler for jira_watch_issues
+func WatchIssuesHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	jql := req.GetString("jql", "")
+	maxIssues := min(max(req.GetInt("max_issues", 50), 1), config.Current().Bulk.MaxIssues)
+	if jql == "" {
+		return mcp.NewToolResultError("Missing required parameter: jql"), nil
+	}
+	client, err := clients.GetJiraClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
+	}
+	_, names, err := watcherIDs(ctx, client, req.GetString("usernames", ""))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	keys, err := selectIssues(ctx, client, jql, maxIssues)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	results := make([]bulkResult, 0, len(keys))
+	failed := 0
+	for _, key := range keys {
+		res := bulkResult{Key: key, Status: "ok"}
+		if err := addWatchers(ctx, client, key, names); err != nil {
+			res.Status, res.Error = "failed", err.Error()
+			failed++
+		}
+		results = append(results, res)
+	}
+	out, _ := json.Marshal(map[string]any{
+		"watchers":  names,
+		"total":     len(keys),
+		"succeeded": len(keys) - failed,
+		"failed":    failed,
+		"results":   results,
+	})
+	return mcp.NewToolResultText(string(out)), nil
+}
+
+// Handler for jira_get_votes
+func GetVotesHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	issueKey := req.GetString("issue_key", "")
+	if issueKey == "" {
+		return mcp.NewToolResultError("Missing required parameter: issue_key"), nil
+	}
+	client, err := clients.GetJiraClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
+	}
+	_, resp, err := client.Issue.Vote.Gets(ctx, issueKey)
+	if err != nil {
+		return handlers.ToolError("Failed to get votes", resp, err), nil
+	}
+	return mcp.NewToolResultText(resp.Bytes.String()), nil
+}
+
+// Handler for jira_add_vote
+func AddVoteHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	issueKey := req.GetString("issue_key", "")
+	if issueKey == "" {
+		return mcp.NewToolResultError("Missing required parameter: issue_key"), nil
+	}
+	client, err := clients.GetJiraClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
+	}
+	resp, err := client.Issue.Vote.Add(ctx, issueKey)
+	if err != nil {
+		return handlers.ToolError("Failed to vote", resp, err), nil
+	}
+	return mcp.NewToolResultText("Voted for " + issueKey), nil
+}
+
+// Handler for jira_remove_vote
+func RemoveVoteHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	issueKey := req.GetString("issue_key", "")
+	if issueKey == "" {
+		return mcp.NewToolResultError("Missing required parameter: issue_key"), nil
+	}
+	client, err := clients.GetJiraClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
+	}
+	resp, err := client.Issue.Vote.Delete(ctx, issueKey)
+	if err != nil {
+		return handlers.ToolError("Failed to remove vote", resp, err), nil
+	}
+	return mcp.NewToolResultText("Removed vote from " + issueKey), nil
+}
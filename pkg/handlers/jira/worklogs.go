@@ -0,0 +1,511 @@
+package jira
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	jira "github.com/ctreminiom/go-atlassian/v2/jira/v2"
+	"github.com/ctreminiom/go-atlassian/v2/pkg/infra/models"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"mcp-atlassian-server/pkg/clients"
+	"mcp-atlassian-server/pkg/config"
+	"mcp-atlassian-server/pkg/handlers"
+	"mcp-atlassian-server/pkg/jql"
+	"mcp-atlassian-server/pkg/utils"
+)
+
+// worklogPage is the number of worklogs fetched per request when listing.
+const worklogPage = 1000
+
+// estimateQuery builds the adjustEstimate query of a worklog write. The mode
+// defaults to new when newEstimate is set and to manual when by is set. by is
+// sent as manualParam, which is reduceBy when adding and increaseBy when
+// deleting; updates cannot adjust manually and pass an empty manualParam.
+func estimateQuery(mode, newEstimate, manualParam, by string) (url.Values, error) {
+	mode = strings.ToLower(strings.TrimSpace(mode))
+	newEstimate = strings.TrimSpace(newEstimate)
+	by = strings.TrimSpace(by)
+	if mode == "" {
+		switch {
+		case newEstimate != "":
+			mode = "new"
+		case by != "":
+			mode = "manual"
+		default:
+			return url.Values{}, nil
+		}
+	}
+	q := url.Values{"adjustEstimate": {mode}}
+	switch mode {
+	case "auto", "leave":
+		if newEstimate != "" || by != "" {
+			return nil, fmt.Errorf("adjust_estimate %s takes no new_estimate or adjustment amount", mode)
+		}
+	case "new":
+		if newEstimate == "" {
+			return nil, errors.New("adjust_estimate new requires new_estimate")
+		}
+		if by != "" {
+			return nil, errors.New("adjust_estimate new cannot be combined with an adjustment amount")
+		}
+		q.Set("newEstimate", newEstimate)
+	case "manual":
+		if manualParam == "" {
+			return nil, errors.New("adjust_estimate manual is not supported when updating a worklog")
+		}
+		if by == "" {
+			return nil, fmt.Errorf("adjust_estimate manual requires %s", map[string]string{"reduceBy": "reduce_by", "increaseBy": "increase_by"}[manualParam])
+		}
+		if newEstimate != "" {
+			return nil, errors.New("adjust_estimate manual cannot be combined with new_estimate")
+		}
+		q.Set(manualParam, by)
+	default:
+		return nil, fmt.Errorf("adjust_estimate %q must be auto, new, manual or leave", mode)
+	}
+	return q, nil
+}
+
+// worklogURL returns the endpoint of an issue's worklogs, or of one worklog
+// when id is set, with the given query.
+func worklogURL(issueKey, id string, q url.Values) string {
+	endpoint := fmt.Sprintf("rest/api/2/issue/%s/worklog", issueKey)
+	if id != "" {
+		endpoint += "/" + url.PathEscape(id)
+	}
+	if len(q) > 0 {
+		endpoint += "?" + q.Encode()
+	}
+	return endpoint
+}
+
+// getAllWorklogs fetches every worklog on an issue, oldest first.
+func getAllWorklogs(ctx context.Context, client *jira.Client, issueKey string) ([]*models.IssueWorklogRichTextScheme, *models.ResponseScheme, error) {
+	var all []*models.IssueWorklogRichTextScheme
+	for {
+		page, resp, err := client.Issue.Worklog.Issue(ctx, issueKey, len(all), worklogPage, 0, nil)
+		if err != nil {
+			return nil, resp, err
+		}
+		all = append(all, page.Worklogs...)
+		if len(page.Worklogs) == 0 || len(all) >= page.Total {
+			return all, resp, nil
+		}
+	}
+}
+
+// worklogDay returns the date the work started on, in the worklog's own offset.
+func worklogDay(l *models.IssueWorklogRichTextScheme) (string, error) {
+	t, err := time.Parse(jiraTimeLayout, l.Started)
+	if err != nil {
+		return "", err
+	}
+	return t.Format("2006-01-02"), nil
+}
+
+// worklogAuthoredBy reports whether the worklog author matches user.
+func worklogAuthoredBy(l *models.IssueWorklogRichTextScheme, user string) bool {
+	return l.Author != nil && matchesUser(user, l.Author.Name, l.Author.Key, l.Author.DisplayName, l.Author.EmailAddress, l.Author.AccountID)
+}
+
+// worklogAuthorIs reports whether u wrote the worklog, comparing account
+// IDs on Cloud and usernames or keys on Server and Data Center.
+func worklogAuthorIs(l *models.IssueWorklogRichTextScheme, u jiraUser) bool {
+	switch {
+	case l.Author == nil:
+		return false
+	case u.AccountID != "":
+		return l.Author.AccountID == u.AccountID
+	}
+	return (u.Key != "" && l.Author.Key == u.Key) || (u.Name != "" && strings.EqualFold(l.Author.Name, u.Name))
+}
+
+// dayRange parses since and until as YYYY-MM-DD bounds, either of which may be empty.
+func dayRange(since, until string) (string, string, error) {
+	var from, to string
+	if since != "" {
+		t, err := parseDate(since)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid since: %w", err)
+		}
+		from = t.Format("2006-01-02")
+	}
+	if until != "" {
+		t, err := parseDate(until)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid until: %w", err)
+		}
+		to = t.Format("2006-01-02")
+	}
+	if from != "" && to != "" && to < from {
+		return "", "", fmt.Errorf("until %s is before since %s", to, from)
+	}
+	return from, to, nil
+}
+
+// formatDuration renders seconds in Jira's duration notation, e.g. "2h 30m".
+func formatDuration(seconds int) string {
+	h, m := seconds/3600, seconds%3600/60
+	switch {
+	case h > 0 && m > 0:
+		return fmt.Sprintf("%dh %dm", h, m)
+	case h > 0:
+		return fmt.Sprintf("%dh", h)
+	}
+	return fmt.Sprintf("%dm", m)
+}
+
+func hours(seconds int) float64 {
+	return math.Round(float64(seconds)/36) / 100
+}
+
+// Handler for jira_get_worklog
+func GetWorklogHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	issueKey := req.GetString("issue_key", "")
+	author := strings.TrimSpace(req.GetString("author", ""))
+	limit := handlers.Limit(req, 50)
+	startAt := max(req.GetInt("start_at", 0), 0)
+	if issueKey == "" {
+		return mcp.NewToolResultError("Missing required parameter: issue_key"), nil
+	}
+	from, to, err := dayRange(req.GetString("since", ""), req.GetString("until", ""))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	client, err := clients.GetJiraClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
+	}
+	all, resp, err := getAllWorklogs(ctx, client, issueKey)
+	if err != nil {
+		return handlers.ToolError("Failed to get worklogs", resp, err), nil
+	}
+	matched := []*models.IssueWorklogRichTextScheme{}
+	seconds := 0
+	for _, l := range all {
+		if author != "" && !worklogAuthoredBy(l, author) {
+			continue
+		}
+		if from != "" || to != "" {
+			day, err := worklogDay(l)
+			if err != nil || (from != "" && day < from) || (to != "" && day > to) {
+				continue
+			}
+		}
+		matched = append(matched, l)
+		seconds += l.TimeSpentSeconds
+	}
+	page := []*models.IssueWorklogRichTextScheme{}
+	if startAt < len(matched) {
+		page = matched[startAt:min(startAt+limit, len(matched))]
+	}
+	out, _ := json.Marshal(map[string]any{
+		"startAt":      startAt,
+		"maxResults":   limit,
+		"total":        len(matched),
+		"isLast":       startAt+len(page) >= len(matched),
+		"totalSeconds": seconds,
+		"timeSpent":    formatDuration(seconds),
+		"worklogs":     page,
+	})
+	return mcp.NewToolResultText(string(out)), nil
+}
+
+// Handler for jira_add_worklog
+func AddWorklogHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	issueKey, err := req.RequireString("issue_key")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	timeSpent, err := req.RequireString("time_spent")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	comment := req.GetString("comment", "")
+	started := req.GetString("started", "")
+	if started != "" {
+		started, err = utils.ParseJiraTime(started)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("error parsing started:", err), nil
+		}
+	}
+	mode := req.GetString("adjust_estimate", "")
+	newEstimate := req.GetString("new_estimate", "")
+	originalEstimate := strings.TrimSpace(req.GetString("original_estimate", ""))
+	// remaining_estimate predates adjust_estimate and sets the remaining
+	// estimate outright.
+	if remaining := req.GetString("remaining_estimate", ""); remaining != "" {
+		if mode != "" || newEstimate != "" {
+			return mcp.NewToolResultError("remaining_estimate cannot be combined with adjust_estimate or new_estimate"), nil
+		}
+		mode, newEstimate = "new", remaining
+	}
+	query, err := estimateQuery(mode, newEstimate, "reduceBy", req.GetString("reduce_by", ""))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	client, err := clients.GetJiraClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
+	}
+
+	payload := map[string]any{
+		"timeSpent": timeSpent,
+	}
+	if comment != "" {
+		payload["comment"] = comment
+	}
+	if started != "" {
+		payload["started"] = started
+	}
+	reqHttp, err := client.NewRequest(ctx, "POST", worklogURL(issueKey, "", query), "application/json", payload)
+	if err != nil {
+		return mcp.NewToolResultError("Failed to create HTTP request: " + err.Error()), nil
+	}
+	var structure any
+	resp, err := client.Call(reqHttp, &structure)
+	if err != nil {
+		return handlers.ToolError("Failed to add worklog", resp, err), nil
+	}
+	body := resp.Bytes.String()
+
+	// The original estimate is an issue field, not part of the worklog.
+	if originalEstimate != "" {
+		fields := map[string]any{"timetracking": map[string]any{"originalEstimate": originalEstimate}}
+		reqHttp, err := client.NewRequest(ctx, "PUT", fmt.Sprintf("rest/api/2/issue/%s", issueKey), "", map[string]any{"fields": fields})
+		if err != nil {
+			return mcp.NewToolResultError("Failed to create HTTP request: " + err.Error()), nil
+		}
+		if resp, err := client.Call(reqHttp, nil); err != nil {
+			return handlers.ToolError("Worklog added, but failed to set the original estimate", resp, err), nil
+		}
+	}
+	return mcp.NewToolResultText(body), nil
+}
+
+// Handler for jira_update_worklog
+func UpdateWorklogHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	issueKey := req.GetString("issue_key", "")
+	worklogID := req.GetString("worklog_id", "")
+	timeSpent := req.GetString("time_spent", "")
+	comment := req.GetString("comment", "")
+	started := req.GetString("started", "")
+	if issueKey == "" || worklogID == "" {
+		return mcp.NewToolResultError("Missing required parameters: issue_key and worklog_id are required"), nil
+	}
+	if timeSpent == "" && comment == "" && started == "" {
+		return mcp.NewToolResultError("Nothing to change: set time_spent, comment or started"), nil
+	}
+	if started != "" {
+		var err error
+		if started, err = utils.ParseJiraTime(started); err != nil {
+			return mcp.NewToolResultErrorFromErr("error parsing started:", err), nil
+		}
+	}
+	query, err := estimateQuery(req.GetString("adjust_estimate", ""), req.GetString("new_estimate", ""), "", "")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	client, err := clients.GetJiraClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
+	}
+	// Jira requires the time spent and start on every update, so carry over
+	// whichever the caller did not set.
+	current, resp, err := client.Issue.Worklog.Get(ctx, issueKey, worklogID, nil)
+	if err != nil {
+		return handlers.ToolError("Failed to get worklog", resp, err), nil
+	}
+	payload := map[string]any{"timeSpent": timeSpent, "started": started}
+	if timeSpent == "" {
+		payload["timeSpent"] = current.TimeSpent
+	}
+	if started == "" {
+		payload["started"] = current.Started
+	}
+	if comment != "" {
+		payload["comment"] = comment
+	}
+	reqHttp, err := client.NewRequest(ctx, "PUT", worklogURL(issueKey, worklogID, query), "application/json", payload)
+	if err != nil {
+		return mcp.NewToolResultError("Failed to create HTTP request: " + err.Error()), nil
+	}
+	resp, err = client.Call(reqHttp, nil)
+	if err != nil {
+		return handlers.ToolError("Failed to update worklog", resp, err), nil
+	}
+	return mcp.NewToolResultText(resp.Bytes.String()), nil
+}
+
+// Handler for jira_delete_worklog
+func DeleteWorklogHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	issueKey := req.GetString("issue_key", "")
+	worklogID := req.GetString("worklog_id", "")
+	if issueKey == "" || worklogID == "" {
+		return mcp.NewToolResultError("Missing required parameters: issue_key and worklog_id are required"), nil
+	}
+	query, err := estimateQuery(req.GetString("adjust_estimate", ""), req.GetString("new_estimate", ""), "increaseBy", req.GetString("increase_by", ""))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	client, err := clients.GetJiraClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
+	}
+	reqHttp, err := client.NewRequest(ctx, "DELETE", worklogURL(issueKey, worklogID, query), "", nil)
+	if err != nil {
+		return mcp.NewToolResultError("Failed to create HTTP request: " + err.Error()), nil
+	}
+	if resp, err := client.Call(reqHttp, nil); err != nil {
+		return handlers.ToolError("Failed to delete worklog", resp, err), nil
+	}
+	return mcp.NewToolResultText("Worklog deleted successfully"), nil
+}
+
+// timesheetGroup aggregates the work logged against one day, issue or epic.
+type timesheetGroup struct {
+	Key       string   `json:"key"`
+	Summary   string   `json:"summary,omitempty"`
+	Seconds   int      `json:"seconds"`
+	TimeSpent string   `json:"timeSpent"`
+	Hours     float64  `json:"hours"`
+	Entries   int      `json:"entries"`
+	Issues    []string `json:"issues,omitempty"`
+}
+
+// Handler for jira_timesheet
+func TimesheetHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	user := strings.TrimSpace(req.GetString("user", ""))
+	groupBy := strings.ToLower(req.GetString("group_by", "day"))
+	maxIssues := min(max(req.GetInt("max_issues", 100), 1), config.Current().Bulk.MaxIssues)
+	since := req.GetString("since", "")
+	if since == "" {
+		return mcp.NewToolResultError("Missing required parameter: since"), nil
+	}
+	if groupBy != "day" && groupBy != "issue" && groupBy != "epic" {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid group_by %q: must be day, issue or epic", groupBy)), nil
+	}
+	until := req.GetString("until", "")
+	if until == "" {
+		until = time.Now().Format("2006-01-02")
+	}
+	from, to, err := dayRange(since, until)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	client, err := clients.GetJiraClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError("Jira client error: " + err.Error()), nil
+	}
+	resolver, err := newUserResolver(ctx, client)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	// The search names the author as the instance expects; the worklogs are
+	// then filtered against the same user.
+	var author jiraUser
+	authorJQL := "currentUser()"
+	if user == "" {
+		me, resp, err := client.MySelf.Details(ctx, nil)
+		if err != nil {
+			return handlers.ToolError("Failed to get current user", resp, err), nil
+		}
+		author = jiraUser{Name: me.Name, Key: me.Key, AccountID: me.AccountID, DisplayName: me.DisplayName}
+	} else {
+		if author, err = resolver.resolve(ctx, user, ""); err != nil {
+			return mcp.NewToolResultError("Invalid user: " + err.Error()), nil
+		}
+		authorJQL = jql.Quote(resolver.id(author))
+	}
+
+	fields := []string{"summary", "issuetype"}
+	var epics *epicFinder
+	if groupBy == "epic" {
+		h, err := getHierarchyFields(ctx, client)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		epics = &epicFinder{client: client, h: h, epics: map[string]string{}}
+		fields = h.searchFields()
+	}
+	query := jql.And(req.GetString("jql", ""),
+		"worklogAuthor = "+authorJQL,
+		"worklogDate >= "+jql.Quote(from),
+		"worklogDate <= "+jql.Quote(to))
+	issues, err := searchRawIssues(ctx, client, query, fields, maxIssues)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	groups := map[string]*timesheetGroup{}
+	total := 0
+	for _, issue := range issues {
+		logs, resp, err := getAllWorklogs(ctx, client, issue.Key)
+		if err != nil {
+			return handlers.ToolError("Failed to get worklogs for "+issue.Key, resp, err), nil
+		}
+		// The search only narrows the issues; a matching issue also carries
+		// other people's work and work outside the range.
+		for _, l := range logs {
+			day, err := worklogDay(l)
+			if err != nil || day < from || day > to || !worklogAuthorIs(l, author) {
+				continue
+			}
+			key, summary := day, ""
+			switch groupBy {
+			case "issue":
+				key, summary = issue.Key, fmt.Sprint(issue.Fields["summary"])
+			case "epic":
+				if key, err = epics.epic(ctx, issue); err != nil {
+					return mcp.NewToolResultError(err.Error()), nil
+				}
+			}
+			g := groups[key]
+			if g == nil {
+				g = &timesheetGroup{Key: key, Summary: summary}
+				groups[key] = g
+			}
+			g.Seconds += l.TimeSpentSeconds
+			g.Entries++
+			if groupBy != "issue" && !slices.Contains(g.Issues, issue.Key) {
+				g.Issues = append(g.Issues, issue.Key)
+			}
+			total += l.TimeSpentSeconds
+		}
+	}
+
+	result := make([]*timesheetGroup, 0, len(groups))
+	for _, g := range groups {
+		g.TimeSpent, g.Hours = formatDuration(g.Seconds), hours(g.Seconds)
+		result = append(result, g)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		// Work without an epic sorts last.
+		if (result[i].Key == "") != (result[j].Key == "") {
+			return result[j].Key == ""
+		}
+		return result[i].Key < result[j].Key
+	})
+	out, _ := json.Marshal(map[string]any{
+		"user":         resolver.id(author),
+		"since":        from,
+		"until":        to,
+		"groupBy":      groupBy,
+		"jql":          query,
+		"totalSeconds": total,
+		"timeSpent":    formatDuration(total),
+		"hours":        hours(total),
+		"groups":       result,
+	})
+	return mcp.NewToolResultText(string(out)), nil
+}
@@ -0,0 +1,20 @@
+package handlers
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"mcp-atlassian-server/pkg/config"
+)
+
+// Limit returns the "limit" argument of req, falling back to def when it is
+// missing or not positive, and capped at the configured limits.max_results.
+func Limit(req mcp.CallToolRequest, def int) int {
+	limit := req.GetInt("limit", def)
+	if limit < 1 {
+		limit = def
+	}
+	if max := config.Current().Limits.MaxResults; max > 0 && limit > max {
+		limit = max
+	}
+	return limit
+}
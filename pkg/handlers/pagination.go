@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"mcp-atlassian-server/pkg/config"
+)
+
+// Pager holds the paging arguments of a list tool: "limit", the tool's
+// offset argument, "cursor" and "fetch_all".
+type Pager struct {
+	Start    int
+	Limit    int
+	FetchAll bool
+	scope    string
+}
+
+// cursor is what a nextCursor token encodes: where the next page starts and
+// a hash of the query it belongs to.
+type cursor struct {
+	Start int    `json:"s"`
+	Scope string `json:"q"`
+}
+
+// NewPager reads the paging arguments of req. offsetArg names the tool's
+// offset argument, such as "start_at", and scope identifies the query so that
+// a cursor is not reused for a different one. A cursor takes precedence over
+// the offset.
+func NewPager(req mcp.CallToolRequest, def int, offsetArg, scope string) (*Pager, error) {
+	p := &Pager{
+		Start:    max(req.GetInt(offsetArg, 0), 0),
+		Limit:    Limit(req, def),
+		FetchAll: req.GetBool("fetch_all", false),
+		scope:    scopeHash(scope),
+	}
+	token := req.GetString("cursor", "")
+	if token == "" {
+		return p, nil
+	}
+	var c cursor
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err == nil {
+		err = json.Unmarshal(raw, &c)
+	}
+	if err != nil || c.Start < 0 {
+		return nil, errors.New("invalid cursor: pass the nextCursor of a previous response unchanged")
+	}
+	if c.Scope != p.scope {
+		return nil, errors.New("cursor belongs to a different query: omit it to start from the first page")
+	}
+	p.Start = c.Start
+	return p, nil
+}
+
+func scopeHash(scope string) string {
+	h := fnv.New32a()
+	h.Write([]byte(scope))
+	return strconv.FormatUint(uint64(h.Sum32()), 36)
+}
+
+// cursor returns the token that continues the query at start.
+func (p *Pager) cursor(start int) string {
+	raw, _ := json.Marshal(cursor{Start: start, Scope: p.scope})
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// PageFunc fetches limit items from start. It returns the decoded response,
+// which holds the items under the key given to Collect, and the total number
+// of items the query matches.
+type PageFunc func(start, limit int) (body map[string]any, total int, err error)
+
+// Collect fetches the page at p.Start or, with fetch_all, every page from
+// there until the total or limits.max_fetch_all is reached. It returns the
+// first response with the items of all pages under key and these fields set:
+// startAt, maxResults, total, returned, isLast, and nextCursor when there is
+// more to fetch.
+func (p *Pager) Collect(key string, fetch PageFunc) (map[string]any, error) {
+	size, ceiling := p.Limit, config.Current().Limits.MaxFetchAll
+	if p.FetchAll {
+		size = max(size, config.Current().Limits.MaxResults)
+	}
+	body, total, err := fetch(p.Start, size)
+	if err != nil {
+		return nil, err
+	}
+	items, _ := body[key].([]any)
+	for p.FetchAll && len(items) < ceiling && p.Start+len(items) < total {
+		more, t, err := fetch(p.Start+len(items), min(size, ceiling-len(items)))
+		if err != nil {
+			return nil, err
+		}
+		page, _ := more[key].([]any)
+		if len(page) == 0 {
+			break
+		}
+		items, total = append(items, page...), t
+	}
+	if p.FetchAll && len(items) > ceiling {
+		items = items[:ceiling]
+	}
+	if items == nil {
+		items = []any{}
+	}
+	next := p.Start + len(items)
+	body[key] = items
+	body["startAt"] = p.Start
+	body["maxResults"] = size
+	body["total"] = total
+	body["returned"] = len(items)
+	body["isLast"] = next >= total || len(items) == 0
+	if !body["isLast"].(bool) {
+		body["nextCursor"] = p.cursor(next)
+		if p.FetchAll {
+			body["note"] = fmt.Sprintf("fetch_all stopped after %d of %d items (limits.max_fetch_all); pass nextCursor to continue", len(items), total)
+		}
+	}
+	return body, nil
+}
+
+// DecodePage decodes a JSON object returned by a paged endpoint.
+func DecodePage(data []byte) (map[string]any, error) {
+	var body map[string]any
+	if err := json.Unmarshal(data, &body); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return body, nil
+}
+
+// IntField reads a numeric field of a decoded response, or of one returned
+// by Collect, or def when it is missing.
+func IntField(body map[string]any, name string, def int) int {
+	switch n := body[name].(type) {
+	case float64:
+		return int(n)
+	case int:
+		return n
+	}
+	return def
+}
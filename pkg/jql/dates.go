@@ -0,0 +1,100 @@
+package jql
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	lastNRe    = regexp.MustCompile(`^(?:last|past)\s+(\d+)\s+(minute|hour|day|week|month|year)s?$`)
+	boundRe    = regexp.MustCompile(`^(since|after|before|on)\s+(\S+)$`)
+	betweenRe  = regexp.MustCompile(`^(?:between\s+)?(\S+)\s*(?:and|\.\.|to)\s*(\S+)$`)
+	periodUnit = map[string]string{"week": "Week", "month": "Month", "year": "Year"}
+	// Relative dates in JQL use m for minutes and M for months.
+	durationUnit = map[string]string{"minute": "m", "hour": "h", "day": "d", "week": "w", "month": "M", "year": "y"}
+)
+
+// DateClause turns a period such as "today", "last week", "last 7 days",
+// "since 2024-03-01", "on 2024-03-01" or "2024-03-01..2024-03-31" into a
+// condition on a date field. Dated bounds include the whole day.
+func DateClause(field, period string) (string, error) {
+	p := strings.ToLower(strings.Join(strings.Fields(period), " "))
+	from, to, err := dateRange(p)
+	if err != nil {
+		return "", err
+	}
+	var clauses []string
+	if from != "" {
+		clauses = append(clauses, field+" >= "+from)
+	}
+	if to != "" {
+		clauses = append(clauses, field+" < "+to)
+	}
+	return strings.Join(clauses, " AND "), nil
+}
+
+// dateRange returns the JQL start (inclusive) and end (exclusive) of a period.
+func dateRange(p string) (from, to string, err error) {
+	switch p {
+	case "today":
+		return "startOfDay()", "", nil
+	case "yesterday":
+		return "startOfDay(-1)", "startOfDay()", nil
+	}
+	if which, unit, ok := strings.Cut(p, " "); ok && periodUnit[unit] != "" {
+		switch which {
+		case "this":
+			return "startOf" + periodUnit[unit] + "()", "", nil
+		case "last", "previous":
+			return "startOf" + periodUnit[unit] + "(-1)", "startOf" + periodUnit[unit] + "()", nil
+		}
+	}
+	if m := lastNRe.FindStringSubmatch(p); m != nil {
+		return Quote("-" + m[1] + durationUnit[m[2]]), "", nil
+	}
+	if m := boundRe.FindStringSubmatch(p); m != nil {
+		day, err := parseDay(m[2])
+		if err != nil {
+			return "", "", err
+		}
+		switch m[1] {
+		case "since":
+			return dayString(day), "", nil
+		case "after":
+			return dayString(day.AddDate(0, 0, 1)), "", nil
+		case "before":
+			return "", dayString(day), nil
+		}
+		return dayString(day), dayString(day.AddDate(0, 0, 1)), nil
+	}
+	if m := betweenRe.FindStringSubmatch(p); m != nil {
+		start, err := parseDay(m[1])
+		if err != nil {
+			return "", "", err
+		}
+		end, err := parseDay(m[2])
+		if err != nil {
+			return "", "", err
+		}
+		return dayString(start), dayString(end.AddDate(0, 0, 1)), nil
+	}
+	if day, err := parseDay(p); err == nil {
+		return dayString(day), dayString(day.AddDate(0, 0, 1)), nil
+	}
+	return "", "", fmt.Errorf("unknown period %q: use today, yesterday, this week, last month, last 7 days, since 2024-03-01, before 2024-03-01, on 2024-03-01 or 2024-03-01..2024-03-31", p)
+}
+
+func parseDay(s string) (time.Time, error) {
+	for _, layout := range []string{"2006-01-02", "2006/01/02"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q: use YYYY-MM-DD", s)
+}
+
+func dayString(t time.Time) string {
+	return Quote(t.Format("2006-01-02"))
+}
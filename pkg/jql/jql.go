@@ -0,0 +1,155 @@
+// Package jql parses, validates and builds Jira Query Language queries.
+package jql
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Error is a problem at a position of a query. Pos is the 1-based character
+// position in the query.
+type Error struct {
+	Pos         int      `json:"position"`
+	Message     string   `json:"message"`
+	Suggestions []string `json:"suggestions,omitempty"`
+}
+
+func newError(src string, offset int, format string, args ...any) *Error {
+	return &Error{Pos: column(src, offset), Message: fmt.Sprintf(format, args...)}
+}
+
+// column converts a byte offset into a 1-based character position.
+func column(src string, offset int) int {
+	return utf8.RuneCountInString(src[:offset]) + 1
+}
+
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("position %d: %s", e.Pos, e.Message)
+	if len(e.Suggestions) > 0 {
+		msg += "; did you mean " + strings.Join(e.Suggestions, " or ") + "?"
+	}
+	return msg
+}
+
+// Errors lists every problem found in a query.
+type Errors []*Error
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Quote returns s as a double-quoted JQL string, escaping quotes, backslashes
+// and line breaks.
+func Quote(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+	return `"` + r.Replace(s) + `"`
+}
+
+// In returns a clause matching field against values: field = "v" for one
+// value, field in ("a", "b") for several.
+func In(field string, values ...string) string {
+	if len(values) == 1 {
+		return field + " = " + Quote(values[0])
+	}
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = Quote(v)
+	}
+	return field + " in (" + strings.Join(quoted, ", ") + ")"
+}
+
+// SplitOrderBy splits a query into its condition and its ORDER BY clause,
+// which keeps its leading "ORDER BY". Unlike a text search it ignores ORDER
+// BY inside quoted values and parentheses.
+func SplitOrderBy(query string) (where, orderBy string) {
+	toks, _ := lex(query)
+	depth := 0
+	for i, t := range toks {
+		switch {
+		case t.kind == tokLParen:
+			depth++
+		case t.kind == tokRParen:
+			depth--
+		case depth == 0 && t.is("ORDER") && i+1 < len(toks) && toks[i+1].is("BY"):
+			return strings.TrimSpace(query[:t.start]), strings.TrimSpace(query[t.start:])
+		}
+	}
+	return strings.TrimSpace(query), ""
+}
+
+// And restricts query with further clauses, keeping any ORDER BY last.
+func And(query string, clauses ...string) string {
+	where, orderBy := SplitOrderBy(query)
+	if where != "" {
+		clauses = append([]string{"(" + where + ")"}, clauses...)
+	}
+	out := strings.Join(clauses, " AND ")
+	if orderBy != "" {
+		out = strings.TrimSpace(out + " " + orderBy)
+	}
+	return out
+}
+
+// closest returns up to three candidates near word: those it is a prefix of,
+// or within a small edit distance, best first.
+func closest(word string, candidates []string) []string {
+	word = strings.ToLower(word)
+	limit := 1 + len(word)/3
+	type scored struct {
+		s string
+		d int
+	}
+	var found []scored
+	seen := map[string]bool{}
+	for _, c := range candidates {
+		lc := strings.ToLower(c)
+		if seen[lc] {
+			continue
+		}
+		seen[lc] = true
+		d := editDistance(word, lc)
+		if strings.HasPrefix(lc, word) && word != "" {
+			d = min(d, 1)
+		}
+		if d <= limit {
+			found = append(found, scored{c, d})
+		}
+	}
+	for i := 1; i < len(found); i++ {
+		for j := i; j > 0 && found[j].d < found[j-1].d; j-- {
+			found[j], found[j-1] = found[j-1], found[j]
+		}
+	}
+	var out []string
+	for i := 0; i < len(found) && i < 3; i++ {
+		out = append(out, found[i].s)
+	}
+	return out
+}
+
+// editDistance is the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur := make([]int, len(rb)+1)
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,190 @@
+package jql
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"", ""},
+		{"ORDER BY created DESC", "ORDER BY created DESC"},
+		{"project = PROJ and status = 'To Do' order by key", `project = PROJ AND status = "To Do" ORDER BY key`},
+		{`a = 1 OR b = 2 AND NOT c = 3`, `a = 1 OR b = 2 AND NOT c = 3`},
+		{`(a = 1 OR b = 2) && !(c ~ "x" || d != y)`, `(a = 1 OR b = 2) AND NOT (c ~ "x" OR d != y)`},
+		{`assignee in (currentUser(), membersOf("jira users")) and cf[10014] is not EMPTY`, `assignee in (currentUser(), membersOf("jira users")) AND cf[10014] is not EMPTY`},
+		{`"Epic Link" = PROJ-1 AND created >= -1w`, `"Epic Link" = PROJ-1 AND created >= -1w`},
+		{`status WAS NOT IN (Open, "In Progress") BY jdoe DURING ("2024/01/01", endOfMonth())`, `status was not in (Open, "In Progress") by jdoe during ("2024/01/01", endOfMonth())`},
+		{`status changed FROM Open TO Done after startOfWeek(-1)`, `status changed from Open to Done after startOfWeek(-1)`},
+		{`summary ~ "say \"hi\"" AND issue.property[review].state = done`, `summary ~ "say \"hi\"" AND issue.property[review].state = done`},
+		{`issue in linkedIssues(PROJ-1, "is blocked by")`, `issue in linkedIssues(PROJ-1, "is blocked by")`},
+		{`summary ~ "order by" ORDER BY key ASC, priority`, `summary ~ "order by" ORDER BY key ASC, priority`},
+		{`labels = voilà`, `labels = voilà`},
+		{`labels = Рома AND reporter = Łukasz`, `labels = Рома AND reporter = Łukasz`},
+		{`labels in (été, 日本語) and summary ~ "naïve"`, `labels in (été, 日本語) AND summary ~ "naïve"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			q, err := Parse(tt.query)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if got := q.String(); got != tt.want {
+				t.Errorf("String() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		query   string
+		pos     int
+		message string
+		suggest []string
+	}{
+		{"status in (Open", 11, "unbalanced (", nil},
+		{"(status = Open", 1, "unbalanced (", nil},
+		{"status = Open)", 14, "unbalanced )", nil},
+		{"status = In Progress", 10, "expected a value after =, found the keyword IN", []string{`"In Progress"`}},
+		{"status = Code Review", 15, `expected AND, OR or ORDER BY, found "Review"`, []string{`"Code Review"`}},
+		{"status = Open ADN type = Bug", 15, `found "ADN"`, []string{"AND"}},
+		{"status iss EMPTY", 8, "expected an operator after status", []string{"is"}},
+		{"Epic Link = PROJ-1", 6, "expected an operator after Epic", []string{`"Epic Link"`}},
+		{"status is Done", 11, "expected EMPTY or NULL after IS", nil},
+		{"status in Done", 11, "expected a list in parentheses after IN", nil},
+		{"status = ", 10, "expected a value after =, found the end of the query", nil},
+		{"summary ~ 'open", 11, "unterminated string", nil},
+		{"status = Open ORDER key", 21, "expected BY after ORDER", nil},
+		{"AND status = Open", 1, "expected a field name, found the keyword AND", nil},
+		{"é = 1 é", 7, `found "é"`, nil},
+		{"labels = Рома Рома", 15, `found "Рома"`, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			_, err := Parse(tt.query)
+			var e *Error
+			if !errors.As(err, &e) {
+				t.Fatalf("Parse() error = %v, want *Error", err)
+			}
+			if e.Pos != tt.pos || !strings.Contains(e.Message, tt.message) {
+				t.Errorf("error = %v, want position %d: %s", e, tt.pos, tt.message)
+			}
+			for _, s := range tt.suggest {
+				if !strings.Contains(strings.Join(e.Suggestions, "|"), s) {
+					t.Errorf("suggestions = %q, want %s", e.Suggestions, s)
+				}
+			}
+		})
+	}
+}
+
+func TestAnd(t *testing.T) {
+	tests := []struct {
+		query   string
+		clauses []string
+		want    string
+	}{
+		{"", []string{`project = "PROJ"`}, `project = "PROJ"`},
+		{"status = Done", []string{`project = "PROJ"`}, `(status = Done) AND project = "PROJ"`},
+		{"status = Done order by key", []string{"a = 1", "b = 2"}, `(status = Done) AND a = 1 AND b = 2 order by key`},
+		{"ORDER BY created DESC", []string{"a = 1"}, `a = 1 ORDER BY created DESC`},
+		{`summary ~ "order by" OR (x = 1 ORDER BY y)`, []string{"a = 1"}, `(summary ~ "order by" OR (x = 1 ORDER BY y)) AND a = 1`},
+	}
+	for _, tt := range tests {
+		if got := And(tt.query, tt.clauses...); got != tt.want {
+			t.Errorf("And(%q) = %s, want %s", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestQuoteAndIn(t *testing.T) {
+	if got := Quote("say \"hi\"\\\n"); got != `"say \"hi\"\\\n"` {
+		t.Errorf("Quote() = %s", got)
+	}
+	if got := In("project", "PROJ"); got != `project = "PROJ"` {
+		t.Errorf("In() = %s", got)
+	}
+	if got := In("labels", "a", `b"c`); got != `labels in ("a", "b\"c")` {
+		t.Errorf("In() = %s", got)
+	}
+	q, err := Parse(In("summary", `it's "quoted"`))
+	if err != nil || q.String() != `summary = "it's \"quoted\""` {
+		t.Errorf("quoted value does not round trip: %v, %v", q, err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	a := &Autocomplete{
+		Fields: []AutocompleteField{
+			{Value: "status", DisplayName: "Status", Orderable: "true", Operators: []string{"=", "!=", "in", "not in", "is", "is not", "was"}},
+			{Value: "summary", DisplayName: "Summary", Orderable: "true", Operators: []string{"~", "!~", "is", "is not"}},
+			{Value: "text", DisplayName: "text", Operators: []string{"~"}},
+			{Value: `"Epic Link"`, DisplayName: "Epic Link - cf[10014]", CFID: "cf[10014]", Orderable: "true", Operators: []string{"=", "in"}},
+		},
+		Functions:     []AutocompleteFunction{{Value: "currentUser()"}, {Value: `membersOf("")`}},
+		ReservedWords: []string{"and", "limit", "select"},
+	}
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{`status = Done AND "epic link" = PROJ-1 AND cf[10014] in (PROJ-1) ORDER BY "Epic Link"`, nil},
+		{`issue.property[review].state = done`, nil},
+		{`stauts = Done`, []string{`position 1: unknown field stauts; did you mean status?`}},
+		{`summary = login`, []string{`position 9: operator = cannot be used with summary; it supports ~, !~, is, is not; did you mean ~?`}},
+		{`status in (membersof(x), currentUsr())`, []string{"position 26: unknown function currentUsr(); did you mean currentUser()?"}},
+		{`status = limit`, []string{`position 10: limit is a reserved word, quote it; did you mean "limit"?`}},
+		{`text ~ x ORDER BY text`, []string{"position 19: cannot order by text"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			q, err := Parse(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			errs := Validate(q, a)
+			if len(errs) != len(tt.want) {
+				t.Fatalf("Validate() = %v, want %d errors", errs, len(tt.want))
+			}
+			for i, want := range tt.want {
+				if errs[i].Error() != want {
+					t.Errorf("error %d = %s, want %s", i, errs[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestDateClause(t *testing.T) {
+	tests := []struct {
+		period string
+		want   string
+	}{
+		{"today", "created >= startOfDay()"},
+		{"Yesterday", "created >= startOfDay(-1) AND created < startOfDay()"},
+		{"this week", "created >= startOfWeek()"},
+		{"last month", "created >= startOfMonth(-1) AND created < startOfMonth()"},
+		{"last 7 days", `created >= "-7d"`},
+		{"past 2 months", `created >= "-2M"`},
+		{"since 2024-03-01", `created >= "2024-03-01"`},
+		{"after 2024-03-31", `created >= "2024-04-01"`},
+		{"before 2024/03/01", `created < "2024-03-01"`},
+		{"2024-03-01", `created >= "2024-03-01" AND created < "2024-03-02"`},
+		{"2024-03-01..2024-03-31", `created >= "2024-03-01" AND created < "2024-04-01"`},
+		{"between 2024-03-01 and 2024-03-02", `created >= "2024-03-01" AND created < "2024-03-03"`},
+	}
+	for _, tt := range tests {
+		got, err := DateClause("created", tt.period)
+		if err != nil || got != tt.want {
+			t.Errorf("DateClause(%q) = %s, %v, want %s", tt.period, got, err, tt.want)
+		}
+	}
+	if _, err := DateClause("created", "fortnight"); err == nil || !strings.Contains(err.Error(), `unknown period "fortnight"`) {
+		t.Errorf("DateClause(fortnight) error = %v", err)
+	}
+}
@@ -0,0 +1,161 @@
+package jql
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokEOF    tokenKind = iota
+	tokWord             // unquoted field, value, keyword or function name
+	tokString           // quoted string, Value holds it unescaped
+	tokOp               // =, !=, ~, !~, <, <=, >, >=
+	tokLParen
+	tokRParen
+	tokComma
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+	start int // byte offsets into the query
+	end   int
+}
+
+// is reports whether t is one of the unquoted keywords, ignoring case.
+func (t token) is(keywords ...string) bool {
+	if t.kind != tokWord {
+		return false
+	}
+	for _, k := range keywords {
+		if strings.EqualFold(t.value, k) {
+			return true
+		}
+	}
+	return false
+}
+
+// wordBreaks are the characters that end an unquoted word.
+const wordBreaks = "\"'=!<>~(),&|"
+
+// lex splits a query into tokens. On an error it returns the tokens read so
+// far, so callers that only look for ORDER BY can still use them.
+func lex(src string) ([]token, *Error) {
+	var toks []token
+	for i := 0; i < len(src); {
+		c := src[i]
+		r, width := utf8.DecodeRuneInString(src[i:])
+		switch {
+		case unicode.IsSpace(r):
+			i += width
+		case c == '(' || c == ')' || c == ',':
+			kind := map[byte]tokenKind{'(': tokLParen, ')': tokRParen, ',': tokComma}[c]
+			toks = append(toks, token{kind: kind, value: string(c), start: i, end: i + 1})
+			i++
+		case c == '"' || c == '\'':
+			value, end, ok := scanString(src, i)
+			if !ok {
+				return toks, newError(src, i, "unterminated string, add a closing %c", c)
+			}
+			toks = append(toks, token{kind: tokString, value: value, start: i, end: end})
+			i = end
+		case c == '&' || c == '|':
+			end := i + 1
+			if end < len(src) && src[end] == c {
+				end++
+			}
+			toks = append(toks, token{kind: tokWord, value: map[byte]string{'&': "AND", '|': "OR"}[c], start: i, end: end})
+			i = end
+		case strings.IndexByte("=!<>~", c) >= 0:
+			end := i + 1
+			if end < len(src) && (src[end] == '=' || (c == '!' && src[end] == '~')) && c != '=' && c != '~' {
+				end++
+			}
+			op := src[i:end]
+			if op == "!" {
+				toks = append(toks, token{kind: tokWord, value: "NOT", start: i, end: end})
+			} else {
+				toks = append(toks, token{kind: tokOp, value: op, start: i, end: end})
+			}
+			i = end
+		default:
+			value, end, err := scanWord(src, i)
+			if err != nil {
+				return toks, err
+			}
+			toks = append(toks, token{kind: tokWord, value: value, start: i, end: end})
+			i = end
+		}
+	}
+	return append(toks, token{kind: tokEOF, start: len(src), end: len(src)}), nil
+}
+
+// scanString reads the string quoted by src[start], resolving backslash
+// escapes.
+func scanString(src string, start int) (string, int, bool) {
+	quote := src[start]
+	var b strings.Builder
+	for i := start + 1; i < len(src); i++ {
+		switch c := src[i]; {
+		case c == quote:
+			return b.String(), i + 1, true
+		case c == '\\' && i+1 < len(src):
+			i++
+			b.WriteByte(unescape(src[i]))
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", len(src), false
+}
+
+func unescape(c byte) byte {
+	switch c {
+	case 'n':
+		return '\n'
+	case 't':
+		return '\t'
+	case 'r':
+		return '\r'
+	}
+	return c
+}
+
+// scanWord reads an unquoted word. Brackets belong to the word, as in
+// cf[10010] or issue.property[review].state, and a backslash escapes the
+// next character.
+func scanWord(src string, start int) (string, int, *Error) {
+	var b strings.Builder
+	i := start
+	for i < len(src) {
+		c := src[i]
+		r, width := utf8.DecodeRuneInString(src[i:])
+		switch {
+		case unicode.IsSpace(r) || strings.IndexByte(wordBreaks, c) >= 0:
+			return b.String(), i, nil
+		case c == '\\' && i+1 < len(src):
+			if src[i+1] < utf8.RuneSelf {
+				b.WriteByte(unescape(src[i+1]))
+				i += 2
+				break
+			}
+			_, n := utf8.DecodeRuneInString(src[i+1:])
+			b.WriteString(src[i+1 : i+1+n])
+			i += 1 + n
+		case c == '[':
+			end := strings.IndexByte(src[i:], ']')
+			if end < 0 {
+				return "", 0, newError(src, i, "unclosed [, add a closing ]")
+			}
+			b.WriteString(src[i : i+end+1])
+			i += end + 1
+		default:
+			b.WriteString(src[i : i+width])
+			i += width
+		}
+	}
+	return b.String(), i, nil
+}
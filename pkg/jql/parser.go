@@ -0,0 +1,584 @@
+package jql
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Query is a parsed JQL query. Where is nil when the query only orders.
+type Query struct {
+	Where   Clause
+	OrderBy []OrderField
+}
+
+// Clause is one of *AndClause, *OrClause, *NotClause or *Terminal.
+type Clause interface {
+	String() string
+}
+
+// AndClause matches issues matching all of its clauses.
+type AndClause struct{ Clauses []Clause }
+
+// OrClause matches issues matching any of its clauses.
+type OrClause struct{ Clauses []Clause }
+
+// NotClause matches issues not matching its clause.
+type NotClause struct{ Clause Clause }
+
+// Field is a field reference, such as status, cf[10010] or "Epic Link".
+type Field struct {
+	Name   string
+	Quoted bool
+	Pos    int
+}
+
+// Terminal compares a field with an operand, such as status = Done. Operator
+// is lower case; Operand is nil for CHANGED without a value.
+type Terminal struct {
+	Field      Field
+	Operator   string
+	OpPos      int
+	Operand    Operand
+	Predicates []Predicate
+}
+
+// Predicate qualifies a WAS or CHANGED clause, such as AFTER -1w.
+type Predicate struct {
+	Operator string
+	Operand  Operand
+}
+
+// Operand is one of *Value, *Empty, *List or *Function.
+type Operand interface {
+	String() string
+}
+
+// Value is a single value. Quoted values are re-quoted when rendered.
+type Value struct {
+	Text   string
+	Quoted bool
+	Pos    int
+}
+
+// Empty is EMPTY or NULL.
+type Empty struct{ Pos int }
+
+// List is a parenthesised list of operands.
+type List struct{ Values []Operand }
+
+// Function is a function call such as currentUser() or startOfDay(-1).
+type Function struct {
+	Name string
+	Args []*Value
+	Pos  int
+}
+
+// OrderField is one field of an ORDER BY clause. Direction is "", "ASC" or
+// "DESC".
+type OrderField struct {
+	Field     Field
+	Direction string
+}
+
+// reservedWords cannot be used unquoted as field names or values.
+var reservedWords = []string{"AND", "OR", "NOT", "EMPTY", "NULL", "ORDER", "BY", "IN", "IS", "WAS", "CHANGED"}
+
+// operatorWords are the operators spelt as words, for suggestions.
+var operatorWords = []string{"in", "not in", "is", "is not", "was", "was in", "was not", "was not in", "changed"}
+
+var predicateWords = []string{"AFTER", "BEFORE", "ON", "DURING", "FROM", "TO", "BY"}
+
+type parser struct {
+	src  string
+	toks []token
+	i    int
+	last token // the last value read, to suggest quoting multi-word values
+}
+
+// Parse parses a query. Syntax errors are returned as an *Error with the
+// position of the offending token.
+func Parse(src string) (*Query, error) {
+	toks, lexErr := lex(src)
+	if lexErr != nil {
+		return nil, lexErr
+	}
+	p := &parser{src: src, toks: toks}
+	q, err := p.query()
+	if err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+func (p *parser) peek() token { return p.toks[p.i] }
+
+func (p *parser) next() token {
+	t := p.toks[p.i]
+	if t.kind != tokEOF {
+		p.i++
+	}
+	return t
+}
+
+func (p *parser) errorAt(t token, format string, args ...any) *Error {
+	return newError(p.src, t.start, format, args...)
+}
+
+func (p *parser) pos(t token) int { return column(p.src, t.start) }
+
+func describe(t token) string {
+	switch t.kind {
+	case tokEOF:
+		return "the end of the query"
+	case tokString:
+		return Quote(t.value)
+	}
+	return fmt.Sprintf("%q", t.value)
+}
+
+func (p *parser) query() (*Query, *Error) {
+	q := &Query{}
+	if t := p.peek(); t.kind != tokEOF && !t.is("ORDER") {
+		where, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		q.Where = where
+	}
+	if p.peek().is("ORDER") {
+		p.next()
+		if t := p.next(); !t.is("BY") {
+			return nil, p.errorAt(t, "expected BY after ORDER, found %s", describe(t))
+		}
+		for {
+			f, err := p.field()
+			if err != nil {
+				return nil, err
+			}
+			of := OrderField{Field: f}
+			if t := p.peek(); t.is("ASC", "DESC") {
+				of.Direction = strings.ToUpper(p.next().value)
+			}
+			q.OrderBy = append(q.OrderBy, of)
+			if p.peek().kind != tokComma {
+				break
+			}
+			p.next()
+		}
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, p.unexpected(t, "AND, OR or ORDER BY")
+	}
+	return q, nil
+}
+
+// unexpected reports a token found where a clause should have ended, with a
+// keyword it may be a typo of or the quoting a multi-word value needs.
+func (p *parser) unexpected(t token, expected string) *Error {
+	err := p.errorAt(t, "expected %s, found %s", expected, describe(t))
+	if t.kind == tokRParen {
+		err.Message = "unbalanced ), there is no ( to close"
+		return err
+	}
+	if t.kind != tokWord {
+		return err
+	}
+	if p.last.kind == tokWord && p.last.end < t.start {
+		text := p.src[p.last.start:t.end]
+		if !strings.ContainsAny(text, wordBreaks) {
+			err.Suggestions = append(err.Suggestions, Quote(text))
+		}
+	}
+	for _, k := range closest(t.value, []string{"AND", "OR", "ORDER BY"}) {
+		err.Suggestions = append(err.Suggestions, k)
+	}
+	return err
+}
+
+func (p *parser) or() (Clause, *Error) {
+	var clauses []Clause
+	for {
+		c, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		clauses = append(clauses, c)
+		if !p.peek().is("OR") {
+			break
+		}
+		p.next()
+	}
+	if len(clauses) == 1 {
+		return clauses[0], nil
+	}
+	return &OrClause{Clauses: clauses}, nil
+}
+
+func (p *parser) and() (Clause, *Error) {
+	var clauses []Clause
+	for {
+		c, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		clauses = append(clauses, c)
+		if !p.peek().is("AND") {
+			break
+		}
+		p.next()
+	}
+	if len(clauses) == 1 {
+		return clauses[0], nil
+	}
+	return &AndClause{Clauses: clauses}, nil
+}
+
+func (p *parser) not() (Clause, *Error) {
+	t := p.peek()
+	switch {
+	case t.is("NOT"):
+		p.next()
+		c, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		return &NotClause{Clause: c}, nil
+	case t.kind == tokLParen:
+		p.next()
+		c, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if end := p.peek(); end.kind != tokRParen {
+			if end.kind == tokEOF {
+				return nil, p.errorAt(t, "unbalanced (, add a closing )")
+			}
+			return nil, p.unexpected(end, "AND, OR or )")
+		}
+		p.next()
+		return c, nil
+	}
+	return p.terminal()
+}
+
+func (p *parser) field() (Field, *Error) {
+	t := p.next()
+	switch {
+	case t.kind == tokString:
+		return Field{Name: t.value, Quoted: true, Pos: p.pos(t)}, nil
+	case t.kind == tokWord && !t.is(reservedWords...):
+		return Field{Name: t.value, Pos: p.pos(t)}, nil
+	case t.kind == tokWord:
+		return Field{}, p.errorAt(t, "expected a field name, found the keyword %s", strings.ToUpper(t.value))
+	}
+	return Field{}, p.errorAt(t, "expected a field name, found %s", describe(t))
+}
+
+func (p *parser) terminal() (Clause, *Error) {
+	f, err := p.field()
+	if err != nil {
+		return nil, err
+	}
+	opTok := p.peek()
+	op, err := p.operator(f)
+	if err != nil {
+		return nil, err
+	}
+	c := &Terminal{Field: f, Operator: op, OpPos: p.pos(opTok)}
+	switch op {
+	case "changed":
+	case "in", "not in", "was in", "was not in":
+		t := p.peek()
+		if t.kind == tokLParen {
+			c.Operand, err = p.list()
+		} else if c.Operand, err = p.operand(op); err == nil {
+			if _, ok := c.Operand.(*Function); !ok {
+				return nil, p.errorAt(t, "expected a list in parentheses after %s, found %s", strings.ToUpper(op), describe(t))
+			}
+		}
+	case "is", "is not":
+		t := p.next()
+		if !t.is("EMPTY", "NULL") {
+			return nil, p.errorAt(t, "expected EMPTY or NULL after %s, found %s", strings.ToUpper(op), describe(t))
+		}
+		c.Operand = &Empty{Pos: p.pos(t)}
+	default:
+		c.Operand, err = p.operand(op)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if strings.HasPrefix(op, "was") || op == "changed" {
+		if c.Predicates, err = p.predicates(); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+func (p *parser) operator(f Field) (string, *Error) {
+	t := p.next()
+	switch {
+	case t.kind == tokOp:
+		return t.value, nil
+	case t.is("IN", "CHANGED"):
+		return strings.ToLower(t.value), nil
+	case t.is("NOT"):
+		if n := p.next(); !n.is("IN") {
+			return "", p.errorAt(n, "expected IN after NOT, found %s", describe(n))
+		}
+		return "not in", nil
+	case t.is("IS"):
+		if p.peek().is("NOT") {
+			p.next()
+			return "is not", nil
+		}
+		return "is", nil
+	case t.is("WAS"):
+		op := "was"
+		if p.peek().is("NOT") {
+			p.next()
+			op += " not"
+		}
+		if p.peek().is("IN") {
+			p.next()
+			op += " in"
+		}
+		return op, nil
+	}
+	err := p.errorAt(t, "expected an operator after %s, found %s", f.Name, describe(t))
+	switch {
+	case t.kind != tokWord:
+	case !f.Quoted && (p.peek().kind == tokOp || p.peek().is("IN", "NOT", "IS", "WAS", "CHANGED")):
+		// A field name with spaces, such as Epic Link = X.
+		err.Suggestions = []string{Quote(f.Name + " " + t.value)}
+	default:
+		err.Suggestions = closest(t.value, operatorWords)
+	}
+	return "", err
+}
+
+// operand reads a value, EMPTY or a function call.
+func (p *parser) operand(op string) (Operand, *Error) {
+	t := p.next()
+	switch {
+	case t.kind == tokString:
+		p.last = t
+		return &Value{Text: t.value, Quoted: true, Pos: p.pos(t)}, nil
+	case t.is("EMPTY", "NULL"):
+		return &Empty{Pos: p.pos(t)}, nil
+	case t.kind == tokWord && p.peek().kind == tokLParen:
+		return p.function(t)
+	case t.kind == tokWord && !t.is(reservedWords...):
+		p.last = t
+		return &Value{Text: t.value, Pos: p.pos(t)}, nil
+	case t.kind == tokWord:
+		// Usually the first word of a value such as In Progress.
+		end := t.end
+		for j := p.i; p.toks[j].kind == tokWord && !p.toks[j].is("AND", "OR", "ORDER"); j++ {
+			end = p.toks[j].end
+		}
+		err := p.errorAt(t, "expected a value after %s, found the keyword %s", strings.ToUpper(op), strings.ToUpper(t.value))
+		err.Suggestions = []string{Quote(p.src[t.start:end])}
+		return nil, err
+	}
+	return nil, p.errorAt(t, "expected a value after %s, found %s", strings.ToUpper(op), describe(t))
+}
+
+func (p *parser) function(name token) (Operand, *Error) {
+	open := p.next()
+	f := &Function{Name: name.value, Pos: p.pos(name)}
+	p.last = token{}
+	if p.peek().kind == tokRParen {
+		p.next()
+		return f, nil
+	}
+	for {
+		t := p.next()
+		switch t.kind {
+		case tokWord, tokString:
+			f.Args = append(f.Args, &Value{Text: t.value, Quoted: t.kind == tokString, Pos: p.pos(t)})
+		case tokEOF:
+			return nil, p.errorAt(open, "unbalanced ( after %s, add a closing )", name.value)
+		default:
+			return nil, p.errorAt(t, "expected an argument of %s(), found %s", name.value, describe(t))
+		}
+		switch t := p.next(); t.kind {
+		case tokComma:
+		case tokRParen:
+			return f, nil
+		case tokEOF:
+			return nil, p.errorAt(open, "unbalanced ( after %s, add a closing )", name.value)
+		default:
+			return nil, p.errorAt(t, "expected , or ) in the arguments of %s(), found %s", name.value, describe(t))
+		}
+	}
+}
+
+func (p *parser) list() (Operand, *Error) {
+	open := p.next()
+	l := &List{}
+	for {
+		if p.peek().kind == tokEOF {
+			return nil, p.errorAt(open, "unbalanced (, add a closing )")
+		}
+		v, err := p.operand("IN")
+		if err != nil {
+			return nil, err
+		}
+		l.Values = append(l.Values, v)
+		switch t := p.next(); t.kind {
+		case tokComma:
+		case tokRParen:
+			p.last = token{}
+			return l, nil
+		case tokEOF:
+			return nil, p.errorAt(open, "unbalanced (, add a closing )")
+		default:
+			err := p.errorAt(t, "expected , or ) in the list, found %s", describe(t))
+			if t.kind == tokWord && p.last.kind == tokWord {
+				err.Suggestions = []string{Quote(p.src[p.last.start:t.end])}
+			}
+			return nil, err
+		}
+	}
+}
+
+func (p *parser) predicates() ([]Predicate, *Error) {
+	var preds []Predicate
+	for p.peek().is(predicateWords...) {
+		t := p.next()
+		pred := Predicate{Operator: strings.ToLower(t.value)}
+		if t.is("DURING") {
+			open := p.peek()
+			if open.kind != tokLParen {
+				return nil, p.errorAt(open, "expected (start, end) after DURING, found %s", describe(open))
+			}
+			l, err := p.list()
+			if err != nil {
+				return nil, err
+			}
+			if n := len(l.(*List).Values); n != 2 {
+				return nil, p.errorAt(open, "DURING takes two dates, found %d", n)
+			}
+			pred.Operand = l
+		} else {
+			v, err := p.operand(t.value)
+			if err != nil {
+				return nil, err
+			}
+			pred.Operand = v
+		}
+		preds = append(preds, pred)
+	}
+	return preds, nil
+}
+
+// String renders the query as JQL. Quoted names and values are re-quoted,
+// so the result is safe to combine with other clauses.
+func (q *Query) String() string {
+	var b strings.Builder
+	if q.Where != nil {
+		b.WriteString(q.Where.String())
+	}
+	if len(q.OrderBy) > 0 {
+		if b.Len() > 0 {
+			b.WriteString(" ")
+		}
+		b.WriteString("ORDER BY ")
+		for i, f := range q.OrderBy {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteString(f.Field.String())
+			if f.Direction != "" {
+				b.WriteString(" " + f.Direction)
+			}
+		}
+	}
+	return b.String()
+}
+
+func (f Field) String() string {
+	if f.Quoted {
+		return Quote(f.Name)
+	}
+	return f.Name
+}
+
+func (c *AndClause) String() string { return join(c.Clauses, " AND ", true) }
+
+func (c *OrClause) String() string { return join(c.Clauses, " OR ", false) }
+
+func join(clauses []Clause, sep string, groupOr bool) string {
+	parts := make([]string, len(clauses))
+	for i, c := range clauses {
+		parts[i] = c.String()
+		if _, isOr := c.(*OrClause); isOr && groupOr {
+			parts[i] = "(" + parts[i] + ")"
+		}
+	}
+	return strings.Join(parts, sep)
+}
+
+func (c *NotClause) String() string {
+	switch c.Clause.(type) {
+	case *AndClause, *OrClause:
+		return "NOT (" + c.Clause.String() + ")"
+	}
+	return "NOT " + c.Clause.String()
+}
+
+func (c *Terminal) String() string {
+	s := c.Field.String() + " " + c.Operator
+	if c.Operand != nil {
+		s += " " + c.Operand.String()
+	}
+	for _, p := range c.Predicates {
+		s += " " + p.Operator + " " + p.Operand.String()
+	}
+	return s
+}
+
+func (v *Value) String() string {
+	if v.Quoted {
+		return Quote(v.Text)
+	}
+	return v.Text
+}
+
+func (*Empty) String() string { return "EMPTY" }
+
+func (l *List) String() string {
+	parts := make([]string, len(l.Values))
+	for i, v := range l.Values {
+		parts[i] = v.String()
+	}
+	return "(" + strings.Join(parts, ", ") + ")"
+}
+
+func (f *Function) String() string {
+	args := make([]string, len(f.Args))
+	for i, a := range f.Args {
+		args[i] = a.String()
+	}
+	return f.Name + "(" + strings.Join(args, ", ") + ")"
+}
+
+// Walk calls fn for every terminal clause of c, depth first.
+func Walk(c Clause, fn func(*Terminal)) {
+	switch x := c.(type) {
+	case *AndClause:
+		for _, sub := range x.Clauses {
+			Walk(sub, fn)
+		}
+	case *OrClause:
+		for _, sub := range x.Clauses {
+			Walk(sub, fn)
+		}
+	case *NotClause:
+		Walk(x.Clause, fn)
+	case *Terminal:
+		fn(x)
+	}
+}
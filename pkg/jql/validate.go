@@ -0,0 +1,168 @@
+package jql
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Autocomplete is the reference data Jira returns from
+// /rest/api/2/jql/autocompletedata: the fields, functions and reserved words
+// of the instance.
+type Autocomplete struct {
+	Fields        []AutocompleteField    `json:"visibleFieldNames"`
+	Functions     []AutocompleteFunction `json:"visibleFunctionNames"`
+	ReservedWords []string               `json:"jqlReservedWords"`
+}
+
+// AutocompleteField is a field that can be searched. Jira sends its boolean
+// flags as strings.
+type AutocompleteField struct {
+	Value       string   `json:"value"`
+	DisplayName string   `json:"displayName"`
+	Orderable   string   `json:"orderable,omitempty"`
+	Searchable  string   `json:"searchable,omitempty"`
+	CFID        string   `json:"cfid,omitempty"`
+	Operators   []string `json:"operators"`
+	Types       []string `json:"types,omitempty"`
+}
+
+// AutocompleteFunction is a function that can be used as a value, such as
+// currentUser().
+type AutocompleteFunction struct {
+	Value       string   `json:"value"`
+	DisplayName string   `json:"displayName"`
+	IsList      string   `json:"isList,omitempty"`
+	Types       []string `json:"types,omitempty"`
+}
+
+// Name is the field name to write in a query, without quotes.
+func (f *AutocompleteField) Name() string {
+	return strings.Trim(f.Value, `"`)
+}
+
+// cfSuffix is the " - cf[10010]" Jira appends to custom field display names.
+var cfSuffix = regexp.MustCompile(`\s+-\s+cf\[\d+\]$`)
+
+// Field finds a field by its name, display name or cf[id] reference,
+// ignoring case.
+func (a *Autocomplete) Field(name string) *AutocompleteField {
+	name = strings.ToLower(name)
+	for i := range a.Fields {
+		f := &a.Fields[i]
+		if strings.ToLower(f.Name()) == name || strings.ToLower(f.CFID) == name ||
+			strings.ToLower(cfSuffix.ReplaceAllString(f.DisplayName, "")) == name {
+			return f
+		}
+	}
+	return nil
+}
+
+func (a *Autocomplete) fieldNames() []string {
+	names := make([]string, len(a.Fields))
+	for i := range a.Fields {
+		names[i] = a.Fields[i].Name()
+	}
+	return names
+}
+
+// functionName strips the arguments from a function such as membersOf("").
+func functionName(value string) string {
+	name, _, _ := strings.Cut(value, "(")
+	return name
+}
+
+func (a *Autocomplete) function(name string) *AutocompleteFunction {
+	for i := range a.Functions {
+		if strings.EqualFold(functionName(a.Functions[i].Value), name) {
+			return &a.Functions[i]
+		}
+	}
+	return nil
+}
+
+func (a *Autocomplete) reserved(word string) bool {
+	for _, w := range a.ReservedWords {
+		if strings.EqualFold(w, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks q against the fields, operators, functions and reserved
+// words of an instance. Entity properties, such as issue.property[x].y, are
+// not listed by Jira and are not checked.
+func Validate(q *Query, a *Autocomplete) Errors {
+	var errs Errors
+	fieldError := func(f Field, format string, suggest []string) {
+		errs = append(errs, &Error{Pos: f.Pos, Message: fmt.Sprintf(format, f.Name), Suggestions: suggest})
+	}
+	lookup := func(f Field) *AutocompleteField {
+		if strings.HasPrefix(strings.ToLower(f.Name), "issue.property") {
+			return nil
+		}
+		if !f.Quoted && a.reserved(f.Name) {
+			fieldError(f, "%s is a reserved word, quote it", []string{Quote(f.Name)})
+			return nil
+		}
+		field := a.Field(f.Name)
+		if field == nil {
+			fieldError(f, "unknown field %s", closest(f.Name, a.fieldNames()))
+		}
+		return field
+	}
+	var checkOperand func(o Operand)
+	checkOperand = func(o Operand) {
+		switch x := o.(type) {
+		case *Value:
+			if !x.Quoted && a.reserved(x.Text) {
+				errs = append(errs, &Error{Pos: x.Pos, Message: x.Text + " is a reserved word, quote it", Suggestions: []string{Quote(x.Text)}})
+			}
+		case *List:
+			for _, v := range x.Values {
+				checkOperand(v)
+			}
+		case *Function:
+			if a.function(x.Name) == nil {
+				names := make([]string, len(a.Functions))
+				for i, fn := range a.Functions {
+					names[i] = functionName(fn.Value) + "()"
+				}
+				errs = append(errs, &Error{Pos: x.Pos, Message: "unknown function " + x.Name + "()", Suggestions: closest(x.Name+"()", names)})
+			}
+		}
+	}
+	if q.Where != nil {
+		Walk(q.Where, func(t *Terminal) {
+			if field := lookup(t.Field); field != nil && len(field.Operators) > 0 && !containsFold(field.Operators, t.Operator) {
+				errs = append(errs, &Error{
+					Pos:         t.OpPos,
+					Message:     "operator " + strings.ToUpper(t.Operator) + " cannot be used with " + t.Field.Name + "; it supports " + strings.Join(field.Operators, ", "),
+					Suggestions: closest(t.Operator, field.Operators),
+				})
+			}
+			if t.Operand != nil {
+				checkOperand(t.Operand)
+			}
+			for _, p := range t.Predicates {
+				checkOperand(p.Operand)
+			}
+		})
+	}
+	for _, o := range q.OrderBy {
+		if field := lookup(o.Field); field != nil && field.Orderable != "true" {
+			fieldError(o.Field, "cannot order by %s", nil)
+		}
+	}
+	return errs
+}
+
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -22,6 +22,18 @@ func AddTools(s *server.MCPServer) {
 			mcp.Description("Maximum number of results (1-50)"),
 			mcp.DefaultNumber(10),
 		),
+		mcp.WithNumber("start",
+			mcp.Description("Starting index for pagination (0-based)"),
+			mcp.DefaultNumber(0),
+		),
+		mcp.WithString("cursor",
+			mcp.Description("nextCursor of a previous response, to get the next page"),
+			mcp.DefaultString(""),
+		),
+		mcp.WithBoolean("fetch_all",
+			mcp.Description("Fetch every page, up to the configured limits.max_fetch_all items"),
+			mcp.DefaultBool(false),
+		),
 		mcp.WithString("spaces_filter",
 			mcp.Description("(Optional) Comma-separated list of space keys to filter results by."),
 			mcp.DefaultString(""),
@@ -78,6 +90,14 @@ func AddTools(s *server.MCPServer) {
 			mcp.Description("Starting index for pagination (0-based)"),
 			mcp.DefaultNumber(0),
 		),
+		mcp.WithString("cursor",
+			mcp.Description("nextCursor of a previous response, to get the next page"),
+			mcp.DefaultString(""),
+		),
+		mcp.WithBoolean("fetch_all",
+			mcp.Description("Fetch every page, up to the configured limits.max_fetch_all items"),
+			mcp.DefaultBool(false),
+		),
 	), confluence.GetPageChildrenHandler)
 
 	s.AddTool(mcp.NewTool("confluence_get_comments",
@@ -0,0 +1,252 @@
+package confluence
+
+import (
+	"strings"
+	"testing"
+
+	"mcp-atlassian-server/pkg/fakeatlassian"
+)
+
+type toolTest struct {
+	name     string
+	tool     string
+	args     map[string]any
+	setup    func(t *testing.T, srv *fakeatlassian.Server)
+	wantErr  bool
+	contains []string
+	excludes []string
+	check    func(t *testing.T, srv *fakeatlassian.Server, out string)
+}
+
+func runToolTests(t *testing.T, tests []toolTest) {
+	t.Helper()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := fakeatlassian.New(t)
+			if tt.setup != nil {
+				tt.setup(t, srv)
+			}
+			c := fakeatlassian.NewMCPClient(t, AddTools)
+			out, isErr := fakeatlassian.CallTool(t, c, tt.tool, tt.args)
+			if isErr != tt.wantErr {
+				t.Fatalf("IsError = %v, want %v; output: %s", isErr, tt.wantErr, out)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(out, want) {
+					t.Errorf("output does not contain %q:\n%s", want, out)
+				}
+			}
+			for _, unwanted := range tt.excludes {
+				if strings.Contains(out, unwanted) {
+					t.Errorf("output unexpectedly contains %q:\n%s", unwanted, out)
+				}
+			}
+			if tt.check != nil {
+				tt.check(t, srv, out)
+			}
+		})
+	}
+}
+
+func TestReadTools(t *testing.T) {
+	runToolTests(t, []toolTest{
+		{
+			name:     "ping",
+			tool:     "confluence_ping",
+			contains: []string{"Confluence OK"},
+		},
+		{
+			name:     "ping with bad token",
+			tool:     "confluence_ping",
+			setup:    func(t *testing.T, _ *fakeatlassian.Server) { t.Setenv("CONFLUENCE_PERSONAL_TOKEN", "wrong") },
+			wantErr:  true,
+			contains: []string{"HTTP 401", "Hint: Authentication failed"},
+		},
+		{
+			name:     "search with spaces filter",
+			tool:     "confluence_search",
+			args:     map[string]any{"query": "title ~ \"Runbook\"", "spaces_filter": "DEV, OPS"},
+			contains: []string{`"title":"Runbook"`},
+			excludes: []string{"Team Home"},
+			check: func(t *testing.T, srv *fakeatlassian.Server, _ string) {
+				want := `(title ~ "Runbook") AND (space="DEV" OR space="OPS")`
+				if got := srv.LastSearch(); got != want {
+					t.Errorf("CQL = %q, want %q", got, want)
+				}
+			},
+		},
+		{
+			name:     "get page as markdown",
+			tool:     "confluence_get_page",
+			args:     map[string]any{"page_id": "1001", "include_metadata": false},
+			contains: []string{"# Welcome", "**"},
+			excludes: []string{"<h1>"},
+		},
+		{
+			name:     "get page with metadata",
+			tool:     "confluence_get_page",
+			args:     map[string]any{"page_id": "1001"},
+			contains: []string{`"title":"Team Home"`, `"number":3`},
+		},
+		{
+			name:     "get missing page",
+			tool:     "confluence_get_page",
+			args:     map[string]any{"page_id": "9999"},
+			wantErr:  true,
+			contains: []string{"Failed to retrieve page by ID: HTTP 404", "No content found"},
+		},
+		{
+			name:     "get page by title",
+			tool:     "confluence_get_page",
+			args:     map[string]any{"title": "Runbook", "space_key": "DEV"},
+			contains: []string{`"id":"1002"`},
+		},
+		{
+			name:     "get page by unknown title",
+			tool:     "confluence_get_page",
+			args:     map[string]any{"title": "Nope", "space_key": "DEV"},
+			wantErr:  true,
+			contains: []string{"Page with title 'Nope' not found in space 'DEV'"},
+		},
+		{
+			name:     "get page without identifiers",
+			tool:     "confluence_get_page",
+			wantErr:  true,
+			contains: []string{"Either 'page_id' OR both 'title' and 'space_key' must be provided."},
+		},
+		{
+			name:     "get page children",
+			tool:     "confluence_get_page_children",
+			args:     map[string]any{"parent_id": "1001"},
+			contains: []string{`"count":1`, `"title":"Runbook"`},
+		},
+		{
+			name:     "page children are counted when there are more",
+			tool:     "confluence_get_page_children",
+			setup:    addChildPages,
+			args:     map[string]any{"parent_id": "1001", "limit": 1},
+			contains: []string{`"total":3`, `"returned":1`, `"isLast":false`, `"nextCursor":`},
+			check: func(t *testing.T, srv *fakeatlassian.Server, _ string) {
+				if got := srv.Searches; len(got) != 1 || got[0] != "parent = 1001 AND type = page" {
+					t.Errorf("searches = %q, want one count of the children", got)
+				}
+			},
+		},
+		{
+			name:     "fetch all page children",
+			tool:     "confluence_get_page_children",
+			setup:    addChildPages,
+			args:     map[string]any{"parent_id": "1001", "limit": 1, "fetch_all": true},
+			contains: []string{`"total":3`, `"returned":3`, `"isLast":true`, `"title":"Postmortems"`},
+			excludes: []string{"nextCursor"},
+		},
+		{
+			name:     "search reports the total",
+			tool:     "confluence_search",
+			setup:    addChildPages,
+			args:     map[string]any{"query": "space = DEV", "limit": 2, "start": 1},
+			contains: []string{`"total":4`, `"startAt":1`, `"returned":2`, `"nextCursor":`},
+		},
+		{
+			name:     "get comments",
+			tool:     "confluence_get_comments",
+			args:     map[string]any{"page_id": "1001"},
+			contains: []string{"Nice page"},
+		},
+		{
+			name:     "get labels",
+			tool:     "confluence_get_labels",
+			args:     map[string]any{"page_id": "1001"},
+			contains: []string{`"name":"home"`},
+		},
+	})
+}
+
+func TestWriteTools(t *testing.T) {
+	runToolTests(t, []toolTest{
+		{
+			name:     "add label",
+			tool:     "confluence_add_label",
+			args:     map[string]any{"page_id": "1002", "name": "ops"},
+			contains: []string{`"name":"ops"`},
+			check: func(t *testing.T, srv *fakeatlassian.Server, _ string) {
+				if got := srv.Pages["1002"].Labels; len(got) != 1 || got[0] != "ops" {
+					t.Errorf("labels = %v, want [ops]", got)
+				}
+			},
+		},
+		{
+			name:     "create page",
+			tool:     "confluence_create_page",
+			args:     map[string]any{"space_key": "DEV", "title": "Onboarding", "content": "Hello", "parent_id": "1001"},
+			contains: []string{`"title":"Onboarding"`},
+			check: func(t *testing.T, srv *fakeatlassian.Server, _ string) {
+				for _, p := range srv.Pages {
+					if p.Title == "Onboarding" {
+						if p.ParentID != "1001" {
+							t.Errorf("parent = %q, want 1001", p.ParentID)
+						}
+						return
+					}
+				}
+				t.Error("page was not created")
+			},
+		},
+		{
+			name:     "create duplicate page",
+			tool:     "confluence_create_page",
+			args:     map[string]any{"space_key": "DEV", "title": "Runbook", "content": "Again"},
+			wantErr:  true,
+			contains: []string{"Failed to create page: HTTP 400", "already exists"},
+		},
+		{
+			name:     "update page",
+			tool:     "confluence_update_page",
+			args:     map[string]any{"page_id": "1001", "title": "Team Home", "content": "Updated"},
+			contains: []string{`"number":4`},
+			check: func(t *testing.T, srv *fakeatlassian.Server, _ string) {
+				if got := srv.Pages["1001"].Body; got != "Updated" {
+					t.Errorf("body = %q, want Updated", got)
+				}
+			},
+		},
+		{
+			name:     "delete page",
+			tool:     "confluence_delete_page",
+			args:     map[string]any{"page_id": "1002"},
+			contains: []string{"Page 1002 deleted successfully"},
+			check: func(t *testing.T, srv *fakeatlassian.Server, _ string) {
+				if _, ok := srv.Pages["1002"]; ok {
+					t.Error("page 1002 still exists")
+				}
+			},
+		},
+		{
+			name:     "delete missing page",
+			tool:     "confluence_delete_page",
+			args:     map[string]any{"page_id": "9999"},
+			wantErr:  true,
+			contains: []string{"Failed to delete page: HTTP 404", "Hint:"},
+		},
+		{
+			name: "add comment",
+			tool: "confluence_add_comment",
+			args: map[string]any{"page_id": "1002", "content": "<p>Thanks</p>"},
+			check: func(t *testing.T, srv *fakeatlassian.Server, _ string) {
+				if got := srv.Pages["1002"].Comments; len(got) != 1 || got[0] != "<p>Thanks</p>" {
+					t.Errorf("comments = %v", got)
+				}
+			},
+		},
+	})
+}
+
+// addChildPages gives page 1001 three children.
+func addChildPages(t *testing.T, srv *fakeatlassian.Server) {
+	for _, p := range []*fakeatlassian.Page{
+		{ID: "1003", Type: "page", Title: "Onboarding", SpaceKey: "DEV", Version: 1, ParentID: "1001"},
+		{ID: "1004", Type: "page", Title: "Postmortems", SpaceKey: "DEV", Version: 1, ParentID: "1001"},
+	} {
+		srv.Pages[p.ID] = p
+	}
+}
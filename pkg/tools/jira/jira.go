@@ -19,6 +19,13 @@ func AddTools(s *server.MCPServer) {
 		),
 	), jira.GetUserProfileHandler)
 
+	s.AddTool(mcp.NewTool("jira_search_users",
+		mcp.WithDescription("Search Jira users by username, display name or email, optionally only those assignable in a project."),
+		mcp.WithString("query", mcp.Description("Part of a username, display name or email"), mcp.Required()),
+		mcp.WithString("project_key", mcp.Description("Only return users who can be assigned issues in this project"), mcp.DefaultString("")),
+		mcp.WithNumber("limit", mcp.Description("Maximum number of users (1-50)"), mcp.DefaultNumber(10)),
+	), jira.SearchUsersHandler)
+
 	s.AddTool(mcp.NewTool("jira_get_issue",
 		mcp.WithDescription("Get details of a specific Jira issue."),
 		mcp.WithString("issue_key", mcp.Description("Jira issue key (e.g., 'PROJ-123')"), mcp.Required()),
@@ -32,13 +39,106 @@ func AddTools(s *server.MCPServer) {
 	s.AddTool(mcp.NewTool("jira_search",
 		mcp.WithDescription("Search Jira issues using JQL (Jira Query Language)."),
 		mcp.WithString("jql", mcp.Description("JQL query string (e.g., 'project = PROJ AND status = \"In Progress\"')"), mcp.Required()),
-		mcp.WithString("fields", mcp.Description("Comma-separated fields to return in the results. Use '*all' for all fields."), mcp.DefaultString("")),
+		mcp.WithString("fields", mcp.Description("Comma-separated fields to return in the results with format json. Use '*all' for all fields."), mcp.DefaultString("")),
 		mcp.WithNumber("limit", mcp.Description("Maximum number of results (1-50)"), mcp.DefaultNumber(10)),
 		mcp.WithNumber("start_at", mcp.Description("Starting index for pagination (0-based)"), mcp.DefaultNumber(0)),
+		mcp.WithString("cursor", mcp.Description("nextCursor of a previous response, to get the next page"), mcp.DefaultString("")),
+		mcp.WithBoolean("fetch_all", mcp.Description("Fetch every page, up to the configured limits.max_fetch_all items"), mcp.DefaultBool(false)),
+		mcp.WithString("format", mcp.Description("'json' for the full Jira response, or 'compact', 'markdown_table' or 'csv' for one row per issue with the chosen columns"), mcp.DefaultString("json")),
+		mcp.WithString("columns", mcp.Description("Comma-separated columns for compact, markdown_table and csv: key, type, status, priority, assignee, summary, updated, or any field ID or name such as 'Story Points'"), mcp.DefaultString("key,type,status,priority,assignee,summary,updated")),
 		mcp.WithString("projects_filter", mcp.Description("Comma-separated list of project keys to filter results by."), mcp.DefaultString("")),
 		mcp.WithString("expand", mcp.Description("Fields to expand (e.g., 'renderedFields', 'transitions', 'changelog')"), mcp.DefaultString("")),
 	), jira.SearchHandler)
 
+	s.AddTool(mcp.NewTool("jira_validate_jql",
+		mcp.WithDescription("Check a JQL query without running it: syntax, and the field names, operators and functions of this Jira instance. Problems are reported with their position and suggested fixes."),
+		mcp.WithString("jql", mcp.Description("JQL query to check"), mcp.Required()),
+	), jira.ValidateJQLHandler)
+
+	s.AddTool(mcp.NewTool("jira_jql_autocomplete",
+		mcp.WithDescription("Suggest JQL field names and functions, or the values of a field, as the Jira search box does."),
+		mcp.WithString("field", mcp.Description("Field to suggest values for (e.g., 'status', 'assignee'); leave empty to suggest fields and functions"), mcp.DefaultString("")),
+		mcp.WithString("value", mcp.Description("Start of the value, or of the field name when no field is given"), mcp.DefaultString("")),
+		mcp.WithNumber("limit", mcp.Description("Maximum number of suggestions (1-50)"), mcp.DefaultNumber(20)),
+	), jira.JQLAutocompleteHandler)
+
+	s.AddTool(mcp.NewTool("jira_build_jql",
+		mcp.WithDescription("Build a correctly quoted JQL query from simple criteria, with dates given as periods such as 'today', 'last week', 'last 7 days', 'since 2024-03-01' or '2024-03-01..2024-03-31'. The result is checked against the instance; pass it to jira_search."),
+		mcp.WithString("project", mcp.Description("Comma-separated project keys"), mcp.DefaultString("")),
+		mcp.WithString("issue_type", mcp.Description("Comma-separated issue types"), mcp.DefaultString("")),
+		mcp.WithString("status", mcp.Description("Comma-separated statuses"), mcp.DefaultString("")),
+		mcp.WithString("priority", mcp.Description("Comma-separated priorities"), mcp.DefaultString("")),
+		mcp.WithString("assignee", mcp.Description("Comma-separated users, 'me' or 'unassigned'"), mcp.DefaultString("")),
+		mcp.WithString("reporter", mcp.Description("Comma-separated users or 'me'"), mcp.DefaultString("")),
+		mcp.WithString("labels", mcp.Description("Comma-separated labels, any of which must match"), mcp.DefaultString("")),
+		mcp.WithString("components", mcp.Description("Comma-separated components"), mcp.DefaultString("")),
+		mcp.WithString("fix_version", mcp.Description("Comma-separated fix versions"), mcp.DefaultString("")),
+		mcp.WithString("text", mcp.Description("Words to search for in the summary, description and comments"), mcp.DefaultString("")),
+		mcp.WithString("created", mcp.Description("Period the issue was created in"), mcp.DefaultString("")),
+		mcp.WithString("updated", mcp.Description("Period the issue was last updated in"), mcp.DefaultString("")),
+		mcp.WithString("resolved", mcp.Description("Period the issue was resolved in"), mcp.DefaultString("")),
+		mcp.WithString("due", mcp.Description("Period the issue is due in"), mcp.DefaultString("")),
+		mcp.WithBoolean("unresolved", mcp.Description("Only unresolved issues"), mcp.DefaultBool(false)),
+		mcp.WithString("jql", mcp.Description("Further JQL to combine with the criteria"), mcp.DefaultString("")),
+		mcp.WithString("order_by", mcp.Description("Ordering, e.g. 'priority DESC, created'"), mcp.DefaultString("")),
+	), jira.BuildJQLHandler)
+
+	s.AddTool(mcp.NewTool("jira_get_filters",
+		mcp.WithDescription("List saved filters with their JQL, owner and who they are shared with."),
+		mcp.WithString("which", mcp.Description("'my' for the filters you own, 'favourite' for your favourites, 'all' for both"), mcp.DefaultString("all")),
+	), jira.GetFiltersHandler)
+
+	s.AddTool(mcp.NewTool("jira_get_filter",
+		mcp.WithDescription("Get a saved filter with its JQL and share permissions."),
+		mcp.WithString("filter", mcp.Description("Filter ID or name"), mcp.Required()),
+	), jira.GetFilterHandler)
+
+	s.AddTool(mcp.NewTool("jira_run_filter",
+		mcp.WithDescription("Search Jira issues with the JQL of a saved filter."),
+		mcp.WithString("filter", mcp.Description("Filter ID or name"), mcp.Required()),
+		mcp.WithString("fields", mcp.Description("Comma-separated fields to return in the results with format json. Use '*all' for all fields."), mcp.DefaultString("")),
+		mcp.WithNumber("limit", mcp.Description("Maximum number of results (1-50)"), mcp.DefaultNumber(10)),
+		mcp.WithNumber("start_at", mcp.Description("Starting index for pagination (0-based)"), mcp.DefaultNumber(0)),
+		mcp.WithString("cursor", mcp.Description("nextCursor of a previous response, to get the next page"), mcp.DefaultString("")),
+		mcp.WithBoolean("fetch_all", mcp.Description("Fetch every page, up to the configured limits.max_fetch_all items"), mcp.DefaultBool(false)),
+		mcp.WithString("format", mcp.Description("'json' for the full Jira response, or 'compact', 'markdown_table' or 'csv' for one row per issue with the chosen columns"), mcp.DefaultString("json")),
+		mcp.WithString("columns", mcp.Description("Comma-separated columns for compact, markdown_table and csv: key, type, status, priority, assignee, summary, updated, or any field ID or name such as 'Story Points'"), mcp.DefaultString("key,type,status,priority,assignee,summary,updated")),
+		mcp.WithString("projects_filter", mcp.Description("Comma-separated list of project keys to filter results by."), mcp.DefaultString("")),
+		mcp.WithString("expand", mcp.Description("Fields to expand (e.g., 'renderedFields', 'transitions', 'changelog')"), mcp.DefaultString("")),
+	), jira.RunFilterHandler)
+
+	s.AddTool(mcp.NewTool("jira_create_filter",
+		mcp.WithDescription("Save a JQL query as a filter, optionally sharing it."),
+		mcp.WithString("name", mcp.Description("Filter name"), mcp.Required()),
+		mcp.WithString("jql", mcp.Description("JQL of the filter"), mcp.Required()),
+		mcp.WithString("description", mcp.Description("Filter description"), mcp.DefaultString("")),
+		mcp.WithBoolean("favourite", mcp.Description("Add the filter to your favourites"), mcp.DefaultBool(false)),
+		mcp.WithString("share_permissions", mcp.Description("Comma-separated shares: 'group:NAME', 'project:KEY', 'project:KEY:ROLE', 'user:NAME', 'authenticated' or 'global'"), mcp.DefaultString("")),
+	), jira.CreateFilterHandler)
+
+	s.AddTool(mcp.NewTool("jira_update_filter",
+		mcp.WithDescription("Rename a saved filter or change its JQL, description, favourite flag or share permissions. Only the given values change."),
+		mcp.WithString("filter", mcp.Description("Filter ID or exact name"), mcp.Required()),
+		mcp.WithString("name", mcp.Description("New name"), mcp.DefaultString("")),
+		mcp.WithString("jql", mcp.Description("New JQL"), mcp.DefaultString("")),
+		mcp.WithString("description", mcp.Description("New description"), mcp.DefaultString("")),
+		mcp.WithBoolean("favourite", mcp.Description("Add to or remove from your favourites")),
+		mcp.WithString("share_permissions", mcp.Description("Comma-separated shares replacing the current ones: 'group:NAME', 'project:KEY', 'project:KEY:ROLE', 'user:NAME', 'authenticated', 'global', or 'private' to share with nobody"), mcp.DefaultString("")),
+	), jira.UpdateFilterHandler)
+
+	s.AddTool(mcp.NewTool("jira_get_dashboards",
+		mcp.WithDescription("List Jira dashboards, optionally with their gadgets and the filters behind them."),
+		mcp.WithString("which", mcp.Description("'my' for the dashboards you own, 'favourite' for your favourites, 'all' for every dashboard you can see"), mcp.DefaultString("all")),
+		mcp.WithBoolean("include_gadgets", mcp.Description("List each dashboard's gadgets and their filters (Cloud only)"), mcp.DefaultBool(false)),
+		mcp.WithNumber("limit", mcp.Description("Maximum number of dashboards (1-50)"), mcp.DefaultNumber(20)),
+		mcp.WithNumber("start_at", mcp.Description("Starting index for pagination (0-based)"), mcp.DefaultNumber(0)),
+	), jira.GetDashboardsHandler)
+
+	s.AddTool(mcp.NewTool("jira_get_dashboard",
+		mcp.WithDescription("Get a dashboard with its gadgets and the saved filters and JQL they report on. Gadgets are only listed by Jira Cloud."),
+		mcp.WithString("dashboard_id", mcp.Description("Dashboard ID"), mcp.Required()),
+	), jira.GetDashboardHandler)
+
 	s.AddTool(mcp.NewTool("jira_search_fields",
 		mcp.WithDescription("Search Jira fields by keyword with fuzzy match."),
 		mcp.WithString("keyword", mcp.Description("Keyword for fuzzy search. If left empty, lists the first 'limit' available fields in their default order."), mcp.DefaultString("")),
@@ -47,20 +147,149 @@ func AddTools(s *server.MCPServer) {
 	), jira.SearchFieldsHandler)
 
 	s.AddTool(mcp.NewTool("jira_get_project_issues",
-		mcp.WithDescription("Get all issues for a specific Jira project."),
+		mcp.WithDescription("Get the issues of a Jira project, a page at a time. The response gives the total and a nextCursor while there are more."),
 		mcp.WithString("project_key", mcp.Description("The project key"), mcp.Required()),
 		mcp.WithNumber("limit", mcp.Description("Maximum number of results (1-50)"), mcp.DefaultNumber(10)),
 		mcp.WithNumber("start_at", mcp.Description("Starting index for pagination (0-based)"), mcp.DefaultNumber(0)),
+		mcp.WithString("cursor", mcp.Description("nextCursor of a previous response, to get the next page"), mcp.DefaultString("")),
+		mcp.WithBoolean("fetch_all", mcp.Description("Fetch every page, up to the configured limits.max_fetch_all items"), mcp.DefaultBool(false)),
+		mcp.WithString("format", mcp.Description("'json' for the full Jira response, or 'compact', 'markdown_table' or 'csv' for one row per issue with the chosen columns"), mcp.DefaultString("json")),
+		mcp.WithString("columns", mcp.Description("Comma-separated columns for compact, markdown_table and csv: key, type, status, priority, assignee, summary, updated, or any field ID or name such as 'Story Points'"), mcp.DefaultString("key,type,status,priority,assignee,summary,updated")),
 	), jira.GetProjectIssuesHandler)
 
+	s.AddTool(mcp.NewTool("jira_list_projects",
+		mcp.WithDescription("List Jira projects with their category and lead, optionally filtered by name or category."),
+		mcp.WithString("query", mcp.Description("Only projects whose key or name contains this text"), mcp.DefaultString("")),
+		mcp.WithString("category", mcp.Description("Only projects in this project category"), mcp.DefaultString("")),
+		mcp.WithNumber("limit", mcp.Description("Maximum number of projects (1-50)"), mcp.DefaultNumber(50)),
+		mcp.WithNumber("start_at", mcp.Description("Starting index for pagination (0-based)"), mcp.DefaultNumber(0)),
+	), jira.ListProjectsHandler)
+
+	s.AddTool(mcp.NewTool("jira_get_project",
+		mcp.WithDescription("Get a Jira project with its components, versions, issue types, roles and workflow scheme."),
+		mcp.WithString("project_key", mcp.Description("The project key"), mcp.Required()),
+	), jira.GetProjectHandler)
+
+	s.AddTool(mcp.NewTool("jira_get_issue_types",
+		mcp.WithDescription("List the issue types of a Jira project, or of the whole instance."),
+		mcp.WithString("project_key", mcp.Description("Optional project key"), mcp.DefaultString("")),
+	), jira.GetIssueTypesHandler)
+
+	s.AddTool(mcp.NewTool("jira_get_components",
+		mcp.WithDescription("List the components of a Jira project."),
+		mcp.WithString("project_key", mcp.Description("The project key"), mcp.Required()),
+	), jira.GetComponentsHandler)
+
+	s.AddTool(mcp.NewTool("jira_create_component",
+		mcp.WithDescription("Create a component in a Jira project."),
+		mcp.WithString("project_key", mcp.Description("The project key"), mcp.Required()),
+		mcp.WithString("name", mcp.Description("Component name"), mcp.Required()),
+		mcp.WithString("description", mcp.Description("Optional description"), mcp.DefaultString("")),
+		mcp.WithString("lead", mcp.Description("Optional component lead (username, display name or email)"), mcp.DefaultString("")),
+		mcp.WithString("assignee_type", mcp.Description("Default assignee: PROJECT_DEFAULT, COMPONENT_LEAD, PROJECT_LEAD or UNASSIGNED"), mcp.DefaultString("")),
+	), jira.CreateComponentHandler)
+
+	s.AddTool(mcp.NewTool("jira_update_component",
+		mcp.WithDescription("Update a Jira component. Only the given values change."),
+		mcp.WithString("component_id", mcp.Description("Component ID"), mcp.Required()),
+		mcp.WithString("name", mcp.Description("New name"), mcp.DefaultString("")),
+		mcp.WithString("description", mcp.Description("New description"), mcp.DefaultString("")),
+		mcp.WithString("lead", mcp.Description("New component lead (username, display name or email)"), mcp.DefaultString("")),
+		mcp.WithString("assignee_type", mcp.Description("Default assignee: PROJECT_DEFAULT, COMPONENT_LEAD, PROJECT_LEAD or UNASSIGNED"), mcp.DefaultString("")),
+	), jira.UpdateComponentHandler)
+
+	s.AddTool(mcp.NewTool("jira_delete_component",
+		mcp.WithDescription("Delete a Jira component."),
+		mcp.WithString("component_id", mcp.Description("Component ID"), mcp.Required()),
+		mcp.WithString("move_issues_to", mcp.Description("Optional ID of a component to move the issues to"), mcp.DefaultString("")),
+	), jira.DeleteComponentHandler)
+
+	s.AddTool(mcp.NewTool("jira_get_versions",
+		mcp.WithDescription("List the versions of a Jira project."),
+		mcp.WithString("project_key", mcp.Description("The project key"), mcp.Required()),
+	), jira.GetVersionsHandler)
+
+	s.AddTool(mcp.NewTool("jira_create_version",
+		mcp.WithDescription("Create a version in a Jira project."),
+		mcp.WithString("project_key", mcp.Description("The project key"), mcp.Required()),
+		mcp.WithString("name", mcp.Description("Version name"), mcp.Required()),
+		mcp.WithString("description", mcp.Description("Optional description"), mcp.DefaultString("")),
+		mcp.WithString("start_date", mcp.Description("Optional start date (YYYY-MM-DD)"), mcp.DefaultString("")),
+		mcp.WithString("release_date", mcp.Description("Optional release date (YYYY-MM-DD)"), mcp.DefaultString("")),
+	), jira.CreateVersionHandler)
+
+	s.AddTool(mcp.NewTool("jira_update_version",
+		mcp.WithDescription("Update a Jira version. Only the given values change."),
+		mcp.WithString("version_id", mcp.Description("Version ID"), mcp.Required()),
+		mcp.WithString("name", mcp.Description("New name"), mcp.DefaultString("")),
+		mcp.WithString("description", mcp.Description("New description"), mcp.DefaultString("")),
+		mcp.WithString("start_date", mcp.Description("New start date (YYYY-MM-DD)"), mcp.DefaultString("")),
+		mcp.WithString("release_date", mcp.Description("New release date (YYYY-MM-DD)"), mcp.DefaultString("")),
+	), jira.UpdateVersionHandler)
+
+	s.AddTool(mcp.NewTool("jira_delete_version",
+		mcp.WithDescription("Delete a Jira version."),
+		mcp.WithString("version_id", mcp.Description("Version ID"), mcp.Required()),
+		mcp.WithString("move_fix_issues_to", mcp.Description("Optional ID of a version to move issues fixed in this one to"), mcp.DefaultString("")),
+		mcp.WithString("move_affected_issues_to", mcp.Description("Optional ID of a version to move issues affecting this one to"), mcp.DefaultString("")),
+	), jira.DeleteVersionHandler)
+
+	s.AddTool(mcp.NewTool("jira_release_version",
+		mcp.WithDescription("Mark a Jira version as released, optionally moving its unresolved issues to another version first."),
+		mcp.WithString("version_id", mcp.Description("Version ID"), mcp.Required()),
+		mcp.WithString("release_date", mcp.Description("Release date (YYYY-MM-DD), defaults to today"), mcp.DefaultString("")),
+		mcp.WithString("move_unresolved_to", mcp.Description("Optional ID of a version to move unresolved issues to; the version is not released if any move fails"), mcp.DefaultString("")),
+		mcp.WithNumber("max_issues", mcp.Description("Maximum number of unresolved issues to move"), mcp.DefaultNumber(100)),
+	), jira.ReleaseVersionHandler)
+
+	s.AddTool(mcp.NewTool("jira_archive_version",
+		mcp.WithDescription("Archive or unarchive a Jira version."),
+		mcp.WithString("version_id", mcp.Description("Version ID"), mcp.Required()),
+		mcp.WithBoolean("archived", mcp.Description("Set to false to unarchive the version"), mcp.DefaultBool(true)),
+	), jira.ArchiveVersionHandler)
+
+	s.AddTool(mcp.NewTool("jira_move_unresolved_issues",
+		mcp.WithDescription("Move the unresolved issues of a fixVersion to another version."),
+		mcp.WithString("from_version_id", mcp.Description("ID of the version to move issues from"), mcp.Required()),
+		mcp.WithString("to_version_id", mcp.Description("ID of the version to move issues to"), mcp.Required()),
+		mcp.WithNumber("max_issues", mcp.Description("Maximum number of issues to move"), mcp.DefaultNumber(100)),
+	), jira.MoveUnresolvedIssuesHandler)
+
+	s.AddTool(mcp.NewTool("jira_release_notes",
+		mcp.WithDescription("Render Markdown release notes for the issues in a fixVersion, optionally publishing them as a Confluence page."),
+		mcp.WithString("project_key", mcp.Description("Jira project key (e.g., 'PROJ')"), mcp.Required()),
+		mcp.WithString("version", mcp.Description("Version name or ID"), mcp.Required()),
+		mcp.WithString("group_by", mcp.Description("Group issues by 'type' or 'component'"), mcp.DefaultString("type")),
+		mcp.WithString("title", mcp.Description("Title of the notes, defaults to '<project> <version> release notes'"), mcp.DefaultString("")),
+		mcp.WithNumber("max_issues", mcp.Description("Maximum number of issues in the version"), mcp.DefaultNumber(200)),
+		mcp.WithString("space_key", mcp.Description("Optional Confluence space key to publish the notes to"), mcp.DefaultString("")),
+		mcp.WithString("parent_id", mcp.Description("Optional ID of the Confluence parent page"), mcp.DefaultString("")),
+	), jira.ReleaseNotesHandler)
+
+	s.AddTool(mcp.NewTool("jira_get_dependency_graph",
+		mcp.WithDescription("Follow issue links from an issue or a JQL set and return the dependency graph with cycles and the critical path of unresolved blockers, as JSON, Mermaid or Graphviz DOT."),
+		mcp.WithString("issue_key", mcp.Description("Issue to start from (e.g., 'PROJ-123'); set this or jql"), mcp.DefaultString("")),
+		mcp.WithString("jql", mcp.Description("JQL selecting the issues to start from; set this or issue_key"), mcp.DefaultString("")),
+		mcp.WithString("link_types", mcp.Description("Comma-separated link types to follow, e.g. 'blocks, relates, duplicates, epic, parent', or 'all'"), mcp.DefaultString("blocks")),
+		mcp.WithString("direction", mcp.Description("'inward' follows what an issue depends on (is blocked by), 'outward' what depends on it, 'both' follows either"), mcp.DefaultString("both")),
+		mcp.WithNumber("depth", mcp.Description("How many links away from the starting issues to go (0-10)"), mcp.DefaultNumber(3)),
+		mcp.WithNumber("max_issues", mcp.Description("Maximum number of issues in the graph"), mcp.DefaultNumber(200)),
+		mcp.WithString("format", mcp.Description("'json', 'mermaid' or 'dot'"), mcp.DefaultString("json")),
+	), jira.GetDependencyGraphHandler)
+
 	s.AddTool(mcp.NewTool("jira_get_transitions",
 		mcp.WithDescription("Get available status transitions for a Jira issue."),
 		mcp.WithString("issue_key", mcp.Description("Jira issue key (e.g., 'PROJ-123')"), mcp.Required()),
 	), jira.GetTransitionsHandler)
 
 	s.AddTool(mcp.NewTool("jira_get_worklog",
-		mcp.WithDescription("Get worklog entries for a Jira issue."),
+		mcp.WithDescription("Get worklog entries for a Jira issue, oldest first, with the total time logged."),
 		mcp.WithString("issue_key", mcp.Description("Jira issue key (e.g., 'PROJ-123')"), mcp.Required()),
+		mcp.WithNumber("limit", mcp.Description("Maximum number of worklogs (1-50)"), mcp.DefaultNumber(50)),
+		mcp.WithNumber("start_at", mcp.Description("Starting index for pagination (0-based)"), mcp.DefaultNumber(0)),
+		mcp.WithString("author", mcp.Description("Only work logged by this user (username, display name or email)"), mcp.DefaultString("")),
+		mcp.WithString("since", mcp.Description("Only work started on or after this date (YYYY-MM-DD)"), mcp.DefaultString("")),
+		mcp.WithString("until", mcp.Description("Only work started on or before this date (YYYY-MM-DD)"), mcp.DefaultString("")),
 	), jira.GetWorklogHandler)
 
 	s.AddTool(mcp.NewTool("jira_get_agile_boards",
@@ -76,8 +305,12 @@ func AddTools(s *server.MCPServer) {
 		mcp.WithDescription("Get all issues linked to a specific board filtered by JQL."),
 		mcp.WithNumber("board_id", mcp.Description("The id of the board (e.g., '1001')"), mcp.Required()),
 		mcp.WithString("jql", mcp.Description("JQL query string to filter issues."), mcp.Required()),
-		mcp.WithString("fields", mcp.Description("Comma-separated fields to return in the results. Use '*all' for all fields."), mcp.DefaultString("")),
+		mcp.WithString("fields", mcp.Description("Comma-separated fields to return in the results with format json. Use '*all' for all fields."), mcp.DefaultString("")),
 		mcp.WithNumber("start_at", mcp.Description("Starting index for pagination (0-based)"), mcp.DefaultNumber(0)),
+		mcp.WithString("cursor", mcp.Description("nextCursor of a previous response, to get the next page"), mcp.DefaultString("")),
+		mcp.WithBoolean("fetch_all", mcp.Description("Fetch every page, up to the configured limits.max_fetch_all items"), mcp.DefaultBool(false)),
+		mcp.WithString("format", mcp.Description("'json' for the full Jira response, or 'compact', 'markdown_table' or 'csv' for one row per issue with the chosen columns"), mcp.DefaultString("json")),
+		mcp.WithString("columns", mcp.Description("Comma-separated columns for compact, markdown_table and csv: key, type, status, priority, assignee, summary, updated, or any field ID or name such as 'Story Points'"), mcp.DefaultString("key,type,status,priority,assignee,summary,updated")),
 		mcp.WithNumber("limit", mcp.Description("Maximum number of results (1-50)"), mcp.DefaultNumber(10)),
 		mcp.WithString("expand", mcp.Description("Optional fields to expand in the response (e.g., 'changelog')."), mcp.DefaultString("version")),
 	), jira.GetBoardIssuesHandler)
@@ -93,11 +326,28 @@ func AddTools(s *server.MCPServer) {
 	s.AddTool(mcp.NewTool("jira_get_sprint_issues",
 		mcp.WithDescription("Get Jira issues from sprint."),
 		mcp.WithNumber("sprint_id", mcp.Description("The id of sprint (e.g., '10001')"), mcp.Required()),
-		mcp.WithString("fields", mcp.Description("Comma-separated fields to return in the results. Use '*all' for all fields."), mcp.DefaultString("")),
+		mcp.WithString("fields", mcp.Description("Comma-separated fields to return in the results with format json. Use '*all' for all fields."), mcp.DefaultString("")),
 		mcp.WithNumber("start_at", mcp.Description("Starting index for pagination (0-based)"), mcp.DefaultNumber(0)),
+		mcp.WithString("cursor", mcp.Description("nextCursor of a previous response, to get the next page"), mcp.DefaultString("")),
+		mcp.WithBoolean("fetch_all", mcp.Description("Fetch every page, up to the configured limits.max_fetch_all items"), mcp.DefaultBool(false)),
+		mcp.WithString("format", mcp.Description("'json' for the full Jira response, or 'compact', 'markdown_table' or 'csv' for one row per issue with the chosen columns"), mcp.DefaultString("json")),
+		mcp.WithString("columns", mcp.Description("Comma-separated columns for compact, markdown_table and csv: key, type, status, priority, assignee, summary, updated, or any field ID or name such as 'Story Points'"), mcp.DefaultString("key,type,status,priority,assignee,summary,updated")),
 		mcp.WithNumber("limit", mcp.Description("Maximum number of results (1-50)"), mcp.DefaultNumber(10)),
 	), jira.GetSprintIssuesHandler)
 
+	s.AddTool(mcp.NewTool("jira_get_board_backlog",
+		mcp.WithDescription("Get the backlog of a Jira board: issues not in an active or future sprint, in rank order."),
+		mcp.WithNumber("board_id", mcp.Description("The id of the board (e.g., '1001')"), mcp.Required()),
+		mcp.WithString("jql", mcp.Description("Optional JQL query to filter the backlog"), mcp.DefaultString("")),
+		mcp.WithString("fields", mcp.Description("Comma-separated fields to return in the results with format json. Use '*all' for all fields."), mcp.DefaultString("")),
+		mcp.WithNumber("start_at", mcp.Description("Starting index for pagination (0-based)"), mcp.DefaultNumber(0)),
+		mcp.WithString("cursor", mcp.Description("nextCursor of a previous response, to get the next page"), mcp.DefaultString("")),
+		mcp.WithBoolean("fetch_all", mcp.Description("Fetch every page, up to the configured limits.max_fetch_all items"), mcp.DefaultBool(false)),
+		mcp.WithString("format", mcp.Description("'json' for the full Jira response, or 'compact', 'markdown_table' or 'csv' for one row per issue with the chosen columns"), mcp.DefaultString("json")),
+		mcp.WithString("columns", mcp.Description("Comma-separated columns for compact, markdown_table and csv: key, type, status, priority, assignee, summary, updated, or any field ID or name such as 'Story Points'"), mcp.DefaultString("key,type,status,priority,assignee,summary,updated")),
+		mcp.WithNumber("limit", mcp.Description("Maximum number of results (1-50)"), mcp.DefaultNumber(10)),
+	), jira.GetBoardBacklogHandler)
+
 	s.AddTool(mcp.NewTool("jira_download_attachments",
 		mcp.WithDescription("Download attachments from a Jira issue."),
 		mcp.WithString("issue_key", mcp.Description("Jira issue key"), mcp.Required()),
@@ -108,17 +358,62 @@ func AddTools(s *server.MCPServer) {
 		mcp.WithDescription("Get all available issue link types."),
 	), jira.GetLinkTypesHandler)
 
+	s.AddTool(mcp.NewTool("jira_get_create_meta",
+		mcp.WithDescription("Get the fields of the create screen of a Jira project: the required fields of each issue type, or every field with its allowed values for one issue type."),
+		mcp.WithString("project_key", mcp.Description("Jira project key (e.g., 'PROJ')"), mcp.Required()),
+		mcp.WithString("issue_type", mcp.Description("Optional issue type name or ID to list all fields for"), mcp.DefaultString("")),
+	), jira.GetCreateMetaHandler)
+
 	s.AddTool(mcp.NewTool("jira_create_issue",
 		mcp.WithDescription("Create a new Jira issue with optional Epic link or parent for subtasks."),
 		mcp.WithString("project_key", mcp.Description("The JIRA project key"), mcp.Required()),
 		mcp.WithString("summary", mcp.Description("Summary/title of the issue"), mcp.Required()),
 		mcp.WithString("issue_type", mcp.Description("Issue type (e.g., 'Task', 'Bug', 'Story', 'Epic', 'Subtask')"), mcp.Required()),
-		mcp.WithString("assignee", mcp.Description("Assignee's user identifier (email, display name, or account ID)"), mcp.DefaultString("")),
+		mcp.WithString("assignee", mcp.Description("Assignee's username, email, display name or account ID; must match one user assignable in the project"), mcp.DefaultString("")),
 		mcp.WithString("description", mcp.Description("Issue description"), mcp.DefaultString("")),
 		mcp.WithString("components", mcp.Description("Comma-separated list of component names"), mcp.DefaultString("")),
 		mcp.WithString("additional_fields", mcp.Description("JSON string of additional fields"), mcp.DefaultString("")),
+		mcp.WithString("parent_key", mcp.Description("Key of the parent issue, for sub-tasks"), mcp.DefaultString("")),
+		mcp.WithString("epic_key", mcp.Description("Key of the epic to create the issue in"), mcp.DefaultString("")),
 	), jira.CreateIssueHandler)
 
+	s.AddTool(mcp.NewTool("jira_create_subtask",
+		mcp.WithDescription("Create a sub-task under an existing issue, in the parent's project."),
+		mcp.WithString("parent_key", mcp.Description("Key of the parent issue (e.g., 'PROJ-123')"), mcp.Required()),
+		mcp.WithString("summary", mcp.Description("Summary/title of the sub-task"), mcp.Required()),
+		mcp.WithString("issue_type", mcp.Description("Sub-task issue type, defaults to the project's first sub-task type"), mcp.DefaultString("")),
+		mcp.WithString("assignee", mcp.Description("Assignee's username, email, display name or account ID"), mcp.DefaultString("")),
+		mcp.WithString("description", mcp.Description("Sub-task description"), mcp.DefaultString("")),
+		mcp.WithString("components", mcp.Description("Comma-separated list of component names"), mcp.DefaultString("")),
+		mcp.WithString("additional_fields", mcp.Description("JSON string of additional fields"), mcp.DefaultString("")),
+	), jira.CreateSubtaskHandler)
+
+	s.AddTool(mcp.NewTool("jira_clone_issue",
+		mcp.WithDescription("Copy an issue, in its own project or another one, linking the copy to the original. Fields, labels and components are copied where the target project offers them; sub-tasks, links and attachments on request. Reports what could not be carried over."),
+		mcp.WithString("issue_key", mcp.Description("Issue to clone (e.g., 'PROJ-123')"), mcp.Required()),
+		mcp.WithString("project_key", mcp.Description("Project to create the copy in, defaults to the issue's project"), mcp.DefaultString("")),
+		mcp.WithString("issue_type", mcp.Description("Issue type of the copy, defaults to the issue's type"), mcp.DefaultString("")),
+		mcp.WithString("type_mapping", mcp.Description("JSON object mapping source issue types to target ones, for sub-tasks, e.g. {\"Sub-task\": \"Technical task\"}"), mcp.DefaultString("")),
+		mcp.WithString("fields", mcp.Description("JSON object of fields to set on the copy instead of the copied values, by ID or display name, e.g. {\"summary\": \"New title\"}"), mcp.DefaultString("")),
+		mcp.WithBoolean("include_subtasks", mcp.Description("Clone the sub-tasks under the copy"), mcp.DefaultBool(false)),
+		mcp.WithBoolean("include_links", mcp.Description("Recreate the issue links on the copy"), mcp.DefaultBool(false)),
+		mcp.WithBoolean("include_attachments", mcp.Description("Copy the attachments"), mcp.DefaultBool(false)),
+		mcp.WithBoolean("include_labels", mcp.Description("Copy the labels"), mcp.DefaultBool(true)),
+		mcp.WithBoolean("include_components", mcp.Description("Copy the components that exist in the target project"), mcp.DefaultBool(true)),
+		mcp.WithBoolean("link_to_original", mcp.Description("Link the copy to the original with a Cloners link"), mcp.DefaultBool(true)),
+	), jira.CloneIssueHandler)
+
+	s.AddTool(mcp.NewTool("jira_move_issue",
+		mcp.WithDescription("Move an issue to another project: recreate it there with its sub-tasks, links, attachments and comments, map its issue type and walk it to the matching status, then link or delete the original. Reports what could not be carried over."),
+		mcp.WithString("issue_key", mcp.Description("Issue to move (e.g., 'INTAKE-12')"), mcp.Required()),
+		mcp.WithString("project_key", mcp.Description("Project to move the issue to"), mcp.Required()),
+		mcp.WithString("issue_type", mcp.Description("Issue type in the target project, defaults to the mapped or same-named type"), mcp.DefaultString("")),
+		mcp.WithString("type_mapping", mcp.Description("JSON object mapping source issue types to target ones, e.g. {\"Bug\": \"Defect\"}"), mcp.DefaultString("")),
+		mcp.WithString("status_mapping", mcp.Description("JSON object mapping source statuses to target ones, e.g. {\"Triage\": \"To Do\"}; unmapped statuses keep their name"), mcp.DefaultString("")),
+		mcp.WithString("fields", mcp.Description("JSON object of fields to set on the moved issue, by ID or display name"), mcp.DefaultString("")),
+		mcp.WithBoolean("delete_original", mcp.Description("Delete the original issue and its sub-tasks once moved, unless a sub-task, link, attachment or comment could not be copied; otherwise it is linked and commented"), mcp.DefaultBool(false)),
+	), jira.MoveIssueHandler)
+
 	s.AddTool(mcp.NewTool("jira_batch_create_issues",
 		mcp.WithDescription("Create multiple Jira issues in a batch."),
 		mcp.WithString("issues", mcp.Description("JSON array string of issue objects"), mcp.Required()),
@@ -149,24 +444,163 @@ func AddTools(s *server.MCPServer) {
 		mcp.WithDescription("Add a comment to a Jira issue."),
 		mcp.WithString("issue_key", mcp.Description("Jira issue key"), mcp.Required()),
 		mcp.WithString("comment", mcp.Description("Comment text in Markdown"), mcp.Required()),
+		mcp.WithString("visibility_type", mcp.Description("Restrict the comment to a 'role' or 'group'"), mcp.DefaultString("")),
+		mcp.WithString("visibility_value", mcp.Description("Name of the role or group that can see the comment"), mcp.DefaultString("")),
 	), jira.AddCommentHandler)
 
+	s.AddTool(mcp.NewTool("jira_get_comments",
+		mcp.WithDescription("List the comments on a Jira issue, newest first by default."),
+		mcp.WithString("issue_key", mcp.Description("Jira issue key"), mcp.Required()),
+		mcp.WithNumber("limit", mcp.Description("Maximum number of comments (1-50)"), mcp.DefaultNumber(10)),
+		mcp.WithNumber("start_at", mcp.Description("Starting index for pagination (0-based)"), mcp.DefaultNumber(0)),
+		mcp.WithString("order", mcp.Description("'newest' or 'oldest' first"), mcp.DefaultString("newest")),
+		mcp.WithString("author", mcp.Description("Only comments by this user (username, display name or email)"), mcp.DefaultString("")),
+		mcp.WithString("since", mcp.Description("Only comments created on or after this date (YYYY-MM-DD or ISO time)"), mcp.DefaultString("")),
+		mcp.WithString("until", mcp.Description("Only comments created on or before this date (YYYY-MM-DD or ISO time)"), mcp.DefaultString("")),
+		mcp.WithBoolean("as_markdown", mcp.Description("Return comment bodies as Markdown instead of Jira wiki markup"), mcp.DefaultBool(false)),
+	), jira.GetCommentsHandler)
+
+	s.AddTool(mcp.NewTool("jira_get_comment",
+		mcp.WithDescription("Get a single comment on a Jira issue."),
+		mcp.WithString("issue_key", mcp.Description("Jira issue key"), mcp.Required()),
+		mcp.WithString("comment_id", mcp.Description("ID of the comment"), mcp.Required()),
+		mcp.WithBoolean("as_markdown", mcp.Description("Return the body as Markdown instead of Jira wiki markup"), mcp.DefaultBool(false)),
+	), jira.GetCommentHandler)
+
+	s.AddTool(mcp.NewTool("jira_edit_comment",
+		mcp.WithDescription("Edit the text or visibility of a comment on a Jira issue. Whatever is not given is kept."),
+		mcp.WithString("issue_key", mcp.Description("Jira issue key"), mcp.Required()),
+		mcp.WithString("comment_id", mcp.Description("ID of the comment"), mcp.Required()),
+		mcp.WithString("comment", mcp.Description("New comment text"), mcp.DefaultString("")),
+		mcp.WithString("visibility_type", mcp.Description("Restrict the comment to a 'role' or 'group', or 'none' to make it public"), mcp.DefaultString("")),
+		mcp.WithString("visibility_value", mcp.Description("Name of the role or group that can see the comment"), mcp.DefaultString("")),
+	), jira.EditCommentHandler)
+
+	s.AddTool(mcp.NewTool("jira_delete_comment",
+		mcp.WithDescription("Delete a comment from a Jira issue."),
+		mcp.WithString("issue_key", mcp.Description("Jira issue key"), mcp.Required()),
+		mcp.WithString("comment_id", mcp.Description("ID of the comment"), mcp.Required()),
+	), jira.DeleteCommentHandler)
+
+	s.AddTool(mcp.NewTool("jira_get_watchers",
+		mcp.WithDescription("List the watchers of a Jira issue."),
+		mcp.WithString("issue_key", mcp.Description("Jira issue key"), mcp.Required()),
+	), jira.GetWatchersHandler)
+
+	s.AddTool(mcp.NewTool("jira_add_watchers",
+		mcp.WithDescription("Add watchers to a Jira issue."),
+		mcp.WithString("issue_key", mcp.Description("Jira issue key"), mcp.Required()),
+		mcp.WithString("usernames", mcp.Description("Comma-separated usernames, emails, display names or account IDs; defaults to the current user"), mcp.DefaultString("")),
+	), jira.AddWatchersHandler)
+
+	s.AddTool(mcp.NewTool("jira_remove_watchers",
+		mcp.WithDescription("Remove watchers from a Jira issue."),
+		mcp.WithString("issue_key", mcp.Description("Jira issue key"), mcp.Required()),
+		mcp.WithString("usernames", mcp.Description("Comma-separated usernames, emails, display names or account IDs; defaults to the current user"), mcp.DefaultString("")),
+	), jira.RemoveWatchersHandler)
+
+	s.AddTool(mcp.NewTool("jira_watch_issues",
+		mcp.WithDescription("Add watchers to every Jira issue matched by a JQL query."),
+		mcp.WithString("jql", mcp.Description("JQL selecting the issues"), mcp.Required()),
+		mcp.WithString("usernames", mcp.Description("Comma-separated usernames, emails, display names or account IDs; defaults to the current user"), mcp.DefaultString("")),
+		mcp.WithNumber("max_issues", mcp.Description("Fail if the JQL matches more issues than this; capped by the server's bulk.max_issues"), mcp.DefaultNumber(50)),
+	), jira.WatchIssuesHandler)
+
+	s.AddTool(mcp.NewTool("jira_get_votes",
+		mcp.WithDescription("Get the vote count and voters of a Jira issue."),
+		mcp.WithString("issue_key", mcp.Description("Jira issue key"), mcp.Required()),
+	), jira.GetVotesHandler)
+
+	s.AddTool(mcp.NewTool("jira_add_vote",
+		mcp.WithDescription("Vote for a Jira issue as the current user."),
+		mcp.WithString("issue_key", mcp.Description("Jira issue key"), mcp.Required()),
+	), jira.AddVoteHandler)
+
+	s.AddTool(mcp.NewTool("jira_remove_vote",
+		mcp.WithDescription("Remove the current user's vote from a Jira issue."),
+		mcp.WithString("issue_key", mcp.Description("Jira issue key"), mcp.Required()),
+	), jira.RemoveVoteHandler)
+
 	s.AddTool(mcp.NewTool("jira_add_worklog",
 		mcp.WithDescription("Add a worklog entry to a Jira issue."),
 		mcp.WithString("issue_key", mcp.Description("Jira issue key"), mcp.Required()),
 		mcp.WithString("time_spent", mcp.Description("Time spent in Jira format (e.g., '2h 30m')"), mcp.Required()),
 		mcp.WithString("comment", mcp.Description("Optional comment in Markdown"), mcp.DefaultString("")),
 		mcp.WithString("started", mcp.Description("Optional start time in ISO format"), mcp.DefaultString("")),
-		mcp.WithString("original_estimate", mcp.Description("Optional new original estimate"), mcp.DefaultString("")),
-		mcp.WithString("remaining_estimate", mcp.Description("Optional new remaining estimate"), mcp.DefaultString("")),
+		mcp.WithString("adjust_estimate", mcp.Description("How to adjust the remaining estimate: 'auto' (reduce by time spent), 'new' (set to new_estimate), 'manual' (reduce by reduce_by) or 'leave'"), mcp.DefaultString("")),
+		mcp.WithString("new_estimate", mcp.Description("Remaining estimate to set with adjust_estimate 'new' (e.g., '1d')"), mcp.DefaultString("")),
+		mcp.WithString("reduce_by", mcp.Description("Amount to reduce the remaining estimate by with adjust_estimate 'manual'"), mcp.DefaultString("")),
+		mcp.WithString("original_estimate", mcp.Description("Optional new original estimate for the issue"), mcp.DefaultString("")),
+		mcp.WithString("remaining_estimate", mcp.Description("Deprecated: same as adjust_estimate 'new' with this new_estimate"), mcp.DefaultString("")),
 	), jira.AddWorklogHandler)
 
+	s.AddTool(mcp.NewTool("jira_update_worklog",
+		mcp.WithDescription("Update a worklog entry on a Jira issue."),
+		mcp.WithString("issue_key", mcp.Description("Jira issue key"), mcp.Required()),
+		mcp.WithString("worklog_id", mcp.Description("Worklog ID"), mcp.Required()),
+		mcp.WithString("time_spent", mcp.Description("New time spent in Jira format (e.g., '2h 30m')"), mcp.DefaultString("")),
+		mcp.WithString("comment", mcp.Description("New comment"), mcp.DefaultString("")),
+		mcp.WithString("started", mcp.Description("New start time in ISO format"), mcp.DefaultString("")),
+		mcp.WithString("adjust_estimate", mcp.Description("How to adjust the remaining estimate: 'auto' (by the change in time spent), 'new' (set to new_estimate) or 'leave'"), mcp.DefaultString("")),
+		mcp.WithString("new_estimate", mcp.Description("Remaining estimate to set with adjust_estimate 'new'"), mcp.DefaultString("")),
+	), jira.UpdateWorklogHandler)
+
+	s.AddTool(mcp.NewTool("jira_delete_worklog",
+		mcp.WithDescription("Delete a worklog entry from a Jira issue."),
+		mcp.WithString("issue_key", mcp.Description("Jira issue key"), mcp.Required()),
+		mcp.WithString("worklog_id", mcp.Description("Worklog ID"), mcp.Required()),
+		mcp.WithString("adjust_estimate", mcp.Description("How to adjust the remaining estimate: 'auto' (increase by the time removed), 'new' (set to new_estimate), 'manual' (increase by increase_by) or 'leave'"), mcp.DefaultString("")),
+		mcp.WithString("new_estimate", mcp.Description("Remaining estimate to set with adjust_estimate 'new'"), mcp.DefaultString("")),
+		mcp.WithString("increase_by", mcp.Description("Amount to increase the remaining estimate by with adjust_estimate 'manual'"), mcp.DefaultString("")),
+	), jira.DeleteWorklogHandler)
+
+	s.AddTool(mcp.NewTool("jira_timesheet",
+		mcp.WithDescription("Total the time a user logged between two dates, grouped by day, issue or epic."),
+		mcp.WithString("since", mcp.Description("First day of the range (YYYY-MM-DD)"), mcp.Required()),
+		mcp.WithString("until", mcp.Description("Last day of the range (YYYY-MM-DD); defaults to today"), mcp.DefaultString("")),
+		mcp.WithString("user", mcp.Description("User whose work to report: username, email, display name or account ID; defaults to the current user"), mcp.DefaultString("")),
+		mcp.WithString("jql", mcp.Description("Optional JQL restricting the issues, e.g. 'project = PROJ'"), mcp.DefaultString("")),
+		mcp.WithString("group_by", mcp.Description("'day', 'issue' or 'epic'"), mcp.DefaultString("day")),
+		mcp.WithNumber("max_issues", mcp.Description("Fail if more issues than this have matching work; capped by the server's bulk.max_issues"), mcp.DefaultNumber(100)),
+	), jira.TimesheetHandler)
+
 	s.AddTool(mcp.NewTool("jira_link_to_epic",
 		mcp.WithDescription("Link an existing issue to an epic."),
 		mcp.WithString("issue_key", mcp.Description("The key of the issue to link"), mcp.Required()),
 		mcp.WithString("epic_key", mcp.Description("The key of the epic to link to"), mcp.Required()),
 	), jira.LinkToEpicHandler)
 
+	s.AddTool(mcp.NewTool("jira_move_to_epic",
+		mcp.WithDescription("Move issues into an epic, taking them out of any epic they are in."),
+		mcp.WithString("issue_keys", mcp.Description("Comma-separated issue keys"), mcp.Required()),
+		mcp.WithString("epic_key", mcp.Description("The key of the epic to move the issues to"), mcp.Required()),
+	), jira.MoveToEpicHandler)
+
+	s.AddTool(mcp.NewTool("jira_remove_from_epic",
+		mcp.WithDescription("Take issues out of their epic."),
+		mcp.WithString("issue_keys", mcp.Description("Comma-separated issue keys"), mcp.Required()),
+	), jira.RemoveFromEpicHandler)
+
+	s.AddTool(mcp.NewTool("jira_get_epic_issues",
+		mcp.WithDescription("List the issues in an epic, whether linked through Epic Link or as children of the epic."),
+		mcp.WithString("epic_key", mcp.Description("The key of the epic"), mcp.Required()),
+		mcp.WithNumber("limit", mcp.Description("Maximum number of issues (1-50)"), mcp.DefaultNumber(50)),
+		mcp.WithNumber("start_at", mcp.Description("Starting index for pagination (0-based)"), mcp.DefaultNumber(0)),
+	), jira.GetEpicIssuesHandler)
+
+	s.AddTool(mcp.NewTool("jira_get_subtasks",
+		mcp.WithDescription("List the sub-tasks of an issue."),
+		mcp.WithString("issue_key", mcp.Description("Jira issue key (e.g., 'PROJ-123')"), mcp.Required()),
+	), jira.GetSubtasksHandler)
+
+	s.AddTool(mcp.NewTool("jira_get_hierarchy",
+		mcp.WithDescription("Get the issue tree around an issue, from the top initiative or epic down to sub-tasks, with status rollups. Follows parent, Epic Link and Parent Link fields."),
+		mcp.WithString("issue_key", mcp.Description("Jira issue key (e.g., 'PROJ-123')"), mcp.Required()),
+		mcp.WithBoolean("include_ancestors", mcp.Description("Start the tree at the top of the issue's hierarchy rather than at the issue"), mcp.DefaultBool(true)),
+		mcp.WithNumber("max_depth", mcp.Description("Levels to include below the issue"), mcp.DefaultNumber(3)),
+		mcp.WithNumber("max_issues", mcp.Description("Maximum number of issues in the tree"), mcp.DefaultNumber(200)),
+	), jira.GetHierarchyHandler)
+
 	s.AddTool(mcp.NewTool("jira_create_issue_link",
 		mcp.WithDescription("Create a link between two Jira issues."),
 		mcp.WithString("link_type", mcp.Description("The type of link (e.g., 'Blocks')"), mcp.Required()),
@@ -182,13 +616,36 @@ func AddTools(s *server.MCPServer) {
 	), jira.RemoveIssueLinkHandler)
 
 	s.AddTool(mcp.NewTool("jira_transition_issue",
-		mcp.WithDescription("Transition a Jira issue to a new status."),
+		mcp.WithDescription("Transition a Jira issue to a new status, by target status name or transition ID. Required screen fields that are missing are reported."),
 		mcp.WithString("issue_key", mcp.Description("Jira issue key"), mcp.Required()),
-		mcp.WithString("transition_id", mcp.Description("ID of the transition"), mcp.Required()),
+		mcp.WithString("status", mcp.Description("Target status name (e.g., 'In Review'); a transition name is also accepted"), mcp.DefaultString("")),
+		mcp.WithString("transition_id", mcp.Description("ID of the transition, instead of status"), mcp.DefaultString("")),
 		mcp.WithString("fields", mcp.Description("Optional JSON string of fields to update during transition"), mcp.DefaultString("")),
+		mcp.WithString("resolution", mcp.Description("Optional resolution name (e.g., 'Fixed')"), mcp.DefaultString("")),
 		mcp.WithString("comment", mcp.Description("Optional comment for the transition"), mcp.DefaultString("")),
+		mcp.WithString("worklog", mcp.Description("Optional time spent to log with the transition (e.g., '1h 30m')"), mcp.DefaultString("")),
+		mcp.WithNumber("max_steps", mcp.Description("Maximum number of transitions to walk to reach status. The path is planned over the workflow before any step is applied; intermediate steps cannot take fields and never pass through a done status"), mcp.DefaultNumber(1)),
 	), jira.TransitionIssueHandler)
 
+	s.AddTool(mcp.NewTool("jira_bulk_update",
+		mcp.WithDescription("Apply the same changes to every issue matched by a JQL query. A dry run (the default) lists the affected issues and returns a checkpoint; apply it with dry_run false. Results are reported per issue, and an interrupted or partly failed run can be resumed from its checkpoint."),
+		mcp.WithString("jql", mcp.Description("JQL selecting the issues (e.g., 'project = PROJ AND labels = flaky AND resolution = Unresolved')"), mcp.DefaultString("")),
+		mcp.WithString("checkpoint", mcp.Description("Checkpoint from an earlier call; its issues and operations are used instead of jql and the operation arguments"), mcp.DefaultString("")),
+		mcp.WithBoolean("dry_run", mcp.Description("Only list the affected issues"), mcp.DefaultBool(true)),
+		mcp.WithString("fields", mcp.Description("Optional JSON string of fields to set, by ID or display name"), mcp.DefaultString("")),
+		mcp.WithString("add_labels", mcp.Description("Comma-separated labels to add"), mcp.DefaultString("")),
+		mcp.WithString("remove_labels", mcp.Description("Comma-separated labels to remove"), mcp.DefaultString("")),
+		mcp.WithString("assignee", mcp.Description("Username, email, display name or account ID of the user to assign the issues to; must match one user"), mcp.DefaultString("")),
+		mcp.WithString("comment", mcp.Description("Comment to add to each issue"), mcp.DefaultString("")),
+		mcp.WithString("status", mcp.Description("Target status to transition each issue to"), mcp.DefaultString("")),
+		mcp.WithNumber("max_steps", mcp.Description("Maximum number of transitions to walk to reach status. The path is planned over the workflow before any step is applied and never passes through a done status"), mcp.DefaultNumber(1)),
+		mcp.WithString("link_type", mcp.Description("Link type (e.g., 'Blocks'); each issue is the inward issue of the link"), mcp.DefaultString("")),
+		mcp.WithString("link_issue", mcp.Description("Key of the outward issue to link each issue to"), mcp.DefaultString("")),
+		mcp.WithNumber("max_issues", mcp.Description("Fail if the JQL matches more issues than this; capped by the server's bulk.max_issues"), mcp.DefaultNumber(100)),
+		mcp.WithNumber("concurrency", mcp.Description("Issues updated in parallel (1-10)"), mcp.DefaultNumber(4)),
+		mcp.WithNumber("rate", mcp.Description("Issues started per second (at most 50)"), mcp.DefaultNumber(5)),
+	), jira.BulkUpdateHandler)
+
 	s.AddTool(mcp.NewTool("jira_create_sprint",
 		mcp.WithDescription("Create Jira sprint for a board."),
 		mcp.WithNumber("board_id", mcp.Description("Board ID"), mcp.Required()),
@@ -207,4 +664,43 @@ func AddTools(s *server.MCPServer) {
 		mcp.WithString("end_date", mcp.Description("Optional new end date"), mcp.DefaultString("")),
 		mcp.WithString("goal", mcp.Description("Optional new goal"), mcp.DefaultString("")),
 	), jira.UpdateSprintHandler)
+
+	s.AddTool(mcp.NewTool("jira_start_sprint",
+		mcp.WithDescription("Start a future Jira sprint. Only one sprint of a board can be active."),
+		mcp.WithNumber("sprint_id", mcp.Description("The ID of the sprint"), mcp.Required()),
+		mcp.WithString("start_date", mcp.Description("Start date (ISO format); defaults to now"), mcp.DefaultString("")),
+		mcp.WithString("end_date", mcp.Description("End date (ISO format); defaults to the sprint's planned end date, or two weeks after the start"), mcp.DefaultString("")),
+		mcp.WithString("duration", mcp.Description("Sprint length instead of end_date (e.g., '2w' or '10d')"), mcp.DefaultString("")),
+		mcp.WithString("goal", mcp.Description("Optional sprint goal"), mcp.DefaultString("")),
+	), jira.StartSprintHandler)
+
+	s.AddTool(mcp.NewTool("jira_complete_sprint",
+		mcp.WithDescription("Complete an active Jira sprint. Issues in the last column of the board are done; the others are carried over to another sprint or the backlog. Reports the completed and carried-over issues."),
+		mcp.WithNumber("sprint_id", mcp.Description("The ID of the active sprint"), mcp.Required()),
+		mcp.WithString("move_incomplete_to", mcp.Description("Where incomplete issues go: 'next' for the board's next future sprint (the backlog if there is none), 'backlog', or a sprint ID"), mcp.DefaultString("next")),
+		mcp.WithBoolean("start_next", mcp.Description("Start the sprint the incomplete issues moved to, with the length of the completed sprint"), mcp.DefaultBool(false)),
+		mcp.WithBoolean("dry_run", mcp.Description("Only report what would be completed and carried over"), mcp.DefaultBool(false)),
+	), jira.CompleteSprintHandler)
+
+	s.AddTool(mcp.NewTool("jira_move_issues_to_sprint",
+		mcp.WithDescription("Move issues into an active or future Jira sprint, out of any other open sprint."),
+		mcp.WithNumber("sprint_id", mcp.Description("The ID of the sprint"), mcp.Required()),
+		mcp.WithString("issue_keys", mcp.Description("Comma-separated issue keys (e.g., 'PROJ-1,PROJ-2')"), mcp.Required()),
+		mcp.WithString("rank_before", mcp.Description("Optional issue key to rank the issues before"), mcp.DefaultString("")),
+		mcp.WithString("rank_after", mcp.Description("Optional issue key to rank the issues after"), mcp.DefaultString("")),
+	), jira.MoveIssuesToSprintHandler)
+
+	s.AddTool(mcp.NewTool("jira_move_issues_to_backlog",
+		mcp.WithDescription("Move issues out of their active or future sprints into the backlog."),
+		mcp.WithString("issue_keys", mcp.Description("Comma-separated issue keys (e.g., 'PROJ-1,PROJ-2')"), mcp.Required()),
+		mcp.WithString("rank_before", mcp.Description("Optional issue key to rank the issues before"), mcp.DefaultString("")),
+		mcp.WithString("rank_after", mcp.Description("Optional issue key to rank the issues after"), mcp.DefaultString("")),
+	), jira.MoveIssuesToBacklogHandler)
+
+	s.AddTool(mcp.NewTool("jira_rank_issues",
+		mcp.WithDescription("Rank issues before or after another issue, keeping their order."),
+		mcp.WithString("issue_keys", mcp.Description("Comma-separated issue keys (e.g., 'PROJ-1,PROJ-2')"), mcp.Required()),
+		mcp.WithString("rank_before", mcp.Description("Issue key to rank the issues before"), mcp.DefaultString("")),
+		mcp.WithString("rank_after", mcp.Description("Issue key to rank the issues after"), mcp.DefaultString("")),
+	), jira.RankIssuesHandler)
 }
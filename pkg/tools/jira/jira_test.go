@@ -0,0 +1,2565 @@
+package jira
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/client"
+
+	"mcp-atlassian-server/pkg/config"
+	"mcp-atlassian-server/pkg/fakeatlassian"
+)
+
+type toolTest struct {
+	name     string
+	tool     string
+	args     map[string]any
+	setup    func(t *testing.T, srv *fakeatlassian.Server)
+	wantErr  bool
+	contains []string
+	excludes []string
+	check    func(t *testing.T, srv *fakeatlassian.Server, out string)
+}
+
+func runToolTests(t *testing.T, tests []toolTest) {
+	t.Helper()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := fakeatlassian.New(t)
+			if tt.setup != nil {
+				tt.setup(t, srv)
+			}
+			c := fakeatlassian.NewMCPClient(t, AddTools)
+			out, isErr := fakeatlassian.CallTool(t, c, tt.tool, tt.args)
+			if isErr != tt.wantErr {
+				t.Fatalf("IsError = %v, want %v; output: %s", isErr, tt.wantErr, out)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(out, want) {
+					t.Errorf("output does not contain %q:\n%s", want, out)
+				}
+			}
+			for _, unwanted := range tt.excludes {
+				if strings.Contains(out, unwanted) {
+					t.Errorf("output unexpectedly contains %q:\n%s", unwanted, out)
+				}
+			}
+			if tt.check != nil {
+				tt.check(t, srv, out)
+			}
+		})
+	}
+}
+
+func wantLastSearch(want string) func(*testing.T, *fakeatlassian.Server, string) {
+	return func(t *testing.T, srv *fakeatlassian.Server, _ string) {
+		t.Helper()
+		if got := srv.LastSearch(); got != want {
+			t.Errorf("JQL = %q, want %q", got, want)
+		}
+	}
+}
+
+func wantStatus(key, want string) func(*testing.T, *fakeatlassian.Server, string) {
+	return func(t *testing.T, srv *fakeatlassian.Server, _ string) {
+		t.Helper()
+		if got := srv.Issues[key].Fields["status"].(map[string]any)["name"]; got != want {
+			t.Errorf("%s status = %v, want %s", key, got, want)
+		}
+	}
+}
+
+func TestReadTools(t *testing.T) {
+	runToolTests(t, []toolTest{
+		{
+			name:     "ping",
+			tool:     "jira_ping",
+			contains: []string{"Jira OK"},
+		},
+		{
+			name:     "ping with bad token",
+			tool:     "jira_ping",
+			setup:    func(t *testing.T, _ *fakeatlassian.Server) { t.Setenv("JIRA_PERSONAL_TOKEN", "wrong") },
+			wantErr:  true,
+			contains: []string{"HTTP 401", "You are not authenticated", "Hint: Authentication failed"},
+		},
+		{
+			name:     "user profile uses username on server",
+			tool:     "jira_get_user_profile",
+			args:     map[string]any{"user_identifier": "jdoe"},
+			contains: []string{`"displayName":"John Doe"`},
+			check: func(t *testing.T, srv *fakeatlassian.Server, _ string) {
+				reqs := srv.RequestsTo("GET", "/rest/api/2/user")
+				if len(reqs) != 1 || reqs[0].Query != "username=jdoe" {
+					t.Errorf("requests = %+v, want username=jdoe", reqs)
+				}
+			},
+		},
+		{
+			name:     "unknown user",
+			tool:     "jira_get_user_profile",
+			args:     map[string]any{"user_identifier": "ghost"},
+			wantErr:  true,
+			contains: []string{"HTTP 404", "The user named 'ghost' does not exist"},
+		},
+		{
+			name:     "get issue",
+			tool:     "jira_get_issue",
+			args:     map[string]any{"issue_key": "PROJ-2"},
+			contains: []string{`"key":"PROJ-2"`, "Login form rejects valid passwords"},
+		},
+		{
+			name:     "get issue trims comments",
+			tool:     "jira_get_issue",
+			args:     map[string]any{"issue_key": "PROJ-2", "comment_limit": 1},
+			contains: []string{"Second comment"},
+			excludes: []string{"First comment"},
+		},
+		{
+			name:     "get missing issue",
+			tool:     "jira_get_issue",
+			args:     map[string]any{"issue_key": "PROJ-99"},
+			wantErr:  true,
+			contains: []string{"Failed to get issue: HTTP 404", "Issue Does Not Exist", "Hint:"},
+		},
+		{
+			name:     "search",
+			tool:     "jira_search",
+			args:     map[string]any{"jql": "labels = flaky"},
+			contains: []string{`"key":"PROJ-2"`, `"total":1`},
+			excludes: []string{`"key":"PROJ-3"`},
+		},
+		{
+			name:     "search limit is capped by max_results",
+			tool:     "jira_search",
+			args:     map[string]any{"jql": "project = PROJ", "limit": 50},
+			setup:    func(t *testing.T, _ *fakeatlassian.Server) { t.Setenv("MCP_MAX_RESULTS", "1") },
+			contains: []string{`"key":"PROJ-1"`, `"maxResults":1`},
+			excludes: []string{`"key":"PROJ-2"`},
+		},
+		{
+			name:  "search with projects filter",
+			tool:  "jira_search",
+			args:  map[string]any{"jql": "status = 'To Do'", "projects_filter": "PROJ, OTHER"},
+			check: wantLastSearch(`(status = 'To Do') AND project in ("PROJ", "OTHER")`),
+		},
+		{
+			name:     "search with invalid jql",
+			tool:     "jira_search",
+			args:     map[string]any{"jql": "status in (Open"},
+			wantErr:  true,
+			contains: []string{"Invalid JQL:\n- position 11: unbalanced (, add a closing )"},
+			check: func(t *testing.T, srv *fakeatlassian.Server, _ string) {
+				if len(srv.Searches) != 0 {
+					t.Errorf("invalid JQL was sent to Jira: %q", srv.Searches)
+				}
+			},
+		},
+		{
+			name:     "update issue with a user by display name",
+			tool:     "jira_update_issue",
+			args:     map[string]any{"issue_key": "PROJ-3", "fields": `{"Assignee": "Alice Smith"}`},
+			contains: []string{"updated successfully"},
+			check: func(t *testing.T, srv *fakeatlassian.Server, _ string) {
+				if got := fmt.Sprint(srv.Issues["PROJ-3"].Fields["assignee"]); !strings.Contains(got, "name:asmith") {
+					t.Errorf("assignee = %v", got)
+				}
+			},
+		},
+		{
+			name: "update issue with a user by email on cloud",
+			tool: "jira_update_issue",
+			setup: func(t *testing.T, srv *fakeatlassian.Server) {
+				srv.Deployment = "Cloud"
+				srv.Users[1]["accountId"] = "5b10ac8d82e05b22cc7d4ef5"
+			},
+			args:     map[string]any{"issue_key": "PROJ-3", "fields": `{"assignee": "alice.smith@example.com"}`},
+			contains: []string{"updated successfully"},
+			check: func(t *testing.T, srv *fakeatlassian.Server, _ string) {
+				if got := fmt.Sprint(srv.Issues["PROJ-3"].Fields["assignee"]); !strings.Contains(got, "accountId:5b10ac8d82e05b22cc7d4ef5") {
+					t.Errorf("assignee = %v", got)
+				}
+			},
+		},
+		{
+			name:     "update issue with an unknown user",
+			tool:     "jira_update_issue",
+			args:     map[string]any{"issue_key": "PROJ-3", "fields": `{"assignee": "ghost"}`},
+			wantErr:  true,
+			contains: []string{`field "Assignee" (assignee): no user matches "ghost"`},
+		},
+		{
+			name:     "search fields",
+			tool:     "jira_search_fields",
+			args:     map[string]any{"keyword": "story"},
+			contains: []string{"customfield_10016", "Story Points"},
+			excludes: []string{"Epic Link"},
+		},
+		{
+			name:     "project issues",
+			tool:     "jira_get_project_issues",
+			args:     map[string]any{"project_key": "PROJ"},
+			contains: []string{`"key":"PROJ-1"`, `"key":"PROJ-3"`},
+			check:    wantLastSearch(`project = "PROJ"`),
+		},
+		{
+			name:     "transitions",
+			tool:     "jira_get_transitions",
+			args:     map[string]any{"issue_key": "PROJ-2"},
+			contains: []string{"Resolve", "Stop Progress"},
+		},
+		{
+			name:     "worklog",
+			tool:     "jira_get_worklog",
+			args:     map[string]any{"issue_key": "PROJ-2"},
+			contains: []string{"Investigation"},
+		},
+		{
+			name:     "agile boards by type",
+			tool:     "jira_get_agile_boards",
+			args:     map[string]any{"board_type": "kanban"},
+			contains: []string{"Support kanban"},
+			excludes: []string{"PROJ board"},
+		},
+		{
+			name:     "board issues",
+			tool:     "jira_get_board_issues",
+			args:     map[string]any{"board_id": 1, "jql": "status = 'To Do'"},
+			contains: []string{`"key":"PROJ-1"`, `"key":"PROJ-3"`},
+			excludes: []string{`"key":"PROJ-2"`},
+		},
+		{
+			name:     "missing board",
+			tool:     "jira_get_board_issues",
+			args:     map[string]any{"board_id": 9, "jql": ""},
+			wantErr:  true,
+			contains: []string{"HTTP 404", "Board does not exist"},
+		},
+		{
+			name:     "sprints by state",
+			tool:     "jira_get_sprints_from_board",
+			args:     map[string]any{"board_id": 1, "state": "active"},
+			contains: []string{"Sprint 1"},
+			excludes: []string{"Sprint 2"},
+		},
+		{
+			name:     "sprint issues",
+			tool:     "jira_get_sprint_issues",
+			args:     map[string]any{"sprint_id": 100},
+			contains: []string{`"key":"PROJ-2"`, `"key":"PROJ-3"`},
+		},
+		{
+			name:     "sprint issues require id",
+			tool:     "jira_get_sprint_issues",
+			args:     map[string]any{},
+			wantErr:  true,
+			contains: []string{"sprint_id"},
+		},
+		{
+			name:     "link types",
+			tool:     "jira_get_link_types",
+			contains: []string{"Blocks", "is duplicated by"},
+		},
+		{
+			name:     "download attachments unsupported",
+			tool:     "jira_download_attachments",
+			args:     map[string]any{"issue_key": "PROJ-1", "target_dir": "/tmp"},
+			wantErr:  true,
+			contains: []string{"not supported"},
+		},
+		{
+			name:     "batch changelogs unsupported",
+			tool:     "jira_batch_get_changelogs",
+			args:     map[string]any{"issue_ids_or_keys": "PROJ-1"},
+			wantErr:  true,
+			contains: []string{"not supported"},
+		},
+	})
+}
+
+func TestWriteTools(t *testing.T) {
+	runToolTests(t, []toolTest{
+		{
+			name:     "create issue",
+			tool:     "jira_create_issue",
+			args:     map[string]any{"project_key": "PROJ", "summary": "New story", "issue_type": "Story", "assignee": "asmith", "components": "UI, API"},
+			contains: []string{`"key":"PROJ-4"`},
+			check: func(t *testing.T, srv *fakeatlassian.Server, _ string) {
+				issue := srv.Issues["PROJ-4"]
+				if issue == nil {
+					t.Fatal("issue PROJ-4 was not created")
+				}
+				if got := issue.Fields["summary"]; got != "New story" {
+					t.Errorf("summary = %v", got)
+				}
+				if got := issue.Fields["assignee"].(map[string]any)["name"]; got != "asmith" {
+					t.Errorf("assignee = %v", got)
+				}
+				if got := len(issue.Fields["components"].([]any)); got != 2 {
+					t.Errorf("components = %d, want 2", got)
+				}
+			},
+		},
+		{
+			name:     "create issue missing parameters",
+			tool:     "jira_create_issue",
+			args:     map[string]any{"project_key": "PROJ", "summary": "", "issue_type": "Task"},
+			wantErr:  true,
+			contains: []string{"Missing required parameters"},
+		},
+		{
+			name:     "create issue with unknown assignee",
+			tool:     "jira_create_issue",
+			args:     map[string]any{"project_key": "PROJ", "summary": "x", "issue_type": "Task", "assignee": "ghost"},
+			wantErr:  true,
+			contains: []string{`Invalid assignee: no user assignable in project PROJ matches "ghost"`},
+			check: func(t *testing.T, srv *fakeatlassian.Server, _ string) {
+				if reqs := srv.RequestsTo("POST", "/rest/api/2/issue"); len(reqs) != 0 {
+					t.Errorf("issue created despite unknown assignee: %+v", reqs)
+				}
+			},
+		},
+		{
+			name:    "batch create unsupported",
+			tool:    "jira_batch_create_issues",
+			args:    map[string]any{"issues": "[]"},
+			wantErr: true,
+		},
+		{
+			name:     "update issue",
+			tool:     "jira_update_issue",
+			args:     map[string]any{"issue_key": "PROJ-3", "fields": `{"summary":"Renamed","labels":["ui"]}`},
+			contains: []string{"updated successfully"},
+			check: func(t *testing.T, srv *fakeatlassian.Server, _ string) {
+				fields := srv.Issues["PROJ-3"].Fields
+				if fields["summary"] != "Renamed" {
+					t.Errorf("summary = %v", fields["summary"])
+				}
+				if labels, _ := json.Marshal(fields["labels"]); string(labels) != `["ui"]` {
+					t.Errorf("labels = %s", labels)
+				}
+			},
+		},
+		{
+			name:     "update issue with invalid json",
+			tool:     "jira_update_issue",
+			args:     map[string]any{"issue_key": "PROJ-3", "fields": `{summary`},
+			wantErr:  true,
+			contains: []string{"Invalid fields JSON"},
+		},
+		{
+			name:     "delete issue",
+			tool:     "jira_delete_issue",
+			args:     map[string]any{"issue_key": "PROJ-3"},
+			contains: []string{"deleted successfully"},
+			check: func(t *testing.T, srv *fakeatlassian.Server, _ string) {
+				if _, ok := srv.Issues["PROJ-3"]; ok {
+					t.Error("PROJ-3 still exists")
+				}
+			},
+		},
+		{
+			name:     "add comment",
+			tool:     "jira_add_comment",
+			args:     map[string]any{"issue_key": "PROJ-2", "comment": "Third comment"},
+			contains: []string{"Third comment"},
+			check: func(t *testing.T, srv *fakeatlassian.Server, _ string) {
+				if got := len(srv.Comments["PROJ-2"]); got != 3 {
+					t.Errorf("comments = %d, want 3", got)
+				}
+			},
+		},
+		{
+			name: "add worklog",
+			tool: "jira_add_worklog",
+			args: map[string]any{"issue_key": "PROJ-3", "time_spent": "2h", "started": "2024-01-05", "comment": "Pairing"},
+			check: func(t *testing.T, srv *fakeatlassian.Server, _ string) {
+				logs := srv.Worklogs["PROJ-3"]
+				if len(logs) != 1 || logs[0]["started"] != "2024-01-05T00:00:00.000+0000" || logs[0]["comment"] != "Pairing" {
+					t.Errorf("worklogs = %+v", logs)
+				}
+			},
+		},
+		{
+			name:     "add worklog with bad start",
+			tool:     "jira_add_worklog",
+			args:     map[string]any{"issue_key": "PROJ-3", "time_spent": "2h", "started": "yesterday"},
+			wantErr:  true,
+			contains: []string{"could not parse time"},
+		},
+		{
+			name: "link to epic",
+			tool: "jira_link_to_epic",
+			args: map[string]any{"issue_key": "PROJ-2", "epic_key": "PROJ-3"},
+			check: func(t *testing.T, srv *fakeatlassian.Server, _ string) {
+				if got := srv.Issues["PROJ-2"].Fields["customfield_10014"]; got != "PROJ-3" {
+					t.Errorf("epic link = %v", got)
+				}
+			},
+		},
+		{
+			name:     "create issue link",
+			tool:     "jira_create_issue_link",
+			args:     map[string]any{"link_type": "Blocks", "inward_issue_key": "PROJ-2", "outward_issue_key": "PROJ-3"},
+			contains: []string{"created successfully"},
+			check: func(t *testing.T, srv *fakeatlassian.Server, _ string) {
+				if len(srv.Links) != 1 {
+					t.Errorf("links = %d, want 1", len(srv.Links))
+				}
+			},
+		},
+		{
+			name:     "create issue link with unknown type",
+			tool:     "jira_create_issue_link",
+			args:     map[string]any{"link_type": "Causes", "inward_issue_key": "PROJ-2", "outward_issue_key": "PROJ-3"},
+			wantErr:  true,
+			contains: []string{"HTTP 404", "No issue link type with name 'Causes' found."},
+		},
+		{
+			name:     "remove missing issue link",
+			tool:     "jira_remove_issue_link",
+			args:     map[string]any{"link_id": "1"},
+			wantErr:  true,
+			contains: []string{"HTTP 404"},
+		},
+		{
+			name:     "transition issue",
+			tool:     "jira_transition_issue",
+			args:     map[string]any{"issue_key": "PROJ-2", "transition_id": "21", "comment": "Fixed in build 42"},
+			contains: []string{"transitioned successfully"},
+			check: func(t *testing.T, srv *fakeatlassian.Server, _ string) {
+				if got := srv.Issues["PROJ-2"].Fields["status"].(map[string]any)["name"]; got != "Done" {
+					t.Errorf("status = %v, want Done", got)
+				}
+				comments := srv.Comments["PROJ-2"]
+				if comments[len(comments)-1]["body"] != "Fixed in build 42" {
+					t.Errorf("transition comment not added: %+v", comments)
+				}
+			},
+		},
+		{
+			name:     "invalid transition",
+			tool:     "jira_transition_issue",
+			args:     map[string]any{"issue_key": "PROJ-2", "transition_id": "11"},
+			wantErr:  true,
+			contains: []string{"HTTP 400", "not valid for the current state"},
+		},
+		{
+			name:     "transition issue by status name",
+			tool:     "jira_transition_issue",
+			args:     map[string]any{"issue_key": "PROJ-2", "status": "in review"},
+			contains: []string{"transitioned successfully: In Progress -> In Review"},
+			check:    wantStatus("PROJ-2", "In Review"),
+		},
+		{
+			name:     "transition issue to an unreachable status",
+			tool:     "jira_transition_issue",
+			args:     map[string]any{"issue_key": "PROJ-1", "status": "In Review"},
+			wantErr:  true,
+			contains: []string{"Cannot reach status In Review from To Do", `"Start Progress" (id 11) to In Progress`, "max_steps"},
+			check:    wantStatus("PROJ-1", "To Do"),
+		},
+		{
+			name:     "transition issue through intermediate statuses",
+			tool:     "jira_transition_issue",
+			args:     map[string]any{"issue_key": "PROJ-1", "status": "In Review", "max_steps": 3},
+			contains: []string{"To Do -> In Progress -> In Review"},
+			check:    wantStatus("PROJ-1", "In Review"),
+		},
+		{
+			name: "transition issue through intermediate statuses on Cloud",
+			tool: "jira_transition_issue",
+			setup: func(t *testing.T, srv *fakeatlassian.Server) {
+				srv.Deployment = "Cloud"
+			},
+			args:     map[string]any{"issue_key": "PROJ-1", "status": "In Review", "max_steps": 3},
+			contains: []string{"To Do -> In Progress -> In Review"},
+			check: func(t *testing.T, srv *fakeatlassian.Server, out string) {
+				wantStatus("PROJ-1", "In Review")(t, srv, out)
+				if reqs := srv.RequestsTo("GET", "/workflow/search"); len(reqs) != 1 {
+					t.Errorf("workflow requests = %+v", reqs)
+				}
+			},
+		},
+		{
+			name: "transition issue without a readable workflow",
+			tool: "jira_transition_issue",
+			setup: func(t *testing.T, srv *fakeatlassian.Server) {
+				srv.Missing = []string{"/projectconfig/1/workflow"}
+			},
+			args:     map[string]any{"issue_key": "PROJ-1", "status": "In Review", "max_steps": 3},
+			wantErr:  true,
+			contains: []string{"No known workflow path to status In Review from To Do", "The workflow could not be read", "Nothing was applied; PROJ-1 is still in To Do"},
+			check: func(t *testing.T, srv *fakeatlassian.Server, out string) {
+				wantStatus("PROJ-1", "To Do")(t, srv, out)
+				if reqs := srv.RequestsTo("POST", "/issue/PROJ-1/transitions"); len(reqs) != 0 {
+					t.Errorf("transitions applied: %+v", reqs)
+				}
+			},
+		},
+		{
+			name: "transition issue reports the steps applied before a failure",
+			tool: "jira_transition_issue",
+			setup: func(t *testing.T, srv *fakeatlassian.Server) {
+				srv.Transitions["In Progress"][2]["fields"] = map[string]any{"customfield_10020": map[string]any{"required": true, "name": "Team"}}
+			},
+			args:     map[string]any{"issue_key": "PROJ-1", "status": "In Review", "max_steps": 3},
+			wantErr:  true,
+			contains: []string{`"Request Review" (id 51) to In Review requires fields`, "Applied Start Progress (To Do -> In Progress); PROJ-1 was left in In Progress"},
+			check:    wantStatus("PROJ-1", "In Progress"),
+		},
+		{
+			name: "transition issue does not pass through done statuses",
+			tool: "jira_transition_issue",
+			setup: func(t *testing.T, srv *fakeatlassian.Server) {
+				archived := map[string]any{"id": "10003", "name": "Archived", "statusCategory": map[string]any{"key": "indeterminate"}}
+				srv.Transitions["To Do"] = []map[string]any{{"id": "91", "name": "Close", "to": srv.Transitions["In Progress"][0]["to"]}}
+				srv.Transitions["Done"] = append(srv.Transitions["Done"], map[string]any{"id": "81", "name": "Archive", "to": archived})
+			},
+			args:     map[string]any{"issue_key": "PROJ-1", "status": "Archived", "max_steps": 3},
+			wantErr:  true,
+			contains: []string{"No workflow path to status Archived from To Do without passing through a done status", `"Close" (id 91) to Done`},
+			check:    wantStatus("PROJ-1", "To Do"),
+		},
+		{
+			name: "transition issue without a required field",
+			tool: "jira_transition_issue",
+			setup: func(t *testing.T, srv *fakeatlassian.Server) {
+				srv.Issues["PROJ-2"].Fields["status"] = map[string]any{"id": "10002", "name": "In Review"}
+			},
+			args:     map[string]any{"issue_key": "PROJ-2", "status": "Done"},
+			wantErr:  true,
+			contains: []string{`"Approve" (id 61) to Done requires fields`, "Resolution (resolution), one of: Fixed, Won't Do"},
+			check:    wantStatus("PROJ-2", "In Review"),
+		},
+		{
+			name: "transition issue with resolution, comment and worklog",
+			tool: "jira_transition_issue",
+			setup: func(t *testing.T, srv *fakeatlassian.Server) {
+				srv.Issues["PROJ-2"].Fields["status"] = map[string]any{"id": "10002", "name": "In Review"}
+			},
+			args:     map[string]any{"issue_key": "PROJ-2", "status": "Done", "resolution": "Fixed", "comment": "Shipped", "worklog": "30m"},
+			contains: []string{"In Review -> Done"},
+			check: func(t *testing.T, srv *fakeatlassian.Server, out string) {
+				wantStatus("PROJ-2", "Done")(t, srv, out)
+				if got, _ := json.Marshal(srv.Issues["PROJ-2"].Fields["resolution"]); string(got) != `{"name":"Fixed"}` {
+					t.Errorf("resolution = %s", got)
+				}
+				if comments := srv.Comments["PROJ-2"]; comments[len(comments)-1]["body"] != "Shipped" {
+					t.Errorf("comment not added: %+v", comments)
+				}
+				if logs := srv.Worklogs["PROJ-2"]; len(logs) != 2 || logs[1]["timeSpent"] != "30m" {
+					t.Errorf("worklog not added: %+v", logs)
+				}
+			},
+		},
+		{
+			name:     "transition issue already in status",
+			tool:     "jira_transition_issue",
+			args:     map[string]any{"issue_key": "PROJ-2", "status": "In Progress"},
+			contains: []string{"already in status In Progress"},
+		},
+		{
+			name:     "create sprint",
+			tool:     "jira_create_sprint",
+			args:     map[string]any{"board_id": 1, "sprint_name": "Sprint 3", "goal": "Polish"},
+			contains: []string{"Sprint 3"},
+			check: func(t *testing.T, srv *fakeatlassian.Server, _ string) {
+				if len(srv.Sprints) != 3 {
+					t.Errorf("sprints = %d, want 3", len(srv.Sprints))
+				}
+			},
+		},
+		{
+			name: "update sprint",
+			tool: "jira_update_sprint",
+			args: map[string]any{"sprint_id": 101, "goal": "Stabilise"},
+			check: func(t *testing.T, srv *fakeatlassian.Server, _ string) {
+				if got := srv.Sprints[101]["goal"]; got != "Stabilise" {
+					t.Errorf("goal = %v", got)
+				}
+			},
+		},
+	})
+}
+
+func TestFieldNames(t *testing.T) {
+	runToolTests(t, []toolTest{
+		{
+			name:     "get issue returns display names",
+			tool:     "jira_get_issue",
+			args:     map[string]any{"issue_key": "PROJ-3", "fields": "summary, Story Points, epic link"},
+			contains: []string{`"Story Points":3`, `"Epic Link":"PROJ-1"`},
+			excludes: []string{"customfield_10016"},
+			check: func(t *testing.T, srv *fakeatlassian.Server, _ string) {
+				reqs := srv.RequestsTo("GET", "/issue/PROJ-3")
+				if len(reqs) != 1 || !strings.Contains(reqs[0].Query, "customfield_10016") || !strings.Contains(reqs[0].Query, "customfield_10014") {
+					t.Errorf("fields were not resolved to IDs: %+v", reqs)
+				}
+			},
+		},
+		{
+			name:     "search returns display names",
+			tool:     "jira_search",
+			args:     map[string]any{"jql": "key = PROJ-3", "fields": "Story Points"},
+			contains: []string{`"Story Points":3`},
+		},
+		{
+			name: "create issue with display names",
+			tool: "jira_create_issue",
+			args: map[string]any{
+				"project_key": "PROJ", "summary": "Estimate me", "issue_type": "Story",
+				"additional_fields": `{"Story Points": "5", "Team": "Platform", "Target Date": "2026-03-31T10:00:00Z", "Epic Link": "PROJ-1"}`,
+			},
+			contains: []string{`"key":"PROJ-4"`},
+			check: func(t *testing.T, srv *fakeatlassian.Server, _ string) {
+				fields := srv.Issues["PROJ-4"].Fields
+				if fields["customfield_10016"] != 5.0 {
+					t.Errorf("story points = %#v", fields["customfield_10016"])
+				}
+				if team, _ := json.Marshal(fields["customfield_10020"]); string(team) != `{"value":"Platform"}` {
+					t.Errorf("team = %s", team)
+				}
+				if fields["customfield_10030"] != "2026-03-31" {
+					t.Errorf("target date = %#v", fields["customfield_10030"])
+				}
+				if fields["customfield_10014"] != "PROJ-1" {
+					t.Errorf("epic link = %#v", fields["customfield_10014"])
+				}
+			},
+		},
+		{
+			name:     "update issue with display names",
+			tool:     "jira_update_issue",
+			args:     map[string]any{"issue_key": "PROJ-3", "fields": `{"story points": 8}`, "additional_fields": `{"Labels": "ui, api"}`},
+			contains: []string{"updated successfully"},
+			check: func(t *testing.T, srv *fakeatlassian.Server, _ string) {
+				fields := srv.Issues["PROJ-3"].Fields
+				if fields["customfield_10016"] != 8.0 {
+					t.Errorf("story points = %#v", fields["customfield_10016"])
+				}
+				if labels, _ := json.Marshal(fields["labels"]); string(labels) != `["ui","api"]` {
+					t.Errorf("labels = %s", labels)
+				}
+			},
+		},
+		{
+			name:     "update issue with a value of the wrong type",
+			tool:     "jira_update_issue",
+			args:     map[string]any{"issue_key": "PROJ-3", "fields": `{"Story Points": "lots"}`},
+			wantErr:  true,
+			contains: []string{`field "Story Points" (customfield_10016): "lots" is not a number`},
+		},
+		{
+			name:     "update issue with an unknown custom field",
+			tool:     "jira_update_issue",
+			args:     map[string]any{"issue_key": "PROJ-3", "fields": `{"customfield_99999": 1}`},
+			wantErr:  true,
+			contains: []string{"HTTP 400", "customfield_99999: Field 'customfield_99999' cannot be set"},
+		},
+		{
+			name:     "search fields",
+			tool:     "jira_search_fields",
+			args:     map[string]any{"keyword": "team"},
+			contains: []string{`"total":1`, "customfield_10020"},
+		},
+	})
+}
+
+func TestFieldCatalogIsCached(t *testing.T) {
+	srv := fakeatlassian.New(t)
+	c := fakeatlassian.NewMCPClient(t, AddTools)
+	for i := 0; i < 2; i++ {
+		if out, isErr := fakeatlassian.CallTool(t, c, "jira_get_issue", map[string]any{"issue_key": "PROJ-1"}); isErr {
+			t.Fatal(out)
+		}
+	}
+	if got := len(srv.RequestsTo("GET", "/rest/api/2/field")); got != 1 {
+		t.Errorf("field catalog fetched %d times, want 1", got)
+	}
+	if out, isErr := fakeatlassian.CallTool(t, c, "jira_search_fields", map[string]any{"keyword": "story", "refresh": true}); isErr {
+		t.Fatal(out)
+	}
+	if got := len(srv.RequestsTo("GET", "/rest/api/2/field")); got != 2 {
+		t.Errorf("field catalog fetched %d times after refresh, want 2", got)
+	}
+}
+
+func TestBulkUpdate(t *testing.T) {
+	t.Setenv("MCP_BULK_CHECKPOINT_DIR", t.TempDir())
+	srv := fakeatlassian.New(t)
+	c := fakeatlassian.NewMCPClient(t, AddTools)
+	call := func(args map[string]any) map[string]any {
+		t.Helper()
+		out, isErr := fakeatlassian.CallTool(t, c, "jira_bulk_update", args)
+		if isErr {
+			t.Fatal(out)
+		}
+		var result map[string]any
+		if err := json.Unmarshal([]byte(out), &result); err != nil {
+			t.Fatalf("%v: %s", err, out)
+		}
+		return result
+	}
+
+	plan := call(map[string]any{
+		"jql":           "project = PROJ AND issuetype != Epic",
+		"add_labels":    "triaged",
+		"remove_labels": "flaky",
+		"comment":       "Bulk triage",
+		"status":        "Done",
+		"rate":          50,
+	})
+	if keys, _ := json.Marshal(plan["keys"]); string(keys) != `["PROJ-2","PROJ-3"]` {
+		t.Fatalf("dry run keys = %s", keys)
+	}
+	if labels, _ := json.Marshal(srv.Issues["PROJ-2"].Fields["labels"]); string(labels) != `["flaky"]` {
+		t.Errorf("dry run changed labels: %s", labels)
+	}
+	checkpoint := plan["checkpoint"].(string)
+
+	// PROJ-3 is in To Do, which has no direct transition to Done.
+	result := call(map[string]any{"checkpoint": checkpoint, "dry_run": false, "rate": 50})
+	if result["succeeded"] != 1.0 || result["failed"] != 1.0 || result["complete"] != false {
+		t.Fatalf("first run = %+v", result)
+	}
+	if labels, _ := json.Marshal(srv.Issues["PROJ-2"].Fields["labels"]); string(labels) != `["triaged"]` {
+		t.Errorf("labels = %s", labels)
+	}
+	wantStatus("PROJ-2", "Done")(t, srv, "")
+	if comments := srv.Comments["PROJ-2"]; comments[len(comments)-1]["body"] != "Bulk triage" {
+		t.Errorf("comment not added: %+v", comments)
+	}
+
+	// Resuming retries only the failed issue.
+	srv.Issues["PROJ-3"].Fields["status"] = map[string]any{"id": "3", "name": "In Progress"}
+	result = call(map[string]any{"checkpoint": checkpoint, "dry_run": false, "rate": 50})
+	if result["succeeded"] != 2.0 || result["complete"] != true {
+		t.Fatalf("resumed run = %+v", result)
+	}
+	wantStatus("PROJ-3", "Done")(t, srv, "")
+	if got := len(srv.RequestsTo("PUT", "/issue/PROJ-2")); got != 1 {
+		t.Errorf("PROJ-2 edited %d times, want 1", got)
+	}
+	// PROJ-3 was edited and commented before its transition failed; resuming
+	// only transitions it.
+	if got := len(srv.RequestsTo("PUT", "/issue/PROJ-3")); got != 1 {
+		t.Errorf("PROJ-3 edited %d times, want 1", got)
+	}
+	var comments int
+	for _, c := range srv.Comments["PROJ-3"] {
+		if c["body"] == "Bulk triage" {
+			comments++
+		}
+	}
+	if comments != 1 {
+		t.Errorf("PROJ-3 has %d bulk comments, want 1", comments)
+	}
+	if steps := fmt.Sprint(result["results"].([]any)[1].(map[string]any)["steps"]); steps != "[edited In Progress -> Done]" {
+		t.Errorf("PROJ-3 steps = %s", steps)
+	}
+}
+
+func TestBulkUpdateErrors(t *testing.T) {
+	t.Setenv("MCP_BULK_CHECKPOINT_DIR", t.TempDir())
+	runToolTests(t, []toolTest{
+		{
+			name:     "no operations",
+			tool:     "jira_bulk_update",
+			args:     map[string]any{"jql": "project = PROJ"},
+			wantErr:  true,
+			contains: []string{"No operations given"},
+		},
+		{
+			name:     "too many issues",
+			tool:     "jira_bulk_update",
+			args:     map[string]any{"jql": "project = PROJ", "add_labels": "x", "max_issues": 2},
+			wantErr:  true,
+			contains: []string{"JQL matches 3 issues, more than the limit of 2"},
+		},
+		{
+			name:     "unknown checkpoint",
+			tool:     "jira_bulk_update",
+			args:     map[string]any{"checkpoint": "0123456789abcdef", "dry_run": false},
+			wantErr:  true,
+			contains: []string{"checkpoint 0123456789abcdef not found"},
+		},
+		{
+			name:     "assign by display name",
+			tool:     "jira_bulk_update",
+			args:     map[string]any{"jql": "key = PROJ-3", "assignee": "Alice Smith", "dry_run": false},
+			contains: []string{`"assignee":"Alice Smith","assignee_ref":{"name":"asmith"}`, `"complete":true`},
+			check: func(t *testing.T, srv *fakeatlassian.Server, _ string) {
+				if got := fmt.Sprint(srv.Issues["PROJ-3"].Fields["assignee"]); !strings.Contains(got, "name:asmith") {
+					t.Errorf("assignee = %v", got)
+				}
+			},
+		},
+		{
+			name: "assign on cloud by email",
+			tool: "jira_bulk_update",
+			setup: func(t *testing.T, srv *fakeatlassian.Server) {
+				srv.Deployment = "Cloud"
+				srv.Users[1]["accountId"] = "5b10ac8d82e05b22cc7d4ef5"
+			},
+			args:     map[string]any{"jql": "key = PROJ-3", "assignee": "alice.smith@example.com", "dry_run": false},
+			contains: []string{`"assignee_ref":{"accountId":"5b10ac8d82e05b22cc7d4ef5"}`, `"complete":true`},
+			check: func(t *testing.T, srv *fakeatlassian.Server, _ string) {
+				if got := fmt.Sprint(srv.Issues["PROJ-3"].Fields["assignee"]); !strings.Contains(got, "accountId:5b10ac8d82e05b22cc7d4ef5") {
+					t.Errorf("assignee = %v", got)
+				}
+			},
+		},
+		{
+			name:     "unknown assignee",
+			tool:     "jira_bulk_update",
+			args:     map[string]any{"jql": "key = PROJ-3", "assignee": "ghost"},
+			wantErr:  true,
+			contains: []string{`Invalid assignee: no user matches "ghost"`},
+		},
+		{
+			name:     "link issues",
+			tool:     "jira_bulk_update",
+			args:     map[string]any{"jql": "key = PROJ-3", "link_type": "Blocks", "link_issue": "PROJ-2", "dry_run": false},
+			contains: []string{`"steps":["linked Blocks PROJ-2"]`, `"complete":true`},
+			check: func(t *testing.T, srv *fakeatlassian.Server, _ string) {
+				if len(srv.Links) != 1 {
+					t.Errorf("links = %+v", srv.Links)
+				}
+			},
+		},
+	})
+}
+
+func TestComments(t *testing.T) {
+	runToolTests(t, []toolTest{
+		{
+			name:     "list comments newest first",
+			tool:     "jira_get_comments",
+			args:     map[string]any{"issue_key": "PROJ-2", "limit": 1},
+			contains: []string{`"total":2`, `"isLast":false`, "Second comment"},
+			excludes: []string{"First comment"},
+		},
+		{
+			name:     "list comments by author",
+			tool:     "jira_get_comments",
+			args:     map[string]any{"issue_key": "PROJ-2", "author": "John Doe"},
+			contains: []string{`"total":1`, "First comment"},
+			excludes: []string{"Second comment"},
+		},
+		{
+			name:     "list comments by date",
+			tool:     "jira_get_comments",
+			args:     map[string]any{"issue_key": "PROJ-2", "since": "2024-01-04", "until": "2024-01-04"},
+			contains: []string{`"total":1`, "Second comment"},
+		},
+		{
+			name: "list comments as markdown",
+			tool: "jira_get_comments",
+			setup: func(t *testing.T, srv *fakeatlassian.Server) {
+				srv.Comments["PROJ-2"][0]["body"] = "A *bold* claim"
+			},
+			args:     map[string]any{"issue_key": "PROJ-2", "order": "oldest", "as_markdown": true},
+			contains: []string{`"body":"A **bold** claim"`},
+			excludes: []string{"renderedBody"},
+		},
+		{
+			name:     "get comment",
+			tool:     "jira_get_comment",
+			args:     map[string]any{"issue_key": "PROJ-2", "comment_id": "30001"},
+			contains: []string{"First comment"},
+		},
+		{
+			name:     "get missing comment",
+			tool:     "jira_get_comment",
+			args:     map[string]any{"issue_key": "PROJ-2", "comment_id": "99"},
+			wantErr:  true,
+			contains: []string{"HTTP 404"},
+		},
+		{
+			name: "edit comment keeps visibility",
+			tool: "jira_edit_comment",
+			setup: func(t *testing.T, srv *fakeatlassian.Server) {
+				srv.Comments["PROJ-2"][0]["visibility"] = map[string]any{"type": "role", "value": "Developers"}
+			},
+			args:     map[string]any{"issue_key": "PROJ-2", "comment_id": "30001", "comment": "Corrected comment"},
+			contains: []string{"Corrected comment", "Developers"},
+		},
+		{
+			name:     "restrict comment visibility",
+			tool:     "jira_edit_comment",
+			args:     map[string]any{"issue_key": "PROJ-2", "comment_id": "30002", "visibility_type": "group", "visibility_value": "jira-admins"},
+			contains: []string{"Second comment", `"visibility":{"type":"group","value":"jira-admins"}`},
+		},
+		{
+			name:     "edit comment without changes",
+			tool:     "jira_edit_comment",
+			args:     map[string]any{"issue_key": "PROJ-2", "comment_id": "30002"},
+			wantErr:  true,
+			contains: []string{"Nothing to change"},
+		},
+		{
+			name:     "add restricted comment",
+			tool:     "jira_add_comment",
+			args:     map[string]any{"issue_key": "PROJ-3", "comment": "Internal note", "visibility_type": "role", "visibility_value": "Developers"},
+			contains: []string{`"visibility":{"type":"role","value":"Developers"}`},
+		},
+		{
+			name:     "add comment with invalid visibility",
+			tool:     "jira_add_comment",
+			args:     map[string]any{"issue_key": "PROJ-3", "comment": "Internal note", "visibility_type": "team"},
+			wantErr:  true,
+			contains: []string{`visibility_type "team" must be role, group or none`},
+		},
+		{
+			name:     "delete comment",
+			tool:     "jira_delete_comment",
+			args:     map[string]any{"issue_key": "PROJ-2", "comment_id": "30001"},
+			contains: []string{"deleted successfully"},
+			check: func(t *testing.T, srv *fakeatlassian.Server, _ string) {
+				if comments := srv.Comments["PROJ-2"]; len(comments) != 1 || comments[0]["id"] != "30002" {
+					t.Errorf("comments = %+v", comments)
+				}
+			},
+		},
+	})
+}
+
+func TestWatchersAndVotes(t *testing.T) {
+	runToolTests(t, []toolTest{
+		{
+			name:     "get watchers",
+			tool:     "jira_get_watchers",
+			args:     map[string]any{"issue_key": "PROJ-2"},
+			contains: []string{`"watchCount":1`, "John Doe"},
+		},
+		{
+			name:     "add watchers",
+			tool:     "jira_add_watchers",
+			args:     map[string]any{"issue_key": "PROJ-2", "usernames": "asmith, jdoe"},
+			contains: []string{"Added asmith, jdoe as watchers of PROJ-2"},
+			check: func(t *testing.T, srv *fakeatlassian.Server, _ string) {
+				if got := srv.Watchers["PROJ-2"]; !slices.Equal(got, []string{"jdoe", "asmith"}) {
+					t.Errorf("watchers = %v", got)
+				}
+				reqs := srv.RequestsTo("POST", "/issue/PROJ-2/watchers")
+				if len(reqs) != 2 || strings.TrimSpace(reqs[0].Body) != `"asmith"` {
+					t.Errorf("watcher requests = %+v", reqs)
+				}
+			},
+		},
+		{
+			name:     "add unknown watcher",
+			tool:     "jira_add_watchers",
+			args:     map[string]any{"issue_key": "PROJ-2", "usernames": "ghost"},
+			wantErr:  true,
+			contains: []string{`invalid watcher: no user matches "ghost"`},
+			check: func(t *testing.T, srv *fakeatlassian.Server, _ string) {
+				if reqs := srv.RequestsTo("POST", "/issue/PROJ-2/watchers"); len(reqs) != 0 {
+					t.Errorf("watcher requests = %+v", reqs)
+				}
+			},
+		},
+		{
+			name: "add watcher by email on Cloud",
+			tool: "jira_add_watchers",
+			setup: func(t *testing.T, srv *fakeatlassian.Server) {
+				srv.Deployment = "Cloud"
+				srv.Users[1]["accountId"] = "5b10ac8d82e05b22cc7d4ef5"
+			},
+			args:     map[string]any{"issue_key": "PROJ-2", "usernames": "alice.smith@example.com"},
+			contains: []string{"Added 5b10ac8d82e05b22cc7d4ef5 as watchers of PROJ-2"},
+			check: func(t *testing.T, srv *fakeatlassian.Server, _ string) {
+				reqs := srv.RequestsTo("POST", "/issue/PROJ-2/watchers")
+				if len(reqs) != 1 || strings.TrimSpace(reqs[0].Body) != `"5b10ac8d82e05b22cc7d4ef5"` {
+					t.Errorf("watcher requests = %+v", reqs)
+				}
+				if got := srv.Watchers["PROJ-2"]; !slices.Equal(got, []string{"jdoe", "asmith"}) {
+					t.Errorf("watchers = %v", got)
+				}
+			},
+		},
+		{
+			name: "unwatch as the current user on Cloud",
+			tool: "jira_remove_watchers",
+			setup: func(t *testing.T, srv *fakeatlassian.Server) {
+				srv.Deployment = "Cloud"
+				srv.Users[0]["accountId"] = "557058f0c5e3a1a2b3c4d5e6"
+			},
+			args:     map[string]any{"issue_key": "PROJ-2"},
+			contains: []string{"Removed 557058f0c5e3a1a2b3c4d5e6"},
+			check: func(t *testing.T, srv *fakeatlassian.Server, _ string) {
+				if got := srv.Watchers["PROJ-2"]; len(got) != 0 {
+					t.Errorf("watchers = %v", got)
+				}
+				reqs := srv.RequestsTo("DELETE", "/issue/PROJ-2/watchers")
+				if len(reqs) != 1 || !strings.Contains(reqs[0].Query, "accountId=557058f0c5e3a1a2b3c4d5e6") {
+					t.Errorf("watcher requests = %+v", reqs)
+				}
+			},
+		},
+		{
+			name:     "watch as the current user",
+			tool:     "jira_add_watchers",
+			args:     map[string]any{"issue_key": "PROJ-1"},
+			contains: []string{"Added jdoe"},
+		},
+		{
+			name:     "remove watchers",
+			tool:     "jira_remove_watchers",
+			args:     map[string]any{"issue_key": "PROJ-2"},
+			contains: []string{"Removed jdoe"},
+			check: func(t *testing.T, srv *fakeatlassian.Server, _ string) {
+				if got := srv.Watchers["PROJ-2"]; len(got) != 0 {
+					t.Errorf("watchers = %v", got)
+				}
+			},
+		},
+		{
+			name:     "watch issues matching JQL",
+			tool:     "jira_watch_issues",
+			args:     map[string]any{"jql": "project = PROJ", "usernames": "asmith"},
+			contains: []string{`"total":3`, `"succeeded":3`},
+			check: func(t *testing.T, srv *fakeatlassian.Server, _ string) {
+				for _, key := range []string{"PROJ-1", "PROJ-2", "PROJ-3"} {
+					if !slices.Contains(srv.Watchers[key], "asmith") {
+						t.Errorf("%s watchers = %v", key, srv.Watchers[key])
+					}
+				}
+			},
+		},
+		{
+			name:     "get votes",
+			tool:     "jira_get_votes",
+			args:     map[string]any{"issue_key": "PROJ-2"},
+			contains: []string{`"votes":1`, "Alice Smith"},
+		},
+		{
+			name:     "vote",
+			tool:     "jira_add_vote",
+			args:     map[string]any{"issue_key": "PROJ-2"},
+			contains: []string{"Voted for PROJ-2"},
+			check: func(t *testing.T, srv *fakeatlassian.Server, _ string) {
+				if got := srv.Votes["PROJ-2"]; !slices.Equal(got, []string{"asmith", "jdoe"}) {
+					t.Errorf("votes = %v", got)
+				}
+			},
+		},
+		{
+			name: "vote on a resolved issue",
+			tool: "jira_add_vote",
+			setup: func(t *testing.T, srv *fakeatlassian.Server) {
+				srv.Issues["PROJ-2"].Fields["resolution"] = map[string]any{"name": "Fixed"}
+			},
+			args:     map[string]any{"issue_key": "PROJ-2"},
+			wantErr:  true,
+			contains: []string{"cannot vote for a resolved issue"},
+		},
+		{
+			name: "remove vote",
+			tool: "jira_remove_vote",
+			setup: func(t *testing.T, srv *fakeatlassian.Server) {
+				srv.Votes["PROJ-2"] = []string{"asmith", "jdoe"}
+			},
+			args:     map[string]any{"issue_key": "PROJ-2"},
+			contains: []string{"Removed vote"},
+			check: func(t *testing.T, srv *fakeatlassian.Server, _ string) {
+				if got := srv.Votes["PROJ-2"]; !slices.Equal(got, []string{"asmith"}) {
+					t.Errorf("votes = %v", got)
+				}
+			},
+		},
+	})
+}
+
+func TestWorklogs(t *testing.T) {
+	// seedWork logs 30m by jdoe on PROJ-3 and 2h by asmith on PROJ-2, next to
+	// the seeded hour jdoe logged on PROJ-2.
+	seedWork := func(t *testing.T, srv *fakeatlassian.Server) {
+		jdoe := map[string]any{"name": "jdoe", "displayName": "John Doe"}
+		asmith := map[string]any{"name": "asmith", "displayName": "Alice Smith"}
+		srv.Worklogs["PROJ-3"] = []map[string]any{{"id": "20002", "author": jdoe, "timeSpent": "30m", "timeSpentSeconds": 1800, "started": "2024-01-05T14:00:00.000+0000", "comment": "Review"}}
+		srv.Worklogs["PROJ-2"] = append(srv.Worklogs["PROJ-2"], map[string]any{"id": "20003", "author": asmith, "timeSpent": "2h", "timeSpentSeconds": 7200, "started": "2024-01-04T13:00:00.000+0000", "comment": "Fix"})
+	}
+	wantQuery := func(method, suffix, want string) func(*testing.T, *fakeatlassian.Server, string) {
+		return func(t *testing.T, srv *fakeatlassian.Server, _ string) {
+			t.Helper()
+			reqs := srv.RequestsTo(method, suffix)
+			if len(reqs) != 1 || reqs[0].Query != want {
+				t.Errorf("%s %s requests = %+v, want query %q", method, suffix, reqs, want)
+			}
+		}
+	}
+	runToolTests(t, []toolTest{
+		{
+			name:     "worklog by author",
+			tool:     "jira_get_worklog",
+			setup:    seedWork,
+			args:     map[string]any{"issue_key": "PROJ-2", "author": "Alice Smith"},
+			contains: []string{`"total":1`, `"timeSpent":"2h"`, "Fix"},
+			excludes: []string{"Investigation"},
+		},
+		{
+			name:     "worklog by date",
+			tool:     "jira_get_worklog",
+			setup:    seedWork,
+			args:     map[string]any{"issue_key": "PROJ-2", "since": "2024-01-05"},
+			contains: []string{`"total":0`, `"worklogs":[]`},
+		},
+		{
+			name:  "add worklog with manual estimate",
+			tool:  "jira_add_worklog",
+			args:  map[string]any{"issue_key": "PROJ-3", "time_spent": "2h", "adjust_estimate": "manual", "reduce_by": "1h"},
+			check: wantQuery("POST", "/issue/PROJ-3/worklog", "adjustEstimate=manual&reduceBy=1h"),
+		},
+		{
+			name: "add worklog with legacy estimates",
+			tool: "jira_add_worklog",
+			args: map[string]any{"issue_key": "PROJ-3", "time_spent": "2h", "remaining_estimate": "3h", "original_estimate": "1d"},
+			check: func(t *testing.T, srv *fakeatlassian.Server, out string) {
+				wantQuery("POST", "/issue/PROJ-3/worklog", "adjustEstimate=new&newEstimate=3h")(t, srv, out)
+				if got := srv.Issues["PROJ-3"].Fields["timetracking"]; !strings.Contains(fmt.Sprint(got), "originalEstimate:1d") {
+					t.Errorf("timetracking = %v", got)
+				}
+			},
+		},
+		{
+			name:     "add worklog without reduce_by",
+			tool:     "jira_add_worklog",
+			args:     map[string]any{"issue_key": "PROJ-3", "time_spent": "2h", "adjust_estimate": "manual"},
+			wantErr:  true,
+			contains: []string{"adjust_estimate manual requires reduce_by"},
+		},
+		{
+			name:     "update worklog",
+			tool:     "jira_update_worklog",
+			args:     map[string]any{"issue_key": "PROJ-2", "worklog_id": "20001", "comment": "Root cause found", "new_estimate": "2h"},
+			contains: []string{"Root cause found"},
+			check: func(t *testing.T, srv *fakeatlassian.Server, out string) {
+				wantQuery("PUT", "/issue/PROJ-2/worklog/20001", "adjustEstimate=new&newEstimate=2h")(t, srv, out)
+				if l := srv.Worklogs["PROJ-2"][0]; l["timeSpent"] != "1h" || l["comment"] != "Root cause found" {
+					t.Errorf("worklog = %+v", l)
+				}
+			},
+		},
+		{
+			name:     "update worklog cannot adjust manually",
+			tool:     "jira_update_worklog",
+			args:     map[string]any{"issue_key": "PROJ-2", "worklog_id": "20001", "time_spent": "2h", "adjust_estimate": "manual"},
+			wantErr:  true,
+			contains: []string{"not supported when updating"},
+		},
+		{
+			name:     "delete worklog",
+			tool:     "jira_delete_worklog",
+			args:     map[string]any{"issue_key": "PROJ-2", "worklog_id": "20001", "increase_by": "1h"},
+			contains: []string{"Worklog deleted"},
+			check: func(t *testing.T, srv *fakeatlassian.Server, out string) {
+				wantQuery("DELETE", "/issue/PROJ-2/worklog/20001", "adjustEstimate=manual&increaseBy=1h")(t, srv, out)
+				if logs := srv.Worklogs["PROJ-2"]; len(logs) != 0 {
+					t.Errorf("worklogs = %+v", logs)
+				}
+			},
+		},
+		{
+			name:     "delete unknown worklog",
+			tool:     "jira_delete_worklog",
+			args:     map[string]any{"issue_key": "PROJ-2", "worklog_id": "99999"},
+			wantErr:  true,
+			contains: []string{"HTTP 404"},
+		},
+		{
+			name:     "timesheet by day",
+			tool:     "jira_timesheet",
+			setup:    seedWork,
+			args:     map[string]any{"since": "2024-01-01", "until": "2024-01-31"},
+			contains: []string{`"user":"jdoe"`, `"timeSpent":"1h 30m"`, `"hours":1.5`, `{"key":"2024-01-04","seconds":3600,"timeSpent":"1h","hours":1,"entries":1,"issues":["PROJ-2"]}`, `"key":"2024-01-05"`},
+			check:    wantLastSearch(`worklogAuthor = currentUser() AND worklogDate >= "2024-01-01" AND worklogDate <= "2024-01-31"`),
+		},
+		{
+			name: "timesheet for a user on Cloud",
+			tool: "jira_timesheet",
+			setup: func(t *testing.T, srv *fakeatlassian.Server) {
+				seedWork(t, srv)
+				srv.Deployment = "Cloud"
+				srv.Users[1]["accountId"] = "5b10ac8d82e05b22cc7d4ef5"
+				srv.Worklogs["PROJ-2"][1]["author"] = map[string]any{"accountId": "5b10ac8d82e05b22cc7d4ef5", "displayName": "Alice Smith"}
+				// A namesake's work must not be counted.
+				srv.Worklogs["PROJ-2"] = append(srv.Worklogs["PROJ-2"], map[string]any{"id": "20004", "author": map[string]any{"accountId": "6c21bd9e93f16c33dd8e5fa6", "displayName": "Alice Smith"}, "timeSpent": "1h", "timeSpentSeconds": 3600, "started": "2024-01-04T15:00:00.000+0000"})
+			},
+			args:     map[string]any{"user": "alice.smith@example.com", "since": "2024-01-04", "until": "2024-01-04"},
+			contains: []string{`"user":"5b10ac8d82e05b22cc7d4ef5"`, `"totalSeconds":7200`},
+			check:    wantLastSearch(`worklogAuthor = "5b10ac8d82e05b22cc7d4ef5" AND worklogDate >= "2024-01-04" AND worklogDate <= "2024-01-04"`),
+		},
+		{
+			name:     "timesheet for an unknown user",
+			tool:     "jira_timesheet",
+			args:     map[string]any{"user": "ghost", "since": "2024-01-01"},
+			wantErr:  true,
+			contains: []string{`Invalid user: no user matches "ghost"`},
+		},
+		{
+			name:     "timesheet by issue for another user",
+			tool:     "jira_timesheet",
+			setup:    seedWork,
+			args:     map[string]any{"user": "asmith", "since": "2024-01-04", "until": "2024-01-04", "group_by": "issue", "jql": "project = PROJ ORDER BY key"},
+			contains: []string{`"timeSpent":"2h"`, `"key":"PROJ-2","summary":"Login form rejects valid passwords"`},
+			excludes: []string{"PROJ-3"},
+			check:    wantLastSearch(`(project = PROJ) AND worklogAuthor = "asmith" AND worklogDate >= "2024-01-04" AND worklogDate <= "2024-01-04" ORDER BY key`),
+		},
+		{
+			name:     "timesheet by epic",
+			tool:     "jira_timesheet",
+			setup:    seedWork,
+			args:     map[string]any{"since": "2024-01-01", "until": "2024-01-31", "group_by": "epic"},
+			contains: []string{`"groups":[{"key":"PROJ-1","seconds":5400,"timeSpent":"1h 30m","hours":1.5,"entries":2,"issues":["PROJ-2","PROJ-3"]}]`},
+		},
+		{
+			name: "timesheet by epic through sub-task parents",
+			tool: "jira_timesheet",
+			setup: func(t *testing.T, srv *fakeatlassian.Server) {
+				seedWork(t, srv)
+				srv.Issues["PROJ-4"] = &fakeatlassian.Issue{ID: "10004", Key: "PROJ-4", Fields: map[string]any{
+					"project":   map[string]any{"id": "10000", "key": "PROJ"},
+					"summary":   "Write tests",
+					"issuetype": map[string]any{"id": "10003", "name": "Sub-task", "subtask": true},
+					"parent":    map[string]any{"key": "PROJ-3"},
+				}}
+				srv.Worklogs["PROJ-4"] = []map[string]any{{"id": "20004", "author": map[string]any{"name": "jdoe"}, "timeSpent": "1h", "timeSpentSeconds": 3600, "started": "2024-01-06T09:00:00.000+0000"}}
+				// A parent-based hierarchy: the story reaches its epic through parent.
+				delete(srv.Issues["PROJ-3"].Fields, "customfield_10014")
+				srv.Issues["PROJ-3"].Fields["parent"] = map[string]any{"key": "PROJ-1"}
+			},
+			args:     map[string]any{"since": "2024-01-01", "until": "2024-01-31", "group_by": "epic"},
+			contains: []string{`"groups":[{"key":"PROJ-1","seconds":9000,"timeSpent":"2h 30m","hours":2.5,"entries":3,"issues":["PROJ-2","PROJ-3","PROJ-4"]}]`},
+		},
+		{
+			name:     "timesheet with inverted range",
+			tool:     "jira_timesheet",
+			args:     map[string]any{"since": "2024-02-01", "until": "2024-01-01"},
+			wantErr:  true,
+			contains: []string{"before since"},
+		},
+	})
+}
+
+func TestUsers(t *testing.T) {
+	// addNamesake adds an inactive second John Doe.
+	addNamesake := func(t *testing.T, srv *fakeatlassian.Server) {
+		srv.Users = append(srv.Users, map[string]any{"name": "jdoe2", "key": "JIRAUSER10002", "displayName": "John Doe", "emailAddress": "jdoe2@example.com", "active": false})
+	}
+	runToolTests(t, []toolTest{
+		{
+			name:     "search users",
+			tool:     "jira_search_users",
+			args:     map[string]any{"query": "example.com"},
+			contains: []string{`"total":2`, `"name":"jdoe"`, `"name":"asmith"`},
+			check: func(t *testing.T, srv *fakeatlassian.Server, _ string) {
+				reqs := srv.RequestsTo("GET", "/user/search")
+				if len(reqs) != 1 || reqs[0].Query != "maxResults=10&username=example.com" {
+					t.Errorf("requests = %+v", reqs)
+				}
+			},
+		},
+		{
+			name:     "search assignable users",
+			tool:     "jira_search_users",
+			setup:    addNamesake,
+			args:     map[string]any{"query": "john", "project_key": "PROJ"},
+			contains: []string{`"total":1`, `"name":"jdoe"`},
+			excludes: []string{"jdoe2"},
+		},
+		{
+			name:     "user profile by email",
+			tool:     "jira_get_user_profile",
+			args:     map[string]any{"user_identifier": "alice.smith@example.com"},
+			contains: []string{`"name":"asmith"`},
+		},
+		{
+			name:     "user profile by ambiguous name",
+			tool:     "jira_get_user_profile",
+			setup:    addNamesake,
+			args:     map[string]any{"user_identifier": "John Doe"},
+			wantErr:  true,
+			contains: []string{`user "John Doe" is ambiguous`, "John Doe (jdoe, john.doe@example.com); John Doe (jdoe2, jdoe2@example.com)", "use the username"},
+		},
+		{
+			name:  "create issue assigned by display name",
+			tool:  "jira_create_issue",
+			setup: addNamesake,
+			args:  map[string]any{"project_key": "PROJ", "summary": "x", "issue_type": "Task", "assignee": "john doe"},
+			check: func(t *testing.T, srv *fakeatlassian.Server, _ string) {
+				if got := srv.Issues["PROJ-4"].Fields["assignee"]; !strings.Contains(fmt.Sprint(got), "name:jdoe]") {
+					t.Errorf("assignee = %v", got)
+				}
+			},
+		},
+		{
+			name: "user profile on cloud uses the account ID",
+			tool: "jira_get_user_profile",
+			setup: func(t *testing.T, srv *fakeatlassian.Server) {
+				srv.Deployment = "Cloud"
+				srv.Users[1]["accountId"] = "5b10ac8d82e05b22cc7d4ef5"
+			},
+			args:     map[string]any{"user_identifier": "5b10ac8d82e05b22cc7d4ef5"},
+			contains: []string{`"displayName":"Alice Smith"`},
+			check: func(t *testing.T, srv *fakeatlassian.Server, _ string) {
+				reqs := srv.RequestsTo("GET", "/rest/api/2/user")
+				if len(reqs) != 1 || reqs[0].Query != "accountId=5b10ac8d82e05b22cc7d4ef5" {
+					t.Errorf("requests = %+v, want accountId=5b10ac8d82e05b22cc7d4ef5", reqs)
+				}
+			},
+		},
+		{
+			name: "user profile on cloud by email",
+			tool: "jira_get_user_profile",
+			setup: func(t *testing.T, srv *fakeatlassian.Server) {
+				srv.Deployment = "Cloud"
+				srv.Users[1]["accountId"] = "5b10ac8d82e05b22cc7d4ef5"
+			},
+			args:     map[string]any{"user_identifier": "alice.smith@example.com"},
+			contains: []string{`"accountId":"5b10ac8d82e05b22cc7d4ef5"`},
+		},
+		{
+			name: "create issue on cloud uses the account ID",
+			tool: "jira_create_issue",
+			setup: func(t *testing.T, srv *fakeatlassian.Server) {
+				srv.Deployment = "Cloud"
+				srv.Users[1]["accountId"] = "5b10ac8d82e05b22cc7d4ef5"
+			},
+			args: map[string]any{"project_key": "PROJ", "summary": "x", "issue_type": "Task", "assignee": "Alice"},
+			check: func(t *testing.T, srv *fakeatlassian.Server, _ string) {
+				if got := srv.Issues["PROJ-4"].Fields["assignee"]; fmt.Sprint(got) != "map[accountId:5b10ac8d82e05b22cc7d4ef5]" {
+					t.Errorf("assignee = %v", got)
+				}
+				reqs := srv.RequestsTo("GET", "/user/assignable/search")
+				if len(reqs) != 1 || !strings.Contains(reqs[0].Query, "query=Alice") {
+					t.Errorf("requests = %+v", reqs)
+				}
+			},
+		},
+	})
+}
+
+func TestProjects(t *testing.T) {
+	runToolTests(t, []toolTest{
+		{
+			name:     "list projects",
+			tool:     "jira_list_projects",
+			contains: []string{`"total":2`, `"key":"PROJ"`, `"category":"Engineering"`, `"lead":{"name":"jdoe"`, `"key":"SUP"`},
+		},
+		{
+			name:     "list projects by category",
+			tool:     "jira_list_projects",
+			args:     map[string]any{"category": "operations"},
+			contains: []string{`"total":1`, `"key":"SUP"`},
+			excludes: []string{`"key":"PROJ"`},
+		},
+		{
+			name:     "list projects by name",
+			tool:     "jira_list_projects",
+			args:     map[string]any{"query": "supp"},
+			contains: []string{`"total":1`, `"name":"Support"`},
+		},
+		{
+			name:     "get project",
+			tool:     "jira_get_project",
+			args:     map[string]any{"project_key": "PROJ"},
+			contains: []string{`"components":[{`, `"name":"1.1"`, `"issueTypes":[{`, `"roles":["Administrators","Developers","Users"]`, `"workflowScheme":{`, "PROJ Workflow Scheme"},
+		},
+		{
+			name:     "get unknown project",
+			tool:     "jira_get_project",
+			args:     map[string]any{"project_key": "NOPE"},
+			wantErr:  true,
+			contains: []string{"Failed to get project: HTTP 404", "No project could be found with key 'NOPE'"},
+		},
+		{
+			name:     "issue types of a project",
+			tool:     "jira_get_issue_types",
+			args:     map[string]any{"project_key": "PROJ"},
+			contains: []string{`"name":"Sub-task"`},
+		},
+		{
+			name:     "components",
+			tool:     "jira_get_components",
+			args:     map[string]any{"project_key": "PROJ"},
+			contains: []string{`"name":"UI"`, `"name":"API"`},
+		},
+		{
+			name:     "create component with lead by display name",
+			tool:     "jira_create_component",
+			args:     map[string]any{"project_key": "PROJ", "name": "Mobile", "lead": "Alice Smith", "assignee_type": "component_lead"},
+			contains: []string{`"name":"Mobile"`, `"assigneeType":"COMPONENT_LEAD"`},
+			check: func(t *testing.T, srv *fakeatlassian.Server, _ string) {
+				reqs := srv.RequestsTo("POST", "/rest/api/2/component")
+				if len(reqs) != 1 || !strings.Contains(reqs[0].Body, `"leadUserName":"asmith"`) {
+					t.Errorf("requests = %+v", reqs)
+				}
+			},
+		},
+		{
+			name:     "create duplicate component",
+			tool:     "jira_create_component",
+			args:     map[string]any{"project_key": "PROJ", "name": "ui"},
+			wantErr:  true,
+			contains: []string{"Failed to create component: HTTP 400", "already exists"},
+		},
+		{
+			name: "delete component moving its issues",
+			tool: "jira_delete_component",
+			setup: func(t *testing.T, srv *fakeatlassian.Server) {
+				srv.Issues["PROJ-2"].Fields["components"] = []any{map[string]any{"id": "10100", "name": "UI"}}
+			},
+			args:     map[string]any{"component_id": "10100", "move_issues_to": "10101"},
+			contains: []string{"Component deleted"},
+			check: func(t *testing.T, srv *fakeatlassian.Server, _ string) {
+				if got := fmt.Sprint(srv.Issues["PROJ-2"].Fields["components"]); got != "[map[id:10101 name:API]]" {
+					t.Errorf("components = %s", got)
+				}
+				if len(srv.Components) != 1 {
+					t.Errorf("components left = %v", srv.Components)
+				}
+			},
+		},
+		{
+			name:     "create version",
+			tool:     "jira_create_version",
+			args:     map[string]any{"project_key": "PROJ", "name": "2.0", "release_date": "2024-06-01T12:00:00Z"},
+			contains: []string{`"name":"2.0"`, `"releaseDate":"2024-06-01"`, `"released":false`},
+		},
+		{
+			name:     "create version with bad date",
+			tool:     "jira_create_version",
+			args:     map[string]any{"project_key": "PROJ", "name": "2.0", "start_date": "soon"},
+			wantErr:  true,
+			contains: []string{"invalid start_date"},
+		},
+		{
+			name:     "update version",
+			tool:     "jira_update_version",
+			args:     map[string]any{"version_id": "10201", "description": "Remember me"},
+			contains: []string{`"description":"Remember me"`, `"name":"1.1"`},
+		},
+		{
+			name: "delete version moving fixed issues",
+			tool: "jira_delete_version",
+			setup: func(t *testing.T, srv *fakeatlassian.Server) {
+				srv.Issues["PROJ-3"].Fields["fixVersions"] = []any{map[string]any{"id": "10201", "name": "1.1"}}
+			},
+			args: map[string]any{"version_id": "10201", "move_fix_issues_to": "10200"},
+			check: func(t *testing.T, srv *fakeatlassian.Server, _ string) {
+				if got := fmt.Sprint(srv.Issues["PROJ-3"].Fields["fixVersions"]); got != "[map[id:10200 name:1.0]]" {
+					t.Errorf("fixVersions = %s", got)
+				}
+			},
+		},
+	})
+}
+
+func TestReleases(t *testing.T) {
+	inRelease := func(t *testing.T, srv *fakeatlassian.Server) {
+		for _, key := range []string{"PROJ-2", "PROJ-3"} {
+			srv.Issues[key].Fields["fixVersions"] = []any{map[string]any{"id": "10201", "name": "1.1"}}
+		}
+		srv.Issues["PROJ-2"].Fields["components"] = []any{map[string]any{"id": "10100", "name": "UI"}}
+		srv.Issues["PROJ-3"].Fields["resolution"] = map[string]any{"name": "Done"}
+	}
+	runToolTests(t, []toolTest{
+		{
+			name:     "release version",
+			tool:     "jira_release_version",
+			args:     map[string]any{"version_id": "10201", "release_date": "2024-07-01"},
+			contains: []string{`"released":true`, `"releaseDate":"2024-07-01"`},
+			excludes: []string{`"moved"`},
+		},
+		{
+			name: "release version moving unresolved issues",
+			tool: "jira_release_version",
+			setup: func(t *testing.T, srv *fakeatlassian.Server) {
+				inRelease(t, srv)
+				srv.Versions = append(srv.Versions, map[string]any{"id": "10202", "name": "1.2", "project": "PROJ", "released": false, "archived": false})
+			},
+			args:     map[string]any{"version_id": "10201", "move_unresolved_to": "10202"},
+			contains: []string{`"to":"1.2"`, `"succeeded":1`, `"key":"PROJ-2"`, `"released":true`},
+			check: func(t *testing.T, srv *fakeatlassian.Server, _ string) {
+				if got := fmt.Sprint(srv.Issues["PROJ-2"].Fields["fixVersions"]); got != "[map[id:10202]]" {
+					t.Errorf("PROJ-2 fixVersions = %s", got)
+				}
+				if got := fmt.Sprint(srv.Issues["PROJ-3"].Fields["fixVersions"]); got != "[map[id:10201 name:1.1]]" {
+					t.Errorf("PROJ-3 fixVersions = %s", got)
+				}
+			},
+		},
+		{
+			name:     "move unresolved to unknown version",
+			tool:     "jira_move_unresolved_issues",
+			args:     map[string]any{"from_version_id": "10201", "to_version_id": "99999"},
+			wantErr:  true,
+			contains: []string{"Failed to get version 99999: HTTP 404"},
+		},
+		{
+			name:     "archive version",
+			tool:     "jira_archive_version",
+			args:     map[string]any{"version_id": "10200"},
+			contains: []string{`"archived":true`},
+		},
+		{
+			name:     "unarchive version",
+			tool:     "jira_archive_version",
+			args:     map[string]any{"version_id": "10200", "archived": false},
+			contains: []string{`"archived":false`},
+		},
+		{
+			name:     "release notes by type",
+			tool:     "jira_release_notes",
+			setup:    inRelease,
+			args:     map[string]any{"project_key": "PROJ", "version": "1.1"},
+			contains: []string{"# PROJ 1.1 release notes", "## Bug\n\n- [PROJ-2](", "/browse/PROJ-2) Login form rejects valid passwords", "## Story\n\n- [PROJ-3]("},
+			check:    wantLastSearch(`project = "PROJ" AND fixVersion = 10201 ORDER BY key ASC`),
+		},
+		{
+			name:     "release notes by component",
+			tool:     "jira_release_notes",
+			setup:    inRelease,
+			args:     map[string]any{"project_key": "PROJ", "version": "10201", "group_by": "component"},
+			contains: []string{"## UI\n\n- [PROJ-2](", "## Other\n\n- [PROJ-3]("},
+		},
+		{
+			name:     "release notes for unknown version",
+			tool:     "jira_release_notes",
+			args:     map[string]any{"project_key": "PROJ", "version": "9.9"},
+			wantErr:  true,
+			contains: []string{`project PROJ has no version "9.9"`},
+		},
+		{
+			name:     "publish release notes",
+			tool:     "jira_release_notes",
+			setup:    inRelease,
+			args:     map[string]any{"project_key": "PROJ", "version": "1.1", "space_key": "DEV", "title": "Release 1.1"},
+			contains: []string{"# Release 1.1", "Published to Confluence page"},
+			check: func(t *testing.T, srv *fakeatlassian.Server, _ string) {
+				for _, p := range srv.Pages {
+					if p.Title == "Release 1.1" {
+						if p.SpaceKey != "DEV" || !strings.Contains(p.Body, "h2. Bug") || !strings.Contains(p.Body, "* [PROJ-2|") {
+							t.Errorf("page = %+v", p)
+						}
+						return
+					}
+				}
+				t.Error("release notes page not created")
+			},
+		},
+	})
+}
+
+func TestCreateMeta(t *testing.T) {
+	requireTeam := func(t *testing.T, srv *fakeatlassian.Server) {
+		srv.Required["Bug"] = []string{"customfield_10020", "duedate"}
+	}
+	runToolTests(t, []toolTest{
+		{
+			name:     "required fields per issue type",
+			tool:     "jira_get_create_meta",
+			args:     map[string]any{"project_key": "PROJ"},
+			contains: []string{`"name":"Sub-task"`, `{"id":"parent","name":"Parent","type":"issuelink","required":true}`, `{"id":"summary","name":"Summary","type":"string","required":true}`},
+			excludes: []string{`"id":"labels"`},
+		},
+		{
+			name:     "fields of one issue type",
+			tool:     "jira_get_create_meta",
+			args:     map[string]any{"project_key": "PROJ", "issue_type": "bug"},
+			contains: []string{`"name":"Bug"`, `"id":"customfield_10020","name":"Team","type":"option","required":false,"allowedValues":["Platform","Mobile","Payments"]`, `"type":"array of component","required":false,"allowedValues":["UI","API"]`},
+		},
+		{
+			name:     "create meta of unknown project",
+			tool:     "jira_get_create_meta",
+			args:     map[string]any{"project_key": "NOPE"},
+			wantErr:  true,
+			contains: []string{"project NOPE does not exist or you cannot create issues in it"},
+		},
+		{
+			name:     "create issue reports every missing required field",
+			tool:     "jira_create_issue",
+			setup:    requireTeam,
+			args:     map[string]any{"project_key": "PROJ", "summary": "Crash", "issue_type": "Bug"},
+			wantErr:  true,
+			contains: []string{"cannot create Bug in PROJ:", "- missing required field Due Date (duedate)\n", "- missing required field Team (customfield_10020); valid values: Platform, Mobile, Payments"},
+			check: func(t *testing.T, srv *fakeatlassian.Server, _ string) {
+				if reqs := srv.RequestsTo("POST", "/rest/api/2/issue"); len(reqs) != 0 {
+					t.Errorf("issue created despite missing fields: %v", reqs)
+				}
+			},
+		},
+		{
+			name:  "create issue matches allowed values by case and prefix",
+			tool:  "jira_create_issue",
+			setup: requireTeam,
+			args: map[string]any{
+				"project_key": "PROJ", "summary": "Crash", "issue_type": "bug", "components": "ui",
+				"additional_fields": `{"Team": "plat", "priority": "HIGH", "duedate": "2026-01-31"}`,
+			},
+			contains: []string{`"key":"PROJ-4"`},
+			check: func(t *testing.T, srv *fakeatlassian.Server, _ string) {
+				got, _ := json.Marshal(srv.Issues["PROJ-4"].Fields)
+				for _, want := range []string{`"issuetype":{"id":"10004","name":"Bug"`, `"components":[{"name":"UI"}]`, `"customfield_10020":{"value":"Platform"}`, `"priority":{"name":"High"}`} {
+					if !strings.Contains(string(got), want) {
+						t.Errorf("fields %s do not contain %s", got, want)
+					}
+				}
+			},
+		},
+		{
+			name:     "create issue with invalid values",
+			tool:     "jira_create_issue",
+			args:     map[string]any{"project_key": "PROJ", "summary": "x", "issue_type": "Task", "components": "UI, Backend", "additional_fields": `{"Team": "Desktop"}`},
+			wantErr:  true,
+			contains: []string{`- Component/s (components): no value matches "Backend"; valid values: UI, API`, `- Team (customfield_10020): no value matches "Desktop"; valid values: Platform, Mobile, Payments`},
+		},
+		{
+			name:     "create issue does not guess misspelt or partial values",
+			tool:     "jira_create_issue",
+			args:     map[string]any{"project_key": "PROJ", "summary": "x", "issue_type": "Task", "components": "pi", "additional_fields": `{"Team": "form", "priority": "hgh"}`},
+			wantErr:  true,
+			contains: []string{`- Component/s (components): no value matches "pi"; did you mean "UI" or "API"; valid values: UI, API`, `- Team (customfield_10020): no value matches "form"; did you mean "Platform"; valid values: Platform, Mobile, Payments`, `- Priority (priority): no value matches "hgh"; did you mean "High"; valid values:`},
+			check: func(t *testing.T, srv *fakeatlassian.Server, _ string) {
+				if reqs := srv.RequestsTo("POST", "/rest/api/2/issue"); len(reqs) != 0 {
+					t.Errorf("issue created with guessed values: %v", reqs)
+				}
+			},
+		},
+		{
+			name:     "create issue with a misspelt issue type",
+			tool:     "jira_create_issue",
+			args:     map[string]any{"project_key": "PROJ", "summary": "x", "issue_type": "bgu"},
+			wantErr:  true,
+			contains: []string{`invalid issue type for project PROJ: no value matches "bgu"; did you mean "Bug"`},
+		},
+		{
+			name:     "create issue with unknown issue type",
+			tool:     "jira_create_issue",
+			args:     map[string]any{"project_key": "PROJ", "summary": "x", "issue_type": "Incident"},
+			wantErr:  true,
+			contains: []string{`invalid issue type for project PROJ: no value matches "Incident"; valid values: Epic, Story, Task, Sub-task, Bug`},
+		},
+		{
+			name: "create meta from the paged resources",
+			tool: "jira_get_create_meta",
+			setup: func(t *testing.T, srv *fakeatlassian.Server) {
+				srv.Missing = []string{"/issue/createmeta"}
+			},
+			args:     map[string]any{"project_key": "PROJ", "issue_type": "Bug"},
+			contains: []string{`"name":"Bug"`, `{"id":"summary","name":"Summary","type":"string","required":true}`, `"id":"customfield_10020","name":"Team","type":"option","required":false,"allowedValues":["Platform","Mobile","Payments"]`},
+			check: func(t *testing.T, srv *fakeatlassian.Server, _ string) {
+				if reqs := srv.RequestsTo("GET", "/issue/createmeta/PROJ/issuetypes/10004"); len(reqs) != 1 {
+					t.Errorf("field requests = %+v", reqs)
+				}
+			},
+		},
+		{
+			name: "create issue validates against the paged resources",
+			tool: "jira_create_issue",
+			setup: func(t *testing.T, srv *fakeatlassian.Server) {
+				requireTeam(t, srv)
+				srv.Missing = []string{"/issue/createmeta"}
+			},
+			args:     map[string]any{"project_key": "PROJ", "summary": "Crash", "issue_type": "Bug"},
+			wantErr:  true,
+			contains: []string{"- missing required field Team (customfield_10020)"},
+		},
+		{
+			name: "create issue without create metadata",
+			tool: "jira_create_issue",
+			setup: func(t *testing.T, srv *fakeatlassian.Server) {
+				srv.Missing = []string{"/issue/createmeta", "/issuetypes"}
+			},
+			args:     map[string]any{"project_key": "PROJ", "summary": "Crash", "issue_type": "Bug", "components": "UI"},
+			contains: []string{`"key":"PROJ-4"`},
+			check: func(t *testing.T, srv *fakeatlassian.Server, _ string) {
+				reqs := srv.RequestsTo("POST", "/rest/api/2/issue")
+				if len(reqs) != 1 || !strings.Contains(reqs[0].Body, `"issuetype":{"name":"Bug"}`) {
+					t.Errorf("create requests = %+v", reqs)
+				}
+			},
+		},
+	})
+}
+
+func TestHierarchy(t *testing.T) {
+	withSubtask := func(t *testing.T, srv *fakeatlassian.Server) {
+		srv.Issues["PROJ-4"] = &fakeatlassian.Issue{ID: "10004", Key: "PROJ-4", Fields: map[string]any{
+			"project":   map[string]any{"id": "10000", "key": "PROJ"},
+			"summary":   "Write tests",
+			"issuetype": map[string]any{"id": "10003", "name": "Sub-task", "subtask": true},
+			"status":    map[string]any{"id": "10001", "name": "Done", "statusCategory": map[string]any{"key": "done"}},
+			"parent":    map[string]any{"key": "PROJ-3"},
+		}}
+	}
+	runToolTests(t, []toolTest{
+		{
+			name:  "hierarchy from a sub-task",
+			tool:  "jira_get_hierarchy",
+			setup: withSubtask,
+			args:  map[string]any{"issue_key": "PROJ-4"},
+			contains: []string{
+				`"path":["PROJ-1","PROJ-3","PROJ-4"]`, `"total":4`,
+				`"key":"PROJ-1","summary":"Epic for the login revamp","type":"Epic","status":"To Do","assignee":"John Doe","rollup":{"total":3,"toDo":1,"inProgress":1,"done":1,"percentDone":33}`,
+				`"key":"PROJ-3","summary":"Add remember-me checkbox","type":"Story","status":"To Do","relation":"epic","rollup":{"total":1,"toDo":0,"inProgress":0,"done":1,"percentDone":100}`,
+				`"key":"PROJ-4","summary":"Write tests","type":"Sub-task","status":"Done","relation":"subtask"}`,
+			},
+		},
+		{
+			name:     "hierarchy below an issue",
+			tool:     "jira_get_hierarchy",
+			setup:    withSubtask,
+			args:     map[string]any{"issue_key": "PROJ-3", "include_ancestors": false},
+			contains: []string{`"path":["PROJ-3"]`, `"total":2`, `"root":{"key":"PROJ-3"`},
+			excludes: []string{`"PROJ-1"`},
+		},
+		{
+			name:     "epic issues",
+			tool:     "jira_get_epic_issues",
+			args:     map[string]any{"epic_key": "PROJ-1"},
+			contains: []string{`"total":2`, `"key":"PROJ-2"`, `"key":"PROJ-3"`, `"relation":"epic"`},
+			check:    wantLastSearch("parent in (PROJ-1) OR cf[10014] in (PROJ-1) ORDER BY key ASC"),
+		},
+		{
+			name:     "subtasks",
+			tool:     "jira_get_subtasks",
+			setup:    withSubtask,
+			args:     map[string]any{"issue_key": "PROJ-3"},
+			contains: []string{`"total":1`, `"key":"PROJ-4"`, `"relation":"subtask"`},
+		},
+		{
+			name:     "create subtask",
+			tool:     "jira_create_subtask",
+			args:     map[string]any{"parent_key": "PROJ-3", "summary": "Style the checkbox"},
+			contains: []string{`"key":"PROJ-4"`},
+			check: func(t *testing.T, srv *fakeatlassian.Server, _ string) {
+				got, _ := json.Marshal(srv.Issues["PROJ-4"].Fields)
+				for _, want := range []string{`"parent":{"key":"PROJ-3"}`, `"name":"Sub-task","subtask":true`} {
+					if !strings.Contains(string(got), want) {
+						t.Errorf("fields %s do not contain %s", got, want)
+					}
+				}
+			},
+		},
+		{
+			name:     "create subtask of a subtask",
+			tool:     "jira_create_subtask",
+			setup:    withSubtask,
+			args:     map[string]any{"parent_key": "PROJ-4", "summary": "Nested"},
+			wantErr:  true,
+			contains: []string{"PROJ-4 is a sub-task; sub-tasks cannot have sub-tasks"},
+		},
+		{
+			name:     "create issue in an epic",
+			tool:     "jira_create_issue",
+			args:     map[string]any{"project_key": "PROJ", "summary": "Forgot password link", "issue_type": "Story", "epic_key": "PROJ-1"},
+			contains: []string{`"key":"PROJ-4"`},
+			check: func(t *testing.T, srv *fakeatlassian.Server, _ string) {
+				if got := srv.Issues["PROJ-4"].Fields["customfield_10014"]; got != "PROJ-1" {
+					t.Errorf("epic link = %v", got)
+				}
+			},
+		},
+		{
+			name:     "remove from epic",
+			tool:     "jira_remove_from_epic",
+			args:     map[string]any{"issue_keys": "PROJ-2, PROJ-3"},
+			contains: []string{"Removed PROJ-2, PROJ-3 from their epic"},
+			check: func(t *testing.T, srv *fakeatlassian.Server, _ string) {
+				for _, key := range []string{"PROJ-2", "PROJ-3"} {
+					if got, ok := srv.Issues[key].Fields["customfield_10014"]; ok {
+						t.Errorf("%s epic link = %v", key, got)
+					}
+				}
+			},
+		},
+	})
+}
+
+func TestDependencyGraph(t *testing.T) {
+	chain := func(t *testing.T, srv *fakeatlassian.Server) {
+		srv.Link("Blocks", "PROJ-1", "PROJ-2")
+		srv.Link("Blocks", "PROJ-2", "PROJ-3")
+	}
+	cycle := func(t *testing.T, srv *fakeatlassian.Server) {
+		chain(t, srv)
+		srv.Link("Blocks", "PROJ-3", "PROJ-1")
+	}
+	runToolTests(t, []toolTest{
+		{
+			name:  "blocking chain",
+			tool:  "jira_get_dependency_graph",
+			setup: chain,
+			args:  map[string]any{"issue_key": "PROJ-3"},
+			contains: []string{
+				`"roots":["PROJ-3"]`,
+				`{"from":"PROJ-1","to":"PROJ-2","type":"Blocks","label":"blocks"}`,
+				`{"from":"PROJ-2","to":"PROJ-3","type":"Blocks","label":"blocks"}`,
+				`"cycles":[]`, `"criticalPath":["PROJ-1","PROJ-2","PROJ-3"]`,
+			},
+		},
+		{
+			name:     "outward only",
+			tool:     "jira_get_dependency_graph",
+			setup:    chain,
+			args:     map[string]any{"issue_key": "PROJ-2", "direction": "outward"},
+			contains: []string{`{"from":"PROJ-2","to":"PROJ-3"`},
+			excludes: []string{`"from":"PROJ-1"`},
+		},
+		{
+			name:     "cycle",
+			tool:     "jira_get_dependency_graph",
+			setup:    cycle,
+			args:     map[string]any{"issue_key": "PROJ-1"},
+			contains: []string{`"cycles":[["PROJ-1","PROJ-2","PROJ-3","PROJ-1"]]`},
+		},
+		{
+			name:  "mermaid",
+			tool:  "jira_get_dependency_graph",
+			setup: chain,
+			args:  map[string]any{"issue_key": "PROJ-3", "format": "mermaid"},
+			contains: []string{
+				"graph LR", `PROJ_2["PROJ-2: Login form rejects valid passwords (In Progress)"]`, "PROJ_1 -->|blocks| PROJ_2", "linkStyle 0,1 stroke:#d00",
+			},
+		},
+		{
+			name:     "dot",
+			tool:     "jira_get_dependency_graph",
+			setup:    cycle,
+			args:     map[string]any{"issue_key": "PROJ-1", "format": "dot"},
+			contains: []string{"digraph dependencies {", `"PROJ-1" -> "PROJ-2" [label="blocks"`, "// cycle: PROJ-1 -> PROJ-2 -> PROJ-3 -> PROJ-1"},
+		},
+		{
+			name:     "epic membership",
+			tool:     "jira_get_dependency_graph",
+			args:     map[string]any{"issue_key": "PROJ-1", "link_types": "epic"},
+			contains: []string{`{"from":"PROJ-1","to":"PROJ-2","type":"epic"`, `{"from":"PROJ-1","to":"PROJ-3","type":"epic"`},
+		},
+		{
+			name: "link types match whole names and verbs only",
+			tool: "jira_get_dependency_graph",
+			setup: func(t *testing.T, srv *fakeatlassian.Server) {
+				srv.LinkTypes = append(srv.LinkTypes, map[string]any{"id": "10003", "name": "Blocker gate", "inward": "is gated by", "outward": "gates"})
+				srv.Link("Blocker gate", "PROJ-1", "PROJ-3")
+				srv.Link("Blocks", "PROJ-1", "PROJ-2")
+				srv.Link("Duplicate", "PROJ-2", "PROJ-3")
+			},
+			args:     map[string]any{"issue_key": "PROJ-1", "link_types": "blocks, duplicated"},
+			contains: []string{`{"from":"PROJ-1","to":"PROJ-2","type":"Blocks"`, `{"from":"PROJ-2","to":"PROJ-3","type":"Duplicate"`},
+			excludes: []string{"Blocker gate"},
+		},
+		{
+			name:     "partial link type name",
+			tool:     "jira_get_dependency_graph",
+			args:     map[string]any{"issue_key": "PROJ-1", "link_types": "bloc"},
+			wantErr:  true,
+			contains: []string{`unknown link type "bloc"`},
+		},
+		{
+			name:     "unknown link type",
+			tool:     "jira_get_dependency_graph",
+			args:     map[string]any{"issue_key": "PROJ-1", "link_types": "causes"},
+			wantErr:  true,
+			contains: []string{`unknown link type "causes"`, "Blocks", "epic", "all"},
+		},
+		{
+			name:     "issue key and jql",
+			tool:     "jira_get_dependency_graph",
+			args:     map[string]any{"issue_key": "PROJ-1", "jql": "project = PROJ"},
+			wantErr:  true,
+			contains: []string{"issue_key", "jql"},
+		},
+	})
+}
+
+func TestCloneAndMove(t *testing.T) {
+	withSubtask := func(t *testing.T, srv *fakeatlassian.Server) {
+		srv.Issues["PROJ-4"] = &fakeatlassian.Issue{ID: "10004", Key: "PROJ-4", Fields: map[string]any{
+			"project":   map[string]any{"id": "10000", "key": "PROJ"},
+			"summary":   "Write tests",
+			"issuetype": map[string]any{"id": "10003", "name": "Sub-task", "subtask": true},
+			"status":    map[string]any{"id": "1", "name": "To Do", "statusCategory": map[string]any{"key": "new"}},
+			"parent":    map[string]any{"key": "PROJ-3"},
+		}}
+		srv.Issues["PROJ-3"].Fields["components"] = []any{map[string]any{"id": "10100", "name": "UI"}}
+		srv.Issues["PROJ-3"].Fields["labels"] = []any{"login"}
+		srv.Link("Blocks", "PROJ-2", "PROJ-3")
+		srv.Attach("PROJ-3", "mockup.txt", []byte("remember me"))
+	}
+	wantField := func(key, field, want string) func(*testing.T, *fakeatlassian.Server, string) {
+		return func(t *testing.T, srv *fakeatlassian.Server, _ string) {
+			t.Helper()
+			issue := srv.Issues[key]
+			if issue == nil {
+				t.Fatalf("%s was not created", key)
+			}
+			got, _ := json.Marshal(issue.Fields[field])
+			if !strings.Contains(string(got), want) {
+				t.Errorf("%s %s = %s, want %s", key, field, got, want)
+			}
+		}
+	}
+	runToolTests(t, []toolTest{
+		{
+			name:     "clone in the same project",
+			tool:     "jira_clone_issue",
+			args:     map[string]any{"issue_key": "PROJ-2"},
+			contains: []string{`"source":"PROJ-2","key":"PROJ-4","issueType":"Bug"`},
+			excludes: []string{`"skipped"`},
+			check: func(t *testing.T, srv *fakeatlassian.Server, out string) {
+				wantField("PROJ-4", "summary", `"Login form rejects valid passwords"`)(t, srv, out)
+				wantField("PROJ-4", "labels", `["flaky"]`)(t, srv, out)
+				wantField("PROJ-4", "priority", `{"name":"High"}`)(t, srv, out)
+				wantField("PROJ-4", "assignee", `{"name":"asmith"}`)(t, srv, out)
+				wantField("PROJ-4", "customfield_10014", `"PROJ-1"`)(t, srv, out)
+				wantField("PROJ-4", "issuelinks", `"name":"Cloners"`)(t, srv, out)
+			},
+		},
+		{
+			name:  "clone to another project with sub-tasks, links and attachments",
+			tool:  "jira_clone_issue",
+			setup: withSubtask,
+			args: map[string]any{
+				"issue_key": "PROJ-3", "project_key": "SUP", "fields": `{"Summary": "Remember me for support"}`,
+				"include_subtasks": true, "include_links": true, "include_attachments": true,
+			},
+			contains: []string{
+				`"source":"PROJ-3","key":"SUP-1"`, `"links":1,"attachments":1`,
+				`"subtasks":[{"source":"PROJ-4","key":"SUP-2","issueType":"Sub-task"}]`,
+				`"skipped":["Component/s: UI is not available in SUP"]`,
+			},
+			check: func(t *testing.T, srv *fakeatlassian.Server, out string) {
+				wantField("SUP-1", "summary", `"Remember me for support"`)(t, srv, out)
+				wantField("SUP-1", "labels", `["login"]`)(t, srv, out)
+				wantField("SUP-1", "attachment", `"filename":"mockup.txt"`)(t, srv, out)
+				wantField("SUP-1", "issuelinks", `"inwardIssue":{"fields":{"issuetype":{"id":"10004","name":"Bug"}`)(t, srv, out)
+				wantField("SUP-2", "parent", `{"key":"SUP-1"}`)(t, srv, out)
+				wantField("SUP-2", "summary", `"Write tests"`)(t, srv, out)
+			},
+		},
+		{
+			name:     "clone without labels",
+			tool:     "jira_clone_issue",
+			args:     map[string]any{"issue_key": "PROJ-2", "include_labels": false, "link_to_original": false},
+			contains: []string{`"key":"PROJ-4"`},
+			check: func(t *testing.T, srv *fakeatlassian.Server, out string) {
+				wantField("PROJ-4", "labels", `[]`)(t, srv, out)
+				wantField("PROJ-4", "issuelinks", `null`)(t, srv, out)
+			},
+		},
+		{
+			name: "move with comments and status",
+			tool: "jira_move_issue",
+			args: map[string]any{"issue_key": "PROJ-2", "project_key": "SUP", "type_mapping": `{"Bug": "Task"}`},
+			contains: []string{
+				`"source":"PROJ-2","key":"SUP-1","issueType":"Task","status":"In Progress","statusPath":["To Do","In Progress"],"comments":2`,
+				`history, worklogs, watchers and votes stay with PROJ-2`, `"originalDeleted":false`,
+			},
+			check: func(t *testing.T, srv *fakeatlassian.Server, out string) {
+				if got := srv.Comments["SUP-1"][0]["body"]; got != "_Originally posted by John Doe on 2024-01-03T11:00:00.000+0000:_\n\nFirst comment" {
+					t.Errorf("first comment = %q", got)
+				}
+				if got := srv.Comments["PROJ-2"][2]["body"]; got != "Moved to SUP-1." {
+					t.Errorf("note on original = %q", got)
+				}
+			},
+		},
+		{
+			name:     "move to a resolved status",
+			tool:     "jira_move_issue",
+			args:     map[string]any{"issue_key": "PROJ-2", "project_key": "SUP", "status_mapping": `{"in progress": "done"}`, "delete_original": true},
+			contains: []string{`"status":"Done","statusPath":["To Do","In Progress","Done"]`, `"originalDeleted":true`},
+			check: func(t *testing.T, srv *fakeatlassian.Server, _ string) {
+				if srv.Issues["PROJ-2"] != nil {
+					t.Error("PROJ-2 was not deleted")
+				}
+			},
+		},
+		{
+			name: "move keeps the original when a sub-task is not copied",
+			tool: "jira_move_issue",
+			setup: func(t *testing.T, srv *fakeatlassian.Server) {
+				withSubtask(t, srv)
+				srv.Required["Sub-task"] = []string{"duedate"}
+			},
+			args: map[string]any{"issue_key": "PROJ-3", "project_key": "SUP", "delete_original": true},
+			contains: []string{
+				`"source":"PROJ-3","key":"SUP-1"`, `"originalDeleted":false`,
+				`"originalKept":"PROJ-3 was not deleted because not everything was copied:\n- sub-task PROJ-4: `,
+			},
+			check: func(t *testing.T, srv *fakeatlassian.Server, _ string) {
+				if srv.Issues["PROJ-3"] == nil || srv.Issues["PROJ-4"] == nil {
+					t.Error("the original or its sub-task was deleted")
+				}
+				if got := srv.Comments["PROJ-3"]; len(got) == 0 || got[len(got)-1]["body"] != "Moved to SUP-1." {
+					t.Errorf("comments on original = %v", got)
+				}
+			},
+		},
+		{
+			name:     "move to a status missing from the workflow",
+			tool:     "jira_move_issue",
+			args:     map[string]any{"issue_key": "PROJ-2", "project_key": "SUP", "status_mapping": `{"In Progress": "Closed"}`},
+			wantErr:  true,
+			contains: []string{"PROJ-2: status Closed does not exist for Bug in SUP; valid statuses: To Do, Done, In Review, In Progress"},
+			check: func(t *testing.T, srv *fakeatlassian.Server, _ string) {
+				if srv.Issues["SUP-1"] != nil {
+					t.Error("SUP-1 was created")
+				}
+			},
+		},
+		{
+			name:     "move to an unknown issue type",
+			tool:     "jira_move_issue",
+			args:     map[string]any{"issue_key": "PROJ-2", "project_key": "SUP", "issue_type": "Incident"},
+			wantErr:  true,
+			contains: []string{`invalid issue type for project SUP: no value matches "Incident"`},
+			check: func(t *testing.T, srv *fakeatlassian.Server, _ string) {
+				if srv.Issues["SUP-1"] != nil {
+					t.Error("SUP-1 was created")
+				}
+			},
+		},
+		{
+			name:     "move within the project",
+			tool:     "jira_move_issue",
+			args:     map[string]any{"issue_key": "PROJ-2", "project_key": "PROJ"},
+			wantErr:  true,
+			contains: []string{"PROJ-2 is already in project PROJ"},
+		},
+	})
+}
+
+func TestFiltersAndDashboards(t *testing.T) {
+	cloud := func(t *testing.T, srv *fakeatlassian.Server) { srv.Deployment = "Cloud" }
+	runToolTests(t, []toolTest{
+		{
+			name:     "my and favourite filters",
+			tool:     "jira_get_filters",
+			contains: []string{`"total":3`, `"name":"Open bugs"`, `"sharedWith":["project:PROJ"]`, `"name":"Support queue"`, `"sharedWith":["global"]`},
+		},
+		{
+			name:     "favourite filters",
+			tool:     "jira_get_filters",
+			args:     map[string]any{"which": "favourite"},
+			contains: []string{`"total":2`},
+			excludes: []string{"Team backlog"},
+		},
+		{
+			name:     "filter by name",
+			tool:     "jira_get_filter",
+			args:     map[string]any{"filter": "support"},
+			contains: []string{`"id":"10101"`, `"jql":"project = SUP ORDER BY created DESC"`},
+		},
+		{
+			name:     "unknown filter",
+			tool:     "jira_get_filter",
+			args:     map[string]any{"filter": "nightly"},
+			wantErr:  true,
+			contains: []string{"filter", "Open bugs", "Team backlog"},
+		},
+		{
+			name:     "run filter by name",
+			tool:     "jira_run_filter",
+			args:     map[string]any{"filter": "Team backlog", "limit": 5},
+			contains: []string{"PROJ-"},
+			check:    wantLastSearch(`project = PROJ AND status = "To Do" ORDER BY key ASC`),
+		},
+		{
+			name:  "run filter found by search on cloud",
+			tool:  "jira_run_filter",
+			setup: func(t *testing.T, srv *fakeatlassian.Server) { cloud(t, srv); srv.Filters[1]["favourite"] = false },
+			args:  map[string]any{"filter": "Support queue", "projects_filter": "SUP"},
+			check: wantLastSearch(`(project = SUP) AND project = "SUP" ORDER BY created DESC`),
+		},
+		{
+			name:     "create shared filter",
+			tool:     "jira_create_filter",
+			args:     map[string]any{"name": "My bugs", "jql": "assignee = currentUser()", "favourite": true, "share_permissions": "group:jira-users, project:PROJ:Developers, user:asmith"},
+			contains: []string{`"name":"My bugs"`, `"favourite":true`, `"sharedWith":["group:jira-users","project:PROJ:Developers","user:Alice Smith"]`},
+		},
+		{
+			name:     "create filter with an unknown role",
+			tool:     "jira_create_filter",
+			args:     map[string]any{"name": "My bugs", "jql": "assignee = currentUser()", "share_permissions": "project:PROJ:Testers"},
+			wantErr:  true,
+			contains: []string{"invalid role for project PROJ", "Developers"},
+		},
+		{
+			name:     "create filter with an existing name",
+			tool:     "jira_create_filter",
+			args:     map[string]any{"name": "Open bugs", "jql": "project = PROJ"},
+			wantErr:  true,
+			contains: []string{"Filter with same name already exists."},
+		},
+		{
+			name:     "update filter keeps its jql and makes it private",
+			tool:     "jira_update_filter",
+			args:     map[string]any{"filter": "10100", "name": "Open PROJ bugs", "share_permissions": "private"},
+			contains: []string{`"name":"Open PROJ bugs"`, `"jql":"project = PROJ AND issuetype = Bug AND resolution is EMPTY"`, `"favourite":true`},
+			excludes: []string{"sharedWith"},
+			check: func(t *testing.T, srv *fakeatlassian.Server, _ string) {
+				if got := len(srv.RequestsTo("DELETE", "/filter/10100/permission/1")); got != 1 {
+					t.Errorf("share removed %d times, want 1", got)
+				}
+			},
+		},
+		{
+			name:     "update filter removes it from favourites",
+			tool:     "jira_update_filter",
+			args:     map[string]any{"filter": "Open bugs", "favourite": false},
+			contains: []string{`"name":"Open bugs"`, `"favourite":false`, `"sharedWith":["project:PROJ"]`},
+		},
+		{
+			name:     "update another user's filter",
+			tool:     "jira_update_filter",
+			args:     map[string]any{"filter": "Support queue", "jql": "project = SUP"},
+			wantErr:  true,
+			contains: []string{"owner of the filter"},
+		},
+		{
+			name:     "update filter by exact name in another case",
+			tool:     "jira_update_filter",
+			args:     map[string]any{"filter": "team BACKLOG", "description": "Sprint planning"},
+			contains: []string{`"id":"10102"`, `"name":"Team backlog"`},
+		},
+		{
+			name:     "update filter by partial name",
+			tool:     "jira_update_filter",
+			args:     map[string]any{"filter": "Open", "jql": "project = PROJ"},
+			wantErr:  true,
+			contains: []string{`no filter is named "Open"`, "filter ID", "Open bugs"},
+			check: func(t *testing.T, srv *fakeatlassian.Server, _ string) {
+				if got := len(srv.RequestsTo("PUT", "/filter/10100")); got != 0 {
+					t.Errorf("filter updated %d times, want 0", got)
+				}
+			},
+		},
+		{
+			name:     "my dashboards",
+			tool:     "jira_get_dashboards",
+			args:     map[string]any{"which": "my"},
+			contains: []string{`"total":1`, `"name":"Login team"`},
+			excludes: []string{"Support overview"},
+		},
+		{
+			name:     "dashboard gadgets and their filters",
+			tool:     "jira_get_dashboard",
+			setup:    cloud,
+			args:     map[string]any{"dashboard_id": "10000"},
+			contains: []string{`"title":"Open bugs"`, `"filter":{"id":"10100"`, `"title":"Backlog by assignee"`, `"filter":{"id":"10102","name":"Team backlog"`, `"title":"Welcome"`},
+			check: func(t *testing.T, srv *fakeatlassian.Server, _ string) {
+				if got := len(srv.RequestsTo("GET", "/filter/10100")); got != 1 {
+					t.Errorf("filter 10100 fetched %d times, want 1", got)
+				}
+			},
+		},
+		{
+			name:     "dashboard gadgets on server",
+			tool:     "jira_get_dashboards",
+			args:     map[string]any{"include_gadgets": true},
+			contains: []string{`"total":2`, "only Jira Cloud does"},
+		},
+	})
+}
+
+func TestJQLTools(t *testing.T) {
+	runToolTests(t, []toolTest{
+		{
+			name:     "valid jql is normalised",
+			tool:     "jira_validate_jql",
+			args:     map[string]any{"jql": `"story points" > 3 and status was 'In Progress' order by cf[10016] desc`},
+			contains: []string{`"valid":true`, `"jql":"\"story points\" > 3 AND status was \"In Progress\" ORDER BY cf[10016] DESC"`},
+		},
+		{
+			name:     "syntax error",
+			tool:     "jira_validate_jql",
+			args:     map[string]any{"jql": "project = PROJ AND status = In Review"},
+			contains: []string{`"valid":false`, `{"position":29,"message":"expected a value after =, found the keyword IN","suggestions":["\"In Review\""]}`},
+		},
+		{
+			name: "unknown field, operator and function",
+			tool: "jira_validate_jql",
+			args: map[string]any{"jql": "asignee = currentUsr() AND summary = login ORDER BY text"},
+			contains: []string{
+				`{"position":1,"message":"unknown field asignee","suggestions":["assignee"]}`,
+				`{"position":11,"message":"unknown function currentUsr()","suggestions":["currentUser()"]}`,
+				`{"position":36,"message":"operator = cannot be used with summary; it supports ~, !~, is, is not","suggestions":["~"]}`,
+				`{"position":53,"message":"cannot order by text"}`,
+			},
+		},
+		{
+			name:     "search explains an unknown field Jira rejects",
+			tool:     "jira_search",
+			args:     map[string]any{"jql": "stauts = Done"},
+			wantErr:  true,
+			contains: []string{"Field 'stauts' does not exist", "Possible causes:\n- position 1: unknown field stauts; did you mean status?"},
+			check:    wantLastSearch("stauts = Done"),
+		},
+		{
+			name:     "search runs app fields missing from the reference data",
+			tool:     "jira_search",
+			setup:    func(t *testing.T, srv *fakeatlassian.Server) { srv.AppFields = []string{"issueFunction"} },
+			args:     map[string]any{"jql": `project = PROJ AND issueFunction in hasComments()`},
+			contains: []string{`"total":3`},
+			check:    wantLastSearch(`project = PROJ AND issueFunction in hasComments()`),
+		},
+		{
+			name:     "search rejects a syntax error before calling Jira",
+			tool:     "jira_search",
+			args:     map[string]any{"jql": "status = In Review"},
+			wantErr:  true,
+			contains: []string{"Invalid JQL:\n- position 10: expected a value after =, found the keyword IN"},
+			check: func(t *testing.T, srv *fakeatlassian.Server, _ string) {
+				if len(srv.Searches) != 0 {
+					t.Errorf("searches = %q, want none", srv.Searches)
+				}
+			},
+		},
+		{
+			name:  "projects filter keeps ORDER BY last",
+			tool:  "jira_search",
+			args:  map[string]any{"jql": "labels = flaky ORDER BY key DESC", "projects_filter": "PROJ"},
+			check: wantLastSearch(`(labels = flaky) AND project = "PROJ" ORDER BY key DESC`),
+		},
+		{
+			name:     "suggest fields",
+			tool:     "jira_jql_autocomplete",
+			args:     map[string]any{"value": "story"},
+			contains: []string{`{"displayName":"Story Points - cf[10016]","name":"Story Points","operators":["=","!=",">",">=","<","<=","in","not in","is","is not"],"orderable":true}`},
+		},
+		{
+			name:     "suggest values",
+			tool:     "jira_jql_autocomplete",
+			args:     map[string]any{"field": "Status", "value": "in"},
+			contains: []string{`{"clause":"status = \"In Progress\"","displayName":"In Progress","value":"In Progress"}`, `"value":"In Review"`},
+			excludes: []string{`"value":"Done"`},
+		},
+		{
+			name:     "build jql from criteria",
+			tool:     "jira_build_jql",
+			args:     map[string]any{"project": "PROJ", "status": "To Do, In Progress", "assignee": "me, Alice, unassigned", "text": `say "hi"`, "created": "last 7 days", "unresolved": true, "order_by": "priority DESC"},
+			contains: []string{`"jql":"project = \"PROJ\" AND status in (\"To Do\", \"In Progress\") AND (assignee is EMPTY OR assignee in (currentUser(), \"asmith\")) AND text ~ \"say \\\"hi\\\"\" AND created >= \"-7d\" AND resolution is EMPTY ORDER BY priority DESC"`},
+		},
+		{
+			name:     "build jql keeps the ORDER BY of extra jql",
+			tool:     "jira_build_jql",
+			args:     map[string]any{"jql": "labels = flaky ORDER BY key", "updated": "2024-03-01"},
+			contains: []string{`"jql":"labels = flaky AND updated >= \"2024-03-01\" AND updated < \"2024-03-02\" ORDER BY key"`},
+		},
+		{
+			name:     "build jql with an unknown period",
+			tool:     "jira_build_jql",
+			args:     map[string]any{"created": "fortnight"},
+			wantErr:  true,
+			contains: []string{`created: unknown period "fortnight"`},
+		},
+	})
+}
+
+func TestPagination(t *testing.T) {
+	type page struct {
+		Issues     []struct{ Key string } `json:"issues"`
+		Total      int                    `json:"total"`
+		Returned   int                    `json:"returned"`
+		IsLast     bool                   `json:"isLast"`
+		NextCursor string                 `json:"nextCursor"`
+		Note       string                 `json:"note"`
+	}
+	call := func(t *testing.T, c *client.Client, tool string, args map[string]any) page {
+		t.Helper()
+		out, isErr := fakeatlassian.CallTool(t, c, tool, args)
+		if isErr {
+			t.Fatalf("%s failed: %s", tool, out)
+		}
+		var p page
+		if err := json.Unmarshal([]byte(out), &p); err != nil {
+			t.Fatalf("%s: %v\n%s", tool, err, out)
+		}
+		return p
+	}
+	keys := func(p page) string {
+		var keys []string
+		for _, issue := range p.Issues {
+			keys = append(keys, issue.Key)
+		}
+		return strings.Join(keys, ",")
+	}
+
+	t.Run("cursor continues the search", func(t *testing.T) {
+		fakeatlassian.New(t)
+		c := fakeatlassian.NewMCPClient(t, AddTools)
+		args := map[string]any{"jql": "project = PROJ ORDER BY key", "limit": 2}
+		first := call(t, c, "jira_search", args)
+		if keys(first) != "PROJ-1,PROJ-2" || first.Total != 3 || first.IsLast || first.NextCursor == "" {
+			t.Fatalf("first page = %+v", first)
+		}
+		args["cursor"] = first.NextCursor
+		second := call(t, c, "jira_search", args)
+		if keys(second) != "PROJ-3" || second.Total != 3 || !second.IsLast || second.NextCursor != "" {
+			t.Fatalf("second page = %+v", second)
+		}
+		out, isErr := fakeatlassian.CallTool(t, c, "jira_search", map[string]any{"jql": "project = SUP", "cursor": first.NextCursor})
+		if !isErr || !strings.Contains(out, "cursor belongs to a different query") {
+			t.Errorf("cursor of another query: %s", out)
+		}
+		out, isErr = fakeatlassian.CallTool(t, c, "jira_search", map[string]any{"jql": "project = SUP", "cursor": "nonsense"})
+		if !isErr || !strings.Contains(out, "invalid cursor") {
+			t.Errorf("invalid cursor: %s", out)
+		}
+	})
+
+	t.Run("fetch all", func(t *testing.T) {
+		srv := fakeatlassian.New(t)
+		c := fakeatlassian.NewMCPClient(t, AddTools)
+		all := call(t, c, "jira_get_project_issues", map[string]any{"project_key": "PROJ", "fetch_all": true})
+		if all.Returned != 3 || all.Total != 3 || !all.IsLast {
+			t.Fatalf("fetch_all = %+v", all)
+		}
+		sprint := call(t, c, "jira_get_sprint_issues", map[string]any{"sprint_id": 100, "limit": 1})
+		if keys(sprint) != "PROJ-2" || sprint.Total != 2 || sprint.NextCursor == "" {
+			t.Fatalf("sprint page = %+v", sprint)
+		}
+		board := call(t, c, "jira_get_board_issues", map[string]any{"board_id": 1, "jql": "", "limit": 1, "fetch_all": true})
+		if board.Returned != board.Total || !board.IsLast {
+			t.Fatalf("board fetch_all = %+v", board)
+		}
+
+		cfg := *config.Current()
+		cfg.Limits.MaxResults, cfg.Limits.MaxFetchAll = 1, 2
+		config.Set(&cfg)
+		t.Cleanup(func() { config.Set(nil) })
+		srv.Searches = nil
+		capped := call(t, c, "jira_search", map[string]any{"jql": "project = PROJ", "fetch_all": true})
+		if capped.Returned != 2 || capped.Total != 3 || capped.IsLast || capped.NextCursor == "" || !strings.Contains(capped.Note, "limits.max_fetch_all") {
+			t.Fatalf("capped fetch_all = %+v", capped)
+		}
+		if len(srv.Searches) != 2 {
+			t.Errorf("searches = %q, want 2 pages", srv.Searches)
+		}
+	})
+}
+
+func TestIssueListFormats(t *testing.T) {
+	wantFields := func(want ...string) func(*testing.T, *fakeatlassian.Server, string) {
+		return func(t *testing.T, srv *fakeatlassian.Server, _ string) {
+			t.Helper()
+			var payload struct{ Fields []string }
+			reqs := srv.RequestsTo("POST", "/search")
+			if len(reqs) == 0 || json.Unmarshal([]byte(reqs[len(reqs)-1].Body), &payload) != nil {
+				t.Fatalf("no search request: %v", reqs)
+			}
+			if !slices.Equal(payload.Fields, want) {
+				t.Errorf("fields = %q, want %q", payload.Fields, want)
+			}
+		}
+	}
+	runToolTests(t, []toolTest{
+		{
+			name: "compact",
+			tool: "jira_search",
+			args: map[string]any{"jql": "project = PROJ ORDER BY key", "format": "compact", "limit": 2},
+			contains: []string{
+				`{"assignee":"John Doe","key":"PROJ-1","priority":"Medium","status":"To Do","summary":"Epic for the login revamp","type":"Epic","updated":"2024-01-02T10:00:00.000+0000"}`,
+				`"total":3`, `"returned":2`, `"nextCursor":`,
+			},
+			excludes: []string{`"fields"`, "statusCategory"},
+			check:    wantFields("issuetype", "status", "priority", "assignee", "summary", "updated"),
+		},
+		{
+			name: "markdown table with chosen columns",
+			tool: "jira_get_project_issues",
+			args: map[string]any{"project_key": "PROJ", "format": "markdown_table", "columns": "key, Story Points, labels"},
+			contains: []string{
+				"| key | Story Points | labels |\n| --- | --- | --- |\n",
+				"| PROJ-2 |  | flaky |\n",
+				"| PROJ-3 | 3 |  |\n",
+				"Issues 1-3 of 3.",
+			},
+			check: wantFields("customfield_10016", "labels"),
+		},
+		{
+			name:     "csv",
+			tool:     "jira_get_sprint_issues",
+			args:     map[string]any{"sprint_id": 100, "format": "csv", "columns": "key,summary,assignee", "limit": 1},
+			contains: []string{"key,summary,assignee\nPROJ-2,Login form rejects valid passwords,Alice Smith\n\nIssues 1-1 of 2. Pass cursor"},
+		},
+		{
+			name:     "unknown format",
+			tool:     "jira_get_board_issues",
+			args:     map[string]any{"board_id": 1, "jql": "", "format": "yaml"},
+			wantErr:  true,
+			contains: []string{`Invalid format "yaml": must be json, compact, markdown_table, csv`},
+		},
+	})
+}
+
+func TestSprintLifecycle(t *testing.T) {
+	wantSprintIssues := func(id int, want ...string) func(*testing.T, *fakeatlassian.Server, string) {
+		return func(t *testing.T, srv *fakeatlassian.Server, _ string) {
+			t.Helper()
+			if got := srv.SprintIssue[id]; !slices.Equal(got, want) {
+				t.Errorf("sprint %d issues = %q, want %q", id, got, want)
+			}
+		}
+	}
+	wantRank := func(want ...string) func(*testing.T, *fakeatlassian.Server, string) {
+		return func(t *testing.T, srv *fakeatlassian.Server, _ string) {
+			t.Helper()
+			if got := srv.Rank[:min(len(want), len(srv.Rank))]; !slices.Equal(got, want) {
+				t.Errorf("rank = %q, want %q first", srv.Rank, want)
+			}
+		}
+	}
+	markDone := func(t *testing.T, srv *fakeatlassian.Server) {
+		srv.Issues["PROJ-2"].Fields["status"] = map[string]any{"id": "10001", "name": "Done", "statusCategory": map[string]any{"key": "done"}}
+	}
+	runToolTests(t, []toolTest{
+		{
+			name: "backlog in rank order",
+			tool: "jira_get_board_backlog",
+			args: map[string]any{"board_id": 1, "format": "compact", "columns": "key"},
+			setup: func(t *testing.T, srv *fakeatlassian.Server) {
+				srv.SprintIssue[100] = []string{"PROJ-2"}
+				srv.Rank = []string{"PROJ-3", "PROJ-1"}
+			},
+			contains: []string{`"issues":[{"key":"PROJ-3"},{"key":"PROJ-1"}]`, `"total":2`},
+		},
+		{
+			name: "move to sprint ranked before an issue",
+			tool: "jira_move_issues_to_sprint",
+			args: map[string]any{"sprint_id": 101, "issue_keys": "PROJ-3", "rank_before": "PROJ-1"},
+			setup: func(t *testing.T, srv *fakeatlassian.Server) {
+				srv.SprintIssue[101] = []string{"PROJ-1"}
+			},
+			contains: []string{`"moved":["PROJ-3"]`, `"name":"Sprint 2"`},
+			check: func(t *testing.T, srv *fakeatlassian.Server, out string) {
+				wantSprintIssues(100, "PROJ-2")(t, srv, out)
+				wantSprintIssues(101, "PROJ-1", "PROJ-3")(t, srv, out)
+				wantRank("PROJ-3", "PROJ-1")(t, srv, out)
+			},
+		},
+		{
+			name: "move to closed sprint",
+			tool: "jira_move_issues_to_sprint",
+			args: map[string]any{"sprint_id": 101, "issue_keys": "PROJ-1"},
+			setup: func(t *testing.T, srv *fakeatlassian.Server) {
+				srv.Sprints[101]["state"] = "closed"
+			},
+			wantErr:  true,
+			contains: []string{"Sprint 101 (Sprint 2) is closed; it must be active or future"},
+		},
+		{
+			name:     "move to backlog",
+			tool:     "jira_move_issues_to_backlog",
+			args:     map[string]any{"issue_keys": "PROJ-2", "rank_after": "PROJ-1"},
+			contains: []string{`"moved":["PROJ-2"]`},
+			check: func(t *testing.T, srv *fakeatlassian.Server, out string) {
+				wantSprintIssues(100, "PROJ-3")(t, srv, out)
+				wantRank("PROJ-1", "PROJ-2", "PROJ-3")(t, srv, out)
+			},
+		},
+		{
+			name:     "rank after",
+			tool:     "jira_rank_issues",
+			args:     map[string]any{"issue_keys": "PROJ-3,PROJ-1", "rank_after": "PROJ-2"},
+			contains: []string{`"ranked":["PROJ-3","PROJ-1"]`, `"after":"PROJ-2"`},
+			check:    wantRank("PROJ-2", "PROJ-3", "PROJ-1"),
+		},
+		{
+			name:     "rank needs a target",
+			tool:     "jira_rank_issues",
+			args:     map[string]any{"issue_keys": "PROJ-3"},
+			wantErr:  true,
+			contains: []string{"Set one of rank_before and rank_after"},
+		},
+		{
+			name:     "start while another sprint is active",
+			tool:     "jira_start_sprint",
+			args:     map[string]any{"sprint_id": 101},
+			wantErr:  true,
+			contains: []string{"Another sprint is already active on this board"},
+		},
+		{
+			name: "start with duration",
+			tool: "jira_start_sprint",
+			args: map[string]any{"sprint_id": 101, "start_date": "2024-03-01", "duration": "2w", "goal": "Ship signup"},
+			setup: func(t *testing.T, srv *fakeatlassian.Server) {
+				srv.Sprints[100]["state"] = "closed"
+			},
+			contains: []string{`"state":"active"`, `"startDate":"2024-03-01T00:00:00.000Z"`, `"endDate":"2024-03-15T00:00:00.000Z"`, `"goal":"Ship signup"`},
+		},
+		{
+			name:  "complete and start next",
+			tool:  "jira_complete_sprint",
+			args:  map[string]any{"sprint_id": 100, "start_next": true},
+			setup: markDone,
+			contains: []string{
+				`"completed":[{"key":"PROJ-2","summary":"Login form rejects valid passwords","status":"Done"}]`,
+				`"carriedOver":[{"key":"PROJ-3"`, `"movedTo":{"id":101`, `"started":{"id":101,"name":"Sprint 2","state":"active"`,
+			},
+			check: func(t *testing.T, srv *fakeatlassian.Server, out string) {
+				wantSprintIssues(101, "PROJ-3")(t, srv, out)
+				if srv.Sprints[100]["state"] != "closed" || srv.Sprints[101]["state"] != "active" {
+					t.Errorf("states = %v, %v, want closed, active", srv.Sprints[100]["state"], srv.Sprints[101]["state"])
+				}
+			},
+		},
+		{
+			name:     "complete dry run",
+			tool:     "jira_complete_sprint",
+			args:     map[string]any{"sprint_id": 100, "dry_run": true},
+			contains: []string{`"dryRun":true`, `"completed":[]`, `"carriedOver":[{"key":"PROJ-2"`},
+			check: func(t *testing.T, srv *fakeatlassian.Server, out string) {
+				wantSprintIssues(100, "PROJ-2", "PROJ-3")(t, srv, out)
+				if srv.Sprints[100]["state"] != "active" {
+					t.Errorf("state = %v, want active", srv.Sprints[100]["state"])
+				}
+			},
+		},
+		{
+			name: "complete without a future sprint",
+			tool: "jira_complete_sprint",
+			args: map[string]any{"sprint_id": 100, "start_next": true},
+			setup: func(t *testing.T, srv *fakeatlassian.Server) {
+				delete(srv.Sprints, 101)
+			},
+			contains: []string{`"movedTo":"backlog"`, "the board has no future sprint", "no sprint is started"},
+			check: func(t *testing.T, srv *fakeatlassian.Server, out string) {
+				// The closed sprint keeps its issues so the sprint report lists them as not completed.
+				wantSprintIssues(100, "PROJ-2", "PROJ-3")(t, srv, out)
+				if srv.Sprints[100]["state"] != "closed" {
+					t.Errorf("state = %v, want closed", srv.Sprints[100]["state"])
+				}
+				closed, moved := -1, -1
+				for i, r := range srv.Requests {
+					switch {
+					case r.Method == "POST" && strings.HasSuffix(r.Path, "/sprint/100"):
+						closed = i
+					case r.Method == "POST" && strings.HasSuffix(r.Path, "/backlog/issue"):
+						moved = i
+						if !strings.Contains(r.Body, `"PROJ-2"`) || !strings.Contains(r.Body, `"PROJ-3"`) {
+							t.Errorf("backlog move = %s, want PROJ-2 and PROJ-3", r.Body)
+						}
+					}
+				}
+				if closed < 0 || moved < closed {
+					t.Errorf("close at request %d, backlog move at %d; want the sprint closed first", closed, moved)
+				}
+			},
+		},
+		{
+			name:     "complete a future sprint",
+			tool:     "jira_complete_sprint",
+			args:     map[string]any{"sprint_id": 101},
+			wantErr:  true,
+			contains: []string{"Sprint 101 (Sprint 2) is future; it must be active"},
+		},
+	})
+}